@@ -104,6 +104,63 @@ func (c *Sys) DeletePolicy(name string) error {
 	return err
 }
 
+func (c *Sys) SimulatePolicy(input *PolicySimulateInput) (*PolicySimulateOutput, error) {
+	r := c.c.NewRequest("PUT", "/v1/sys/policies/simulate")
+	if err := r.SetJSONBody(input); err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("data from server response is empty")
+	}
+
+	var result PolicySimulateOutput
+	if err := mapstructure.Decode(secret.Data, &result); err != nil {
+		return nil, err
+	}
+	result.Warnings = secret.Warnings
+
+	return &result, nil
+}
+
+type PolicySimulateInput struct {
+	Policies       []string               `json:"policies,omitempty"`
+	InlinePolicies []string               `json:"inline_policies,omitempty"`
+	Path           string                 `json:"path"`
+	Operation      string                 `json:"operation,omitempty"`
+	Parameters     map[string]interface{} `json:"parameters,omitempty"`
+	WrapTTL        string                 `json:"wrap_ttl,omitempty"`
+	RemoteAddr     string                 `json:"remote_addr,omitempty"`
+	TokenMetadata  map[string]string      `json:"token_metadata,omitempty"`
+	EntityMetadata map[string]string      `json:"entity_metadata,omitempty"`
+	GroupMetadata  map[string]string      `json:"group_metadata,omitempty"`
+	MFAValidated   bool                   `json:"mfa_validated,omitempty"`
+}
+
+type PolicySimulateResult struct {
+	Allowed      bool     `mapstructure:"allowed"`
+	MatchedPath  string   `mapstructure:"matched_path"`
+	Capabilities []string `mapstructure:"capabilities"`
+}
+
+type PolicySimulateOutput struct {
+	PolicySimulateResult `mapstructure:",squash"`
+	Policies             map[string]*PolicySimulateResult `mapstructure:"policies"`
+	Warnings             []string                         `mapstructure:"-"`
+}
+
 type getPoliciesResp struct {
 	Rules string `json:"rules"`
 }
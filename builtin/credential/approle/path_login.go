@@ -295,6 +295,8 @@ func (b *backend) pathLoginUpdate(ctx context.Context, req *logical.Request, dat
 	// Allow for overridden token bound CIDRs
 	auth.BoundCIDRs = tokenBoundCIDRs
 
+	auth.BindClientCert = role.TokenBindClientCert
+
 	return &logical.Response{
 		Auth: auth,
 	}, nil
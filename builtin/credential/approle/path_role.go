@@ -76,6 +76,10 @@ type roleStorageEntry struct {
 	// SecretIDPrefix is the storage prefix for persisting secret IDs. This
 	// differs based on whether the secret IDs are cluster local or not.
 	SecretIDPrefix string `json:"secret_id_prefix" mapstructure:"secret_id_prefix"`
+
+	// TokenBindClientCert, if set, binds the tokens issued using this role
+	// to the TLS client certificate presented during login
+	TokenBindClientCert bool `json:"token_bind_client_cert" mapstructure:"token_bind_client_cert"`
 }
 
 // roleIDStorageEntry represents the reverse mapping from RoleID to Role
@@ -166,6 +170,12 @@ to 0, meaning no expiration.`,
 				Description: `If set, the secret IDs generated using this role will be cluster local. This
 can only be set during role creation and once set, it can't be reset later.`,
 			},
+
+			"token_bind_client_cert": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `If set, tokens issued using this role can only be used by
+clients presenting the TLS client certificate used to log in.`,
+			},
 		},
 		ExistenceCheck: b.pathRoleExistenceCheck,
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -936,6 +946,10 @@ func (b *backend) pathRoleCreateUpdate(ctx context.Context, req *logical.Request
 		role.SecretIDBoundCIDRs = boundCIDRListRaw.([]string)
 	}
 
+	if bindClientCertRaw, ok := data.GetOk("token_bind_client_cert"); ok {
+		role.TokenBindClientCert = bindClientCertRaw.(bool)
+	}
+
 	if len(role.SecretIDBoundCIDRs) != 0 {
 		valid, err := cidrutil.ValidateCIDRListSlice(role.SecretIDBoundCIDRs)
 		if err != nil {
@@ -1008,11 +1022,12 @@ func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, data *
 	}
 
 	respData := map[string]interface{}{
-		"bind_secret_id":        role.BindSecretID,
-		"secret_id_bound_cidrs": role.SecretIDBoundCIDRs,
-		"secret_id_num_uses":    role.SecretIDNumUses,
-		"secret_id_ttl":         role.SecretIDTTL / time.Second,
-		"local_secret_ids":      false,
+		"bind_secret_id":         role.BindSecretID,
+		"secret_id_bound_cidrs":  role.SecretIDBoundCIDRs,
+		"secret_id_num_uses":     role.SecretIDNumUses,
+		"secret_id_ttl":          role.SecretIDTTL / time.Second,
+		"local_secret_ids":       false,
+		"token_bind_client_cert": role.TokenBindClientCert,
 	}
 	role.PopulateTokenData(respData)
 
@@ -1405,6 +1405,72 @@ func TestBackend_validCIDR(t *testing.T) {
 	}
 }
 
+func TestBackend_bindClientCert(t *testing.T) {
+	config := logical.TestBackendConfig()
+	storage := &logical.InmemStorage{}
+	config.StorageView = storage
+
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	connState, err := testConnState("test-fixtures/keys/cert.pem",
+		"test-fixtures/keys/key.pem", "test-fixtures/root/rootcacert.pem")
+	if err != nil {
+		t.Fatalf("error testing connection state: %v", err)
+	}
+	ca, err := ioutil.ReadFile("test-fixtures/root/rootcacert.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bind := range []bool{false, true} {
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "certs/web",
+			Data: map[string]interface{}{
+				"certificate":            string(ca),
+				"token_policies":         "foo",
+				"token_bind_client_cert": bind,
+			},
+			Storage: storage,
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+		}
+
+		resp, err = b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.ReadOperation,
+			Path:      "certs/web",
+			Storage:   storage,
+		})
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+		}
+		if resp.Data["token_bind_client_cert"] != bind {
+			t.Fatalf("expected token_bind_client_cert to be %t, got %v", bind, resp.Data["token_bind_client_cert"])
+		}
+
+		resp, err = b.HandleRequest(context.Background(), &logical.Request{
+			Operation:       logical.UpdateOperation,
+			Path:            "login",
+			Unauthenticated: true,
+			Data: map[string]interface{}{
+				"name": "web",
+			},
+			Storage:    storage,
+			Connection: &logical.Connection{ConnState: &connState},
+		})
+		if err != nil || resp == nil || resp.Auth == nil {
+			t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+		}
+		if resp.Auth.BindClientCert != bind {
+			t.Fatalf("expected auth to bind the client certificate: %t", bind)
+		}
+	}
+}
+
 func TestBackend_invalidCIDR(t *testing.T) {
 	config := logical.TestBackendConfig()
 	storage := &logical.InmemStorage{}
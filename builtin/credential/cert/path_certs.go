@@ -114,6 +114,16 @@ formatted as "oid:value". Expects the extension value to be some type of ASN1 en
 All values much match. Supports globbing on "value".`,
 			},
 
+			"token_bind_client_cert": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `If set, tokens issued by logging in with this
+certificate can only be used by clients presenting the same certificate.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Bind Tokens to Client Certificate",
+					Group: "Tokens",
+				},
+			},
+
 			"display_name": &framework.FieldSchema{
 				Type: framework.TypeString,
 				Description: `The display name to use for clients using this
@@ -243,6 +253,7 @@ func (b *backend) pathCertRead(ctx context.Context, req *logical.Request, d *fra
 		"allowed_uri_sans":             cert.AllowedURISANs,
 		"allowed_organizational_units": cert.AllowedOrganizationalUnits,
 		"required_extensions":          cert.RequiredExtensions,
+		"token_bind_client_cert":       cert.TokenBindClientCert,
 	}
 	cert.PopulateTokenData(data)
 
@@ -309,6 +320,9 @@ func (b *backend) pathCertWrite(ctx context.Context, req *logical.Request, d *fr
 	if requiredExtensionsRaw, ok := d.GetOk("required_extensions"); ok {
 		cert.RequiredExtensions = requiredExtensionsRaw.([]string)
 	}
+	if bindClientCertRaw, ok := d.GetOk("token_bind_client_cert"); ok {
+		cert.TokenBindClientCert = bindClientCertRaw.(bool)
+	}
 
 	// Get tokenutil fields
 	if err := cert.ParseTokenFields(req, d); err != nil {
@@ -425,6 +439,7 @@ type CertEntry struct {
 	AllowedOrganizationalUnits []string
 	RequiredExtensions         []string
 	BoundCIDRs                 []*sockaddr.SockAddrMarshaler
+	TokenBindClientCert        bool
 }
 
 const pathCertHelpSyn = `
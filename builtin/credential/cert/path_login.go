@@ -107,6 +107,7 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *fra
 		},
 	}
 	matched.Entry.PopulateTokenAuth(auth)
+	auth.BindClientCert = matched.Entry.TokenBindClientCert
 
 	return &logical.Response{
 		Auth: auth,
@@ -2,12 +2,14 @@ package github
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/go-github/github"
 	cleanhttp "github.com/hashicorp/go-cleanhttp"
 	"github.com/hashicorp/vault/helper/mfa"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	cache "github.com/patrickmn/go-cache"
 	"golang.org/x/oauth2"
 )
 
@@ -35,7 +37,18 @@ func Backend() *backend {
 		DefaultKey: "default",
 	}
 
+	b.InstallationMap = &framework.PolicyMap{
+		PathMap: framework.PathMap{
+			Name: "installations",
+		},
+		DefaultKey: "default",
+	}
+
+	b.verifyCache = cache.New(cache.NoExpiration, time.Minute)
+
 	allPaths := append(b.TeamMap.Paths(), b.UserMap.Paths()...)
+	allPaths = append(allPaths, b.InstallationMap.Paths()...)
+	allPaths = append(allPaths, pathOrgTeamMap(&b)...)
 	b.Backend = &framework.Backend{
 		Help: backendHelp,
 
@@ -50,6 +63,7 @@ func Backend() *backend {
 			pathConfig(&b),
 		}, append(allPaths, mfa.MFAPaths(b.Backend, pathLogin(&b))...)...),
 		AuthRenew:   b.pathLoginRenew,
+		Invalidate:  b.invalidate,
 		BackendType: logical.TypeCredential,
 	}
 
@@ -62,6 +76,19 @@ type backend struct {
 	TeamMap *framework.PolicyMap
 
 	UserMap *framework.PolicyMap
+
+	InstallationMap *framework.PolicyMap
+
+	// verifyCache holds what GitHub returned when verifying a token, keyed
+	// by a hash of the token.
+	verifyCache *cache.Cache
+}
+
+func (b *backend) invalidate(_ context.Context, key string) {
+	switch key {
+	case "config":
+		b.verifyCache.Flush()
+	}
 }
 
 // Client returns the GitHub client to communicate to GitHub via the
@@ -89,9 +116,11 @@ const backendHelp = `
 The GitHub credential provider allows authentication via GitHub.
 
 Users provide a personal access token to log in, and the credential
-provider verifies they're part of the correct organization and then
-maps the user to a set of Vault policies according to the teams they're
-part of.
+provider verifies they're part of one of the configured organizations
+and then maps the user to a set of Vault policies according to the teams
+they're part of. Allowed GitHub Apps can log in as one of their
+installations with a JWT of the app, and are mapped to policies according
+to the organization of the installation.
 
 After enabling the credential provider, use the "config" route to
 configure it.
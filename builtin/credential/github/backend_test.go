@@ -3,8 +3,13 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -204,3 +209,327 @@ func testAccLogin(t *testing.T, policies []string) logicaltest.TestStep {
 		Check: logicaltest.TestCheckAuth(policies),
 	}
 }
+
+// testGitHubServer fakes the parts of the GitHub API used by the backend. The
+// user "alice" logs in with "user-token". App 7 signs "app-jwt", and app 8
+// "other-app-jwt": both are installed in "org-b", as installations 42 and 43,
+// whose tokens are "installation-token" and "other-installation-token".
+// requests counts the calls made to it.
+type testGitHubServer struct {
+	*httptest.Server
+
+	requests int32
+}
+
+func newTestGitHubServer(t *testing.T) *testGitHubServer {
+	s := &testGitHubServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.requests, 1)
+
+		auth := r.Header.Get("Authorization")
+		var body string
+		switch {
+		case auth == "Bearer user-token" && r.URL.Path == "/user":
+			body = `{"login": "alice", "id": 10}`
+		case auth == "Bearer user-token" && r.URL.Path == "/user/orgs":
+			body = `[
+				{"login": "Org-A", "id": 1},
+				{"login": "org-b", "id": 2},
+				{"login": "org-c", "id": 3}
+			]`
+		case auth == "Bearer user-token" && r.URL.Path == "/user/teams":
+			body = `[
+				{"name": "Core Team", "slug": "core-team", "organization": {"login": "Org-A", "id": 1}},
+				{"name": "ops", "slug": "ops", "organization": {"login": "org-b", "id": 2}},
+				{"name": "admins", "slug": "admins", "organization": {"login": "org-c", "id": 3}}
+			]`
+		case auth == "Bearer app-jwt" && r.Method == "GET" && r.URL.Path == "/app/installations/42":
+			body = `{"id": 42, "app_id": 7, "account": {"login": "org-b", "id": 2}}`
+		case auth == "Bearer app-jwt" && r.Method == "POST" && r.URL.Path == "/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			body = `{"token": "installation-token"}`
+		case auth == "Bearer other-app-jwt" && r.Method == "GET" && r.URL.Path == "/app/installations/43":
+			body = `{"id": 43, "app_id": 8, "account": {"login": "org-b", "id": 2}}`
+		case auth == "Bearer other-app-jwt" && r.Method == "POST" && r.URL.Path == "/app/installations/43/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			body = `{"token": "other-installation-token"}`
+		case (auth == "Bearer installation-token" || auth == "Bearer other-installation-token") && r.URL.Path == "/installation/repositories":
+			body = `{"total_count": 1, "repositories": [{"name": "app", "owner": {"login": "org-b", "id": 2}}]}`
+		case strings.HasPrefix(auth, "Bearer ") && strings.HasPrefix(r.URL.Path, "/app/installations/"):
+			w.WriteHeader(http.StatusNotFound)
+			body = `{"message": "Not Found"}`
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			body = `{"message": "Bad credentials"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	return s
+}
+
+func testFakeBackend(t *testing.T) (*backend, logical.Storage) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b := Backend()
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return b, config.StorageView
+}
+
+func testWrite(t *testing.T, b *backend, s logical.Storage, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      path,
+		Storage:   s,
+		Data:      data,
+		Connection: &logical.Connection{
+			RemoteAddr: "127.0.0.1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("error writing %q: %v", path, err)
+	}
+	return resp
+}
+
+func testLoginPolicies(t *testing.T, resp *logical.Response, expected []string) {
+	t.Helper()
+	if resp == nil || resp.IsError() || resp.Auth == nil {
+		t.Fatalf("bad login response: %#v", resp)
+	}
+	policies := append([]string(nil), resp.Auth.Policies...)
+	sort.Strings(policies)
+	sort.Strings(expected)
+	if !reflect.DeepEqual(policies, expected) {
+		t.Fatalf("expected policies %v, got %v", expected, policies)
+	}
+}
+
+func TestBackend_MultipleOrgs(t *testing.T) {
+	server := newTestGitHubServer(t)
+	defer server.Close()
+	b, s := testFakeBackend(t)
+
+	testWrite(t, b, s, "config", map[string]interface{}{
+		"organization":  "org-a",
+		"organizations": "org-b",
+		"base_url":      server.URL,
+	})
+	// Unqualified teams only match within the legacy organization
+	testWrite(t, b, s, "map/teams/core-team", map[string]interface{}{"value": "core"})
+	testWrite(t, b, s, "map/teams/ops", map[string]interface{}{"value": "unqualified-ops"})
+	testWrite(t, b, s, "map/teams/org-b/ops", map[string]interface{}{"value": "ops"})
+	testWrite(t, b, s, "map/teams/org-c/admins", map[string]interface{}{"value": "admins"})
+	testWrite(t, b, s, "map/users/alice", map[string]interface{}{"value": "alice"})
+
+	resp := testWrite(t, b, s, "login", map[string]interface{}{"token": "user-token"})
+	testLoginPolicies(t, resp, []string{"core", "ops", "alice"})
+	if resp.Auth.Alias.Name != "alice" {
+		t.Fatalf("bad alias: %q", resp.Auth.Alias.Name)
+	}
+	if resp.Auth.Metadata["org"] != "Org-A,org-b" {
+		t.Fatalf("bad org metadata: %q", resp.Auth.Metadata["org"])
+	}
+	var groups []string
+	for _, alias := range resp.Auth.GroupAliases {
+		groups = append(groups, alias.Name)
+	}
+	expectedGroups := []string{"Core Team", "core-team", "Org-A/core-team", "org-b/ops"}
+	if !reflect.DeepEqual(groups, expectedGroups) {
+		t.Fatalf("expected group aliases %v, got %v", expectedGroups, groups)
+	}
+
+	listResp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ListOperation,
+		Path:      "map/teams/org-b/",
+		Storage:   s,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := listResp.Data["keys"].([]string); !reflect.DeepEqual(keys, []string{"ops"}) {
+		t.Fatalf("bad keys: %v", keys)
+	}
+
+	// A user outside of all the configured organizations can't log in
+	testWrite(t, b, s, "config", map[string]interface{}{
+		"organization":  "",
+		"organizations": "org-d",
+	})
+	resp = testWrite(t, b, s, "login", map[string]interface{}{"token": "user-token"})
+	if !resp.IsError() {
+		t.Fatalf("expected login to fail, got %#v", resp)
+	}
+}
+
+func TestBackend_InstallationLogin(t *testing.T) {
+	server := newTestGitHubServer(t)
+	defer server.Close()
+	b, s := testFakeBackend(t)
+
+	testWrite(t, b, s, "config", map[string]interface{}{
+		"organizations":   "org-a,org-b",
+		"allowed_app_ids": "7",
+		"base_url":        server.URL,
+	})
+	testWrite(t, b, s, "map/installations/org-b", map[string]interface{}{"value": "deploy"})
+
+	login := func(appJWT string, installationID int) *logical.Response {
+		t.Helper()
+		return testWrite(t, b, s, "login", map[string]interface{}{
+			"app_jwt":         appJWT,
+			"installation_id": installationID,
+		})
+	}
+	renew := func(auth *logical.Auth) (*logical.Response, error) {
+		auth.TokenPolicies = auth.Policies
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.RenewOperation,
+			Path:      "login",
+			Storage:   s,
+			Auth:      auth,
+			Connection: &logical.Connection{
+				RemoteAddr: "127.0.0.1",
+			},
+		})
+	}
+
+	resp := login("app-jwt", 42)
+	testLoginPolicies(t, resp, []string{"deploy"})
+	if resp.Auth.Alias.Name != "installation:42" {
+		t.Fatalf("bad alias: %q", resp.Auth.Alias.Name)
+	}
+	if resp.Auth.Metadata["app_id"] != "7" || resp.Auth.Metadata["installation_id"] != "42" || resp.Auth.Metadata["org"] != "org-b" {
+		t.Fatalf("bad metadata: %#v", resp.Auth.Metadata)
+	}
+	if resp.Auth.InternalData["installation_token"] != "installation-token" {
+		t.Fatalf("bad internal data: %#v", resp.Auth.InternalData)
+	}
+	auth := resp.Auth
+
+	// Renewals verify the minted installation token
+	if resp, err := renew(auth); err != nil || resp.IsError() {
+		t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+	}
+
+	// Apps which aren't allowed can't log in, even if installed in a
+	// configured organization
+	resp = login("other-app-jwt", 43)
+	if !resp.IsError() {
+		t.Fatalf("expected login of an app that isn't allowed to fail, got %#v", resp)
+	}
+
+	// The JWT of an app can't be used for the installations of another
+	if _, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "login",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"app_jwt":         "app-jwt",
+			"installation_id": 43,
+		},
+	}); err == nil {
+		t.Fatal("expected an error logging in as the installation of another app")
+	}
+
+	for _, data := range []map[string]interface{}{
+		{"token": "user-token", "app_jwt": "app-jwt", "installation_id": 42},
+		{"app_jwt": "app-jwt"},
+		{"installation_id": 42},
+	} {
+		if resp := testWrite(t, b, s, "login", data); !resp.IsError() {
+			t.Fatalf("expected an error logging in with %v, got %#v", data, resp)
+		}
+	}
+
+	// Only the allowed installations can log in if some are set
+	testWrite(t, b, s, "config", map[string]interface{}{"allowed_installation_ids": "41"})
+	if resp = login("app-jwt", 42); !resp.IsError() {
+		t.Fatalf("expected login of an installation that isn't allowed to fail, got %#v", resp)
+	}
+	testWrite(t, b, s, "config", map[string]interface{}{"allowed_installation_ids": "41,42"})
+	testLoginPolicies(t, login("app-jwt", 42), []string{"deploy"})
+
+	// Tokens of apps which are no longer allowed can't be renewed
+	testWrite(t, b, s, "config", map[string]interface{}{"allowed_app_ids": "8"})
+	if resp, err := renew(auth); err != nil || !resp.IsError() {
+		t.Fatalf("expected renewal to fail, got resp: %#v\nerr: %v", resp, err)
+	}
+
+	// The installation must be in a configured organization
+	testWrite(t, b, s, "config", map[string]interface{}{
+		"organizations":            "org-a",
+		"allowed_app_ids":          "7",
+		"allowed_installation_ids": "",
+	})
+	if resp = login("app-jwt", 42); !resp.IsError() {
+		t.Fatalf("expected login to fail, got %#v", resp)
+	}
+	if resp, err := renew(auth); err != nil || !resp.IsError() {
+		t.Fatalf("expected renewal to fail, got resp: %#v\nerr: %v", resp, err)
+	}
+}
+
+func TestBackend_VerifyCache(t *testing.T) {
+	server := newTestGitHubServer(t)
+	defer server.Close()
+	b, s := testFakeBackend(t)
+
+	testWrite(t, b, s, "config", map[string]interface{}{
+		"organization": "org-a",
+		"base_url":     server.URL,
+	})
+	testWrite(t, b, s, "map/teams/core-team", map[string]interface{}{"value": "core"})
+
+	login := func(policies ...string) {
+		t.Helper()
+		resp := testWrite(t, b, s, "login", map[string]interface{}{"token": "user-token"})
+		testLoginPolicies(t, resp, policies)
+	}
+
+	// Without caching, every login asks GitHub
+	login("core")
+	requests := atomic.LoadInt32(&server.requests)
+	login("core")
+	if atomic.LoadInt32(&server.requests) != 2*requests {
+		t.Fatalf("expected %d requests, got %d", 2*requests, server.requests)
+	}
+
+	testWrite(t, b, s, "config", map[string]interface{}{"cache_ttl": "1m"})
+	atomic.StoreInt32(&server.requests, 0)
+	login("core")
+	login("core")
+	if atomic.LoadInt32(&server.requests) != requests {
+		t.Fatalf("expected %d requests, got %d", requests, server.requests)
+	}
+
+	// Policies aren't cached, only what GitHub returned
+	testWrite(t, b, s, "map/teams/core-team", map[string]interface{}{"value": "core,extra"})
+	login("core", "extra")
+	if atomic.LoadInt32(&server.requests) != requests {
+		t.Fatalf("expected %d requests, got %d", requests, server.requests)
+	}
+
+	// Writing the configuration empties the cache
+	testWrite(t, b, s, "config", map[string]interface{}{"cache_ttl": "1m"})
+	login("core", "extra")
+	if atomic.LoadInt32(&server.requests) != 2*requests {
+		t.Fatalf("expected %d requests, got %d", 2*requests, server.requests)
+	}
+
+	// Failed verifications aren't cached
+	testWrite(t, b, s, "config", map[string]interface{}{"organization": "org-d"})
+	atomic.StoreInt32(&server.requests, 0)
+	for i := 0; i < 2; i++ {
+		resp := testWrite(t, b, s, "login", map[string]interface{}{"token": "user-token"})
+		if !resp.IsError() {
+			t.Fatalf("expected login to fail, got %#v", resp)
+		}
+	}
+	if atomic.LoadInt32(&server.requests) != 4 {
+		t.Fatalf("expected 4 requests, got %d", server.requests)
+	}
+}
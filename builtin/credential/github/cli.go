@@ -22,6 +22,15 @@ func (h *CLIHandler) Auth(c *api.Client, m map[string]string) (*api.Secret, erro
 		mount = "github"
 	}
 
+	path := fmt.Sprintf("auth/%s/login", mount)
+
+	if appJWT := m["app_jwt"]; appJWT != "" {
+		return login(c, path, map[string]interface{}{
+			"app_jwt":         strings.TrimSpace(appJWT),
+			"installation_id": m["installation_id"],
+		})
+	}
+
 	// Extract or prompt for token
 	token := m["token"]
 	if token == "" {
@@ -52,10 +61,13 @@ func (h *CLIHandler) Auth(c *api.Client, m map[string]string) (*api.Secret, erro
 		}
 	}
 
-	path := fmt.Sprintf("auth/%s/login", mount)
-	secret, err := c.Logical().Write(path, map[string]interface{}{
+	return login(c, path, map[string]interface{}{
 		"token": strings.TrimSpace(token),
 	})
+}
+
+func login(c *api.Client, path string, data map[string]interface{}) (*api.Secret, error) {
+	secret, err := c.Logical().Write(path, data)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +90,11 @@ Usage: vault login -method=github [CONFIG K=V...]
 
       $ vault login -method=github token=abcd1234
 
+  Authenticate as an installation of a GitHub App, using a JWT signed with
+  the private key of the app:
+
+      $ vault login -method=github app_jwt=eyJhbGci... installation_id=1234
+
 Configuration:
 
   mount=<string>
@@ -89,6 +106,14 @@ Configuration:
   token=<string>
       GitHub personal access token to use for authentication. If not provided,
       Vault will prompt for the value.
+
+  app_jwt=<string>
+      JWT signed with the private key of a GitHub App, to authenticate as an
+      installation of the app instead of with a personal access token.
+
+  installation_id=<int>
+      ID of the installation of the GitHub App to authenticate as. Required
+      with app_jwt.
 `
 
 	return strings.TrimSpace(help)
@@ -22,6 +22,26 @@ func pathConfig(b *backend) *framework.Path {
 				Description: "The organization users must be part of",
 			},
 
+			"organizations": &framework.FieldSchema{
+				Type: framework.TypeCommaStringSlice,
+				Description: `Additional organizations users may be part
+of. Teams of these organizations are only
+matched by their org-qualified slug.`,
+			},
+
+			"allowed_app_ids": &framework.FieldSchema{
+				Type: framework.TypeCommaIntSlice,
+				Description: `IDs of the GitHub Apps which may log in
+with an installation. If empty, GitHub Apps
+can't log in.`,
+			},
+
+			"allowed_installation_ids": &framework.FieldSchema{
+				Type: framework.TypeCommaIntSlice,
+				Description: `If set, IDs of the only installations of
+the allowed GitHub Apps which may log in.`,
+			},
+
 			"base_url": &framework.FieldSchema{
 				Type: framework.TypeString,
 				Description: `The API endpoint to use. Useful if you
@@ -32,6 +52,16 @@ API-compatible authentication server.`,
 					Group: "GitHub Options",
 				},
 			},
+			"cache_ttl": &framework.FieldSchema{
+				Type: framework.TypeDurationSecond,
+				Description: `Duration for which the result of verifying
+a token with GitHub is cached. Defaults to
+0, which disables caching.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Cache TTL",
+					Group: "GitHub Options",
+				},
+			},
 			"ttl": &framework.FieldSchema{
 				Type:        framework.TypeDurationSecond,
 				Description: tokenutil.DeprecationText("token_ttl"),
@@ -68,6 +98,25 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		c.Organization = organizationRaw.(string)
 	}
 
+	if organizationsRaw, ok := data.GetOk("organizations"); ok {
+		c.Organizations = organizationsRaw.([]string)
+	}
+
+	if allowedAppIDsRaw, ok := data.GetOk("allowed_app_ids"); ok {
+		c.AllowedAppIDs = toInt64s(allowedAppIDsRaw.([]int))
+	}
+
+	if allowedInstallationIDsRaw, ok := data.GetOk("allowed_installation_ids"); ok {
+		c.AllowedInstallationIDs = toInt64s(allowedInstallationIDsRaw.([]int))
+	}
+
+	if cacheTTLRaw, ok := data.GetOk("cache_ttl"); ok {
+		c.CacheTTL = time.Duration(cacheTTLRaw.(int)) * time.Second
+		if c.CacheTTL < 0 {
+			return logical.ErrorResponse("cache_ttl cannot be negative"), nil
+		}
+	}
+
 	if baseURLRaw, ok := data.GetOk("base_url"); ok {
 		baseURL := baseURLRaw.(string)
 		_, err := url.Parse(baseURL)
@@ -104,6 +153,9 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, dat
 		return nil, err
 	}
 
+	// Cached verification results may not hold under the new configuration
+	b.verifyCache.Flush()
+
 	return nil, nil
 }
 
@@ -117,8 +169,12 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, data
 	}
 
 	d := map[string]interface{}{
-		"organization": config.Organization,
-		"base_url":     config.BaseURL,
+		"organization":             config.Organization,
+		"organizations":            config.Organizations,
+		"allowed_app_ids":          config.AllowedAppIDs,
+		"allowed_installation_ids": config.AllowedInstallationIDs,
+		"base_url":                 config.BaseURL,
+		"cache_ttl":                int64(config.CacheTTL.Seconds()),
 	}
 	config.PopulateTokenData(d)
 
@@ -164,8 +220,67 @@ func (b *backend) Config(ctx context.Context, s logical.Storage) (*config, error
 type config struct {
 	tokenutil.TokenParams
 
-	Organization string        `json:"organization" structs:"organization" mapstructure:"organization"`
-	BaseURL      string        `json:"base_url" structs:"base_url" mapstructure:"base_url"`
-	TTL          time.Duration `json:"ttl" structs:"ttl" mapstructure:"ttl"`
-	MaxTTL       time.Duration `json:"max_ttl" structs:"max_ttl" mapstructure:"max_ttl"`
+	Organization           string        `json:"organization" structs:"organization" mapstructure:"organization"`
+	Organizations          []string      `json:"organizations" structs:"organizations" mapstructure:"organizations"`
+	AllowedAppIDs          []int64       `json:"allowed_app_ids" structs:"allowed_app_ids" mapstructure:"allowed_app_ids"`
+	AllowedInstallationIDs []int64       `json:"allowed_installation_ids" structs:"allowed_installation_ids" mapstructure:"allowed_installation_ids"`
+	BaseURL                string        `json:"base_url" structs:"base_url" mapstructure:"base_url"`
+	CacheTTL               time.Duration `json:"cache_ttl" structs:"cache_ttl" mapstructure:"cache_ttl"`
+	TTL                    time.Duration `json:"ttl" structs:"ttl" mapstructure:"ttl"`
+	MaxTTL                 time.Duration `json:"max_ttl" structs:"max_ttl" mapstructure:"max_ttl"`
+}
+
+// orgs returns the organizations users may be part of, starting with the
+// legacy organization if set.
+func (c *config) orgs() []string {
+	var orgs []string
+	seen := make(map[string]bool)
+	for _, org := range append([]string{c.Organization}, c.Organizations...) {
+		key := strings.ToLower(org)
+		if org == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		orgs = append(orgs, org)
+	}
+	return orgs
+}
+
+// hasOrg returns whether org is one of the configured organizations.
+func (c *config) hasOrg(org string) bool {
+	for _, name := range c.orgs() {
+		if strings.EqualFold(org, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkInstallation returns an error response unless installations of the
+// app may log in, and the installation is allowed if only some are.
+func (c *config) checkInstallation(appID, installationID int64) *logical.Response {
+	if !containsInt64(c.AllowedAppIDs, appID) {
+		return logical.ErrorResponse(fmt.Sprintf("GitHub App %d is not allowed to log in", appID))
+	}
+	if len(c.AllowedInstallationIDs) > 0 && !containsInt64(c.AllowedInstallationIDs, installationID) {
+		return logical.ErrorResponse(fmt.Sprintf("installation %d is not allowed to log in", installationID))
+	}
+	return nil
+}
+
+func containsInt64(list []int64, value int64) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toInt64s(list []int) []int64 {
+	result := make([]int64, 0, len(list))
+	for _, v := range list {
+		result = append(result, int64(v))
+	}
+	return result
 }
@@ -2,8 +2,11 @@ package github
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-github/github"
@@ -22,6 +25,14 @@ func pathLogin(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: "GitHub personal API token",
 			},
+			"app_jwt": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "JWT signed by a GitHub App, used with installation_id instead of token",
+			},
+			"installation_id": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Description: "ID of the installation of the GitHub App to log in as",
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -31,11 +42,58 @@ func pathLogin(b *backend) *framework.Path {
 	}
 }
 
+// credentials are what a token is verified with: a personal access token,
+// or for GitHub Apps, the installation the token is for.
+type credentials struct {
+	Token string
+
+	// AppJWT is given when an app logs in, and used to look up the
+	// installation and mint an installation access token into Token.
+	// Renewals use the minted token instead.
+	AppJWT         string
+	AppID          int64
+	InstallationID int64
+}
+
+func (c *credentials) installation() bool {
+	return c.InstallationID != 0
+}
+
+// loginCredentials returns the credentials given to the login endpoint.
+func loginCredentials(data *framework.FieldData) (*credentials, error) {
+	creds := &credentials{
+		Token:          data.Get("token").(string),
+		AppJWT:         data.Get("app_jwt").(string),
+		InstallationID: int64(data.Get("installation_id").(int)),
+	}
+	switch {
+	case creds.Token != "" && (creds.AppJWT != "" || creds.installation()):
+		return nil, fmt.Errorf("token cannot be given with app_jwt and installation_id")
+	case (creds.AppJWT != "") != creds.installation():
+		return nil, fmt.Errorf("app_jwt and installation_id must be given together")
+	}
+	return creds, nil
+}
+
 func (b *backend) pathLoginAliasLookahead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	token := data.Get("token").(string)
+	creds, err := loginCredentials(data)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	// Minting an installation access token is left to the login itself
+	if creds.installation() {
+		return &logical.Response{
+			Auth: &logical.Auth{
+				Alias: &logical.Alias{
+					Name: installationUsername(creds.InstallationID),
+				},
+			},
+		}, nil
+	}
 
 	var verifyResp *verifyCredentialsResp
-	if verifyResponse, resp, err := b.verifyCredentials(ctx, req, token); err != nil {
+	if verifyResponse, resp, err := b.verifyCredentials(ctx, req, creds); err != nil {
 		return nil, err
 	} else if resp != nil {
 		return resp, nil
@@ -46,17 +104,20 @@ func (b *backend) pathLoginAliasLookahead(ctx context.Context, req *logical.Requ
 	return &logical.Response{
 		Auth: &logical.Auth{
 			Alias: &logical.Alias{
-				Name: *verifyResp.User.Login,
+				Name: verifyResp.Username,
 			},
 		},
 	}, nil
 }
 
 func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	token := data.Get("token").(string)
+	creds, err := loginCredentials(data)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
 
 	var verifyResp *verifyCredentialsResp
-	if verifyResponse, resp, err := b.verifyCredentials(ctx, req, token); err != nil {
+	if verifyResponse, resp, err := b.verifyCredentials(ctx, req, creds); err != nil {
 		return nil, err
 	} else if resp != nil {
 		return resp, nil
@@ -64,17 +125,31 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, data *fra
 		verifyResp = verifyResponse
 	}
 
+	internalData := map[string]interface{}{
+		"token": creds.Token,
+	}
+	metadata := map[string]string{
+		"username": verifyResp.Username,
+		"org":      strings.Join(verifyResp.Orgs, ","),
+	}
+	if creds.installation() {
+		appID := strconv.FormatInt(creds.AppID, 10)
+		installationID := strconv.FormatInt(creds.InstallationID, 10)
+		internalData = map[string]interface{}{
+			"installation_token": creds.Token,
+			"app_id":             appID,
+			"installation_id":    installationID,
+		}
+		metadata["app_id"] = appID
+		metadata["installation_id"] = installationID
+	}
+
 	auth := &logical.Auth{
-		InternalData: map[string]interface{}{
-			"token": token,
-		},
-		Metadata: map[string]string{
-			"username": *verifyResp.User.Login,
-			"org":      *verifyResp.Org.Login,
-		},
-		DisplayName: *verifyResp.User.Login,
+		InternalData: internalData,
+		Metadata:     metadata,
+		DisplayName:  verifyResp.Username,
 		Alias: &logical.Alias{
-			Name: *verifyResp.User.Login,
+			Name: verifyResp.Username,
 		},
 	}
 	verifyResp.Config.PopulateTokenAuth(auth)
@@ -105,14 +180,13 @@ func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *f
 		return nil, fmt.Errorf("request auth was nil")
 	}
 
-	tokenRaw, ok := req.Auth.InternalData["token"]
-	if !ok {
-		return nil, fmt.Errorf("token created in previous version of Vault cannot be validated properly at renewal time")
+	creds, err := renewCredentials(req.Auth)
+	if err != nil {
+		return nil, err
 	}
-	token := tokenRaw.(string)
 
 	var verifyResp *verifyCredentialsResp
-	if verifyResponse, resp, err := b.verifyCredentials(ctx, req, token); err != nil {
+	if verifyResponse, resp, err := b.verifyCredentials(ctx, req, creds); err != nil {
 		return nil, err
 	} else if resp != nil {
 		return resp, nil
@@ -140,7 +214,35 @@ func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *f
 	return resp, nil
 }
 
-func (b *backend) verifyCredentials(ctx context.Context, req *logical.Request, token string) (*verifyCredentialsResp, *logical.Response, error) {
+// renewCredentials returns the credentials a token was created with.
+func renewCredentials(auth *logical.Auth) (*credentials, error) {
+	if token, ok := auth.InternalData["token"].(string); ok {
+		return &credentials{
+			Token: token,
+		}, nil
+	}
+
+	token, ok := auth.InternalData["installation_token"].(string)
+	appID, _ := auth.InternalData["app_id"].(string)
+	installationID, _ := auth.InternalData["installation_id"].(string)
+	if !ok || appID == "" || installationID == "" {
+		return nil, fmt.Errorf("token created in previous version of Vault cannot be validated properly at renewal time")
+	}
+
+	creds := &credentials{
+		Token: token,
+	}
+	var err error
+	if creds.AppID, err = strconv.ParseInt(appID, 10, 64); err != nil {
+		return nil, errwrap.Wrapf("error parsing app ID: {{err}}", err)
+	}
+	if creds.InstallationID, err = strconv.ParseInt(installationID, 10, 64); err != nil {
+		return nil, errwrap.Wrapf("error parsing installation ID: {{err}}", err)
+	}
+	return creds, nil
+}
+
+func (b *backend) verifyCredentials(ctx context.Context, req *logical.Request, creds *credentials) (*verifyCredentialsResp, *logical.Response, error) {
 	config, err := b.Config(ctx, req.Storage)
 	if err != nil {
 		return nil, nil, err
@@ -160,33 +262,186 @@ func (b *backend) verifyCredentials(ctx context.Context, req *logical.Request, t
 		}
 	}
 
-	if config.Organization == "" {
+	if len(config.orgs()) == 0 {
 		return nil, logical.ErrorResponse(
 			"organization not found in configuration"), nil
 	}
 
+	var id *githubIdentity
+	var resp *logical.Response
+	if creds.AppJWT != "" {
+		id, resp, err = b.mintInstallationToken(ctx, config, creds)
+	} else {
+		id, resp, err = b.lookupIdentity(ctx, config, creds)
+	}
+	if err != nil || resp != nil {
+		return nil, resp, err
+	}
+
+	// Checked again on renewal, in case the app is no longer allowed
+	if creds.installation() {
+		if resp := config.checkInstallation(creds.AppID, creds.InstallationID); resp != nil {
+			return nil, resp, nil
+		}
+	}
+
+	var policies []string
+	if creds.installation() {
+		policies, err = b.InstallationMap.Policies(ctx, req.Storage, id.Orgs...)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		groupPoliciesList, err := b.TeamMap.Policies(ctx, req.Storage, id.TeamNames...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		userPoliciesList, err := b.UserMap.Policies(ctx, req.Storage, []string{id.Login}...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		policies = append(groupPoliciesList, userPoliciesList...)
+	}
+
+	username := id.Login
+	if creds.installation() {
+		username = installationUsername(creds.InstallationID)
+	}
+
+	return &verifyCredentialsResp{
+		Username:  username,
+		Orgs:      id.Orgs,
+		Policies:  policies,
+		TeamNames: id.TeamNames,
+		Config:    config,
+	}, nil, nil
+}
+
+// installationUsername returns the name under which an installation of a
+// GitHub App logs in. Installation IDs are unique across apps, and GitHub
+// logins can't contain a colon, so this never collides with another
+// installation or the name of a user.
+func installationUsername(installationID int64) string {
+	return fmt.Sprintf("installation:%d", installationID)
+}
+
+// configuredClient returns a GitHub client authenticating with token, which
+// talks to the configured base URL.
+func (b *backend) configuredClient(config *config, token string) (*github.Client, error) {
 	client, err := b.Client(token)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if config.BaseURL != "" {
 		parsedURL, err := url.Parse(config.BaseURL)
 		if err != nil {
-			return nil, nil, errwrap.Wrapf("successfully parsed base_url when set but failing to parse now: {{err}}", err)
+			return nil, errwrap.Wrapf("successfully parsed base_url when set but failing to parse now: {{err}}", err)
 		}
 		client.BaseURL = parsedURL
 	}
 
+	return client, nil
+}
+
+// mintInstallationToken looks up the installation with the JWT of the app,
+// which GitHub only allows for installations of that app, and mints an
+// installation access token into creds for renewing the Vault token. The
+// app and installation come from GitHub rather than the caller, so only
+// allowed apps installed in a configured organization can log in.
+func (b *backend) mintInstallationToken(ctx context.Context, config *config, creds *credentials) (*githubIdentity, *logical.Response, error) {
+	client, err := b.configuredClient(config, creds.AppJWT)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	installation, _, err := client.Apps.GetInstallation(ctx, creds.InstallationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp := config.checkInstallation(installation.GetAppID(), installation.GetID()); resp != nil {
+		return nil, resp, nil
+	}
+
+	org := installation.GetAccount().GetLogin()
+	if !config.hasOrg(org) {
+		return nil, logical.ErrorResponse("installation is not part of required org"), nil
+	}
+
+	// The client predates the move of this endpoint under app/
+	tokenReq, err := client.NewRequest("POST", fmt.Sprintf("app/installations/%d/access_tokens", creds.InstallationID), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	token := new(github.InstallationToken)
+	if _, err := client.Do(ctx, tokenReq, token); err != nil {
+		return nil, nil, err
+	}
+	if token.GetToken() == "" {
+		return nil, nil, fmt.Errorf("no installation access token returned by GitHub")
+	}
+
+	creds.AppID = installation.GetAppID()
+	creds.Token = token.GetToken()
+
+	return &githubIdentity{
+		Login: org,
+		Orgs:  []string{org},
+	}, nil, nil
+}
+
+// lookupIdentity asks GitHub who the token belongs to, or returns the
+// cached answer if caching is enabled and the token was recently verified.
+// Only the answer is cached: policies are always read from the mappings.
+func (b *backend) lookupIdentity(ctx context.Context, config *config, creds *credentials) (*githubIdentity, *logical.Response, error) {
+	kind := "user"
+	if creds.installation() {
+		kind = "installation"
+	}
+	sum := sha256.Sum256([]byte(kind + ":" + creds.Token))
+	cacheKey := hex.EncodeToString(sum[:])
+
+	if config.CacheTTL > 0 {
+		if cached, ok := b.verifyCache.Get(cacheKey); ok {
+			return cached.(*githubIdentity), nil, nil
+		}
+	}
+
+	client, err := b.configuredClient(config, creds.Token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var id *githubIdentity
+	var resp *logical.Response
+	if creds.installation() {
+		id, resp, err = verifyInstallation(ctx, client, config)
+	} else {
+		id, resp, err = verifyUser(ctx, client, config)
+	}
+	if err != nil || resp != nil {
+		return nil, resp, err
+	}
+
+	if config.CacheTTL > 0 {
+		b.verifyCache.Set(cacheKey, id, config.CacheTTL)
+	}
+
+	return id, nil, nil
+}
+
+// verifyUser verifies that the user owning a personal access token is part
+// of a configured organization, and returns the teams they're part of.
+func verifyUser(ctx context.Context, client *github.Client, config *config) (*githubIdentity, *logical.Response, error) {
 	// Get the user
 	user, _, err := client.Users.Get(ctx, "")
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Verify that the user is part of the organization
-	var org *github.Organization
-
+	// Verify that the user is part of an organization
 	orgOpt := &github.ListOptions{
 		PerPage: 100,
 	}
@@ -204,19 +459,26 @@ func (b *backend) verifyCredentials(ctx context.Context, req *logical.Request, t
 		orgOpt.Page = resp.NextPage
 	}
 
+	id := &githubIdentity{
+		Login: *user.Login,
+	}
+
+	// Logins of the configured organizations the user is part of, by ID
+	orgLogins := make(map[int64]string)
 	for _, o := range allOrgs {
-		if strings.EqualFold(*o.Login, config.Organization) {
-			org = o
-			break
+		for _, name := range config.orgs() {
+			if strings.EqualFold(*o.Login, name) {
+				orgLogins[*o.ID] = *o.Login
+				id.Orgs = append(id.Orgs, *o.Login)
+				break
+			}
 		}
 	}
-	if org == nil {
+	if len(orgLogins) == 0 {
 		return nil, logical.ErrorResponse("user is not part of required org"), nil
 	}
 
 	// Get the teams that this user is part of to determine the policies
-	var teamNames []string
-
 	teamOpt := &github.ListOptions{
 		PerPage: 100,
 	}
@@ -235,42 +497,69 @@ func (b *backend) verifyCredentials(ctx context.Context, req *logical.Request, t
 	}
 
 	for _, t := range allTeams {
-		// We only care about teams that are part of the organization we use
-		if *t.Organization.ID != *org.ID {
+		// We only care about teams that are part of the organizations we use
+		orgLogin, ok := orgLogins[*t.Organization.ID]
+		if !ok {
 			continue
 		}
 
-		// Append the names so we can get the policies
-		teamNames = append(teamNames, *t.Name)
-		if *t.Name != *t.Slug {
-			teamNames = append(teamNames, *t.Slug)
+		// Teams of the legacy organization keep matching by their bare name
+		// and slug, so that existing mappings continue to work
+		if strings.EqualFold(orgLogin, config.Organization) {
+			id.TeamNames = append(id.TeamNames, *t.Name)
+			if *t.Name != *t.Slug {
+				id.TeamNames = append(id.TeamNames, *t.Slug)
+			}
 		}
+		id.TeamNames = append(id.TeamNames, orgLogin+"/"+*t.Slug)
 	}
 
-	groupPoliciesList, err := b.TeamMap.Policies(ctx, req.Storage, teamNames...)
+	return id, nil, nil
+}
 
-	if err != nil {
-		return nil, nil, err
+// verifyInstallation verifies that an installation access token minted at
+// login still works, and still belongs to an installation in a configured
+// organization. The organization is derived from the owner of the
+// repositories the installation can access, since an installation token
+// can't look up its installation otherwise.
+func verifyInstallation(ctx context.Context, client *github.Client, config *config) (*githubIdentity, *logical.Response, error) {
+	opt := &github.ListOptions{
+		PerPage: 100,
 	}
 
-	userPoliciesList, err := b.UserMap.Policies(ctx, req.Storage, []string{*user.Login}...)
-
-	if err != nil {
-		return nil, nil, err
+	for {
+		repos, resp, err := client.Apps.ListRepos(ctx, opt)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, repo := range repos {
+			if owner := repo.GetOwner().GetLogin(); config.hasOrg(owner) {
+				return &githubIdentity{
+					Login: owner,
+					Orgs:  []string{owner},
+				}, nil, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
 	}
 
-	return &verifyCredentialsResp{
-		User:      user,
-		Org:       org,
-		Policies:  append(groupPoliciesList, userPoliciesList...),
-		TeamNames: teamNames,
-		Config:    config,
-	}, nil, nil
+	return nil, logical.ErrorResponse("installation is not part of required org"), nil
+}
+
+// githubIdentity is what GitHub returned about the owner of a token. For
+// installations, Login is the login of the organization.
+type githubIdentity struct {
+	Login     string
+	Orgs      []string
+	TeamNames []string
 }
 
 type verifyCredentialsResp struct {
-	User      *github.User
-	Org       *github.Organization
+	Username  string
+	Orgs      []string
 	Policies  []string
 	TeamNames []string
 
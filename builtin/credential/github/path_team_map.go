@@ -0,0 +1,115 @@
+package github
+
+import (
+	"context"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// pathOrgTeamMap returns the paths mapping teams qualified by their
+// organization to policies. The mappings are stored in the team map under
+// "<org>/<slug>", which is how verifyCredentials looks them up.
+func pathOrgTeamMap(b *backend) []*framework.Path {
+	return []*framework.Path{
+		&framework.Path{
+			Pattern: `map/teams/(?P<org>[-\w]+)/$`,
+			Fields: map[string]*framework.FieldSchema{
+				"org": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "Organization of the teams",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ListOperation: b.pathOrgTeamList,
+			},
+
+			HelpSynopsis:    pathOrgTeamMapHelpSyn,
+			HelpDescription: pathOrgTeamMapHelpDesc,
+		},
+		&framework.Path{
+			Pattern: `map/teams/(?P<org>[-\w]+)/(?P<key>[-\w]+)`,
+			Fields: map[string]*framework.FieldSchema{
+				"org": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "Organization of the team",
+				},
+				"key": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "Slug of the team",
+				},
+				"value": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "Comma-separated list of policies",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.CreateOperation: b.pathOrgTeamWrite,
+				logical.ReadOperation:   b.pathOrgTeamRead,
+				logical.UpdateOperation: b.pathOrgTeamWrite,
+				logical.DeleteOperation: b.pathOrgTeamDelete,
+			},
+
+			ExistenceCheck: b.pathOrgTeamExistenceCheck,
+
+			HelpSynopsis:    pathOrgTeamMapHelpSyn,
+			HelpDescription: pathOrgTeamMapHelpDesc,
+		},
+	}
+}
+
+func orgTeamKey(data *framework.FieldData) string {
+	return data.Get("org").(string) + "/" + data.Get("key").(string)
+}
+
+func (b *backend) pathOrgTeamList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	keys, err := b.TeamMap.List(ctx, req.Storage, data.Get("org").(string)+"/")
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(keys), nil
+}
+
+func (b *backend) pathOrgTeamRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	v, err := b.TeamMap.Get(ctx, req.Storage, orgTeamKey(data))
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+	return &logical.Response{
+		Data: v,
+	}, nil
+}
+
+func (b *backend) pathOrgTeamWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return nil, b.TeamMap.Put(ctx, req.Storage, orgTeamKey(data), map[string]interface{}{
+		"value": data.Get("value").(string),
+	})
+}
+
+func (b *backend) pathOrgTeamDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	return nil, b.TeamMap.Delete(ctx, req.Storage, orgTeamKey(data))
+}
+
+func (b *backend) pathOrgTeamExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	v, err := b.TeamMap.Get(ctx, req.Storage, orgTeamKey(data))
+	if err != nil {
+		return false, err
+	}
+	return v != nil, nil
+}
+
+const pathOrgTeamMapHelpSyn = `
+Read/write/delete the policies of a team of a certain organization.
+`
+
+const pathOrgTeamMapHelpDesc = `
+This path maps a team, identified by its organization and slug, to a
+comma-separated list of policies. Unlike the mappings at "map/teams/<team>",
+which only match teams of the legacy "organization", these match teams of
+any configured organization.
+`
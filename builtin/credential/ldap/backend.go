@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	metrics "github.com/armon/go-metrics"
+	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/vault/helper/mfa"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/ldaputil"
@@ -48,14 +51,46 @@ func Backend() *backend {
 		),
 
 		AuthRenew:   b.pathLoginRenew,
+		Invalidate:  b.invalidate,
+		Clean:       b.cleanup,
 		BackendType: logical.TypeCredential,
 	}
 
+	b.ldap = ldaputil.NewLDAP()
+	b.pool = ldaputil.NewConnectionPool()
+	b.cache = ldaputil.NewCache()
+
 	return &b
 }
 
 type backend struct {
 	*framework.Backend
+
+	// ldap dials the connections to the LDAP server
+	ldap ldaputil.LDAP
+
+	// pool holds idle connections to the LDAP server, and cache the lookups
+	// of the users who logged in recently
+	pool  *ldaputil.ConnectionPool
+	cache *ldaputil.Cache
+}
+
+// reset drops the pooled connections and cached lookups, which may no
+// longer be valid after the configuration changed.
+func (b *backend) reset() {
+	b.pool.Close()
+	b.cache.Purge()
+}
+
+func (b *backend) invalidate(_ context.Context, key string) {
+	switch key {
+	case "config":
+		b.reset()
+	}
+}
+
+func (b *backend) cleanup(_ context.Context) {
+	b.reset()
 }
 
 func (b *backend) Login(ctx context.Context, req *logical.Request, username string, password string) ([]string, *logical.Response, []string, error) {
@@ -74,10 +109,10 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 
 	ldapClient := ldaputil.Client{
 		Logger: b.Logger(),
-		LDAP:   ldaputil.NewLDAP(),
+		LDAP:   b.ldap,
 	}
 
-	c, err := ldapClient.DialLDAP(cfg.ConfigEntry)
+	c, err := b.pool.Get(&ldapClient, cfg.ConfigEntry)
 	if err != nil {
 		return nil, logical.ErrorResponse(err.Error()), nil, nil
 	}
@@ -85,19 +120,42 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 		return nil, logical.ErrorResponse("invalid connection returned from LDAP dial"), nil, nil
 	}
 
-	// Clean connection
-	defer c.Close()
+	// Return the connection to the pool if it's still usable, or close it
+	healthy := false
+	defer func() {
+		if healthy {
+			b.pool.Put(cfg.ConfigEntry, c)
+		} else {
+			c.Close()
+		}
+	}()
 
-	userBindDN, err := ldapClient.GetUserBindDN(cfg.ConfigEntry, c, username)
-	if err != nil {
-		if b.Logger().IsDebug() {
-			b.Logger().Debug("error getting user bind DN", "error", err)
+	var cached *ldaputil.CachedUser
+	if cfg.CacheTTL > 0 {
+		var ok bool
+		cached, ok = b.cache.Get(username)
+		if ok {
+			metrics.IncrCounter([]string{"auth", "ldap", "cache", "hit"}, 1)
+		} else {
+			metrics.IncrCounter([]string{"auth", "ldap", "cache", "miss"}, 1)
+		}
+	}
+
+	var userBindDN string
+	if cached != nil {
+		userBindDN = cached.BindDN
+	} else {
+		userBindDN, err = ldapClient.GetUserBindDN(cfg.ConfigEntry, c, username)
+		if err != nil {
+			if b.Logger().IsDebug() {
+				b.Logger().Debug("error getting user bind DN", "error", err)
+			}
+			return nil, logical.ErrorResponse("ldap operation failed"), nil, nil
 		}
-		return nil, logical.ErrorResponse("ldap operation failed"), nil, nil
 	}
 
 	if b.Logger().IsDebug() {
-		b.Logger().Debug("user binddn fetched", "username", username, "binddn", userBindDN)
+		b.Logger().Debug("user binddn fetched", "username", username, "binddn", userBindDN, "cached", cached != nil)
 	}
 
 	// Try to bind as the login user. This is where the actual authentication takes place.
@@ -110,35 +168,48 @@ func (b *backend) Login(ctx context.Context, req *logical.Request, username stri
 		if b.Logger().IsDebug() {
 			b.Logger().Debug("ldap bind failed", "error", err)
 		}
+		// The connection is still usable after a failed authentication
+		healthy = ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials)
 		return nil, logical.ErrorResponse("ldap operation failed"), nil, nil
 	}
 
-	// We re-bind to the BindDN if it's defined because we assume
-	// the BindDN should be the one to search, not the user logging in.
-	if cfg.BindDN != "" && cfg.BindPassword != "" {
-		if err := c.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+	if cached == nil {
+		// We re-bind to the BindDN if it's defined because we assume
+		// the BindDN should be the one to search, not the user logging in.
+		if cfg.BindDN != "" && cfg.BindPassword != "" {
+			if err := c.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+				if b.Logger().IsDebug() {
+					b.Logger().Debug("error while attempting to re-bind with the BindDN User", "error", err)
+				}
+				return nil, logical.ErrorResponse("ldap operation failed"), nil, nil
+			}
 			if b.Logger().IsDebug() {
-				b.Logger().Debug("error while attempting to re-bind with the BindDN User", "error", err)
+				b.Logger().Debug("re-bound to original binddn")
 			}
-			return nil, logical.ErrorResponse("ldap operation failed"), nil, nil
 		}
-		if b.Logger().IsDebug() {
-			b.Logger().Debug("re-bound to original binddn")
+
+		userDN, err := ldapClient.GetUserDN(cfg.ConfigEntry, c, userBindDN)
+		if err != nil {
+			return nil, logical.ErrorResponse(err.Error()), nil, nil
 		}
-	}
 
-	userDN, err := ldapClient.GetUserDN(cfg.ConfigEntry, c, userBindDN)
-	if err != nil {
-		return nil, logical.ErrorResponse(err.Error()), nil, nil
-	}
+		ldapGroups, err := ldapClient.GetLdapGroups(cfg.ConfigEntry, c, userDN, username)
+		if err != nil {
+			return nil, logical.ErrorResponse(err.Error()), nil, nil
+		}
+		if b.Logger().IsDebug() {
+			b.Logger().Debug("groups fetched from server", "num_server_groups", len(ldapGroups), "server_groups", ldapGroups)
+		}
 
-	ldapGroups, err := ldapClient.GetLdapGroups(cfg.ConfigEntry, c, userDN, username)
-	if err != nil {
-		return nil, logical.ErrorResponse(err.Error()), nil, nil
-	}
-	if b.Logger().IsDebug() {
-		b.Logger().Debug("groups fetched from server", "num_server_groups", len(ldapGroups), "server_groups", ldapGroups)
+		cached = &ldaputil.CachedUser{
+			BindDN: userBindDN,
+			UserDN: userDN,
+			Groups: ldapGroups,
+		}
+		b.cache.Put(username, cached, time.Duration(cfg.CacheTTL)*time.Second)
 	}
+	healthy = true
+	ldapGroups := cached.Groups
 
 	ldapResponse := &logical.Response{
 		Data: map[string]interface{}{},
@@ -2,18 +2,24 @@ package ldap
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	goldap "github.com/go-ldap/ldap/v3"
 	"github.com/go-test/deep"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/helper/testhelpers/ldap"
 	logicaltest "github.com/hashicorp/vault/helper/testhelpers/logical"
 	"github.com/hashicorp/vault/sdk/helper/ldaputil"
 	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/helper/tokenutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/mapstructure"
@@ -379,19 +385,19 @@ func TestLdapAuthBackend_UserPolicies(t *testing.T) {
 }
 
 /*
- * Acceptance test for LDAP Auth Method
- *
- * The tests here rely on a docker LDAP server:
- * [https://github.com/rroemhild/docker-test-openldap]
- *
- * ...as well as existence of a person object, `cn=Hermes Conrad,dc=example,dc=com`,
- *    which is a member of a group, `cn=admin_staff,ou=people,dc=example,dc=com`
- *
- * Querying the server from the command line:
- *   $ docker run --privileged -d -p 389:389 --name ldap --rm rroemhild/test-openldap
- *   $ ldapsearch -x -H ldap://localhost -b dc=planetexpress,dc=com -s sub uid=hermes
- *   $ ldapsearch -x -H ldap://localhost -b dc=planetexpress,dc=com -s sub \
-         'member=cn=Hermes Conrad,ou=people,dc=planetexpress,dc=com'
+* Acceptance test for LDAP Auth Method
+*
+* The tests here rely on a docker LDAP server:
+* [https://github.com/rroemhild/docker-test-openldap]
+*
+* ...as well as existence of a person object, `cn=Hermes Conrad,dc=example,dc=com`,
+*    which is a member of a group, `cn=admin_staff,ou=people,dc=example,dc=com`
+*
+  - Querying the server from the command line:
+  - $ docker run --privileged -d -p 389:389 --name ldap --rm rroemhild/test-openldap
+  - $ ldapsearch -x -H ldap://localhost -b dc=planetexpress,dc=com -s sub uid=hermes
+  - $ ldapsearch -x -H ldap://localhost -b dc=planetexpress,dc=com -s sub \
+    'member=cn=Hermes Conrad,ou=people,dc=planetexpress,dc=com'
 */
 func factory(t *testing.T) logical.Backend {
 	defaultLeaseTTLVal := time.Hour * 24
@@ -587,6 +593,18 @@ func TestBackend_configDefaultsAfterUpdate(t *testing.T) {
 						t.Errorf("Default mismatch: deny_null_bind. Expected: '%t', received :'%s'", defaultDenyNullBind, cfg["deny_null_bind"])
 					}
 
+					if cfg["connection_pool_size"] != 0 {
+						t.Errorf("Default mismatch: connection_pool_size. Expected: 0, received :'%v'", cfg["connection_pool_size"])
+					}
+
+					if cfg["cache_ttl"] != 0 {
+						t.Errorf("Default mismatch: cache_ttl. Expected: 0, received :'%v'", cfg["cache_ttl"])
+					}
+
+					if cfg["nested_group_max_depth"] != ldaputil.DefaultNestedGroupMaxDepth {
+						t.Errorf("Default mismatch: nested_group_max_depth. Expected: '%d', received :'%v'", ldaputil.DefaultNestedGroupMaxDepth, cfg["nested_group_max_depth"])
+					}
+
 					return nil
 				},
 			},
@@ -936,6 +954,7 @@ func TestLdapAuthBackend_ConfigUpgrade(t *testing.T) {
 			CaseSensitiveNames:       falseBool,
 			UsePre111GroupCNBehavior: new(bool),
 			RequestTimeout:           cfg.RequestTimeout,
+			NestedGroupMaxDepth:      defParams.NestedGroupMaxDepth,
 		},
 	}
 
@@ -968,3 +987,251 @@ func TestLdapAuthBackend_ConfigUpgrade(t *testing.T) {
 	}
 
 }
+
+const (
+	testLoginUserDN  = "ou=users,dc=example,dc=com"
+	testLoginGroupDN = "ou=groups,dc=example,dc=com"
+)
+
+// testDirectory is an in-memory LDAP directory serving user binds, user
+// searches and group searches with a member filter.
+type testDirectory struct {
+	l sync.Mutex
+
+	// passwords maps bind DNs to their password
+	passwords map[string]string
+	// groups maps group CNs to the DNs of their members
+	groups map[string][]string
+	// bindErr, if set, is returned by all binds
+	bindErr error
+
+	groupSearches int
+	closed        int
+}
+
+func newTestDirectory() *testDirectory {
+	return &testDirectory{
+		passwords: map[string]string{
+			"cn=admin,dc=example,dc=com":   "admin-password",
+			"uid=alice," + testLoginUserDN: "alice-password",
+		},
+		groups: map[string][]string{
+			"admins": {"uid=alice," + testLoginUserDN},
+		},
+	}
+}
+
+func (d *testDirectory) Dial(network, addr string) (ldaputil.Connection, error) {
+	return &testConnection{dir: d}, nil
+}
+
+func (d *testDirectory) DialTLS(network, addr string, config *tls.Config) (ldaputil.Connection, error) {
+	return &testConnection{dir: d}, nil
+}
+
+func (d *testDirectory) stats() (groupSearches, closed int) {
+	d.l.Lock()
+	defer d.l.Unlock()
+	return d.groupSearches, d.closed
+}
+
+type testConnection struct {
+	dir *testDirectory
+}
+
+func (c *testConnection) Add(req *goldap.AddRequest) error { return nil }
+
+func (c *testConnection) Bind(username, password string) error {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	if c.dir.bindErr != nil {
+		return c.dir.bindErr
+	}
+	if expected, ok := c.dir.passwords[username]; !ok || expected != password {
+		return goldap.NewError(goldap.LDAPResultInvalidCredentials, fmt.Errorf("invalid credentials for %q", username))
+	}
+	return nil
+}
+
+func (c *testConnection) Close() {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+	c.dir.closed++
+}
+
+func (c *testConnection) Del(req *goldap.DelRequest) error { return nil }
+
+func (c *testConnection) Modify(req *goldap.ModifyRequest) error { return nil }
+
+func (c *testConnection) Search(req *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	filter := strings.Trim(req.Filter, "()")
+	idx := strings.Index(filter, "=")
+	if idx < 0 {
+		return nil, fmt.Errorf("unsupported filter %q", req.Filter)
+	}
+	attr, value := filter[:idx], filter[idx+1:]
+
+	result := &goldap.SearchResult{}
+	switch {
+	case req.BaseDN == testLoginUserDN && attr == "uid":
+		dn := "uid=" + value + "," + testLoginUserDN
+		if _, ok := c.dir.passwords[dn]; ok {
+			result.Entries = append(result.Entries, goldap.NewEntry(dn, map[string][]string{"uid": {value}}))
+		}
+	case req.BaseDN == testLoginGroupDN && attr == "member":
+		c.dir.groupSearches++
+		for cn, members := range c.dir.groups {
+			if strutil.StrListContains(members, value) {
+				result.Entries = append(result.Entries, goldap.NewEntry("cn="+cn+","+testLoginGroupDN, map[string][]string{"cn": {cn}}))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported search of %q with %q", req.BaseDN, req.Filter)
+	}
+	return result, nil
+}
+
+func (c *testConnection) StartTLS(config *tls.Config) error { return nil }
+
+func (c *testConnection) SetTimeout(timeout time.Duration) {}
+
+func (c *testConnection) UnauthenticatedBind(username string) error { return nil }
+
+func TestLdapAuthBackend_LoginCache(t *testing.T) {
+	b, storage := createBackendWithStorage(t)
+	dir := newTestDirectory()
+	b.ldap = dir
+
+	writeConfig := func() {
+		t.Helper()
+		resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Path:      "config",
+			Operation: logical.UpdateOperation,
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"url":                  "ldap://ldap.example.com",
+				"binddn":               "cn=admin,dc=example,dc=com",
+				"bindpass":             "admin-password",
+				"userdn":               testLoginUserDN,
+				"userattr":             "uid",
+				"groupdn":              testLoginGroupDN,
+				"groupfilter":          "(member={{.UserDN}})",
+				"groupattr":            "cn",
+				"cache_ttl":            "1m",
+				"connection_pool_size": 2,
+			},
+		})
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+		}
+	}
+	writeConfig()
+
+	resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+		Path:      "groups/admins",
+		Operation: logical.UpdateOperation,
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"policies": "admin",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("bad: resp: %#v\nerr: %v", resp, err)
+	}
+
+	login := func(password string) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Path:      "login/alice",
+			Operation: logical.UpdateOperation,
+			Storage:   storage,
+			Data: map[string]interface{}{
+				"password": password,
+			},
+			Connection: &logical.Connection{},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp == nil {
+			t.Fatal("expected a response")
+		}
+		return resp
+	}
+	expectGroupSearches := func(expected int) {
+		t.Helper()
+		if groupSearches, _ := dir.stats(); groupSearches != expected {
+			t.Fatalf("expected %d group searches, got %d", expected, groupSearches)
+		}
+	}
+
+	// The first login looks up the groups and returns its connection to the
+	// pool
+	resp = login("alice-password")
+	if resp.IsError() || !strutil.StrListContains(resp.Auth.Policies, "admin") {
+		t.Fatalf("bad: %#v", resp)
+	}
+	expectGroupSearches(1)
+	if b.pool.Len() != 1 {
+		t.Fatalf("expected the connection to be pooled, got %d", b.pool.Len())
+	}
+
+	// Cache hits skip the group search, but still bind as the user
+	resp = login("alice-password")
+	if resp.IsError() || !strutil.StrListContains(resp.Auth.Policies, "admin") {
+		t.Fatalf("bad: %#v", resp)
+	}
+	expectGroupSearches(1)
+
+	if resp = login("wrong-password"); !resp.IsError() {
+		t.Fatalf("expected a login with a wrong password to fail on a cache hit, got %#v", resp)
+	}
+	expectGroupSearches(1)
+	if b.pool.Len() != 1 {
+		t.Fatalf("expected the connection to be pooled after invalid credentials, got %d", b.pool.Len())
+	}
+
+	// Connections failing otherwise are closed rather than pooled
+	dir.l.Lock()
+	dir.bindErr = goldap.NewError(goldap.LDAPResultUnavailable, errors.New("unavailable"))
+	dir.l.Unlock()
+	_, closedBefore := dir.stats()
+	if resp = login("alice-password"); !resp.IsError() {
+		t.Fatalf("expected the login to fail, got %#v", resp)
+	}
+	if _, closed := dir.stats(); closed != closedBefore+1 || b.pool.Len() != 0 {
+		t.Fatalf("expected the failed connection to be closed, got %d closed and %d pooled", closed-closedBefore, b.pool.Len())
+	}
+	dir.l.Lock()
+	dir.bindErr = nil
+	dir.l.Unlock()
+
+	// Writing the configuration purges the cache and the pool
+	if resp = login("alice-password"); resp.IsError() {
+		t.Fatalf("bad: %#v", resp)
+	}
+	writeConfig()
+	if _, ok := b.cache.Get("alice"); ok {
+		t.Fatal("expected the config write to purge the cache")
+	}
+	if b.pool.Len() != 0 {
+		t.Fatalf("expected the config write to close pooled connections, got %d", b.pool.Len())
+	}
+	if resp = login("alice-password"); resp.IsError() {
+		t.Fatalf("bad: %#v", resp)
+	}
+	expectGroupSearches(2)
+
+	// So does a config change on another node
+	b.invalidate(context.Background(), "config")
+	if _, ok := b.cache.Get("alice"); ok {
+		t.Fatal("expected invalidating the config to purge the cache")
+	}
+	if b.pool.Len() != 0 {
+		t.Fatalf("expected invalidating the config to close pooled connections, got %d", b.pool.Len())
+	}
+}
@@ -154,6 +154,8 @@ func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *
 		return nil, err
 	}
 
+	b.reset()
+
 	return nil, nil
 }
 
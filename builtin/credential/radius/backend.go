@@ -2,6 +2,7 @@ package radius
 
 import (
 	"context"
+	"sync"
 
 	"github.com/hashicorp/vault/helper/mfa"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -17,7 +18,9 @@ func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend,
 }
 
 func Backend() *backend {
-	var b backend
+	b := backend{
+		health: make(map[string]*serverHealth),
+	}
 	b.Backend = &framework.Backend{
 		Help: backendHelp,
 
@@ -38,6 +41,9 @@ func Backend() *backend {
 			pathConfig(&b),
 			pathUsers(&b),
 			pathUsersList(&b),
+			pathGroups(&b),
+			pathGroupsList(&b),
+			pathHealth(&b),
 		},
 			mfa.MFAPaths(b.Backend, pathLogin(&b))...,
 		),
@@ -51,6 +57,11 @@ func Backend() *backend {
 
 type backend struct {
 	*framework.Backend
+
+	// healthLock protects health, which tracks the servers that recently
+	// failed to respond, by address.
+	healthLock sync.Mutex
+	health     map[string]*serverHealth
 }
 
 const backendHelp = `
@@ -64,5 +75,14 @@ Authentication is then done by supplying the two fields for "login".
 
 The backend optionally allows to grant a set of policies to any 
 user that successfully authenticates against the RADIUS server, 
-without them being explicitly mapped in vault.
+without them being explicitly mapped in vault. Policies can also be
+granted to the groups returned by the server in a vendor-specific
+attribute, through the "groups" endpoint.
+
+Several servers can be configured, which are tried in turn when one
+fails to respond. The "health" endpoint reports the servers which
+recently failed.
+
+Passwords and the answers to challenges are sent with PAP. EAP is
+not supported.
 `
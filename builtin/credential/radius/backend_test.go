@@ -2,10 +2,15 @@ package radius
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/md5"
 	"fmt"
+	"net"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +18,8 @@ import (
 	logicaltest "github.com/hashicorp/vault/helper/testhelpers/logical"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/ory/dockertest"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
 )
 
 const (
@@ -316,3 +323,336 @@ func testAccUserLoginPolicy(t *testing.T, user string, data map[string]interface
 		},
 	}
 }
+
+// testRadiusServer is a RADIUS server accepting "alice", who is a member of
+// the groups "admins" and "ops", and "bob", who must answer a challenge for a
+// one-time password. requireMessageAuthenticator makes it reject requests
+// without a valid Message-Authenticator, and sign its responses with it.
+type testRadiusServer struct {
+	addr     string
+	server   *radius.PacketServer
+	requests int32
+
+	requireMessageAuthenticator bool
+}
+
+const (
+	testRadiusSecret   = "test-secret"
+	testRadiusVendorID = 9
+	testRadiusVSAType  = 1
+)
+
+func newTestRadiusServer(t *testing.T, requireMessageAuthenticator bool) *testRadiusServer {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testRadiusServer{
+		addr:                        conn.LocalAddr().String(),
+		requireMessageAuthenticator: requireMessageAuthenticator,
+	}
+	s.server = &radius.PacketServer{
+		SecretSource: radius.StaticSecretSource([]byte(testRadiusSecret)),
+		Handler:      radius.HandlerFunc(s.serveRADIUS),
+	}
+	go s.server.Serve(conn)
+	return s
+}
+
+func (s *testRadiusServer) Close() {
+	s.server.Shutdown(context.Background())
+}
+
+func (s *testRadiusServer) serveRADIUS(w radius.ResponseWriter, r *radius.Request) {
+	atomic.AddInt32(&s.requests, 1)
+
+	if s.requireMessageAuthenticator {
+		received := r.Get(messageAuthenticatorType)
+		req := *r.Packet
+		req.Attributes = make(radius.Attributes)
+		for k, v := range r.Attributes {
+			req.Attributes[k] = v
+		}
+		req.Set(messageAuthenticatorType, make(radius.Attribute, md5.Size))
+		wire, err := req.Encode()
+		if err != nil || !hmac.Equal(received, messageAuthenticator(wire, req.Secret)) {
+			return
+		}
+	}
+
+	username := rfc2865.UserName_GetString(r.Packet)
+	password := rfc2865.UserPassword_GetString(r.Packet)
+	state := rfc2865.State_GetString(r.Packet)
+
+	var resp *radius.Packet
+	switch {
+	case username == "alice" && password == "alice-password":
+		resp = r.Response(radius.CodeAccessAccept)
+		for _, group := range []string{"admins", "ops"} {
+			vsa, _ := radius.NewVendorSpecific(testRadiusVendorID, append([]byte{testRadiusVSAType, byte(2 + len(group))}, group...))
+			resp.Add(rfc2865.VendorSpecific_Type, vsa)
+		}
+	case username == "bob" && password == "bob-password" && state == "":
+		resp = r.Response(radius.CodeAccessChallenge)
+		rfc2865.State_SetString(resp, "otp-state")
+		rfc2865.ReplyMessage_SetString(resp, "Enter your one-time password")
+	case username == "bob" && password == "123456" && state == "otp-state":
+		resp = r.Response(radius.CodeAccessAccept)
+	default:
+		resp = r.Response(radius.CodeAccessReject)
+	}
+
+	if s.requireMessageAuthenticator {
+		resp.Set(messageAuthenticatorType, make(radius.Attribute, md5.Size))
+		wire, _ := resp.Encode()
+		copy(wire[4:20], r.Authenticator[:])
+		resp.Set(messageAuthenticatorType, messageAuthenticator(wire, resp.Secret))
+	}
+	w.Write(resp)
+}
+
+// testDeadServer returns the address of a UDP port nothing listens on.
+func testDeadServer(t *testing.T) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := conn.LocalAddr().String()
+	conn.Close()
+	return addr
+}
+
+func testBackend(t *testing.T) (*backend, logical.Storage) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.System = &logical.StaticSystemView{
+		DefaultLeaseTTLVal: testSysTTL,
+		MaxLeaseTTLVal:     testSysMaxTTL,
+	}
+	b := Backend()
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	return b, config.StorageView
+}
+
+func testRequest(t *testing.T, b *backend, s logical.Storage, op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+	t.Helper()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: op,
+		Path:      path,
+		Storage:   s,
+		Data:      data,
+		Connection: &logical.Connection{
+			RemoteAddr: "127.0.0.1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("error on %q: %v", path, err)
+	}
+	return resp
+}
+
+func TestBackend_Failover(t *testing.T) {
+	server := newTestRadiusServer(t, false)
+	defer server.Close()
+	dead := testDeadServer(t)
+	b, s := testBackend(t)
+
+	testRequest(t, b, s, logical.CreateOperation, "config", map[string]interface{}{
+		"host":         strings.Split(dead, ":")[0],
+		"port":         strings.Split(dead, ":")[1],
+		"hosts":        server.addr,
+		"secret":       testRadiusSecret,
+		"read_timeout": 1,
+	})
+	testRequest(t, b, s, logical.UpdateOperation, "users/alice", map[string]interface{}{
+		"policies": "alice-policy",
+	})
+
+	login := func() {
+		t.Helper()
+		resp := testRequest(t, b, s, logical.UpdateOperation, "login/alice", map[string]interface{}{
+			"password": "alice-password",
+		})
+		if resp == nil || resp.IsError() || resp.Auth == nil {
+			t.Fatalf("bad login response: %#v", resp)
+		}
+	}
+
+	login()
+	resp := testRequest(t, b, s, logical.ReadOperation, "health", nil)
+	servers := resp.Data["servers"].(map[string]interface{})
+	deadHealth := servers[dead].(map[string]interface{})
+	if deadHealth["healthy"].(bool) || deadHealth["failures"].(int) != 1 {
+		t.Fatalf("expected %s to be unhealthy: %#v", dead, deadHealth)
+	}
+	if !servers[server.addr].(map[string]interface{})["healthy"].(bool) {
+		t.Fatalf("expected %s to be healthy: %#v", server.addr, servers)
+	}
+
+	// The unhealthy server isn't tried while the other one responds
+	login()
+	resp = testRequest(t, b, s, logical.ReadOperation, "health", nil)
+	deadHealth = resp.Data["servers"].(map[string]interface{})[dead].(map[string]interface{})
+	if deadHealth["failures"].(int) != 1 {
+		t.Fatalf("expected %s not to be tried again: %#v", dead, deadHealth)
+	}
+	if requests := atomic.LoadInt32(&server.requests); requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestBackend_MessageAuthenticator(t *testing.T) {
+	server := newTestRadiusServer(t, true)
+	defer server.Close()
+	b, s := testBackend(t)
+
+	testRequest(t, b, s, logical.CreateOperation, "config", map[string]interface{}{
+		"host":                       server.addr,
+		"secret":                     testRadiusSecret,
+		"unregistered_user_policies": "unregistered",
+		"read_timeout":               1,
+	})
+
+	// Without the attribute, the server drops the request
+	resp := testRequest(t, b, s, logical.UpdateOperation, "login/alice", map[string]interface{}{
+		"password": "alice-password",
+	})
+	if !resp.IsError() {
+		t.Fatalf("expected login to fail, got %#v", resp)
+	}
+
+	testRequest(t, b, s, logical.UpdateOperation, "config", map[string]interface{}{
+		"message_authenticator": true,
+	})
+	resp = testRequest(t, b, s, logical.UpdateOperation, "login/alice", map[string]interface{}{
+		"password": "alice-password",
+	})
+	if resp == nil || resp.IsError() || resp.Auth == nil {
+		t.Fatalf("bad login response: %#v", resp)
+	}
+
+	// Responses must carry a valid attribute
+	var authenticator [16]byte
+	wire, _ := radius.New(radius.CodeAccessAccept, []byte(testRadiusSecret)).Encode()
+	if err := verifyMessageAuthenticator(wire, authenticator, []byte(testRadiusSecret), true); err == nil {
+		t.Fatal("expected an error verifying a response without Message-Authenticator")
+	}
+	forged := radius.New(radius.CodeAccessAccept, []byte(testRadiusSecret))
+	forged.Set(messageAuthenticatorType, make(radius.Attribute, md5.Size))
+	wire, _ = forged.Encode()
+	if err := verifyMessageAuthenticator(wire, authenticator, []byte(testRadiusSecret), false); err == nil {
+		t.Fatal("expected an error verifying an invalid Message-Authenticator")
+	}
+}
+
+func TestBackend_Challenge(t *testing.T) {
+	server := newTestRadiusServer(t, false)
+	defer server.Close()
+	b, s := testBackend(t)
+
+	testRequest(t, b, s, logical.CreateOperation, "config", map[string]interface{}{
+		"host":         server.addr,
+		"secret":       testRadiusSecret,
+		"read_timeout": 1,
+	})
+	testRequest(t, b, s, logical.UpdateOperation, "users/bob", map[string]interface{}{
+		"policies": "bob-policy",
+	})
+
+	resp := testRequest(t, b, s, logical.UpdateOperation, "login/bob", map[string]interface{}{
+		"password": "bob-password",
+	})
+	if resp == nil || resp.IsError() || resp.Auth != nil {
+		t.Fatalf("expected a challenge, got %#v", resp)
+	}
+	if resp.Data["reply_message"] != "Enter your one-time password" {
+		t.Fatalf("bad reply message: %#v", resp.Data)
+	}
+	state := resp.Data["state"].(string)
+
+	resp = testRequest(t, b, s, logical.UpdateOperation, "login/bob", map[string]interface{}{
+		"password": "654321",
+		"state":    state,
+	})
+	if !resp.IsError() {
+		t.Fatalf("expected a wrong one-time password to fail, got %#v", resp)
+	}
+
+	resp = testRequest(t, b, s, logical.UpdateOperation, "login/bob", map[string]interface{}{
+		"password": "123456",
+		"state":    state,
+	})
+	if resp == nil || resp.IsError() || resp.Auth == nil {
+		t.Fatalf("bad login response: %#v", resp)
+	}
+	if !reflect.DeepEqual(resp.Auth.Policies, []string{"bob-policy"}) {
+		t.Fatalf("bad policies: %v", resp.Auth.Policies)
+	}
+	if _, ok := resp.Auth.InternalData["password"]; ok {
+		t.Fatal("the response to a challenge must not be stored")
+	}
+
+	// The state can't redirect the request to another server
+	forged := encodeChallengeState("127.0.0.1:1", []byte("otp-state"))
+	resp = testRequest(t, b, s, logical.UpdateOperation, "login/bob", map[string]interface{}{
+		"password": "123456",
+		"state":    forged,
+	})
+	if !resp.IsError() {
+		t.Fatalf("expected a forged state to fail, got %#v", resp)
+	}
+}
+
+func TestBackend_GroupVSA(t *testing.T) {
+	server := newTestRadiusServer(t, false)
+	defer server.Close()
+	b, s := testBackend(t)
+
+	testRequest(t, b, s, logical.CreateOperation, "config", map[string]interface{}{
+		"host":                       server.addr,
+		"secret":                     testRadiusSecret,
+		"unregistered_user_policies": "unregistered",
+		"group_vsa_vendor_id":        testRadiusVendorID,
+		"group_vsa_type":             testRadiusVSAType,
+		"read_timeout":               1,
+	})
+	testRequest(t, b, s, logical.UpdateOperation, "groups/Admins", map[string]interface{}{
+		"policies": "admin-policy",
+	})
+	testRequest(t, b, s, logical.UpdateOperation, "groups/ops", map[string]interface{}{
+		"policies": "ops-policy,admin-policy",
+	})
+
+	resp := testRequest(t, b, s, logical.ListOperation, "groups/", nil)
+	if !reflect.DeepEqual(resp.Data["keys"], []string{"admins", "ops"}) {
+		t.Fatalf("bad groups: %#v", resp.Data["keys"])
+	}
+
+	resp = testRequest(t, b, s, logical.UpdateOperation, "login/alice", map[string]interface{}{
+		"password": "alice-password",
+	})
+	if resp == nil || resp.IsError() || resp.Auth == nil {
+		t.Fatalf("bad login response: %#v", resp)
+	}
+	expected := []string{"unregistered", "admin-policy", "ops-policy"}
+	if !reflect.DeepEqual(resp.Auth.Policies, expected) {
+		t.Fatalf("expected policies %v, got %v", expected, resp.Auth.Policies)
+	}
+	var groups []string
+	for _, alias := range resp.Auth.GroupAliases {
+		groups = append(groups, alias.Name)
+	}
+	if !reflect.DeepEqual(groups, []string{"admins", "ops"}) {
+		t.Fatalf("bad group aliases: %v", groups)
+	}
+
+	resp = testRequest(t, b, s, logical.UpdateOperation, "config", map[string]interface{}{
+		"group_vsa_type": 0,
+	})
+	if !resp.IsError() {
+		t.Fatalf("expected an invalid group_vsa_type to fail, got %#v", resp)
+	}
+}
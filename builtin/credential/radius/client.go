@@ -0,0 +1,238 @@
+package radius
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"layeh.com/radius"
+	"layeh.com/radius/rfc2865"
+)
+
+// messageAuthenticatorType is the type of the Message-Authenticator
+// attribute, defined in RFC 3579.
+const messageAuthenticatorType radius.Type = 80
+
+// serverHealth tracks a server which failed to respond.
+type serverHealth struct {
+	LastError  string
+	Failures   int
+	RetryAfter time.Time
+}
+
+// orderServers returns the configured servers in the order they should be
+// tried: servers which recently failed to respond come last, starting with
+// the one which failed longest ago.
+func (b *backend) orderServers(cfg *ConfigEntry) []string {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	now := time.Now()
+	var healthy, unhealthy []string
+	for _, server := range cfg.servers() {
+		if h, ok := b.health[server]; ok && now.Before(h.RetryAfter) {
+			unhealthy = append(unhealthy, server)
+			continue
+		}
+		healthy = append(healthy, server)
+	}
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		return b.health[unhealthy[i]].RetryAfter.Before(b.health[unhealthy[j]].RetryAfter)
+	})
+
+	return append(healthy, unhealthy...)
+}
+
+func (b *backend) markUnhealthy(cfg *ConfigEntry, server string, err error) {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	h, ok := b.health[server]
+	if !ok {
+		h = &serverHealth{}
+		b.health[server] = h
+	}
+	h.LastError = err.Error()
+	h.Failures++
+	h.RetryAfter = time.Now().Add(time.Duration(cfg.UnhealthyTimeout) * time.Second)
+}
+
+func (b *backend) markHealthy(server string) {
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	delete(b.health, server)
+}
+
+// exchangeFailover sends the packet to the configured servers in turn, until
+// one of them responds. It returns the response along with the server which
+// sent it.
+func (b *backend) exchangeFailover(ctx context.Context, cfg *ConfigEntry, packet *radius.Packet) (*radius.Packet, string, error) {
+	var lastErr error
+	for _, server := range b.orderServers(cfg) {
+		received, err := exchange(ctx, cfg, packet, server)
+		if err == nil {
+			b.markHealthy(server)
+			return received, server, nil
+		}
+
+		b.Logger().Warn("RADIUS server failed to respond", "server", server, "error", err)
+		b.markUnhealthy(cfg, server, err)
+		lastErr = err
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("no RADIUS server configured")
+	}
+	return nil, "", lastErr
+}
+
+// exchange sends the packet to a server and waits for its response, for at
+// most the configured read timeout. Responses which can't be authenticated
+// with the shared secret are ignored.
+func exchange(ctx context.Context, cfg *ConfigEntry, packet *radius.Packet, server string) (*radius.Packet, error) {
+	if cfg.MessageAuthenticator {
+		if err := setMessageAuthenticator(packet); err != nil {
+			return nil, err
+		}
+	}
+
+	wire, err := packet.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.ReadTimeout)*time.Second)
+	defer cancel()
+
+	dialer := net.Dialer{
+		Timeout: time.Duration(cfg.DialTimeout) * time.Second,
+	}
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write(wire); err != nil {
+		return nil, err
+	}
+
+	var incoming [radius.MaxPacketLength]byte
+	for {
+		n, err := conn.Read(incoming[:])
+		if err != nil {
+			return nil, err
+		}
+		raw := incoming[:n]
+
+		if n < 20 || raw[1] != packet.Identifier || !radius.IsAuthenticResponse(raw, wire, packet.Secret) {
+			continue
+		}
+
+		received, err := radius.Parse(raw, packet.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := verifyMessageAuthenticator(raw, packet.Authenticator, packet.Secret, cfg.MessageAuthenticator); err != nil {
+			return nil, err
+		}
+
+		return received, nil
+	}
+}
+
+// setMessageAuthenticator sets the Message-Authenticator attribute of a
+// request, which is the HMAC-MD5 of the packet with the attribute zeroed.
+func setMessageAuthenticator(packet *radius.Packet) error {
+	packet.Set(messageAuthenticatorType, make(radius.Attribute, md5.Size))
+	wire, err := packet.Encode()
+	if err != nil {
+		return err
+	}
+	packet.Set(messageAuthenticatorType, messageAuthenticator(wire, packet.Secret))
+	return nil
+}
+
+func messageAuthenticator(wire, secret []byte) radius.Attribute {
+	mac := hmac.New(md5.New, secret)
+	mac.Write(wire)
+	return mac.Sum(nil)
+}
+
+// verifyMessageAuthenticator checks the Message-Authenticator attribute of
+// the raw response to a request with the given authenticator. The attribute
+// is computed over the response with the authenticator of the request in
+// place of its own.
+func verifyMessageAuthenticator(raw []byte, requestAuthenticator [16]byte, secret []byte, required bool) error {
+	buf := make([]byte, len(raw))
+	copy(buf, raw)
+	copy(buf[4:20], requestAuthenticator[:])
+
+	var received []byte
+	for attrs := buf[20:]; len(attrs) >= 2; {
+		length := int(attrs[1])
+		if length < 2 || length > len(attrs) {
+			return errors.New("invalid attribute length")
+		}
+		if radius.Type(attrs[0]) == messageAuthenticatorType {
+			if length != 2+md5.Size {
+				return errors.New("invalid Message-Authenticator length")
+			}
+			received = make([]byte, md5.Size)
+			copy(received, attrs[2:length])
+			for i := 2; i < length; i++ {
+				attrs[i] = 0
+			}
+		}
+		attrs = attrs[length:]
+	}
+
+	if received == nil {
+		if required {
+			return errors.New("response is missing the Message-Authenticator attribute")
+		}
+		return nil
+	}
+	if !hmac.Equal(received, messageAuthenticator(buf, secret)) {
+		return errors.New("response has an invalid Message-Authenticator attribute")
+	}
+	return nil
+}
+
+// vendorSpecificValues returns the values of the vendor-specific attributes
+// of a packet with the given vendor ID and type.
+func vendorSpecificValues(packet *radius.Packet, vendorID uint32, vendorType byte) ([]string, error) {
+	var values []string
+	for _, attr := range packet.Attributes[rfc2865.VendorSpecific_Type] {
+		id, value, err := radius.VendorSpecific(attr)
+		if err != nil {
+			return nil, errwrap.Wrapf("error parsing vendor-specific attribute: {{err}}", err)
+		}
+		if id != vendorID {
+			continue
+		}
+		subAttrs, err := radius.ParseAttributes(value)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("error parsing vendor-specific attribute of vendor %d: {{err}}", vendorID), err)
+		}
+		for _, v := range subAttrs[radius.Type(vendorType)] {
+			values = append(values, string(v))
+		}
+	}
+	return values, nil
+}
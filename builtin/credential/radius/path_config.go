@@ -2,6 +2,8 @@ package radius
 
 import (
 	"context"
+	"net"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
@@ -28,6 +30,26 @@ func pathConfig(b *backend) *framework.Path {
 					Value: 1812,
 				},
 			},
+			"hosts": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Comma-separated list of additional RADIUS servers to fail over to, as host or host:port. The port defaults to the value of port (optional)",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Failover hosts",
+				},
+			},
+			"unhealthy_timeout": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Default:     30,
+				Description: "Number of seconds a server which failed to respond is tried last for (default: 30)",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Value: 30,
+				},
+			},
+			"message_authenticator": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Default:     false,
+				Description: "Send a Message-Authenticator attribute with requests, and require it in responses (default: false)",
+			},
 			"secret": &framework.FieldSchema{
 				Type:        framework.TypeString,
 				Description: "Secret shared with the RADIUS server",
@@ -73,6 +95,22 @@ func pathConfig(b *backend) *framework.Path {
 					Name: "NAS Identifier",
 				},
 			},
+			"group_vsa_vendor_id": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Default:     0,
+				Description: "Vendor ID of the vendor-specific attribute holding the groups of the user, which are mapped to policies through the groups endpoint (optional)",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Group VSA vendor ID",
+				},
+			},
+			"group_vsa_type": &framework.FieldSchema{
+				Type:        framework.TypeInt,
+				Default:     0,
+				Description: "Vendor type of the vendor-specific attribute holding the groups of the user (optional)",
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Group VSA type",
+				},
+			},
 		},
 
 		ExistenceCheck: b.configExistenceCheck,
@@ -139,11 +177,16 @@ func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, d *f
 	data := map[string]interface{}{
 		"host":                       cfg.Host,
 		"port":                       cfg.Port,
+		"hosts":                      cfg.Hosts,
+		"unhealthy_timeout":          cfg.UnhealthyTimeout,
+		"message_authenticator":      cfg.MessageAuthenticator,
 		"unregistered_user_policies": cfg.UnregisteredUserPolicies,
 		"dial_timeout":               cfg.DialTimeout,
 		"read_timeout":               cfg.ReadTimeout,
 		"nas_port":                   cfg.NasPort,
 		"nas_identifier":             cfg.NasIdentifier,
+		"group_vsa_vendor_id":        cfg.GroupVSAVendorID,
+		"group_vsa_type":             cfg.GroupVSAType,
 	}
 	cfg.PopulateTokenData(data)
 
@@ -172,7 +215,15 @@ func (b *backend) pathConfigCreateUpdate(ctx context.Context, req *logical.Reque
 	} else if req.Operation == logical.CreateOperation {
 		cfg.Host = strings.ToLower(d.Get("host").(string))
 	}
-	if cfg.Host == "" {
+
+	hosts, ok := d.GetOk("hosts")
+	if ok {
+		cfg.Hosts = nil
+		for _, h := range hosts.([]string) {
+			cfg.Hosts = append(cfg.Hosts, strings.ToLower(h))
+		}
+	}
+	if cfg.Host == "" && len(cfg.Hosts) == 0 {
 		return logical.ErrorResponse("config parameter `host` cannot be empty"), nil
 	}
 
@@ -183,6 +234,20 @@ func (b *backend) pathConfigCreateUpdate(ctx context.Context, req *logical.Reque
 		cfg.Port = d.Get("port").(int)
 	}
 
+	unhealthyTimeout, ok := d.GetOk("unhealthy_timeout")
+	if ok {
+		cfg.UnhealthyTimeout = unhealthyTimeout.(int)
+	} else if req.Operation == logical.CreateOperation {
+		cfg.UnhealthyTimeout = d.Get("unhealthy_timeout").(int)
+	}
+
+	messageAuthenticator, ok := d.GetOk("message_authenticator")
+	if ok {
+		cfg.MessageAuthenticator = messageAuthenticator.(bool)
+	} else if req.Operation == logical.CreateOperation {
+		cfg.MessageAuthenticator = d.Get("message_authenticator").(bool)
+	}
+
 	secret, ok := d.GetOk("secret")
 	if ok {
 		cfg.Secret = secret.(string)
@@ -238,6 +303,23 @@ func (b *backend) pathConfigCreateUpdate(ctx context.Context, req *logical.Reque
 		cfg.NasIdentifier = d.Get("nas_identifier").(string)
 	}
 
+	groupVSAVendorID, ok := d.GetOk("group_vsa_vendor_id")
+	if ok {
+		cfg.GroupVSAVendorID = groupVSAVendorID.(int)
+	} else if req.Operation == logical.CreateOperation {
+		cfg.GroupVSAVendorID = d.Get("group_vsa_vendor_id").(int)
+	}
+
+	groupVSAType, ok := d.GetOk("group_vsa_type")
+	if ok {
+		cfg.GroupVSAType = groupVSAType.(int)
+	} else if req.Operation == logical.CreateOperation {
+		cfg.GroupVSAType = d.Get("group_vsa_type").(int)
+	}
+	if cfg.GroupVSAVendorID != 0 && (cfg.GroupVSAType < 1 || cfg.GroupVSAType > 255) {
+		return logical.ErrorResponse("config parameter `group_vsa_type` must be between 1 and 255"), nil
+	}
+
 	entry, err := logical.StorageEntryJSON("config", cfg)
 	if err != nil {
 		return nil, err
@@ -254,12 +336,33 @@ type ConfigEntry struct {
 
 	Host                     string   `json:"host" structs:"host" mapstructure:"host"`
 	Port                     int      `json:"port" structs:"port" mapstructure:"port"`
+	Hosts                    []string `json:"hosts" structs:"hosts" mapstructure:"hosts"`
+	UnhealthyTimeout         int      `json:"unhealthy_timeout" structs:"unhealthy_timeout" mapstructure:"unhealthy_timeout"`
+	MessageAuthenticator     bool     `json:"message_authenticator" structs:"message_authenticator" mapstructure:"message_authenticator"`
 	Secret                   string   `json:"secret" structs:"secret" mapstructure:"secret"`
 	UnregisteredUserPolicies []string `json:"unregistered_user_policies" structs:"unregistered_user_policies" mapstructure:"unregistered_user_policies"`
 	DialTimeout              int      `json:"dial_timeout" structs:"dial_timeout" mapstructure:"dial_timeout"`
 	ReadTimeout              int      `json:"read_timeout" structs:"read_timeout" mapstructure:"read_timeout"`
 	NasPort                  int      `json:"nas_port" structs:"nas_port" mapstructure:"nas_port"`
 	NasIdentifier            string   `json:"nas_identifier" structs:"nas_identifier" mapstructure:"nas_identifier"`
+	GroupVSAVendorID         int      `json:"group_vsa_vendor_id" structs:"group_vsa_vendor_id" mapstructure:"group_vsa_vendor_id"`
+	GroupVSAType             int      `json:"group_vsa_type" structs:"group_vsa_type" mapstructure:"group_vsa_type"`
+}
+
+// servers returns the addresses of the configured servers, starting with
+// host.
+func (c *ConfigEntry) servers() []string {
+	var servers []string
+	for _, h := range append([]string{c.Host}, c.Hosts...) {
+		if h == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(h); err != nil {
+			h = net.JoinHostPort(h, strconv.Itoa(c.Port))
+		}
+		servers = append(servers, h)
+	}
+	return servers
 }
 
 const pathConfigHelpSyn = `
@@ -0,0 +1,164 @@
+package radius
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathGroupsList(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "groups/?$",
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathGroupList,
+		},
+
+		HelpSynopsis:    pathGroupHelpSyn,
+		HelpDescription: pathGroupHelpDesc,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Navigation: true,
+			ItemType:   "Group",
+		},
+	}
+}
+
+func pathGroups(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `groups/(?P<name>.+)`,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the RADIUS group.",
+			},
+
+			"policies": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Comma-separated list of policies associated to the group.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.DeleteOperation: b.pathGroupDelete,
+			logical.ReadOperation:   b.pathGroupRead,
+			logical.UpdateOperation: b.pathGroupWrite,
+			logical.CreateOperation: b.pathGroupWrite,
+		},
+
+		ExistenceCheck: b.groupExistenceCheck,
+
+		HelpSynopsis:    pathGroupHelpSyn,
+		HelpDescription: pathGroupHelpDesc,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Action:   "Create",
+			ItemType: "Group",
+		},
+	}
+}
+
+func (b *backend) groupExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	groupEntry, err := b.group(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+
+	return groupEntry != nil, nil
+}
+
+func (b *backend) group(ctx context.Context, s logical.Storage, name string) (*GroupEntry, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing group name")
+	}
+
+	entry, err := s.Get(ctx, "group/"+strings.ToLower(name))
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result GroupEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (b *backend) pathGroupDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	err := req.Storage.Delete(ctx, "group/"+strings.ToLower(d.Get("name").(string)))
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathGroupRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	group, err := b.group(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"policies": group.Policies,
+		},
+	}, nil
+}
+
+func (b *backend) pathGroupWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+
+	var policies = policyutil.ParsePolicies(d.Get("policies"))
+	for _, policy := range policies {
+		if policy == "root" {
+			return logical.ErrorResponse("root policy cannot be granted by an auth method"), nil
+		}
+	}
+
+	// Store it
+	entry, err := logical.StorageEntryJSON("group/"+strings.ToLower(d.Get("name").(string)), &GroupEntry{
+		Policies: policies,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathGroupList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	groups, err := req.Storage.List(ctx, "group/")
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(groups), nil
+}
+
+type GroupEntry struct {
+	Policies []string
+}
+
+const pathGroupHelpSyn = `
+Manage the policies of RADIUS groups.
+`
+
+const pathGroupHelpDesc = `
+This endpoint allows you to create, read, update, and delete the policies
+associated to groups. The groups of a user are read from the vendor-specific
+attribute configured with "group_vsa_vendor_id" and "group_vsa_type" in the
+Access-Accept response of the RADIUS server.
+
+Deleting a group will not revoke auth for prior authenticated users.
+`
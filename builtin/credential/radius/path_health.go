@@ -0,0 +1,67 @@
+package radius
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathHealth(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "health",
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathHealthRead,
+		},
+
+		HelpSynopsis:    pathHealthHelpSyn,
+		HelpDescription: pathHealthHelpDesc,
+	}
+}
+
+func (b *backend) pathHealthRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.Config(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	b.healthLock.Lock()
+	defer b.healthLock.Unlock()
+
+	now := time.Now()
+	servers := make(map[string]interface{})
+	for _, server := range cfg.servers() {
+		status := map[string]interface{}{
+			"healthy": true,
+		}
+		if h, ok := b.health[server]; ok {
+			status["healthy"] = !now.Before(h.RetryAfter)
+			status["last_error"] = h.LastError
+			status["failures"] = h.Failures
+			status["retry_after"] = h.RetryAfter.Format(time.RFC3339)
+		}
+		servers[server] = status
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"servers": servers,
+		},
+	}, nil
+}
+
+const pathHealthHelpSyn = `
+Report the health of the configured RADIUS servers.
+`
+
+const pathHealthHelpDesc = `
+This endpoint reports, for each configured server, whether it failed to
+respond to the last request sent to it. Servers which failed are tried last
+until "unhealthy_timeout" has passed. The health of the servers is tracked
+separately by each Vault node, and is lost when Vault restarts.
+`
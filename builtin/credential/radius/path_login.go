@@ -1,12 +1,11 @@
 package radius
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
-	"net"
-	"strconv"
 	"strings"
-	"time"
 
 	"layeh.com/radius"
 	. "layeh.com/radius/rfc2865"
@@ -14,6 +13,7 @@ import (
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/cidrutil"
 	"github.com/hashicorp/vault/sdk/helper/policyutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -33,7 +33,12 @@ func pathLogin(b *backend) *framework.Path {
 
 			"password": &framework.FieldSchema{
 				Type:        framework.TypeString,
-				Description: "Password for this user.",
+				Description: "Password for this user, or the response to a challenge.",
+			},
+
+			"state": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "State returned by a previous login which the RADIUS server challenged.",
 			},
 		},
 
@@ -96,22 +101,22 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framew
 		return logical.ErrorResponse("password cannot be empty"), nil
 	}
 
-	policies, resp, err := b.RadiusLogin(ctx, req, username, password)
+	state := d.Get("state").(string)
+
+	result, resp, err := b.RadiusLogin(ctx, req, cfg, username, password, state)
 	// Handle an internal error
 	if err != nil {
 		return nil, err
 	}
+	// Handle a logical error, or a challenge
 	if resp != nil {
-		// Handle a logical error
-		if resp.IsError() {
-			return resp, nil
-		}
+		return resp, nil
 	}
 
 	auth := &logical.Auth{
 		Metadata: map[string]string{
 			"username": username,
-			"policies": strings.Join(policies, ","),
+			"policies": strings.Join(result.Policies, ","),
 		},
 		InternalData: map[string]interface{}{
 			"password": password,
@@ -121,14 +126,29 @@ func (b *backend) pathLogin(ctx context.Context, req *logical.Request, d *framew
 			Name: username,
 		},
 	}
+	// The response to a challenge can't be used again, so renewals of tokens
+	// from challenged logins don't re-authenticate with the server and reuse
+	// the groups it returned instead
+	if state != "" {
+		auth.InternalData = map[string]interface{}{
+			"challenged": true,
+			"groups":     result.Groups,
+		}
+	}
 	cfg.PopulateTokenAuth(auth)
 
-	resp.Auth = auth
-	if policies != nil {
-		resp.Auth.Policies = append(resp.Auth.Policies, policies...)
+	if result.Policies != nil {
+		auth.Policies = append(auth.Policies, result.Policies...)
+	}
+	for _, group := range result.Groups {
+		auth.GroupAliases = append(auth.GroupAliases, &logical.Alias{
+			Name: group,
+		})
 	}
 
-	return resp, nil
+	return &logical.Response{
+		Auth: auth,
+	}, nil
 }
 
 func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
@@ -141,15 +161,35 @@ func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *f
 	}
 
 	username := req.Auth.Metadata["username"]
-	password := req.Auth.InternalData["password"].(string)
 
-	var resp *logical.Response
 	var loginPolicies []string
+	if challenged, _ := req.Auth.InternalData["challenged"].(bool); challenged {
+		var groups []string
+		if groupsRaw, ok := req.Auth.InternalData["groups"].([]interface{}); ok {
+			for _, group := range groupsRaw {
+				groups = append(groups, group.(string))
+			}
+		}
+		loginPolicies, err = b.policies(ctx, req.Storage, cfg, username, groups)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		password := req.Auth.InternalData["password"].(string)
 
-	loginPolicies, resp, err = b.RadiusLogin(ctx, req, username, password)
-	if err != nil || (resp != nil && resp.IsError()) {
-		return resp, err
+		result, resp, err := b.RadiusLogin(ctx, req, cfg, username, password, "")
+		if err != nil {
+			return nil, err
+		}
+		if resp != nil {
+			if resp.IsError() {
+				return resp, nil
+			}
+			return nil, fmt.Errorf("authentication server challenged the renewal")
+		}
+		loginPolicies = result.Policies
 	}
+
 	finalPolicies := cfg.TokenPolicies
 	if loginPolicies != nil {
 		finalPolicies = append(finalPolicies, loginPolicies...)
@@ -165,52 +205,147 @@ func (b *backend) pathLoginRenew(ctx context.Context, req *logical.Request, d *f
 	return &logical.Response{Auth: req.Auth}, nil
 }
 
-func (b *backend) RadiusLogin(ctx context.Context, req *logical.Request, username string, password string) ([]string, *logical.Response, error) {
-	cfg, err := b.Config(ctx, req)
-	if err != nil {
-		return nil, nil, err
-	}
-	if cfg == nil || cfg.Host == "" || cfg.Secret == "" {
+// radiusLoginResult is the outcome of a successful RADIUS authentication.
+type radiusLoginResult struct {
+	Policies []string
+	Groups   []string
+}
+
+// RadiusLogin authenticates a user against the configured RADIUS servers.
+// If state is set, the password is the response to the challenge it came
+// with. When the server challenges the user, the returned response holds the
+// challenge instead of an error.
+func (b *backend) RadiusLogin(ctx context.Context, req *logical.Request, cfg *ConfigEntry, username, password, state string) (*radiusLoginResult, *logical.Response, error) {
+	if cfg == nil || len(cfg.servers()) == 0 || cfg.Secret == "" {
 		return nil, logical.ErrorResponse("radius backend not configured"), nil
 	}
 
-	hostport := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
-
 	packet := radius.New(radius.CodeAccessRequest, []byte(cfg.Secret))
 	UserName_SetString(packet, username)
-	UserPassword_SetString(packet, password)
+	// The password is encrypted in blocks of 16 bytes, which the library
+	// reads past the end of shorter passwords: give it zeroes to pad with
+	passwordBuf := make([]byte, len(password), len(password)+16)
+	copy(passwordBuf, password)
+	if err := UserPassword_Set(packet, passwordBuf); err != nil {
+		return nil, logical.ErrorResponse(err.Error()), nil
+	}
 	if cfg.NasIdentifier != "" {
 		NASIdentifier_AddString(packet, cfg.NasIdentifier)
 	}
 	packet.Add(5, radius.NewInteger(uint32(cfg.NasPort)))
 
-	client := radius.Client{
-		Dialer: net.Dialer{
-			Timeout: time.Duration(cfg.DialTimeout) * time.Second,
-		},
+	var received *radius.Packet
+	var server string
+	var err error
+	if state != "" {
+		// The challenge must be answered to the server which sent it
+		var rawState []byte
+		server, rawState, err = decodeChallengeState(cfg, state)
+		if err != nil {
+			return nil, logical.ErrorResponse(err.Error()), nil
+		}
+		State_Set(packet, rawState)
+
+		received, err = exchange(ctx, cfg, packet, server)
+		if err != nil {
+			b.markUnhealthy(cfg, server, err)
+		} else {
+			b.markHealthy(server)
+		}
+	} else {
+		received, server, err = b.exchangeFailover(ctx, cfg, packet)
 	}
-	clientCtx, cancelFunc := context.WithTimeout(ctx, time.Duration(cfg.ReadTimeout)*time.Second)
-	received, err := client.Exchange(clientCtx, packet, hostport)
-	cancelFunc()
 	if err != nil {
 		return nil, logical.ErrorResponse(err.Error()), nil
 	}
-	if received.Code != radius.CodeAccessAccept {
+
+	switch received.Code {
+	case radius.CodeAccessAccept:
+	case radius.CodeAccessChallenge:
+		return nil, &logical.Response{
+			Data: map[string]interface{}{
+				"state":         encodeChallengeState(server, State_Get(received)),
+				"reply_message": strings.Join(replyMessages(received), "\n"),
+			},
+		}, nil
+	default:
 		return nil, logical.ErrorResponse("access denied by the authentication server"), nil
 	}
 
-	policies := cfg.UnregisteredUserPolicies
+	var groups []string
+	if cfg.GroupVSAVendorID != 0 {
+		groups, err = vendorSpecificValues(received, uint32(cfg.GroupVSAVendorID), byte(cfg.GroupVSAType))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 
-	// Retrieve user entry from storage
-	user, err := b.user(ctx, req.Storage, username)
+	policies, err := b.policies(ctx, req.Storage, cfg, username, groups)
 	if err != nil {
 		return nil, logical.ErrorResponse("could not retrieve user entry from storage"), err
 	}
+
+	return &radiusLoginResult{
+		Policies: policies,
+		Groups:   groups,
+	}, nil, nil
+}
+
+// policies returns the policies of a user who authenticated as a member of
+// the given groups.
+func (b *backend) policies(ctx context.Context, s logical.Storage, cfg *ConfigEntry, username string, groups []string) ([]string, error) {
+	policies := append([]string(nil), cfg.UnregisteredUserPolicies...)
+
+	// Retrieve user entry from storage
+	user, err := b.user(ctx, s, username)
+	if err != nil {
+		return nil, err
+	}
 	if user != nil {
-		policies = user.Policies
+		policies = append([]string(nil), user.Policies...)
+	}
+
+	for _, name := range groups {
+		group, err := b.group(ctx, s, name)
+		if err != nil {
+			return nil, err
+		}
+		if group != nil {
+			policies = append(policies, group.Policies...)
+		}
 	}
 
-	return policies, &logical.Response{}, nil
+	return strutil.RemoveDuplicatesStable(policies, false), nil
+}
+
+func replyMessages(packet *radius.Packet) []string {
+	var messages []string
+	for _, attr := range packet.Attributes[ReplyMessage_Type] {
+		messages = append(messages, string(attr))
+	}
+	return messages
+}
+
+// encodeChallengeState returns the state given to the client along with a
+// challenge, which identifies both the server and its State attribute.
+func encodeChallengeState(server string, state []byte) string {
+	return base64.RawURLEncoding.EncodeToString(append([]byte(server+"\n"), state...))
+}
+
+func decodeChallengeState(cfg *ConfigEntry, encoded string) (string, []byte, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid state")
+	}
+	i := bytes.IndexByte(raw, '\n')
+	if i < 0 {
+		return "", nil, fmt.Errorf("invalid state")
+	}
+	server := string(raw[:i])
+	if !strutil.StrListContains(cfg.servers(), server) {
+		return "", nil, fmt.Errorf("state refers to a server which is no longer configured")
+	}
+	return server, raw[i+1:], nil
 }
 
 const pathLoginSyn = `
@@ -220,4 +355,9 @@ Log in with a username and password.
 const pathLoginDesc = `
 This endpoint authenticates using a username and password. Please be sure to
 read the note on escaping from the path-help for the 'config' endpoint.
+
+If the RADIUS server challenges the user, for instance for a one-time
+password, no token is returned. The response holds the "reply_message" of
+the server and a "state", which must be sent back along with the username
+and the response to the challenge as the password.
 `
@@ -31,6 +31,7 @@ func Backend() *backend {
 			},
 			SealWrapStorage: []string{
 				"config/root",
+				staticRolePath,
 			},
 		},
 
@@ -41,12 +42,17 @@ func Backend() *backend {
 			pathRoles(&b),
 			pathListRoles(&b),
 			pathUser(&b),
+			pathListStaticRoles(&b),
+			pathStaticRoles(&b),
+			pathStaticCreds(&b),
+			pathRotateStaticRole(&b),
 		},
 
 		Secrets: []*framework.Secret{
 			secretAccessKeys(&b),
 		},
 
+		PeriodicFunc:      b.rotateExpiredStaticRoles,
 		WALRollback:       b.walRollback,
 		WALRollbackMinAge: minAwsUserRollbackAge,
 		BackendType:       logical.TypeLogical,
@@ -61,6 +67,9 @@ type backend struct {
 	// Mutex to protect access to reading and writing policies
 	roleMutex sync.RWMutex
 
+	// Mutex to serialize changes to static roles and their rotations
+	staticRoleMutex sync.Mutex
+
 	// Mutex to protect access to iam/sts clients and client configs
 	clientMutex sync.RWMutex
 
@@ -78,6 +87,10 @@ are automatically revoked at the end of the lease.
 After mounting this backend, credentials to generate IAM keys must
 be configured with the "root" path and policies must be written using
 the "roles/" endpoints before any access keys can be generated.
+
+Static roles, written using the "static-roles/" endpoints, instead rotate
+the access keys of existing IAM users on a schedule. Their current keys
+are read from the "static-creds/" endpoints.
 `
 
 // clientIAM returns the configured IAM client. If nil, it constructs a new one
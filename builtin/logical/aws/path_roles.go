@@ -104,6 +104,35 @@ GetFederationToken API call, acting as a filter on permissions available.`,
 				},
 			},
 
+			"session_tags": &framework.FieldSchema{
+				Type: framework.TypeKVPairs,
+				Description: fmt.Sprintf(`Session tags to pass to AWS, as a map of tag keys to values. Only valid
+when credential_type is %s or %s. Values may contain identity templates, such
+as {{identity.entity.metadata.team}}, which are rendered with the entity of
+the requesting token.`, assumedRoleCred, federationTokenCred),
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Session Tags",
+				},
+			},
+
+			"transitive_tag_keys": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Keys of session_tags which persist across role chaining. Only valid when credential_type is " + assumedRoleCred,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Transitive Tag Keys",
+				},
+			},
+
+			"role_session_name": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `Template for the session name of assumed roles, such as
+"vault-{{identity.entity.name}}". Only valid when credential_type is ` + assumedRoleCred + `.
+If not set, a name is generated from the display name of the requesting token.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Role Session Name",
+				},
+			},
+
 			"arn": &framework.FieldSchema{
 				Type:        framework.TypeString,
 				Description: `Use role_arns or policy_arns instead.`,
@@ -284,6 +313,27 @@ func (b *backend) pathRolesWrite(ctx context.Context, req *logical.Request, d *f
 		roleEntry.PermissionsBoundaryARN = permissionsBoundaryARNRaw.(string)
 	}
 
+	if sessionTagsRaw, ok := d.GetOk("session_tags"); ok {
+		if legacyRole != "" {
+			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with session_tags"), nil
+		}
+		roleEntry.SessionTags = sessionTagsRaw.(map[string]string)
+	}
+
+	if transitiveTagKeysRaw, ok := d.GetOk("transitive_tag_keys"); ok {
+		if legacyRole != "" {
+			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with transitive_tag_keys"), nil
+		}
+		roleEntry.TransitiveTagKeys = transitiveTagKeysRaw.([]string)
+	}
+
+	if roleSessionNameRaw, ok := d.GetOk("role_session_name"); ok {
+		if legacyRole != "" {
+			return logical.ErrorResponse("cannot supply deprecated role or policy parameters with role_session_name"), nil
+		}
+		roleEntry.RoleSessionName = roleSessionNameRaw.(string)
+	}
+
 	if legacyRole != "" {
 		roleEntry = upgradeLegacyPolicyEntry(legacyRole)
 		if roleEntry.InvalidData != "" {
@@ -464,17 +514,20 @@ func setAwsRole(ctx context.Context, s logical.Storage, roleName string, roleEnt
 }
 
 type awsRoleEntry struct {
-	CredentialTypes          []string      `json:"credential_types"`                      // Entries must all be in the set of ("iam_user", "assumed_role", "federation_token")
-	PolicyArns               []string      `json:"policy_arns"`                           // ARNs of managed policies to attach to an IAM user
-	RoleArns                 []string      `json:"role_arns"`                             // ARNs of roles to assume for AssumedRole credentials
-	PolicyDocument           string        `json:"policy_document"`                       // JSON-serialized inline policy to attach to IAM users and/or to specify as the Policy parameter in AssumeRole calls
-	InvalidData              string        `json:"invalid_data,omitempty"`                // Invalid role data. Exists to support converting the legacy role data into the new format
-	ProhibitFlexibleCredPath bool          `json:"prohibit_flexible_cred_path,omitempty"` // Disallow accessing STS credentials via the creds path and vice verse
-	Version                  int           `json:"version"`                               // Version number of the role format
-	DefaultSTSTTL            time.Duration `json:"default_sts_ttl"`                       // Default TTL for STS credentials
-	MaxSTSTTL                time.Duration `json:"max_sts_ttl"`                           // Max allowed TTL for STS credentials
-	UserPath                 string        `json:"user_path"`                             // The path for the IAM user when using "iam_user" credential type
-	PermissionsBoundaryARN   string        `json:"permissions_boundary_arn"`              // ARN of an IAM policy to attach as a permissions boundary
+	CredentialTypes          []string          `json:"credential_types"`                      // Entries must all be in the set of ("iam_user", "assumed_role", "federation_token")
+	PolicyArns               []string          `json:"policy_arns"`                           // ARNs of managed policies to attach to an IAM user
+	RoleArns                 []string          `json:"role_arns"`                             // ARNs of roles to assume for AssumedRole credentials
+	PolicyDocument           string            `json:"policy_document"`                       // JSON-serialized inline policy to attach to IAM users and/or to specify as the Policy parameter in AssumeRole calls
+	InvalidData              string            `json:"invalid_data,omitempty"`                // Invalid role data. Exists to support converting the legacy role data into the new format
+	ProhibitFlexibleCredPath bool              `json:"prohibit_flexible_cred_path,omitempty"` // Disallow accessing STS credentials via the creds path and vice verse
+	Version                  int               `json:"version"`                               // Version number of the role format
+	DefaultSTSTTL            time.Duration     `json:"default_sts_ttl"`                       // Default TTL for STS credentials
+	MaxSTSTTL                time.Duration     `json:"max_sts_ttl"`                           // Max allowed TTL for STS credentials
+	UserPath                 string            `json:"user_path"`                             // The path for the IAM user when using "iam_user" credential type
+	PermissionsBoundaryARN   string            `json:"permissions_boundary_arn"`              // ARN of an IAM policy to attach as a permissions boundary
+	SessionTags              map[string]string `json:"session_tags,omitempty"`                // Session tags to pass to AssumeRole and GetFederationToken calls
+	TransitiveTagKeys        []string          `json:"transitive_tag_keys,omitempty"`         // Keys of session tags which persist across role chaining
+	RoleSessionName          string            `json:"role_session_name,omitempty"`           // Template for the session name of assumed roles
 }
 
 func (r *awsRoleEntry) toResponseData() map[string]interface{} {
@@ -487,6 +540,9 @@ func (r *awsRoleEntry) toResponseData() map[string]interface{} {
 		"max_sts_ttl":              int64(r.MaxSTSTTL.Seconds()),
 		"user_path":                r.UserPath,
 		"permissions_boundary_arn": r.PermissionsBoundaryARN,
+		"session_tags":             r.SessionTags,
+		"transitive_tag_keys":      r.TransitiveTagKeys,
+		"role_session_name":        r.RoleSessionName,
 	}
 
 	if r.InvalidData != "" {
@@ -545,6 +601,43 @@ func (r *awsRoleEntry) validate() error {
 		errors = multierror.Append(errors, fmt.Errorf("cannot supply role_arns when credential_type isn't %s", assumedRoleCred))
 	}
 
+	if len(r.SessionTags) > 0 {
+		if !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) && !strutil.StrListContains(r.CredentialTypes, federationTokenCred) {
+			errors = multierror.Append(errors, fmt.Errorf("session_tags parameter only valid for %s and %s credential types", assumedRoleCred, federationTokenCred))
+		}
+		if len(r.SessionTags) > maxSessionTags {
+			errors = multierror.Append(errors, fmt.Errorf("at most %d session_tags may be supplied", maxSessionTags))
+		}
+		for key, value := range r.SessionTags {
+			if len(key) > 128 {
+				errors = multierror.Append(errors, fmt.Errorf("session tag key %q is longer than 128 characters", key))
+			}
+			if err := validateTemplate(value); err != nil {
+				errors = multierror.Append(errors, fmt.Errorf("invalid template in value of session tag %q: %v", key, err))
+			}
+		}
+	}
+
+	if len(r.TransitiveTagKeys) > 0 {
+		if !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) {
+			errors = multierror.Append(errors, fmt.Errorf("cannot supply transitive_tag_keys when credential_type isn't %s", assumedRoleCred))
+		}
+		for _, key := range r.TransitiveTagKeys {
+			if _, ok := r.SessionTags[key]; !ok {
+				errors = multierror.Append(errors, fmt.Errorf("transitive tag key %q is not a key of session_tags", key))
+			}
+		}
+	}
+
+	if r.RoleSessionName != "" {
+		if !strutil.StrListContains(r.CredentialTypes, assumedRoleCred) {
+			errors = multierror.Append(errors, fmt.Errorf("cannot supply role_session_name when credential_type isn't %s", assumedRoleCred))
+		}
+		if err := validateTemplate(r.RoleSessionName); err != nil {
+			errors = multierror.Append(errors, fmt.Errorf("invalid template in role_session_name: %v", err))
+		}
+	}
+
 	return errors.ErrorOrNil()
 }
 
@@ -554,6 +647,10 @@ func compactJSON(input string) (string, error) {
 	return compacted.String(), err
 }
 
+// maxSessionTags is the number of session tags AWS accepts in a single
+// AssumeRole or GetFederationToken call.
+const maxSessionTags = 50
+
 const (
 	assumedRoleCred     = "assumed_role"
 	iamUserCred         = "iam_user"
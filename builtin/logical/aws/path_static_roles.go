@@ -0,0 +1,316 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	staticRolePath = "static-role/"
+
+	// minStaticRotationPeriod is the shortest rotation period of static
+	// roles. Expired roles are rotated by the periodic function of the
+	// backend, which runs about once a minute.
+	minStaticRotationPeriod = time.Minute
+)
+
+func pathListStaticRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-roles/?$",
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathStaticRoleList,
+		},
+
+		HelpSynopsis:    pathListStaticRolesHelpSyn,
+		HelpDescription: pathListStaticRolesHelpDesc,
+	}
+}
+
+func pathStaticRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-roles/" + framework.GenericNameWithAtRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the static role",
+			},
+			"username": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the existing IAM user whose access keys are rotated. Can't be changed after the role is created.",
+			},
+			"rotation_period": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Period after which the access key of the user is rotated. Must be at least one minute.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.CreateOperation: b.pathStaticRolesWrite,
+			logical.UpdateOperation: b.pathStaticRolesWrite,
+			logical.ReadOperation:   b.pathStaticRolesRead,
+			logical.DeleteOperation: b.pathStaticRolesDelete,
+		},
+
+		ExistenceCheck: b.pathStaticRolesExistenceCheck,
+
+		HelpSynopsis:    pathStaticRolesHelpSyn,
+		HelpDescription: pathStaticRolesHelpDesc,
+	}
+}
+
+func pathStaticCreds(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-creds/" + framework.GenericNameWithAtRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the static role",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathStaticCredsRead,
+		},
+
+		HelpSynopsis:    pathStaticCredsHelpSyn,
+		HelpDescription: pathStaticCredsHelpDesc,
+	}
+}
+
+func pathRotateStaticRole(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "rotate-role/" + framework.GenericNameWithAtRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the static role",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRotateStaticRoleUpdate,
+		},
+
+		HelpSynopsis:    pathRotateStaticRoleHelpSyn,
+		HelpDescription: pathRotateStaticRoleHelpDesc,
+	}
+}
+
+type awsStaticRoleEntry struct {
+	Username          string        `json:"username"`            // Name of the IAM user whose access keys are rotated
+	RotationPeriod    time.Duration `json:"rotation_period"`     // Period after which the access key is rotated
+	AccessKeyID       string        `json:"access_key_id"`       // ID of the current access key
+	SecretAccessKey   string        `json:"secret_access_key"`   // Secret of the current access key
+	LastVaultRotation time.Time     `json:"last_vault_rotation"` // Time of the last rotation
+}
+
+// nextRotationTime returns the time at which the access key is due for
+// rotation.
+func (r *awsStaticRoleEntry) nextRotationTime() time.Time {
+	return r.LastVaultRotation.Add(r.RotationPeriod)
+}
+
+func (r *awsStaticRoleEntry) toResponseData() map[string]interface{} {
+	return map[string]interface{}{
+		"username":            r.Username,
+		"rotation_period":     int64(r.RotationPeriod.Seconds()),
+		"last_vault_rotation": r.LastVaultRotation,
+	}
+}
+
+func (b *backend) staticRole(ctx context.Context, s logical.Storage, name string) (*awsStaticRoleEntry, error) {
+	entry, err := s.Get(ctx, staticRolePath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var role awsStaticRoleEntry
+	if err := entry.DecodeJSON(&role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func setStaticRole(ctx context.Context, s logical.Storage, name string, role *awsStaticRoleEntry) error {
+	entry, err := logical.StorageEntryJSON(staticRolePath+name, role)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func (b *backend) pathStaticRoleList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, staticRolePath)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathStaticRolesExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	role, err := b.staticRole(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) pathStaticRolesRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	role, err := b.staticRole(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: role.toResponseData(),
+	}, nil
+}
+
+func (b *backend) pathStaticRolesWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.staticRoleMutex.Lock()
+	defer b.staticRoleMutex.Unlock()
+
+	role, err := b.staticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+
+	isCreate := role == nil
+	if isCreate {
+		role = &awsStaticRoleEntry{}
+	}
+
+	if usernameRaw, ok := d.GetOk("username"); ok {
+		username := usernameRaw.(string)
+		if !isCreate && username != role.Username {
+			return logical.ErrorResponse("username of static role %q can't be changed", name), nil
+		}
+		role.Username = username
+	}
+	if role.Username == "" {
+		return logical.ErrorResponse("missing username"), nil
+	}
+
+	if rotationPeriodRaw, ok := d.GetOk("rotation_period"); ok {
+		role.RotationPeriod = time.Duration(rotationPeriodRaw.(int)) * time.Second
+	}
+	if role.RotationPeriod < minStaticRotationPeriod {
+		return logical.ErrorResponse(fmt.Sprintf("rotation_period must be at least %d seconds", int(minStaticRotationPeriod.Seconds()))), nil
+	}
+
+	// A new role takes over the user right away, so that its credentials are
+	// known to Vault.
+	if isCreate {
+		if err := b.rotateStaticRole(ctx, req.Storage, name, role); err != nil {
+			return logical.ErrorResponse("unable to rotate access key of user %q: %s", role.Username, err), nil
+		}
+		return nil, nil
+	}
+
+	return nil, setStaticRole(ctx, req.Storage, name, role)
+}
+
+func (b *backend) pathStaticRolesDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.staticRoleMutex.Lock()
+	defer b.staticRoleMutex.Unlock()
+
+	return nil, req.Storage.Delete(ctx, staticRolePath+d.Get("name").(string))
+}
+
+func (b *backend) pathStaticCredsRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	role, err := b.staticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("unknown static role: %s", name), nil
+	}
+
+	ttl := time.Until(role.nextRotationTime())
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"access_key":          role.AccessKeyID,
+			"secret_key":          role.SecretAccessKey,
+			"username":            role.Username,
+			"last_vault_rotation": role.LastVaultRotation,
+			"rotation_period":     int64(role.RotationPeriod.Seconds()),
+			"ttl":                 int64(ttl.Seconds()),
+		},
+	}, nil
+}
+
+func (b *backend) pathRotateStaticRoleUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.staticRoleMutex.Lock()
+	defer b.staticRoleMutex.Unlock()
+
+	role, err := b.staticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("unknown static role: %s", name), nil
+	}
+
+	if err := b.rotateStaticRole(ctx, req.Storage, name, role); err != nil {
+		return logical.ErrorResponse("unable to rotate access key of user %q: %s", role.Username, err), nil
+	}
+	return nil, nil
+}
+
+const pathListStaticRolesHelpSyn = `List the existing static roles in this backend`
+
+const pathListStaticRolesHelpDesc = `Static roles will be listed by the role name.`
+
+const pathStaticRolesHelpSyn = `
+Manage static roles, which rotate the access keys of existing IAM users.
+`
+
+const pathStaticRolesHelpDesc = `
+This path manages static roles. A static role takes over the access keys of
+an existing IAM user: when the role is created, Vault creates a new access key
+for the user, and every rotation_period afterwards it replaces the key with a
+new one. Vault manages all access keys of the user, so it may delete keys it
+didn't create to stay within the limit of two keys per user.
+
+Deleting a static role leaves the current access key of the user in place.
+`
+
+const pathStaticCredsHelpSyn = `
+Read the current access key of a static role.
+`
+
+const pathStaticCredsHelpDesc = `
+This path returns the current access key of the IAM user of a static role,
+along with the number of seconds until it is rotated. The credentials aren't
+leased; they stay valid until the next rotation.
+`
+
+const pathRotateStaticRoleHelpSyn = `
+Rotate the access key of a static role right away.
+`
+
+const pathRotateStaticRoleHelpDesc = `
+This path replaces the access key of the IAM user of a static role with a new
+one, and restarts its rotation period.
+`
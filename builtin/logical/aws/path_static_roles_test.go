@@ -0,0 +1,215 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// mockAccessKeyIAMClient keeps the access keys of IAM users in memory.
+type mockAccessKeyIAMClient struct {
+	iamiface.IAMAPI
+
+	sync.Mutex
+	next int
+	keys map[string][]*iam.AccessKeyMetadata
+}
+
+func (m *mockAccessKeyIAMClient) ListAccessKeys(input *iam.ListAccessKeysInput) (*iam.ListAccessKeysOutput, error) {
+	m.Lock()
+	defer m.Unlock()
+	keys, ok := m.keys[aws.StringValue(input.UserName)]
+	if !ok {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "no such user", nil)
+	}
+	return &iam.ListAccessKeysOutput{AccessKeyMetadata: append([]*iam.AccessKeyMetadata(nil), keys...)}, nil
+}
+
+func (m *mockAccessKeyIAMClient) CreateAccessKey(input *iam.CreateAccessKeyInput) (*iam.CreateAccessKeyOutput, error) {
+	m.Lock()
+	defer m.Unlock()
+	username := aws.StringValue(input.UserName)
+	keys, ok := m.keys[username]
+	if !ok {
+		return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "no such user", nil)
+	}
+	if len(keys) >= 2 {
+		return nil, awserr.New(iam.ErrCodeLimitExceededException, "too many access keys", nil)
+	}
+	m.next++
+	id := fmt.Sprintf("AKIA%d", m.next)
+	m.keys[username] = append(keys, &iam.AccessKeyMetadata{
+		AccessKeyId: aws.String(id),
+		CreateDate:  aws.Time(time.Now().Add(time.Duration(m.next) * time.Second)),
+		UserName:    input.UserName,
+	})
+	return &iam.CreateAccessKeyOutput{
+		AccessKey: &iam.AccessKey{
+			AccessKeyId:     aws.String(id),
+			SecretAccessKey: aws.String("secret-" + id),
+			UserName:        input.UserName,
+		},
+	}, nil
+}
+
+func (m *mockAccessKeyIAMClient) DeleteAccessKey(input *iam.DeleteAccessKeyInput) (*iam.DeleteAccessKeyOutput, error) {
+	m.Lock()
+	defer m.Unlock()
+	username := aws.StringValue(input.UserName)
+	keys := m.keys[username]
+	for i, key := range keys {
+		if aws.StringValue(key.AccessKeyId) == aws.StringValue(input.AccessKeyId) {
+			m.keys[username] = append(keys[:i:i], keys[i+1:]...)
+			return &iam.DeleteAccessKeyOutput{}, nil
+		}
+	}
+	return nil, awserr.New(iam.ErrCodeNoSuchEntityException, "no such access key", nil)
+}
+
+func (m *mockAccessKeyIAMClient) keyIDs(username string) []string {
+	m.Lock()
+	defer m.Unlock()
+	var ids []string
+	for _, key := range m.keys[username] {
+		ids = append(ids, aws.StringValue(key.AccessKeyId))
+	}
+	return ids
+}
+
+func TestBackend_StaticRoles(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b := Backend()
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+
+	// The user starts out with two keys which Vault doesn't know about
+	iamClient := &mockAccessKeyIAMClient{
+		keys: map[string][]*iam.AccessKeyMetadata{
+			"deploy": {
+				{AccessKeyId: aws.String("AKIAOLD1"), CreateDate: aws.Time(time.Now().Add(-2 * time.Hour))},
+				{AccessKeyId: aws.String("AKIAOLD2"), CreateDate: aws.Time(time.Now().Add(-time.Hour))},
+			},
+		},
+	}
+	b.iamClient = iamClient
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := request(logical.CreateOperation, "static-roles/deploy", map[string]interface{}{
+		"username":        "deploy",
+		"rotation_period": 30,
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error for short rotation_period, got: %#v", resp)
+	}
+
+	resp = request(logical.CreateOperation, "static-roles/missing", map[string]interface{}{
+		"username":        "missing",
+		"rotation_period": 3600,
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error for missing user, got: %#v", resp)
+	}
+
+	resp = request(logical.CreateOperation, "static-roles/deploy", map[string]interface{}{
+		"username":        "deploy",
+		"rotation_period": 3600,
+	})
+	if resp != nil && resp.IsError() {
+		t.Fatalf("failed to create static role: %#v", resp)
+	}
+
+	// The oldest key made room for the one created by Vault
+	if ids := iamClient.keyIDs("deploy"); len(ids) != 2 || ids[0] != "AKIAOLD2" || ids[1] != "AKIA1" {
+		t.Fatalf("bad access keys after creating role: %v", ids)
+	}
+
+	resp = request(logical.ReadOperation, "static-creds/deploy", nil)
+	if resp.IsError() {
+		t.Fatalf("failed to read static creds: %#v", resp)
+	}
+	if resp.Data["access_key"] != "AKIA1" || resp.Data["secret_key"] != "secret-AKIA1" {
+		t.Fatalf("bad static creds: %#v", resp.Data)
+	}
+	if ttl := resp.Data["ttl"].(int64); ttl <= 3500 || ttl > 3600 {
+		t.Fatalf("bad ttl: %d", ttl)
+	}
+
+	resp = request(logical.UpdateOperation, "static-roles/deploy", map[string]interface{}{
+		"username": "other",
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error changing username, got: %#v", resp)
+	}
+
+	// Rotating deletes the key Vault didn't create, then the previous key
+	resp = request(logical.UpdateOperation, "rotate-role/deploy", nil)
+	if resp != nil && resp.IsError() {
+		t.Fatalf("failed to rotate static role: %#v", resp)
+	}
+	if ids := iamClient.keyIDs("deploy"); len(ids) != 1 || ids[0] != "AKIA2" {
+		t.Fatalf("bad access keys after rotation: %v", ids)
+	}
+
+	// The periodic function leaves roles alone until they expire
+	if err := b.rotateExpiredStaticRoles(context.Background(), &logical.Request{Storage: config.StorageView}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := iamClient.keyIDs("deploy"); len(ids) != 1 || ids[0] != "AKIA2" {
+		t.Fatalf("bad access keys after periodic function: %v", ids)
+	}
+
+	role, err := b.staticRole(context.Background(), config.StorageView, "deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	role.LastVaultRotation = time.Now().Add(-2 * time.Hour)
+	if err := setStaticRole(context.Background(), config.StorageView, "deploy", role); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.rotateExpiredStaticRoles(context.Background(), &logical.Request{Storage: config.StorageView}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := iamClient.keyIDs("deploy"); len(ids) != 1 || ids[0] != "AKIA3" {
+		t.Fatalf("bad access keys after expiry: %v", ids)
+	}
+
+	resp = request(logical.ReadOperation, "static-creds/deploy", nil)
+	if resp.Data["access_key"] != "AKIA3" {
+		t.Fatalf("bad static creds after expiry: %#v", resp.Data)
+	}
+
+	resp = request(logical.ListOperation, "static-roles/", nil)
+	if keys := resp.Data["keys"].([]string); len(keys) != 1 || keys[0] != "deploy" {
+		t.Fatalf("bad static role list: %v", keys)
+	}
+
+	request(logical.DeleteOperation, "static-roles/deploy", nil)
+	resp = request(logical.ReadOperation, "static-roles/deploy", nil)
+	if resp != nil {
+		t.Fatalf("expected static role to be deleted, got: %#v", resp)
+	}
+}
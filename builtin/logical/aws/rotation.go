@@ -0,0 +1,129 @@
+package aws
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// rotateExpiredStaticRoles rotates the access keys of the static roles whose
+// rotation period has passed. It's the periodic function of the backend.
+func (b *backend) rotateExpiredStaticRoles(ctx context.Context, req *logical.Request) error {
+	if !b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary|consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	names, err := req.Storage.List(ctx, staticRolePath)
+	if err != nil {
+		return err
+	}
+
+	b.staticRoleMutex.Lock()
+	defer b.staticRoleMutex.Unlock()
+
+	var errs *multierror.Error
+	now := time.Now()
+	for _, name := range names {
+		role, err := b.staticRole(ctx, req.Storage, name)
+		if err != nil {
+			errs = multierror.Append(errs, errwrap.Wrapf("error reading static role "+name+": {{err}}", err))
+			continue
+		}
+		if role == nil || now.Before(role.nextRotationTime()) {
+			continue
+		}
+
+		if err := b.rotateStaticRole(ctx, req.Storage, name, role); err != nil {
+			b.Logger().Error("unable to rotate access key of static role", "role", name, "username", role.Username, "error", err)
+			errs = multierror.Append(errs, errwrap.Wrapf("error rotating static role "+name+": {{err}}", err))
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// rotateStaticRole replaces the access key of the user of a static role with
+// a new one and stores it in the role. IAM users have at most two access
+// keys, so if the user already has a key besides the current one, which is
+// left over from an interrupted rotation or wasn't created by Vault, it's
+// deleted first. The caller must hold staticRoleMutex.
+func (b *backend) rotateStaticRole(ctx context.Context, s logical.Storage, name string, role *awsStaticRoleEntry) error {
+	client, err := b.clientIAM(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	keysResp, err := client.ListAccessKeys(&iam.ListAccessKeysInput{
+		UserName: aws.String(role.Username),
+	})
+	if err != nil {
+		return errwrap.Wrapf("error listing access keys: {{err}}", err)
+	}
+
+	// Delete the oldest keys other than the current one, until there is
+	// room for a new key.
+	keys := keysResp.AccessKeyMetadata
+	sort.Slice(keys, func(i, j int) bool {
+		return aws.TimeValue(keys[i].CreateDate).Before(aws.TimeValue(keys[j].CreateDate))
+	})
+	count := len(keys)
+	for _, key := range keys {
+		if count < 2 {
+			break
+		}
+		if aws.StringValue(key.AccessKeyId) == role.AccessKeyID {
+			continue
+		}
+		if err := deleteAccessKey(client, role.Username, aws.StringValue(key.AccessKeyId)); err != nil {
+			return errwrap.Wrapf("error deleting access key: {{err}}", err)
+		}
+		count--
+	}
+
+	createResp, err := client.CreateAccessKey(&iam.CreateAccessKeyInput{
+		UserName: aws.String(role.Username),
+	})
+	if err != nil {
+		return errwrap.Wrapf("error creating access key: {{err}}", err)
+	}
+
+	oldAccessKeyID := role.AccessKeyID
+	role.AccessKeyID = aws.StringValue(createResp.AccessKey.AccessKeyId)
+	role.SecretAccessKey = aws.StringValue(createResp.AccessKey.SecretAccessKey)
+	role.LastVaultRotation = time.Now()
+	if err := setStaticRole(ctx, s, name, role); err != nil {
+		// The new key is unknown to Vault, and deleted by the next rotation.
+		return err
+	}
+
+	// A key which fails to be deleted is deleted by the next rotation, so
+	// don't fail the rotation over it.
+	if oldAccessKeyID != "" {
+		if err := deleteAccessKey(client, role.Username, oldAccessKeyID); err != nil {
+			b.Logger().Warn("unable to delete previous access key of static role", "role", name, "access_key_id", oldAccessKeyID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteAccessKey deletes an access key of a user, ignoring keys which no
+// longer exist.
+func deleteAccessKey(client iamiface.IAMAPI, username, accessKeyID string) error {
+	_, err := client.DeleteAccessKey(&iam.DeleteAccessKeyInput{
+		UserName:    aws.String(username),
+		AccessKeyId: aws.String(accessKeyID),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+		return nil
+	}
+	return err
+}
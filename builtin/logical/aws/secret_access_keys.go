@@ -67,7 +67,7 @@ func genUsername(displayName, policyName, userType string) (ret string, warning
 
 func (b *backend) getFederationToken(ctx context.Context, s logical.Storage,
 	displayName, policyName, policy string, policyARNs []string,
-	lifeTimeInSeconds int64) (*logical.Response, error) {
+	session *stsSession, lifeTimeInSeconds int64) (*logical.Response, error) {
 	stsClient, err := b.clientSTS(ctx, s)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
@@ -85,6 +85,9 @@ func (b *backend) getFederationToken(ctx context.Context, s logical.Storage,
 	if len(policyARNs) > 0 {
 		getTokenInput.PolicyArns = convertPolicyARNs(policyARNs)
 	}
+	if len(session.Tags) > 0 {
+		getTokenInput.Tags = convertTags(session.Tags)
+	}
 
 	// If neither a policy document nor policy ARNs are specified, then GetFederationToken will
 	// return credentials equivalent to that of the Vault server itself. We probably don't want
@@ -126,13 +129,16 @@ func (b *backend) getFederationToken(ctx context.Context, s logical.Storage,
 
 func (b *backend) assumeRole(ctx context.Context, s logical.Storage,
 	displayName, roleName, roleArn, policy string, policyARNs []string,
-	lifeTimeInSeconds int64) (*logical.Response, error) {
+	session *stsSession, lifeTimeInSeconds int64) (*logical.Response, error) {
 	stsClient, err := b.clientSTS(ctx, s)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
-	username, usernameWarning := genUsername(displayName, roleName, "iam_user")
+	username, usernameWarning := session.Name, ""
+	if username == "" {
+		username, usernameWarning = genUsername(displayName, roleName, "iam_user")
+	}
 
 	assumeRoleInput := &sts.AssumeRoleInput{
 		RoleSessionName: aws.String(username),
@@ -145,6 +151,12 @@ func (b *backend) assumeRole(ctx context.Context, s logical.Storage,
 	if len(policyARNs) > 0 {
 		assumeRoleInput.SetPolicyArns(convertPolicyARNs(policyARNs))
 	}
+	if len(session.Tags) > 0 {
+		assumeRoleInput.SetTags(convertTags(session.Tags))
+	}
+	if len(session.TransitiveTagKeys) > 0 {
+		assumeRoleInput.SetTransitiveTagKeys(aws.StringSlice(session.TransitiveTagKeys))
+	}
 	tokenResp, err := stsClient.AssumeRole(assumeRoleInput)
 
 	if err != nil {
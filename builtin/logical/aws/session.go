@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// sessionNameRegex matches the session names accepted by AssumeRole.
+var sessionNameRegex = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+// stsSession holds the session name and tags of STS credentials, rendered
+// for the entity of the requesting token.
+type stsSession struct {
+	// Name is the session name of assumed roles. If empty, one is generated.
+	Name              string
+	Tags              map[string]string
+	TransitiveTagKeys []string
+}
+
+// sessionParams renders the session name and tag templates of the role with
+// the entity of the request.
+func (b *backend) sessionParams(req *logical.Request, role *awsRoleEntry) (*stsSession, error) {
+	session := &stsSession{
+		TransitiveTagKeys: role.TransitiveTagKeys,
+	}
+	if role.RoleSessionName == "" && len(role.SessionTags) == 0 {
+		return session, nil
+	}
+
+	var entity *logical.Entity
+	var groups []*logical.Group
+	if req.EntityID != "" {
+		var err error
+		entity, err = b.System().EntityInfo(req.EntityID)
+		if err != nil {
+			return nil, errwrap.Wrapf("error looking up entity: {{err}}", err)
+		}
+		groups, err = b.System().GroupsForEntity(req.EntityID)
+		if err != nil {
+			return nil, errwrap.Wrapf("error looking up groups of entity: {{err}}", err)
+		}
+	}
+
+	render := func(tpl string) (string, error) {
+		_, out, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+			Mode:   identitytpl.ACLTemplating,
+			String: tpl,
+			Entity: entity,
+			Groups: groups,
+		})
+		return out, err
+	}
+
+	if role.RoleSessionName != "" {
+		name, err := render(role.RoleSessionName)
+		if err != nil {
+			return nil, errwrap.Wrapf("error rendering role_session_name: {{err}}", err)
+		}
+		if !sessionNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("rendered role_session_name %q is invalid; it must match %q", name, sessionNameRegex.String())
+		}
+		session.Name = name
+	}
+
+	if len(role.SessionTags) > 0 {
+		session.Tags = make(map[string]string, len(role.SessionTags))
+		for key, tpl := range role.SessionTags {
+			value, err := render(tpl)
+			if err != nil {
+				return nil, errwrap.Wrapf(fmt.Sprintf("error rendering value of session tag %q: {{err}}", key), err)
+			}
+			if len(value) > 256 {
+				return nil, fmt.Errorf("rendered value of session tag %q is longer than 256 characters", key)
+			}
+			session.Tags[key] = value
+		}
+	}
+
+	return session, nil
+}
+
+// validateTemplate checks that an identity template can be parsed.
+func validateTemplate(tpl string) error {
+	_, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+		Mode:              identitytpl.ACLTemplating,
+		String:            tpl,
+		ValidityCheckOnly: true,
+	})
+	return err
+}
+
+// convertTags converts session tags to their STS representation, sorted by
+// key so that requests are deterministic.
+func convertTags(tags map[string]string) []*sts.Tag {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ret := make([]*sts.Tag, 0, len(keys))
+	for _, key := range keys {
+		ret = append(ret, &sts.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(tags[key]),
+		})
+	}
+	return ret
+}
@@ -0,0 +1,182 @@
+package aws
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/aws/aws-sdk-go/service/sts/stsiface"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+type mockSTSClient struct {
+	stsiface.STSAPI
+
+	assumeRoleInput         *sts.AssumeRoleInput
+	getFederationTokenInput *sts.GetFederationTokenInput
+}
+
+func (m *mockSTSClient) credentials() *sts.Credentials {
+	return &sts.Credentials{
+		AccessKeyId:     aws.String("ASIAEXAMPLE"),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}
+}
+
+func (m *mockSTSClient) AssumeRole(input *sts.AssumeRoleInput) (*sts.AssumeRoleOutput, error) {
+	m.assumeRoleInput = input
+	return &sts.AssumeRoleOutput{Credentials: m.credentials()}, nil
+}
+
+func (m *mockSTSClient) GetFederationToken(input *sts.GetFederationTokenInput) (*sts.GetFederationTokenOutput, error) {
+	m.getFederationTokenInput = input
+	return &sts.GetFederationTokenOutput{Credentials: m.credentials()}, nil
+}
+
+func TestBackend_SessionTags(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	sysView := logical.TestSystemView()
+	sysView.EntityVal = &logical.Entity{
+		ID:   "entity-id",
+		Name: "alice",
+		Metadata: map[string]string{
+			"team": "payments",
+		},
+	}
+	config.System = sysView
+
+	b := Backend()
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	stsClient := &mockSTSClient{}
+	b.stsClient = stsClient
+
+	write := func(path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      path,
+			Storage:   config.StorageView,
+			Data:      data,
+			EntityID:  "entity-id",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := write("roles/tagged", map[string]interface{}{
+		"credential_type": assumedRoleCred,
+		"role_arns":       []string{"arn:aws:iam::123456789012:role/RoleName"},
+		"session_tags": map[string]interface{}{
+			"team":    "{{identity.entity.metadata.team}}",
+			"project": "vault",
+		},
+		"transitive_tag_keys": []string{"team"},
+		"role_session_name":   "vault-{{identity.entity.name}}",
+	})
+	if resp.IsError() {
+		t.Fatalf("failed to write role: %#v", resp)
+	}
+
+	resp = write("sts/tagged", nil)
+	if resp.IsError() {
+		t.Fatalf("failed to assume role: %#v", resp)
+	}
+	input := stsClient.assumeRoleInput
+	if name := aws.StringValue(input.RoleSessionName); name != "vault-alice" {
+		t.Fatalf("bad session name: %q", name)
+	}
+	expectedTags := []*sts.Tag{
+		{Key: aws.String("project"), Value: aws.String("vault")},
+		{Key: aws.String("team"), Value: aws.String("payments")},
+	}
+	if !reflect.DeepEqual(input.Tags, expectedTags) {
+		t.Fatalf("bad tags: %v", input.Tags)
+	}
+	if keys := aws.StringValueSlice(input.TransitiveTagKeys); !reflect.DeepEqual(keys, []string{"team"}) {
+		t.Fatalf("bad transitive tag keys: %v", keys)
+	}
+
+	resp = write("roles/federated", map[string]interface{}{
+		"credential_type": federationTokenCred,
+		"policy_arns":     []string{adminAccessPolicyARN},
+		"session_tags":    map[string]interface{}{"team": "{{identity.entity.metadata.team}}"},
+	})
+	if resp.IsError() {
+		t.Fatalf("failed to write role: %#v", resp)
+	}
+	resp = write("sts/federated", nil)
+	if resp.IsError() {
+		t.Fatalf("failed to get federation token: %#v", resp)
+	}
+	expectedTags = []*sts.Tag{
+		{Key: aws.String("team"), Value: aws.String("payments")},
+	}
+	if !reflect.DeepEqual(stsClient.getFederationTokenInput.Tags, expectedTags) {
+		t.Fatalf("bad tags: %v", stsClient.getFederationTokenInput.Tags)
+	}
+
+	// A session name which renders to an invalid name is rejected
+	resp = write("roles/invalid", map[string]interface{}{
+		"credential_type":   assumedRoleCred,
+		"role_arns":         []string{"arn:aws:iam::123456789012:role/RoleName"},
+		"role_session_name": "vault {{identity.entity.name}}",
+	})
+	if resp.IsError() {
+		t.Fatalf("failed to write role: %#v", resp)
+	}
+	resp = write("sts/invalid", nil)
+	if !resp.IsError() || !strings.Contains(resp.Error().Error(), "role_session_name") {
+		t.Fatalf("expected invalid session name error, got: %#v", resp)
+	}
+}
+
+func TestRoleEntryValidationSessionTags(t *testing.T) {
+	roleEntry := awsRoleEntry{
+		CredentialTypes:   []string{assumedRoleCred},
+		SessionTags:       map[string]string{"team": "{{identity.entity.metadata.team}}"},
+		TransitiveTagKeys: []string{"team"},
+		RoleSessionName:   "vault-{{identity.entity.name}}",
+	}
+	if err := roleEntry.validate(); err != nil {
+		t.Errorf("bad: valid roleEntry %#v failed validation: %v", roleEntry, err)
+	}
+
+	roleEntry.TransitiveTagKeys = []string{"project"}
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: transitive tag key missing from session_tags passed validation")
+	}
+
+	roleEntry.TransitiveTagKeys = nil
+	roleEntry.RoleSessionName = "vault-{{identity.entity.name"
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: unbalanced role_session_name template passed validation")
+	}
+
+	roleEntry = awsRoleEntry{
+		CredentialTypes: []string{iamUserCred},
+		SessionTags:     map[string]string{"team": "payments"},
+	}
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: session_tags with %s passed validation", iamUserCred)
+	}
+
+	roleEntry = awsRoleEntry{
+		CredentialTypes:   []string{federationTokenCred},
+		SessionTags:       map[string]string{"team": "payments"},
+		TransitiveTagKeys: []string{"team"},
+	}
+	if roleEntry.validate() == nil {
+		t.Errorf("bad: transitive_tag_keys with %s passed validation", federationTokenCred)
+	}
+}
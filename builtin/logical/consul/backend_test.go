@@ -3,9 +3,14 @@ package consul
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -701,3 +706,240 @@ key "" {
 	policy = "write"
 }
 `
+
+// testConsulServer fakes the ACL token endpoints of Consul, recording the
+// tokens it's asked to create and the headers of the requests.
+type testConsulServer struct {
+	*httptest.Server
+
+	sync.Mutex
+	bootstrapped bool
+	created      []map[string]interface{}
+	deleted      []string
+	headers      []http.Header
+}
+
+func newTestConsulServer() *testConsulServer {
+	s := &testConsulServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+		s.headers = append(s.headers, r.Header.Clone())
+
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/v1/acl/bootstrap":
+			if s.bootstrapped {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte("ACL bootstrap no longer allowed"))
+				return
+			}
+			s.bootstrapped = true
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"AccessorID": "bootstrap-accessor",
+				"SecretID":   "bootstrap-secret",
+			})
+		case r.Method == "PUT" && r.URL.Path == "/v1/acl/token":
+			if r.Header.Get("X-Consul-Token") == "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			var token map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&token); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			s.created = append(s.created, token)
+			token["AccessorID"] = fmt.Sprintf("accessor-%d", len(s.created))
+			token["SecretID"] = fmt.Sprintf("secret-%d", len(s.created))
+			json.NewEncoder(w).Encode(token)
+		case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/v1/acl/token/"):
+			s.deleted = append(s.deleted, strings.TrimPrefix(r.URL.Path, "/v1/acl/token/"))
+			w.Write([]byte("true"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return s
+}
+
+func TestBackend_Bootstrap(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestConsulServer()
+	defer server.Close()
+
+	req := &logical.Request{
+		Storage:   config.StorageView,
+		Operation: logical.UpdateOperation,
+		Path:      "config/access",
+		Data: map[string]interface{}{
+			"address":   server.Listener.Addr().String(),
+			"bootstrap": true,
+			"token":     "token",
+		},
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error bootstrapping with a token: resp:%#v err:%v", resp, err)
+	}
+
+	delete(req.Data, "token")
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to bootstrap: resp:%#v err:%v", resp, err)
+	}
+
+	// The bootstrapped token is used for API calls
+	req.Path = "roles/test"
+	req.Data = map[string]interface{}{
+		"policies": []string{"test"},
+	}
+	if resp, err := b.HandleRequest(context.Background(), req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write role: resp:%#v err:%v", resp, err)
+	}
+	req.Operation = logical.ReadOperation
+	req.Path = "creds/test"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("failed to read creds: resp:%#v err:%v", resp, err)
+	}
+	if token := server.headers[len(server.headers)-1].Get("X-Consul-Token"); token != "bootstrap-secret" {
+		t.Fatalf("bad token used for API calls: %q", token)
+	}
+
+	// Bootstrapping only works once
+	req.Operation = logical.UpdateOperation
+	req.Path = "config/access"
+	req.Data = map[string]interface{}{
+		"address":   server.Listener.Addr().String(),
+		"bootstrap": true,
+	}
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error bootstrapping twice: resp:%#v err:%v", resp, err)
+	}
+}
+
+func TestBackend_Identities(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newTestConsulServer()
+	defer server.Close()
+
+	req := &logical.Request{
+		Storage:   config.StorageView,
+		Operation: logical.UpdateOperation,
+		Path:      "config/access",
+		Data: map[string]interface{}{
+			"address": server.Listener.Addr().String(),
+			"token":   "management",
+		},
+	}
+	if resp, err := b.HandleRequest(context.Background(), req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write configuration: resp:%#v err:%v", resp, err)
+	}
+
+	req.Path = "roles/invalid"
+	req.Data = map[string]interface{}{
+		"node_identities": []string{"web"},
+	}
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected error for node identity without datacenter: resp:%#v err:%v", resp, err)
+	}
+
+	req.Path = "roles/test"
+	req.Data = map[string]interface{}{
+		"consul_roles":       "ops,dev",
+		"service_identities": []string{"api:dc1,dc2", "web"},
+		"node_identities":    []string{"node-1:dc1"},
+		"consul_namespace":   "team-a",
+		"partition":          "part-1",
+		"local":              true,
+	}
+	if resp, err := b.HandleRequest(context.Background(), req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to write role: resp:%#v err:%v", resp, err)
+	}
+
+	req.Operation = logical.ReadOperation
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("failed to read role: resp:%#v err:%v", resp, err)
+	}
+	expectedRole := map[string]interface{}{
+		"lease":              int64(0),
+		"ttl":                int64(0),
+		"max_ttl":            int64(0),
+		"token_type":         "client",
+		"local":              true,
+		"consul_roles":       []string{"ops", "dev"},
+		"service_identities": []string{"api:dc1,dc2", "web"},
+		"node_identities":    []string{"node-1:dc1"},
+		"consul_namespace":   "team-a",
+		"partition":          "part-1",
+	}
+	if !reflect.DeepEqual(resp.Data, expectedRole) {
+		t.Fatalf("bad role: expected:%#v\nactual:%#v", expectedRole, resp.Data)
+	}
+
+	req.Path = "creds/test"
+	resp, err = b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("failed to read creds: resp:%#v err:%v", resp, err)
+	}
+	if resp.Data["token"] != "secret-1" || resp.Data["accessor"] != "accessor-1" || resp.Data["local"] != true {
+		t.Fatalf("bad creds: %#v", resp.Data)
+	}
+
+	created := server.created[0]
+	expectedToken := map[string]interface{}{
+		"Roles": []interface{}{
+			map[string]interface{}{"ID": "", "Name": "ops"},
+			map[string]interface{}{"ID": "", "Name": "dev"},
+		},
+		"ServiceIdentities": []interface{}{
+			map[string]interface{}{"ServiceName": "api", "Datacenters": []interface{}{"dc1", "dc2"}},
+			map[string]interface{}{"ServiceName": "web"},
+		},
+		"NodeIdentities": []interface{}{
+			map[string]interface{}{"NodeName": "node-1", "Datacenter": "dc1"},
+		},
+		"Local":     true,
+		"Namespace": "team-a",
+		"Partition": "part-1",
+	}
+	for key, expected := range expectedToken {
+		if !reflect.DeepEqual(created[key], expected) {
+			t.Fatalf("bad %s of created token: expected:%#v\nactual:%#v", key, expected, created[key])
+		}
+	}
+	if _, ok := created["Policies"]; ok {
+		t.Fatalf("unexpected policies in created token: %#v", created["Policies"])
+	}
+	headers := server.headers[len(server.headers)-1]
+	if headers.Get("X-Consul-Namespace") != "team-a" || headers.Get("X-Consul-Partition") != "part-1" {
+		t.Fatalf("bad scope headers: %#v", headers)
+	}
+
+	// Revocation happens in the namespace and partition of the token
+	req.Operation = logical.RevokeOperation
+	req.Secret = resp.Secret
+	if resp, err := b.HandleRequest(context.Background(), req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to revoke creds: resp:%#v err:%v", resp, err)
+	}
+	if !reflect.DeepEqual(server.deleted, []string{"accessor-1"}) {
+		t.Fatalf("bad deleted tokens: %v", server.deleted)
+	}
+	headers = server.headers[len(server.headers)-1]
+	if headers.Get("X-Consul-Namespace") != "team-a" || headers.Get("X-Consul-Partition") != "part-1" {
+		t.Fatalf("bad scope headers on revocation: %#v", headers)
+	}
+}
@@ -3,12 +3,20 @@ package consul
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
 func (b *backend) client(ctx context.Context, s logical.Storage) (*api.Client, error, error) {
+	return b.scopedClient(ctx, s, "", "")
+}
+
+// scopedClient returns a client whose requests apply to the given Consul
+// namespace and admin partition. Empty values leave the choice to Consul,
+// which uses the ones of the configured token.
+func (b *backend) scopedClient(ctx context.Context, s logical.Storage, namespace, partition string) (*api.Client, error, error) {
 	conf, userErr, intErr := b.readConfigAccess(ctx, s)
 	if intErr != nil {
 		return nil, nil, intErr
@@ -25,6 +33,39 @@ func (b *backend) client(ctx context.Context, s logical.Storage) (*api.Client, e
 	consulConf.Scheme = conf.Scheme
 	consulConf.Token = conf.Token
 
+	if namespace != "" || partition != "" {
+		httpClient, err := api.NewHttpClient(consulConf.Transport, consulConf.TLSConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		httpClient.Transport = &scopeTransport{
+			namespace: namespace,
+			partition: partition,
+			next:      httpClient.Transport,
+		}
+		consulConf.HttpClient = httpClient
+	}
+
 	client, err := api.NewClient(consulConf)
 	return client, nil, err
 }
+
+// scopeTransport sets the namespace and partition headers of the requests
+// it sends, since this version of the Consul API client doesn't support
+// them.
+type scopeTransport struct {
+	namespace string
+	partition string
+	next      http.RoundTripper
+}
+
+func (t *scopeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if t.namespace != "" {
+		req.Header.Set("X-Consul-Namespace", t.namespace)
+	}
+	if t.partition != "" {
+		req.Header.Set("X-Consul-Partition", t.partition)
+	}
+	return t.next.RoundTrip(req)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -32,6 +33,13 @@ func pathConfigAccess(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: "Token for API calls",
 			},
+
+			"bootstrap": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `Bootstrap the ACL system of a fresh Consul cluster,
+and use the resulting management token for API calls instead of "token".
+Bootstrapping only succeeds once per cluster.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -79,11 +87,32 @@ func (b *backend) pathConfigAccessRead(ctx context.Context, req *logical.Request
 }
 
 func (b *backend) pathConfigAccessWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	entry, err := logical.StorageEntryJSON("config/access", accessConfig{
+	config := accessConfig{
 		Address: data.Get("address").(string),
 		Scheme:  data.Get("scheme").(string),
 		Token:   data.Get("token").(string),
-	})
+	}
+
+	if data.Get("bootstrap").(bool) {
+		if config.Token != "" {
+			return logical.ErrorResponse("token can't be set when bootstrapping"), nil
+		}
+
+		consulConf := api.DefaultNonPooledConfig()
+		consulConf.Address = config.Address
+		consulConf.Scheme = config.Scheme
+		client, err := api.NewClient(consulConf)
+		if err != nil {
+			return nil, err
+		}
+		token, _, err := client.ACL().Bootstrap()
+		if err != nil {
+			return logical.ErrorResponse("failed to bootstrap Consul ACLs: %s", err), nil
+		}
+		config.Token = token.SecretID
+	}
+
+	entry, err := logical.StorageEntryJSON("config/access", config)
 	if err != nil {
 		return nil, err
 	}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/consul/api"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
 )
 
@@ -41,6 +44,39 @@ for 'client' tokens. Required for Consul pre-1.4.`,
 for Consul 1.4 or above.`,
 			},
 
+			"consul_roles": &framework.FieldSchema{
+				Type: framework.TypeCommaStringSlice,
+				Description: `List of Consul ACL roles to attach to the token.
+Available in Consul 1.5 and above.`,
+			},
+
+			"service_identities": &framework.FieldSchema{
+				Type: framework.TypeStringSlice,
+				Description: `List of service identities to attach to the token,
+each of the form "<service>[:<datacenter>,<datacenter>...]". Available in
+Consul 1.5 and above.`,
+			},
+
+			"node_identities": &framework.FieldSchema{
+				Type: framework.TypeStringSlice,
+				Description: `List of node identities to attach to the token,
+each of the form "<node>:<datacenter>". Available in Consul 1.8 and above.`,
+			},
+
+			"consul_namespace": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `Consul namespace to create the token in. Defaults
+to the namespace of the token Vault uses. Available in Consul Enterprise 1.7
+and above.`,
+			},
+
+			"partition": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `Consul admin partition to create the token in.
+Defaults to the partition of the token Vault uses. Available in Consul
+Enterprise 1.11 and above.`,
+			},
+
 			"local": &framework.FieldSchema{
 				Type: framework.TypeBool,
 				Description: `Indicates that the token should not be replicated globally 
@@ -126,6 +162,21 @@ func (b *backend) pathRolesRead(ctx context.Context, req *logical.Request, d *fr
 	if len(result.Policies) > 0 {
 		resp.Data["policies"] = result.Policies
 	}
+	if len(result.ConsulRoles) > 0 {
+		resp.Data["consul_roles"] = result.ConsulRoles
+	}
+	if len(result.ServiceIdentities) > 0 {
+		resp.Data["service_identities"] = result.ServiceIdentities
+	}
+	if len(result.NodeIdentities) > 0 {
+		resp.Data["node_identities"] = result.NodeIdentities
+	}
+	if result.ConsulNamespace != "" {
+		resp.Data["consul_namespace"] = result.ConsulNamespace
+	}
+	if result.Partition != "" {
+		resp.Data["partition"] = result.Partition
+	}
 	return resp, nil
 }
 
@@ -135,13 +186,35 @@ func (b *backend) pathRolesWrite(ctx context.Context, req *logical.Request, d *f
 	name := d.Get("name").(string)
 	policies := d.Get("policies").([]string)
 	local := d.Get("local").(bool)
+	consulRoles := d.Get("consul_roles").([]string)
+	serviceIdentities := d.Get("service_identities").([]string)
+	nodeIdentities := d.Get("node_identities").([]string)
+	consulNamespace := d.Get("consul_namespace").(string)
+	partition := d.Get("partition").(string)
+
+	if _, err := parseServiceIdentities(serviceIdentities); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if _, err := parseNodeIdentities(nodeIdentities); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	hasIdentities := len(policies) > 0 || len(consulRoles) > 0 || len(serviceIdentities) > 0 || len(nodeIdentities) > 0
+	if hasIdentities && policy != "" {
+		return logical.ErrorResponse(
+			"a policy document can't be combined with policies, consul_roles, service_identities or node_identities"), nil
+	}
+	if policy != "" && (consulNamespace != "" || partition != "") {
+		return logical.ErrorResponse(
+			"consul_namespace and partition can't be used with a policy document"), nil
+	}
 
-	if len(policies) == 0 {
+	if !hasIdentities {
 		switch tokenType {
 		case "client":
 			if policy == "" {
 				return logical.ErrorResponse(
-					"Use either a policy document, or a list of policies, depending on your Consul version"), nil
+					"Use either a policy document, or a list of policies, consul_roles, service_identities or node_identities, depending on your Consul version"), nil
 			}
 		case "management":
 		default:
@@ -180,6 +253,12 @@ func (b *backend) pathRolesWrite(ctx context.Context, req *logical.Request, d *f
 		TTL:       ttl,
 		MaxTTL:    maxTTL,
 		Local:     local,
+
+		ConsulRoles:       consulRoles,
+		ServiceIdentities: serviceIdentities,
+		NodeIdentities:    nodeIdentities,
+		ConsulNamespace:   consulNamespace,
+		Partition:         partition,
 	})
 	if err != nil {
 		return nil, err
@@ -207,4 +286,54 @@ type roleConfig struct {
 	MaxTTL    time.Duration `json:"max_ttl"`
 	TokenType string        `json:"token_type"`
 	Local     bool          `json:"local"`
+
+	ConsulRoles       []string `json:"consul_roles"`
+	ServiceIdentities []string `json:"service_identities"`
+	NodeIdentities    []string `json:"node_identities"`
+	ConsulNamespace   string   `json:"consul_namespace"`
+	Partition         string   `json:"partition"`
+}
+
+// parseServiceIdentities parses service identities of the form
+// "<service>[:<datacenter>,<datacenter>...]".
+func parseServiceIdentities(identities []string) ([]*api.ACLServiceIdentity, error) {
+	var ret []*api.ACLServiceIdentity
+	for _, identity := range identities {
+		parts := strings.SplitN(identity, ":", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("invalid service identity %q: missing service name", identity)
+		}
+		serviceIdentity := &api.ACLServiceIdentity{
+			ServiceName: parts[0],
+		}
+		if len(parts) == 2 {
+			serviceIdentity.Datacenters = strutil.ParseDedupAndSortStrings(parts[1], ",")
+		}
+		ret = append(ret, serviceIdentity)
+	}
+	return ret, nil
+}
+
+// aclNodeIdentity is a node identity, which the vendored Consul API doesn't
+// know about yet.
+type aclNodeIdentity struct {
+	NodeName   string
+	Datacenter string
+}
+
+// parseNodeIdentities parses node identities of the form
+// "<node>:<datacenter>".
+func parseNodeIdentities(identities []string) ([]*aclNodeIdentity, error) {
+	var ret []*aclNodeIdentity
+	for _, identity := range identities {
+		parts := strings.SplitN(identity, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid node identity %q: must be of the form <node>:<datacenter>", identity)
+		}
+		ret = append(ret, &aclNodeIdentity{
+			NodeName:   parts[0],
+			Datacenter: parts[1],
+		})
+	}
+	return ret, nil
 }
@@ -97,11 +97,44 @@ func (b *backend) pathTokenRead(ctx context.Context, req *logical.Request, d *fr
 			Name: policyName,
 		})
 	}
-	token, _, err := c.ACL().TokenCreate(&api.ACLToken{
-		Description: tokenName,
-		Policies:    policyLink,
-		Local:       result.Local,
-	}, writeOpts)
+	var roleLink = []*api.ACLTokenRoleLink{}
+	for _, roleName := range result.ConsulRoles {
+		roleLink = append(roleLink, &api.ACLTokenRoleLink{
+			Name: roleName,
+		})
+	}
+	serviceIdentities, err := parseServiceIdentities(result.ServiceIdentities)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	nodeIdentities, err := parseNodeIdentities(result.NodeIdentities)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	// The namespace and partition are set on both the request and the token,
+	// as Consul versions differ in which one they honor.
+	if result.ConsulNamespace != "" || result.Partition != "" {
+		c, userErr, intErr = b.scopedClient(ctx, req.Storage, result.ConsulNamespace, result.Partition)
+		if intErr != nil {
+			return nil, intErr
+		}
+		if userErr != nil {
+			return logical.ErrorResponse(userErr.Error()), nil
+		}
+	}
+
+	var token aclToken
+	_, err = c.Raw().Write("/v1/acl/token", &aclToken{
+		Description:       tokenName,
+		Policies:          policyLink,
+		Roles:             roleLink,
+		ServiceIdentities: serviceIdentities,
+		NodeIdentities:    nodeIdentities,
+		Local:             result.Local,
+		Namespace:         result.ConsulNamespace,
+		Partition:         result.Partition,
+	}, &token, writeOpts)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
@@ -112,12 +145,29 @@ func (b *backend) pathTokenRead(ctx context.Context, req *logical.Request, d *fr
 		"accessor": token.AccessorID,
 		"local":    token.Local,
 	}, map[string]interface{}{
-		"token":   token.AccessorID,
-		"role":    role,
-		"version": tokenPolicyType,
+		"token":            token.AccessorID,
+		"role":             role,
+		"version":          tokenPolicyType,
+		"consul_namespace": result.ConsulNamespace,
+		"partition":        result.Partition,
 	})
 	s.Secret.TTL = result.TTL
 	s.Secret.MaxTTL = result.MaxTTL
 
 	return s, nil
 }
+
+// aclToken is an ACL token of Consul 1.4 and above, including the fields
+// the vendored Consul API doesn't know about yet.
+type aclToken struct {
+	AccessorID        string                    `json:",omitempty"`
+	SecretID          string                    `json:",omitempty"`
+	Description       string                    `json:",omitempty"`
+	Policies          []*api.ACLTokenPolicyLink `json:",omitempty"`
+	Roles             []*api.ACLTokenRoleLink   `json:",omitempty"`
+	ServiceIdentities []*api.ACLServiceIdentity `json:",omitempty"`
+	NodeIdentities    []*aclNodeIdentity        `json:",omitempty"`
+	Local             bool
+	Namespace         string `json:",omitempty"`
+	Partition         string `json:",omitempty"`
+}
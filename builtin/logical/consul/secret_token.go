@@ -58,7 +58,15 @@ func (b *backend) secretTokenRenew(ctx context.Context, req *logical.Request, d
 }
 
 func (b *backend) secretTokenRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
-	c, userErr, intErr := b.client(ctx, req.Storage)
+	var namespace, partition string
+	if namespaceRaw, ok := req.Secret.InternalData["consul_namespace"]; ok {
+		namespace, _ = namespaceRaw.(string)
+	}
+	if partitionRaw, ok := req.Secret.InternalData["partition"]; ok {
+		partition, _ = partitionRaw.(string)
+	}
+
+	c, userErr, intErr := b.scopedClient(ctx, req.Storage, namespace, partition)
 	if intErr != nil {
 		return nil, intErr
 	}
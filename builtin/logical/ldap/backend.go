@@ -0,0 +1,193 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+)
+
+// Factory creates and configures the backend
+func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
+	b := Backend(conf)
+	if err := b.Setup(ctx, conf); err != nil {
+		return nil, err
+	}
+
+	b.credRotationQueue = queue.New()
+	// Create a context with a cancel method for processing any WAL entries and
+	// populating the queue
+	ictx, cancel := context.WithCancel(context.Background())
+	b.cancelQueue = cancel
+	// Load queue and kickoff new periodic ticker
+	go b.initQueue(ictx, conf)
+	return b, nil
+}
+
+// Backend creates a new backend with all the paths and secrets belonging to it
+func Backend(conf *logical.BackendConfig) *backend {
+	var b backend
+	b.Backend = &framework.Backend{
+		Help: strings.TrimSpace(backendHelp),
+
+		PathsSpecial: &logical.Paths{
+			LocalStorage: []string{
+				framework.WALPrefix,
+			},
+			SealWrapStorage: []string{
+				"config",
+				"static-role/*",
+				"password/*",
+			},
+		},
+
+		Paths: framework.PathAppend(
+			[]*framework.Path{
+				pathConfig(&b),
+				pathRotateRoot(&b),
+			},
+			pathStaticRoles(&b),
+			pathRoles(&b),
+			pathLibrary(&b),
+			[]*framework.Path{
+				pathStaticCreds(&b),
+				pathRotateRole(&b),
+				pathCreds(&b),
+			},
+		),
+
+		Secrets: []*framework.Secret{
+			secretCreds(&b),
+			secretServiceAccount(&b),
+		},
+
+		Clean:       b.clean,
+		BackendType: logical.TypeLogical,
+	}
+
+	b.ldap = ldaputil.NewLDAP()
+	b.roleLocks = locksutil.CreateLocks()
+
+	return &b
+}
+
+type backend struct {
+	*framework.Backend
+	sync.RWMutex
+
+	// ldap is used to dial the directory. It is replaced in tests.
+	ldap ldaputil.LDAP
+
+	// credRotationQueue is an in-memory priority queue used to track static
+	// roles that require periodic rotation. Only backends mounted on a
+	// primary server or as a local mount perform the rotations.
+	//
+	// cancelQueue is used to remove the priority queue and terminate the
+	// background ticker.
+	credRotationQueue *queue.PriorityQueue
+	cancelQueue       context.CancelFunc
+
+	// roleLocks is used to lock modifications to static roles, to ensure
+	// concurrent requests and the rotation ticker don't set a password for
+	// the same account at once.
+	roleLocks []*locksutil.LockEntry
+
+	// checkOutLock guards the check-out state of the library service
+	// accounts.
+	checkOutLock sync.Mutex
+}
+
+// connect returns the configuration and a connection to the directory bound
+// with the configured credentials. The caller must close the connection.
+func (b *backend) connect(ctx context.Context, s logical.Storage) (*ldapConfig, ldaputil.Connection, error) {
+	cfg, err := b.config(ctx, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg == nil {
+		return nil, nil, errors.New("the LDAP secrets engine is not configured")
+	}
+
+	client := &ldaputil.Client{
+		Logger: b.Logger(),
+		LDAP:   b.ldap,
+	}
+	conn, err := client.DialLDAP(cfg.ConfigEntry)
+	if err != nil {
+		return nil, nil, errwrap.Wrapf("error connecting to the LDAP server: {{err}}", err)
+	}
+	if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+		conn.Close()
+		return nil, nil, errwrap.Wrapf(fmt.Sprintf("error binding as %q: {{err}}", cfg.BindDN), err)
+	}
+
+	return cfg, conn, nil
+}
+
+// accountDN returns the DN of the account with the given username, searching
+// for an entry whose userattr matches it below userdn.
+func accountDN(cfg *ldapConfig, conn ldaputil.Connection, username string) (string, error) {
+	if cfg.UserDN == "" {
+		return "", errors.New("userdn must be configured to look up accounts by username")
+	}
+
+	filter := fmt.Sprintf("(%s=%s)", cfg.UserAttr, ldap.EscapeFilter(username))
+	result, err := conn.Search(&ldap.SearchRequest{
+		BaseDN:     cfg.UserDN,
+		Scope:      ldap.ScopeWholeSubtree,
+		Filter:     filter,
+		Attributes: []string{"dn"},
+		SizeLimit:  2,
+	})
+	if err != nil {
+		return "", errwrap.Wrapf(fmt.Sprintf("error searching for account %q: {{err}}", username), err)
+	}
+	switch len(result.Entries) {
+	case 0:
+		return "", fmt.Errorf("account %q not found", username)
+	case 1:
+		return result.Entries[0].DN, nil
+	default:
+		return "", fmt.Errorf("multiple entries found for account %q", username)
+	}
+}
+
+// invalidateQueue cancels any background queue loading and destroys the queue.
+func (b *backend) invalidateQueue() {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.cancelQueue != nil {
+		b.cancelQueue()
+	}
+	b.credRotationQueue = nil
+}
+
+// clean cancels any rotation queue loading operation and terminates the
+// background ticker.
+func (b *backend) clean(ctx context.Context) {
+	b.invalidateQueue()
+}
+
+const backendHelp = `
+The LDAP secrets engine manages the credentials of accounts in an LDAP
+directory, such as OpenLDAP or Active Directory.
+
+Static roles map to existing accounts whose passwords are rotated on a
+schedule. Dynamic roles create accounts on demand from LDIF templates and
+delete them when their lease expires. Libraries lend a set of service
+accounts to clients, rotating the password of each account when it is
+checked back in.
+
+After mounting this secrets engine, configure the connection to the directory
+with the "config" endpoint.
+`
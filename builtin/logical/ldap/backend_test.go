@@ -0,0 +1,733 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+)
+
+const (
+	testBindDN   = "cn=admin,dc=example,dc=com"
+	testBindPass = "admin-password"
+	testUserDN   = "ou=users,dc=example,dc=com"
+)
+
+// testDirectory is an in-memory LDAP directory, holding entries keyed by
+// their lowercased DN.
+type testDirectory struct {
+	l       sync.Mutex
+	entries map[string]map[string][]string
+}
+
+func newTestDirectory() *testDirectory {
+	d := &testDirectory{
+		entries: make(map[string]map[string][]string),
+	}
+	d.add(testBindDN, map[string][]string{"cn": {"admin"}, "userPassword": {testBindPass}})
+	d.add(testUserDN, map[string][]string{"ou": {"users"}})
+	d.add("cn=staff,dc=example,dc=com", map[string][]string{"cn": {"staff"}})
+	for _, user := range []string{"alice", "bob", "carol", "dave"} {
+		d.add(fmt.Sprintf("uid=%s,%s", user, testUserDN), map[string][]string{"uid": {user}, "userPassword": {"initial"}})
+	}
+	return d
+}
+
+func (d *testDirectory) add(dn string, attrs map[string][]string) {
+	d.entries[strings.ToLower(dn)] = attrs
+}
+
+func (d *testDirectory) entry(dn string) map[string][]string {
+	d.l.Lock()
+	defer d.l.Unlock()
+	return d.entries[strings.ToLower(dn)]
+}
+
+func (d *testDirectory) password(dn string) string {
+	if entry := d.entry(dn); entry != nil && len(entry["userPassword"]) > 0 {
+		return entry["userPassword"][0]
+	}
+	return ""
+}
+
+func (d *testDirectory) Dial(network, addr string) (ldaputil.Connection, error) {
+	return &testConnection{dir: d}, nil
+}
+
+func (d *testDirectory) DialTLS(network, addr string, config *tls.Config) (ldaputil.Connection, error) {
+	return &testConnection{dir: d}, nil
+}
+
+type testConnection struct {
+	dir *testDirectory
+}
+
+func (c *testConnection) Add(req *ldap.AddRequest) error {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	if _, ok := c.dir.entries[strings.ToLower(req.DN)]; ok {
+		return ldap.NewError(ldap.LDAPResultEntryAlreadyExists, fmt.Errorf("entry %q already exists", req.DN))
+	}
+	attrs := make(map[string][]string)
+	for _, attr := range req.Attributes {
+		attrs[attr.Type] = attr.Vals
+	}
+	c.dir.entries[strings.ToLower(req.DN)] = attrs
+	return nil
+}
+
+func (c *testConnection) Bind(username, password string) error {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	entry, ok := c.dir.entries[strings.ToLower(username)]
+	if !ok || len(entry["userPassword"]) == 0 || entry["userPassword"][0] != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, fmt.Errorf("invalid credentials for %q", username))
+	}
+	return nil
+}
+
+func (c *testConnection) Close() {}
+
+func (c *testConnection) Del(req *ldap.DelRequest) error {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	if _, ok := c.dir.entries[strings.ToLower(req.DN)]; !ok {
+		return ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("entry %q not found", req.DN))
+	}
+	delete(c.dir.entries, strings.ToLower(req.DN))
+	return nil
+}
+
+func (c *testConnection) Modify(req *ldap.ModifyRequest) error {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	entry, ok := c.dir.entries[strings.ToLower(req.DN)]
+	if !ok {
+		return ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("entry %q not found", req.DN))
+	}
+	for _, change := range req.Changes {
+		attr := change.Modification.Type
+		switch change.Operation {
+		case ldap.AddAttribute:
+			entry[attr] = append(entry[attr], change.Modification.Vals...)
+		case ldap.DeleteAttribute:
+			delete(entry, attr)
+		case ldap.ReplaceAttribute:
+			entry[attr] = change.Modification.Vals
+		}
+	}
+	return nil
+}
+
+// Search supports base searches and subtree searches with a single equality
+// filter.
+func (c *testConnection) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.dir.l.Lock()
+	defer c.dir.l.Unlock()
+
+	result := &ldap.SearchResult{}
+	if req.Scope == ldap.ScopeBaseObject {
+		if attrs, ok := c.dir.entries[strings.ToLower(req.BaseDN)]; ok {
+			result.Entries = append(result.Entries, ldap.NewEntry(req.BaseDN, attrs))
+		}
+		return result, nil
+	}
+
+	filter := strings.Trim(req.Filter, "()")
+	idx := strings.Index(filter, "=")
+	if idx < 0 {
+		return nil, fmt.Errorf("unsupported filter %q", req.Filter)
+	}
+	attr, value := filter[:idx], filter[idx+1:]
+	for dn, attrs := range c.dir.entries {
+		if !strings.HasSuffix(dn, ","+strings.ToLower(req.BaseDN)) {
+			continue
+		}
+		for _, v := range attrs[attr] {
+			if v == value {
+				result.Entries = append(result.Entries, ldap.NewEntry(dn, attrs))
+			}
+		}
+	}
+	return result, nil
+}
+
+func (c *testConnection) StartTLS(config *tls.Config) error { return nil }
+
+func (c *testConnection) SetTimeout(timeout time.Duration) {}
+
+func (c *testConnection) UnauthenticatedBind(username string) error { return nil }
+
+func getBackend(t *testing.T) (*backend, logical.Storage, *testDirectory) {
+	t.Helper()
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.System = logical.TestSystemView()
+
+	b := Backend(config)
+	if err := b.Setup(context.Background(), config); err != nil {
+		t.Fatal(err)
+	}
+	dir := newTestDirectory()
+	b.ldap = dir
+	b.credRotationQueue = queue.New()
+
+	configureBackend(t, b, config.StorageView)
+	return b, config.StorageView, dir
+}
+
+func configureBackend(t *testing.T, b *backend, s logical.Storage) {
+	t.Helper()
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Data: map[string]interface{}{
+			"url":      "ldap://ldap.example.com",
+			"binddn":   testBindDN,
+			"bindpass": testBindPass,
+			"userdn":   testUserDN,
+			"userattr": "uid",
+		},
+	})
+}
+
+func testRequest(t *testing.T, b *backend, s logical.Storage, req *logical.Request) *logical.Response {
+	t.Helper()
+
+	req.Storage = s
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("%s %s: err: %v, resp: %#v", req.Operation, req.Path, err, resp)
+	}
+	return resp
+}
+
+func TestBackend_Config(t *testing.T) {
+	b, s, dir := getBackend(t)
+
+	resp := testRequest(t, b, s, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "config",
+	})
+	if _, ok := resp.Data["bindpass"]; ok {
+		t.Fatal("expected the bind password not to be returned")
+	}
+	if resp.Data["schema"] != schemaOpenLDAP || resp.Data["password_length"] != defaultPasswordLength {
+		t.Fatalf("unexpected defaults: %#v", resp.Data)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"schema": "novell",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an invalid schema to be rejected, got err: %v, resp: %#v", err, resp)
+	}
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-root",
+	})
+	password := dir.password(testBindDN)
+	if password == testBindPass || len(password) != defaultPasswordLength {
+		t.Fatalf("expected the bind password to be rotated, got %q", password)
+	}
+	cfg, err := b.config(context.Background(), s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BindPassword != password {
+		t.Fatal("expected the rotated bind password to be stored")
+	}
+}
+
+func TestBackend_StaticRole(t *testing.T) {
+	b, s, dir := getBackend(t)
+	aliceDN := "uid=alice," + testUserDN
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-role/alice",
+		Data: map[string]interface{}{
+			"username":        "alice",
+			"rotation_period": "1h",
+		},
+	})
+
+	resp := testRequest(t, b, s, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-role/alice",
+	})
+	if resp.Data["dn"] != aliceDN || resp.Data["rotation_period"] != float64(3600) {
+		t.Fatalf("unexpected role: %#v", resp.Data)
+	}
+	if _, ok := resp.Data["password"]; ok {
+		t.Fatal("expected the password not to be returned with the role")
+	}
+
+	resp = testRequest(t, b, s, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "static-cred/alice",
+	})
+	password := resp.Data["password"].(string)
+	if password == "initial" || dir.password(aliceDN) != password {
+		t.Fatalf("expected the password to be rotated on creation, got %q", password)
+	}
+
+	item, err := b.credRotationQueue.PopByKey("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := time.Now().Add(time.Hour).Unix(); item.Priority < expected-5 || item.Priority > expected+5 {
+		t.Fatalf("unexpected rotation priority %d, expected about %d", item.Priority, expected)
+	}
+
+	// Make the role due for rotation and run the queue
+	item.Priority = time.Now().Add(-time.Second).Unix()
+	if err := b.pushItem(item); err != nil {
+		t.Fatal(err)
+	}
+	b.rotateCredentials(context.Background(), s)
+	rotated := dir.password(aliceDN)
+	if rotated == password {
+		t.Fatal("expected the queue to rotate the password")
+	}
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "rotate-role/alice",
+	})
+	if dir.password(aliceDN) == rotated {
+		t.Fatal("expected rotate-role to rotate the password")
+	}
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "static-role/alice",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"username": "bob",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected changing the username to fail, got err: %v, resp: %#v", err, resp)
+	}
+
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-role/alice2",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"username":        "alice",
+			"rotation_period": "1h",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected managing the account twice to fail, got err: %v, resp: %#v", err, resp)
+	}
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "static-role/alice",
+	})
+	if item, _ := b.credRotationQueue.PopByKey("alice"); item != nil {
+		t.Fatal("expected the role to be removed from the queue")
+	}
+}
+
+func TestBackend_DynamicRole(t *testing.T) {
+	b, s, dir := getBackend(t)
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "role/dev",
+		Data: map[string]interface{}{
+			"creation_ldif": `
+dn: uid={{.Username}},ou=users,dc=example,dc=com
+objectClass: inetOrgPerson
+uid: {{.Username}}
+userPassword: {{.Password}}
+
+dn: cn=staff,dc=example,dc=com
+changetype: modify
+add: member
+member: uid={{.Username}},ou=users,dc=example,dc=com
+-
+`,
+			"deletion_ldif": `
+dn: uid={{.Username}},ou=users,dc=example,dc=com
+changetype: delete
+`,
+			"rollback_ldif": `
+dn: uid={{.Username}},ou=users,dc=example,dc=com
+changetype: delete
+`,
+			"default_ttl": "1h",
+			"max_ttl":     "2h",
+		},
+	})
+
+	resp := testRequest(t, b, s, &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "creds/dev",
+		DisplayName: "token-test",
+	})
+	username := resp.Data["username"].(string)
+	if !strings.HasPrefix(username, "v_token-te_dev_") {
+		t.Fatalf("unexpected username %q", username)
+	}
+	if resp.Secret.TTL != time.Hour || resp.Secret.MaxTTL != 2*time.Hour {
+		t.Fatalf("unexpected lease: %#v", resp.Secret)
+	}
+	userDN := fmt.Sprintf("uid=%s,%s", username, testUserDN)
+	if dir.password(userDN) != resp.Data["password"] {
+		t.Fatal("expected the account to be created with the password")
+	}
+	if members := dir.entry("cn=staff,dc=example,dc=com")["member"]; len(members) != 1 || members[0] != userDN {
+		t.Fatalf("expected the account to be added to the group, got %v", members)
+	}
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Secret:    resp.Secret,
+	})
+	if dir.entry(userDN) != nil {
+		t.Fatal("expected the account to be deleted on revocation")
+	}
+
+	// Creating the account fails partway when the group is missing; the
+	// rollback must remove the entry that was added
+	dir.l.Lock()
+	delete(dir.entries, "cn=staff,dc=example,dc=com")
+	dir.l.Unlock()
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "creds/dev",
+		Storage:   s,
+	})
+	if err == nil {
+		t.Fatalf("expected the creation to fail, got resp: %#v", resp)
+	}
+	dir.l.Lock()
+	defer dir.l.Unlock()
+	for dn := range dir.entries {
+		if strings.HasPrefix(dn, "uid=v_") {
+			t.Fatalf("expected the entry %q to be rolled back", dn)
+		}
+	}
+}
+
+func TestBackend_DynamicRole_DisplayName(t *testing.T) {
+	b, s, dir := getBackend(t)
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "role/dev",
+		Data: map[string]interface{}{
+			"creation_ldif": `
+dn: uid={{.Username}},ou=users,dc=example,dc=com
+objectClass: inetOrgPerson
+uid: {{.Username}}
+description: {{.DisplayName}}
+`,
+			"deletion_ldif": `
+dn: uid={{.Username}},ou=users,dc=example,dc=com
+changetype: delete
+`,
+		},
+	})
+
+	// A display name must not be able to add lines or attributes to the LDIF
+	resp := testRequest(t, b, s, &logical.Request{
+		Operation:   logical.ReadOperation,
+		Path:        "creds/dev",
+		DisplayName: "oidc-eve\nuserPassword: known,cn=x+sn=y",
+	})
+	entry := dir.entry(fmt.Sprintf("uid=%s,%s", resp.Data["username"], testUserDN))
+	if entry == nil {
+		t.Fatal("expected the account to be created")
+	}
+	if len(entry["userPassword"]) != 0 {
+		t.Fatalf("expected no injected attribute, got %v", entry["userPassword"])
+	}
+	if description := entry["description"]; len(description) != 1 || description[0] != "oidc-eveuserPasswordknowncnxsny" {
+		t.Fatalf("unexpected description %v", description)
+	}
+}
+
+func TestBackend_DynamicRole_InvalidTemplate(t *testing.T) {
+	b, s, _ := getBackend(t)
+
+	for name, data := range map[string]map[string]interface{}{
+		"missing deletion": {
+			"creation_ldif": "dn: uid={{.Username}},ou=users,dc=example,dc=com\nuid: {{.Username}}\n",
+		},
+		"bad template": {
+			"creation_ldif": "dn: uid={{.Username,ou=users,dc=example,dc=com\nuid: x\n",
+			"deletion_ldif": "dn: uid={{.Username}},ou=users,dc=example,dc=com\nchangetype: delete\n",
+		},
+		"unknown field": {
+			"creation_ldif": "dn: uid={{.Name}},ou=users,dc=example,dc=com\nuid: x\n",
+			"deletion_ldif": "dn: uid={{.Username}},ou=users,dc=example,dc=com\nchangetype: delete\n",
+		},
+		"bad ldif": {
+			"creation_ldif": "uid: {{.Username}}\n",
+			"deletion_ldif": "dn: uid={{.Username}},ou=users,dc=example,dc=com\nchangetype: delete\n",
+		},
+	} {
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.CreateOperation,
+			Path:      "role/invalid",
+			Storage:   s,
+			Data:      data,
+		})
+		if err != nil || resp == nil || !resp.IsError() {
+			t.Fatalf("%s: expected an error response, got err: %v, resp: %#v", name, err, resp)
+		}
+	}
+}
+
+// failingStorage fails writes of service account passwords once failPuts is
+// set, after letting allowPuts of them through.
+type failingStorage struct {
+	logical.Storage
+	failPuts  bool
+	allowPuts int
+}
+
+func (s *failingStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if s.failPuts && strings.HasPrefix(entry.Key, passwordPath) {
+		if s.allowPuts == 0 {
+			return fmt.Errorf("storage unavailable")
+		}
+		s.allowPuts--
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+func TestBackend_Library(t *testing.T) {
+	b, s, dir := getBackend(t)
+	bobDN := "uid=bob," + testUserDN
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/team",
+		Data: map[string]interface{}{
+			"service_account_names": "bob,carol",
+			"ttl":                   "1h",
+			"max_ttl":               "4h",
+		},
+	})
+	if dir.password(bobDN) == "initial" {
+		t.Fatal("expected the password to be rotated when adding the account")
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/other",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"service_account_names": "carol,dave",
+		},
+	})
+	if err != nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected an account in two libraries to be rejected, got err: %v, resp: %#v", err, resp)
+	}
+
+	checkOut := func(entityID string) (*logical.Response, error) {
+		return b.HandleRequest(context.Background(), &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "library/team/check-out",
+			Storage:   s,
+			EntityID:  entityID,
+			Data: map[string]interface{}{
+				"ttl": "10m",
+			},
+		})
+	}
+
+	first, err := checkOut("entity-1")
+	if err != nil || first.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, first)
+	}
+	if first.Data["service_account_name"] != "bob" || first.Data["password"] != dir.password(bobDN) {
+		t.Fatalf("unexpected check-out: %#v", first.Data)
+	}
+	if first.Secret.TTL != 10*time.Minute || first.Secret.MaxTTL != 4*time.Hour {
+		t.Fatalf("unexpected lease: %#v", first.Secret)
+	}
+
+	second, err := checkOut("entity-2")
+	if err != nil || second.IsError() || second.Data["service_account_name"] != "carol" {
+		t.Fatalf("err: %v, resp: %#v", err, second)
+	}
+
+	if resp, err := checkOut("entity-3"); err == nil || resp == nil || !resp.IsError() {
+		t.Fatalf("expected no account to be available, got err: %v, resp: %#v", err, resp)
+	}
+
+	resp = testRequest(t, b, s, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "library/team/status",
+	})
+	if status := resp.Data["bob"].(map[string]interface{}); status["available"] != false || status["borrower_entity_id"] != "entity-1" {
+		t.Fatalf("unexpected status: %#v", resp.Data)
+	}
+
+	// Only the borrower can check the account in
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/team/check-in",
+		Storage:   s,
+		EntityID:  "entity-2",
+		Data: map[string]interface{}{
+			"service_account_names": "bob",
+		},
+	})
+	if err != logical.ErrPermissionDenied {
+		t.Fatalf("expected permission denied, got err: %v, resp: %#v", err, resp)
+	}
+
+	password := dir.password(bobDN)
+	resp = testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/team/check-in",
+		EntityID:  "entity-1",
+	})
+	if checkIns := resp.Data["check_ins"].([]string); len(checkIns) != 1 || checkIns[0] != "bob" {
+		t.Fatalf("unexpected check-ins: %#v", resp.Data)
+	}
+	if dir.password(bobDN) == password {
+		t.Fatal("expected the password to be rotated on check-in")
+	}
+
+	// Revoking the lease of a check-out which was checked in must not check
+	// in the account again, now lent to someone else
+	third, err := checkOut("entity-3")
+	if err != nil || third.IsError() || third.Data["service_account_name"] != "bob" {
+		t.Fatalf("err: %v, resp: %#v", err, third)
+	}
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Secret:    first.Secret,
+	})
+	resp = testRequest(t, b, s, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "library/team/status",
+	})
+	if status := resp.Data["bob"].(map[string]interface{}); status["available"] != false {
+		t.Fatalf("expected bob to stay checked out, got %#v", resp.Data)
+	}
+
+	// Revoking the current lease checks the account in
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.RevokeOperation,
+		Secret:    second.Secret,
+	})
+
+	// Operators can check in any account
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/manage/team/check-in",
+		Data: map[string]interface{}{
+			"service_account_names": "bob",
+		},
+	})
+	resp = testRequest(t, b, s, &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "library/team/status",
+	})
+	for _, account := range []string{"bob", "carol"} {
+		if status := resp.Data[account].(map[string]interface{}); status["available"] != true {
+			t.Fatalf("expected %s to be available, got %#v", account, resp.Data)
+		}
+	}
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.DeleteOperation,
+		Path:      "library/team",
+	})
+	if entry, err := s.Get(context.Background(), passwordPath+"bob"); err != nil || entry != nil {
+		t.Fatalf("expected the password to be deleted with the library, got err: %v, entry: %#v", err, entry)
+	}
+}
+
+func TestBackend_Library_RotationStorageFailure(t *testing.T) {
+	b, inmem, dir := getBackend(t)
+	s := &failingStorage{Storage: inmem}
+	bobDN := "uid=bob," + testUserDN
+
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "library/team",
+		Data: map[string]interface{}{
+			"service_account_names": "bob",
+		},
+	})
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/team/check-out",
+		EntityID:  "entity-1",
+	})
+
+	// Storing the pending password succeeds, storing the new one fails after
+	// it was set in the directory
+	s.failPuts = true
+	s.allowPuts = 1
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/team/check-in",
+		Storage:   s,
+		EntityID:  "entity-1",
+	})
+	if err == nil && (resp == nil || !resp.IsError()) {
+		t.Fatalf("expected the check-in to fail, got resp: %#v", resp)
+	}
+
+	pw, err := b.servicePassword(context.Background(), s, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pw.PendingPassword == "" || pw.PendingPassword != dir.password(bobDN) {
+		t.Fatal("expected the password set in the directory to be stored as pending")
+	}
+	co, err := b.checkOut(context.Background(), s, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co.IsAvailable {
+		t.Fatal("expected the account to stay checked out")
+	}
+
+	// Checking the account in again completes the rotation
+	s.failPuts = false
+	testRequest(t, b, s, &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "library/manage/team/check-in",
+	})
+	pw, err = b.servicePassword(context.Background(), s, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pw.PendingPassword != "" || pw.Password != dir.password(bobDN) {
+		t.Fatalf("expected the rotation to complete, got %#v", pw)
+	}
+}
@@ -0,0 +1,67 @@
+package ldap
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/helper/base62"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+)
+
+const (
+	// schemaOpenLDAP stores passwords in the userPassword attribute, leaving
+	// hashing to the server.
+	schemaOpenLDAP = "openldap"
+
+	// schemaAD stores passwords in the unicodePwd attribute of Active
+	// Directory, which requires a TLS connection.
+	schemaAD = "ad"
+
+	defaultPasswordLength = 64
+	minPasswordLength     = 14
+)
+
+func generatePassword(cfg *ldapConfig) (string, error) {
+	return base62.Random(cfg.passwordLength())
+}
+
+// setPassword replaces the password of the entry with the given DN.
+func setPassword(cfg *ldapConfig, conn ldaputil.Connection, dn, password string) error {
+	req := ldap.NewModifyRequest(dn, nil)
+	switch cfg.Schema {
+	case schemaAD:
+		req.Replace("unicodePwd", []string{encodeADPassword(password)})
+	default:
+		req.Replace("userPassword", []string{password})
+	}
+
+	if err := conn.Modify(req); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("error setting the password of %q: {{err}}", dn), err)
+	}
+	return nil
+}
+
+// encodeADPassword returns the password as Active Directory expects it in the
+// unicodePwd attribute: quoted, and encoded as UTF-16LE.
+func encodeADPassword(password string) string {
+	encoded := utf16.Encode([]rune(`"` + password + `"`))
+	buf := make([]byte, 2*len(encoded))
+	for i, r := range encoded {
+		binary.LittleEndian.PutUint16(buf[2*i:], r)
+	}
+	return string(buf)
+}
+
+// templateFuncs are available to the LDIF templates of dynamic roles.
+var templateFuncs = map[string]interface{}{
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"adPassword": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(encodeADPassword(s)))
+	},
+}
@@ -0,0 +1,213 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const configPath = "config"
+
+func pathConfig(b *backend) *framework.Path {
+	fields := ldaputil.ConfigFields()
+	fields["schema"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Default:     schemaOpenLDAP,
+		Description: `The directory schema, which determines how passwords are set. Either "openldap" (userPassword) or "ad" (unicodePwd). Defaults to "openldap".`,
+	}
+	fields["password_length"] = &framework.FieldSchema{
+		Type:        framework.TypeInt,
+		Default:     defaultPasswordLength,
+		Description: fmt.Sprintf("The length of the passwords generated by the engine. Defaults to %d.", defaultPasswordLength),
+	}
+
+	return &framework.Path{
+		Pattern: configPath,
+		Fields:  fields,
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathConfigRead,
+			logical.UpdateOperation: b.pathConfigWrite,
+			logical.DeleteOperation: b.pathConfigDelete,
+		},
+
+		HelpSynopsis:    pathConfigHelpSyn,
+		HelpDescription: pathConfigHelpDesc,
+	}
+}
+
+func pathRotateRoot(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "rotate-root",
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRotateRootUpdate,
+		},
+
+		HelpSynopsis:    pathRotateRootHelpSyn,
+		HelpDescription: pathRotateRootHelpDesc,
+	}
+}
+
+type ldapConfig struct {
+	*ldaputil.ConfigEntry
+
+	Schema         string `json:"schema"`
+	PasswordLength int    `json:"password_length"`
+}
+
+func (c *ldapConfig) passwordLength() int {
+	if c.PasswordLength <= 0 {
+		return defaultPasswordLength
+	}
+	return c.PasswordLength
+}
+
+func (b *backend) config(ctx context.Context, s logical.Storage) (*ldapConfig, error) {
+	entry, err := s.Get(ctx, configPath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	cfg := &ldapConfig{
+		ConfigEntry: new(ldaputil.ConfigEntry),
+	}
+	if err := entry.DecodeJSON(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (b *backend) pathConfigRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		return nil, nil
+	}
+
+	data := cfg.PasswordlessMap()
+	data["schema"] = cfg.Schema
+	data["password_length"] = cfg.passwordLength()
+
+	return &logical.Response{
+		Data: data,
+	}, nil
+}
+
+func (b *backend) pathConfigWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, err := b.config(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing *ldaputil.ConfigEntry
+	if cfg == nil {
+		cfg = &ldapConfig{}
+	} else {
+		existing = cfg.ConfigEntry
+	}
+
+	cfg.ConfigEntry, err = ldaputil.NewConfigEntry(existing, d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if cfg.BindDN == "" || cfg.BindPassword == "" {
+		return logical.ErrorResponse("binddn and bindpass are required"), nil
+	}
+
+	if _, ok := d.GetOk("schema"); ok || existing == nil {
+		cfg.Schema = strings.ToLower(d.Get("schema").(string))
+	}
+	switch cfg.Schema {
+	case schemaOpenLDAP, schemaAD:
+	default:
+		return logical.ErrorResponse(fmt.Sprintf("invalid schema %q, must be %q or %q", cfg.Schema, schemaOpenLDAP, schemaAD)), nil
+	}
+
+	if _, ok := d.GetOk("password_length"); ok || existing == nil {
+		cfg.PasswordLength = d.Get("password_length").(int)
+	}
+	if cfg.PasswordLength < minPasswordLength {
+		return logical.ErrorResponse(fmt.Sprintf("password_length must be at least %d", minPasswordLength)), nil
+	}
+
+	if err := b.putConfig(ctx, req.Storage, cfg); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathConfigDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if err := req.Storage.Delete(ctx, configPath); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) putConfig(ctx context.Context, s logical.Storage, cfg *ldapConfig) error {
+	entry, err := logical.StorageEntryJSON(configPath, cfg)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+func (b *backend) pathRotateRootUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	cfg, conn, err := b.connect(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	password, err := generatePassword(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := setPassword(cfg, conn, cfg.BindDN, password); err != nil {
+		return nil, err
+	}
+
+	cfg.BindPassword = password
+	if err := b.putConfig(ctx, req.Storage, cfg); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+const pathConfigHelpSyn = `
+Configure the LDAP server to connect to, along with its options.
+`
+
+const pathConfigHelpDesc = `
+This endpoint configures the LDAP server the engine manages accounts in. The
+engine binds with binddn and bindpass, which must be allowed to set the
+passwords of the managed accounts and, for dynamic roles, to add and delete
+entries.
+
+Accounts of static roles and libraries are looked up by their username, using
+the userattr attribute below userdn.
+
+Active Directory only allows setting passwords over an encrypted connection,
+so use an "ldaps://" URL or enable starttls with the "ad" schema.
+`
+
+const pathRotateRootHelpSyn = `
+Rotate the password of the bind DN.
+`
+
+const pathRotateRootHelpDesc = `
+This endpoint generates a new password for the configured bind DN, sets it in
+the directory, and stores it in the configuration. The new password is not
+returned.
+`
@@ -0,0 +1,216 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/base62"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// SecretCredsType is the key for the secrets of dynamic roles.
+const SecretCredsType = "creds"
+
+// usernameDisallowedChars matches the characters removed from the display
+// and role names when generating usernames, so that the username can be used
+// in a DN without escaping. They are removed from the display name given to
+// LDIF templates too, where it could otherwise inject lines or attributes.
+var usernameDisallowedChars = regexp.MustCompile("[^a-zA-Z0-9_-]")
+
+func pathCreds(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "creds/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the role.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathCredsRead,
+		},
+
+		HelpSynopsis:    pathCredsHelpSyn,
+		HelpDescription: pathCredsHelpDesc,
+	}
+}
+
+func secretCreds(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type: SecretCredsType,
+		Fields: map[string]*framework.FieldSchema{
+			"username": {
+				Type:        framework.TypeString,
+				Description: "Username of the account",
+			},
+			"password": {
+				Type:        framework.TypeString,
+				Description: "Password of the account",
+			},
+		},
+		Renew:  b.secretCredsRenew,
+		Revoke: b.secretCredsRevoke,
+	}
+}
+
+func generateUsername(displayName, roleName string) (string, error) {
+	suffix, err := base62.Random(20)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("v_%s_%s_%s_%d",
+		truncate(usernameDisallowedChars.ReplaceAllString(displayName, ""), 8),
+		truncate(usernameDisallowedChars.ReplaceAllString(roleName, ""), 8),
+		suffix, time.Now().Unix()), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	role, err := b.role(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("unknown role: %s", name), nil
+	}
+
+	cfg, conn, err := b.connect(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	displayName := usernameDisallowedChars.ReplaceAllString(req.DisplayName, "")
+	username, err := generateUsername(displayName, name)
+	if err != nil {
+		return nil, err
+	}
+	password, err := generatePassword(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tmplData := &ldifTemplateData{
+		Username:    username,
+		Password:    password,
+		DisplayName: displayName,
+		RoleName:    name,
+	}
+
+	creation, err := renderLDIF(role.CreationLDIF, tmplData)
+	if err != nil {
+		return nil, errwrap.Wrapf("error rendering creation_ldif: {{err}}", err)
+	}
+	// Make sure the deletion LDIF renders before creating an account which
+	// couldn't be deleted. The template is stored with the lease, so that
+	// revocation doesn't depend on the role still existing or being unchanged.
+	if _, err := renderLDIF(role.DeletionLDIF, tmplData); err != nil {
+		return nil, errwrap.Wrapf("error rendering deletion_ldif: {{err}}", err)
+	}
+
+	if err := ldaputil.ApplyLDIF(conn, creation); err != nil {
+		if role.RollbackLDIF != "" {
+			rollback, rerr := renderLDIF(role.RollbackLDIF, tmplData)
+			if rerr == nil {
+				rerr = ldaputil.ApplyLDIF(conn, rollback)
+			}
+			if rerr != nil {
+				b.Logger().Error("error rolling back account creation", "username", username, "error", rerr)
+			}
+		}
+		return nil, errwrap.Wrapf("error creating account: {{err}}", err)
+	}
+
+	resp := b.Secret(SecretCredsType).Response(map[string]interface{}{
+		"username": username,
+		"password": password,
+	}, map[string]interface{}{
+		"role":          name,
+		"username":      username,
+		"deletion_ldif": role.DeletionLDIF,
+		"display_name":  displayName,
+	})
+	resp.Secret.TTL = role.DefaultTTL
+	resp.Secret.MaxTTL = role.MaxTTL
+
+	return resp, nil
+}
+
+func (b *backend) secretCredsRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	roleNameRaw, ok := req.Secret.InternalData["role"]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing role internal data")
+	}
+
+	role, err := b.role(ctx, req.Storage, roleNameRaw.(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("error during renew: could not find role with name %q", roleNameRaw)
+	}
+
+	resp := &logical.Response{Secret: req.Secret}
+	resp.Secret.TTL = role.DefaultTTL
+	resp.Secret.MaxTTL = role.MaxTTL
+	return resp, nil
+}
+
+func (b *backend) secretCredsRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	usernameRaw, ok := req.Secret.InternalData["username"]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing username internal data")
+	}
+	deletionLDIFRaw, ok := req.Secret.InternalData["deletion_ldif"]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing deletion_ldif internal data")
+	}
+	displayName, _ := req.Secret.InternalData["display_name"].(string)
+	roleName, _ := req.Secret.InternalData["role"].(string)
+
+	// Render the template with the same data used at creation, except for
+	// the password which deletion templates have no use for
+	deletion, err := renderLDIF(deletionLDIFRaw.(string), &ldifTemplateData{
+		Username:    usernameRaw.(string),
+		DisplayName: displayName,
+		RoleName:    roleName,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("error rendering deletion_ldif: {{err}}", err)
+	}
+
+	_, conn, err := b.connect(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ldaputil.ApplyLDIF(conn, deletion); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("error deleting account %q: {{err}}", usernameRaw), err)
+	}
+
+	return nil, nil
+}
+
+const pathCredsHelpSyn = `
+Request LDAP credentials for a certain role.
+`
+
+const pathCredsHelpDesc = `
+This path creates an account in the directory for a certain role, by applying
+the creation_ldif template of the role. The account is deleted when the lease
+expires or is revoked.
+`
@@ -0,0 +1,834 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/base62"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	libraryPath  = "library/"
+	checkOutPath = "checkout/"
+	passwordPath = "password/"
+
+	// SecretServiceAccountType is the key for the secrets of checked out
+	// service accounts.
+	SecretServiceAccountType = "service_account"
+
+	defaultLibraryTTL = 24 * time.Hour
+)
+
+func pathLibrary(b *backend) []*framework.Path {
+	nameField := &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: "Name of the library.",
+	}
+
+	return []*framework.Path{
+		&framework.Path{
+			Pattern: strings.TrimSuffix(libraryPath, "/") + "/?$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ListOperation: b.pathLibraryList,
+			},
+
+			HelpSynopsis:    pathLibraryHelpSyn,
+			HelpDescription: pathLibraryHelpDesc,
+		},
+		&framework.Path{
+			Pattern: libraryPath + "manage/" + framework.GenericNameRegex("name") + "/check-in$",
+			Fields: map[string]*framework.FieldSchema{
+				"name": nameField,
+				"service_account_names": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The service accounts to check in. Optional if a single account of the library is checked out.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.pathLibraryCheckIn,
+			},
+
+			HelpSynopsis:    pathLibraryManageCheckInHelpSyn,
+			HelpDescription: pathLibraryManageCheckInHelpDesc,
+		},
+		&framework.Path{
+			Pattern: libraryPath + framework.GenericNameRegex("name") + "/check-out$",
+			Fields: map[string]*framework.FieldSchema{
+				"name": nameField,
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The length of time before the check-out expires. Defaults to, and cannot exceed, the ttl of the library.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.pathLibraryCheckOut,
+			},
+
+			HelpSynopsis:    pathLibraryCheckOutHelpSyn,
+			HelpDescription: pathLibraryCheckOutHelpDesc,
+		},
+		&framework.Path{
+			Pattern: libraryPath + framework.GenericNameRegex("name") + "/check-in$",
+			Fields: map[string]*framework.FieldSchema{
+				"name": nameField,
+				"service_account_names": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The service accounts to check in. Optional if a single account of the library is checked out by the caller.",
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: b.pathLibraryCheckIn,
+			},
+
+			HelpSynopsis:    pathLibraryCheckInHelpSyn,
+			HelpDescription: pathLibraryCheckInHelpDesc,
+		},
+		&framework.Path{
+			Pattern: libraryPath + framework.GenericNameRegex("name") + "/status$",
+			Fields: map[string]*framework.FieldSchema{
+				"name": nameField,
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation: b.pathLibraryStatus,
+			},
+
+			HelpSynopsis:    pathLibraryStatusHelpSyn,
+			HelpDescription: pathLibraryStatusHelpDesc,
+		},
+		&framework.Path{
+			Pattern: libraryPath + framework.GenericNameRegex("name"),
+			Fields: map[string]*framework.FieldSchema{
+				"name": nameField,
+				"service_account_names": {
+					Type:        framework.TypeCommaStringSlice,
+					Description: "The usernames of the service accounts that can be checked out. Required when creating the library.",
+				},
+				"ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The default and maximum length of a check-out. Defaults to 24 hours.",
+				},
+				"max_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "The maximum length of a check-out, including renewals. Defaults to 24 hours.",
+				},
+				"disable_check_in_enforcement": {
+					Type:        framework.TypeBool,
+					Description: "Allow any client to check in service accounts, not only the one that checked them out.",
+				},
+			},
+
+			ExistenceCheck: b.pathLibraryExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.pathLibraryRead,
+				logical.CreateOperation: b.pathLibraryCreateUpdate,
+				logical.UpdateOperation: b.pathLibraryCreateUpdate,
+				logical.DeleteOperation: b.pathLibraryDelete,
+			},
+
+			HelpSynopsis:    pathLibraryHelpSyn,
+			HelpDescription: pathLibraryHelpDesc,
+		},
+	}
+}
+
+func secretServiceAccount(b *backend) *framework.Secret {
+	return &framework.Secret{
+		Type: SecretServiceAccountType,
+		Fields: map[string]*framework.FieldSchema{
+			"service_account_name": {
+				Type:        framework.TypeString,
+				Description: "Username of the service account",
+			},
+			"password": {
+				Type:        framework.TypeString,
+				Description: "Password of the service account",
+			},
+		},
+		Renew:  b.secretServiceAccountRenew,
+		Revoke: b.secretServiceAccountRevoke,
+	}
+}
+
+type librarySet struct {
+	ServiceAccountNames       []string      `json:"service_account_names"`
+	TTL                       time.Duration `json:"ttl"`
+	MaxTTL                    time.Duration `json:"max_ttl"`
+	DisableCheckInEnforcement bool          `json:"disable_check_in_enforcement"`
+}
+
+// checkOut records whether a service account is available, and who borrowed
+// it if it isn't.
+type checkOut struct {
+	IsAvailable                 bool   `json:"is_available"`
+	BorrowerEntityID            string `json:"borrower_entity_id"`
+	BorrowerClientTokenAccessor string `json:"borrower_client_token_accessor"`
+
+	// CheckOutID identifies the check-out, so that revoking the lease of a
+	// check-out that was already checked in doesn't check in a later one
+	CheckOutID string `json:"check_out_id"`
+}
+
+// servicePassword holds the current credentials of a service account.
+type servicePassword struct {
+	DN       string `json:"dn"`
+	Password string `json:"password"`
+
+	// PendingPassword is the password being set in the directory. It is
+	// stored beforehand, so that the account's credential isn't lost if the
+	// rotation fails before Password is updated.
+	PendingPassword string `json:"pending_password,omitempty"`
+}
+
+func (b *backend) librarySet(ctx context.Context, s logical.Storage, name string) (*librarySet, error) {
+	entry, err := s.Get(ctx, libraryPath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result librarySet
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *backend) checkOut(ctx context.Context, s logical.Storage, account string) (*checkOut, error) {
+	entry, err := s.Get(ctx, checkOutPath+account)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("no check-out status found for service account %q", account)
+	}
+
+	var result checkOut
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *backend) servicePassword(ctx context.Context, s logical.Storage, account string) (*servicePassword, error) {
+	entry, err := s.Get(ctx, passwordPath+account)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("no password found for service account %q", account)
+	}
+
+	var result servicePassword
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func putJSON(ctx context.Context, s logical.Storage, key string, v interface{}) error {
+	entry, err := logical.StorageEntryJSON(key, v)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// accountOwner returns a description of the static role or library managing
+// the account, or an empty string if it is not managed yet.
+func (b *backend) accountOwner(ctx context.Context, s logical.Storage, username string) (string, error) {
+	roles, err := s.List(ctx, staticRolePath)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range roles {
+		role, err := b.staticRole(ctx, s, name)
+		if err != nil {
+			return "", err
+		}
+		if role != nil && role.Username == username {
+			return fmt.Sprintf("static role %q", name), nil
+		}
+	}
+
+	sets, err := s.List(ctx, libraryPath)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range sets {
+		set, err := b.librarySet(ctx, s, name)
+		if err != nil {
+			return "", err
+		}
+		if set != nil && strutil.StrListContains(set.ServiceAccountNames, username) {
+			return fmt.Sprintf("library %q", name), nil
+		}
+	}
+
+	return "", nil
+}
+
+// rotateServicePassword sets a new password for the service account and
+// stores it. The new password is stored as pending before it is set in the
+// directory, in case storing it afterwards fails.
+func (b *backend) rotateServicePassword(ctx context.Context, s logical.Storage, cfg *ldapConfig, conn ldaputil.Connection, account, dn string) error {
+	pw := &servicePassword{DN: dn}
+	entry, err := s.Get(ctx, passwordPath+account)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		if err := entry.DecodeJSON(pw); err != nil {
+			return err
+		}
+		pw.DN = dn
+	}
+
+	password, err := generatePassword(cfg)
+	if err != nil {
+		return err
+	}
+	pw.PendingPassword = password
+	if err := putJSON(ctx, s, passwordPath+account, pw); err != nil {
+		return errwrap.Wrapf("error storing the pending password: {{err}}", err)
+	}
+
+	if err := setPassword(cfg, conn, dn, password); err != nil {
+		return err
+	}
+
+	pw.Password = password
+	pw.PendingPassword = ""
+	return putJSON(ctx, s, passwordPath+account, pw)
+}
+
+// checkIn rotates the password of the service account and marks it as
+// available. The account stays checked out if the rotation fails, so that
+// the previous password isn't lent to another client.
+func (b *backend) checkIn(ctx context.Context, s logical.Storage, account string) error {
+	pw, err := b.servicePassword(ctx, s, account)
+	if err != nil {
+		return err
+	}
+
+	cfg, conn, err := b.connect(ctx, s)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := b.rotateServicePassword(ctx, s, cfg, conn, account, pw.DN); err != nil {
+		return err
+	}
+	return putJSON(ctx, s, checkOutPath+account, &checkOut{IsAvailable: true})
+}
+
+func (b *backend) pathLibraryExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	set, err := b.librarySet(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return set != nil, nil
+}
+
+func (b *backend) pathLibraryList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, libraryPath)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathLibraryRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	set, err := b.librarySet(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"service_account_names":        set.ServiceAccountNames,
+			"ttl":                          set.TTL.Seconds(),
+			"max_ttl":                      set.MaxTTL.Seconds(),
+			"disable_check_in_enforcement": set.DisableCheckInEnforcement,
+		},
+	}, nil
+}
+
+func (b *backend) pathLibraryCreateUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.checkOutLock.Lock()
+	defer b.checkOutLock.Unlock()
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		set = &librarySet{
+			TTL:    defaultLibraryTTL,
+			MaxTTL: defaultLibraryTTL,
+		}
+	}
+	previous := set.ServiceAccountNames
+
+	if v, ok := data.GetOk("service_account_names"); ok {
+		set.ServiceAccountNames = strutil.RemoveDuplicates(v.([]string), false)
+	}
+	if v, ok := data.GetOk("ttl"); ok {
+		set.TTL = time.Duration(v.(int)) * time.Second
+	}
+	if v, ok := data.GetOk("max_ttl"); ok {
+		set.MaxTTL = time.Duration(v.(int)) * time.Second
+	}
+	if v, ok := data.GetOk("disable_check_in_enforcement"); ok {
+		set.DisableCheckInEnforcement = v.(bool)
+	}
+
+	if len(set.ServiceAccountNames) == 0 {
+		return logical.ErrorResponse("at least one service account name is required"), nil
+	}
+	if set.TTL <= 0 || set.MaxTTL <= 0 {
+		return logical.ErrorResponse("ttl and max_ttl must be greater than zero"), nil
+	}
+	if set.TTL > set.MaxTTL {
+		return logical.ErrorResponse("ttl cannot be greater than max_ttl"), nil
+	}
+
+	var added, removed []string
+	for _, account := range set.ServiceAccountNames {
+		if !strutil.StrListContains(previous, account) {
+			added = append(added, account)
+		}
+	}
+	for _, account := range previous {
+		if !strutil.StrListContains(set.ServiceAccountNames, account) {
+			removed = append(removed, account)
+		}
+	}
+
+	for _, account := range removed {
+		co, err := b.checkOut(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+		if !co.IsAvailable {
+			return logical.ErrorResponse("service account %q must be checked in before removing it from the library", account), nil
+		}
+	}
+
+	if len(added) > 0 {
+		for _, account := range added {
+			if owner, err := b.accountOwner(ctx, req.Storage, account); err != nil {
+				return nil, err
+			} else if owner != "" {
+				return logical.ErrorResponse("account %q is already managed by %s", account, owner), nil
+			}
+		}
+
+		cfg, conn, err := b.connect(ctx, req.Storage)
+		if err != nil {
+			return nil, err
+		}
+		defer conn.Close()
+
+		// Look up all the accounts before changing any password, so that a
+		// typo doesn't leave the library half set up
+		dns := make(map[string]string, len(added))
+		for _, account := range added {
+			dn, err := accountDN(cfg, conn, account)
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+			dns[account] = dn
+		}
+
+		for _, account := range added {
+			if err := b.rotateServicePassword(ctx, req.Storage, cfg, conn, account, dns[account]); err != nil {
+				return nil, err
+			}
+			if err := putJSON(ctx, req.Storage, checkOutPath+account, &checkOut{IsAvailable: true}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, account := range removed {
+		if err := b.deleteServiceAccount(ctx, req.Storage, account); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := putJSON(ctx, req.Storage, libraryPath+name, set); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathLibraryDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.checkOutLock.Lock()
+	defer b.checkOutLock.Unlock()
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, nil
+	}
+
+	for _, account := range set.ServiceAccountNames {
+		co, err := b.checkOut(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+		if !co.IsAvailable {
+			return logical.ErrorResponse("service account %q must be checked in before deleting the library", account), nil
+		}
+	}
+
+	for _, account := range set.ServiceAccountNames {
+		if err := b.deleteServiceAccount(ctx, req.Storage, account); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := req.Storage.Delete(ctx, libraryPath+name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) deleteServiceAccount(ctx context.Context, s logical.Storage, account string) error {
+	if err := s.Delete(ctx, checkOutPath+account); err != nil {
+		return err
+	}
+	return s.Delete(ctx, passwordPath+account)
+}
+
+func (b *backend) pathLibraryCheckOut(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	b.checkOutLock.Lock()
+	defer b.checkOutLock.Unlock()
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return logical.ErrorResponse("unknown library: %s", name), nil
+	}
+
+	ttl := set.TTL
+	if v, ok := data.GetOk("ttl"); ok {
+		requested := time.Duration(v.(int)) * time.Second
+		if requested > 0 && requested < ttl {
+			ttl = requested
+		}
+	}
+
+	for _, account := range set.ServiceAccountNames {
+		co, err := b.checkOut(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+		if !co.IsAvailable {
+			continue
+		}
+
+		pw, err := b.servicePassword(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+
+		checkOutID, err := base62.Random(20)
+		if err != nil {
+			return nil, err
+		}
+		if err := putJSON(ctx, req.Storage, checkOutPath+account, &checkOut{
+			BorrowerEntityID:            req.EntityID,
+			BorrowerClientTokenAccessor: req.ClientTokenAccessor,
+			CheckOutID:                  checkOutID,
+		}); err != nil {
+			return nil, err
+		}
+
+		resp := b.Secret(SecretServiceAccountType).Response(map[string]interface{}{
+			"service_account_name": account,
+			"password":             pw.Password,
+		}, map[string]interface{}{
+			"set_name":             name,
+			"service_account_name": account,
+			"check_out_id":         checkOutID,
+		})
+		resp.Secret.TTL = ttl
+		resp.Secret.MaxTTL = set.MaxTTL
+		resp.Secret.Renewable = true
+
+		return resp, nil
+	}
+
+	return logical.ErrorResponse("no service accounts available for check-out in library %q", name), logical.ErrInvalidRequest
+}
+
+func (b *backend) pathLibraryCheckIn(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+	managed := strings.HasPrefix(req.Path, libraryPath+"manage/")
+
+	b.checkOutLock.Lock()
+	defer b.checkOutLock.Unlock()
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return logical.ErrorResponse("unknown library: %s", name), nil
+	}
+	enforce := !managed && !set.DisableCheckInEnforcement
+
+	// Find the accounts that the caller may check in
+	var candidates []string
+	for _, account := range set.ServiceAccountNames {
+		co, err := b.checkOut(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+		if co.IsAvailable {
+			continue
+		}
+		if enforce && !isBorrower(req, co) {
+			continue
+		}
+		candidates = append(candidates, account)
+	}
+
+	accounts := data.Get("service_account_names").([]string)
+	if len(accounts) == 0 {
+		switch len(candidates) {
+		case 0:
+			return &logical.Response{
+				Data: map[string]interface{}{
+					"check_ins": []string{},
+				},
+			}, nil
+		case 1:
+			accounts = candidates
+		default:
+			return logical.ErrorResponse("service_account_names must be specified when more than one account is checked out"), nil
+		}
+	}
+
+	var checkIns []string
+	for _, account := range accounts {
+		if !strutil.StrListContains(set.ServiceAccountNames, account) {
+			return logical.ErrorResponse("service account %q is not part of library %q", account, name), nil
+		}
+		co, err := b.checkOut(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+		if co.IsAvailable {
+			// Checking in an available account is a no-op
+			continue
+		}
+		if !strutil.StrListContains(candidates, account) {
+			return logical.ErrorResponse("service account %q was checked out by another client", account), logical.ErrPermissionDenied
+		}
+		if err := b.checkIn(ctx, req.Storage, account); err != nil {
+			return nil, err
+		}
+		checkIns = append(checkIns, account)
+	}
+	if checkIns == nil {
+		checkIns = []string{}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"check_ins": checkIns,
+		},
+	}, nil
+}
+
+// isBorrower returns whether the request comes from the client which checked
+// out the account: the same entity or, for tokens without an entity, the same
+// token.
+func isBorrower(req *logical.Request, co *checkOut) bool {
+	if co.BorrowerEntityID != "" {
+		return req.EntityID == co.BorrowerEntityID
+	}
+	return co.BorrowerClientTokenAccessor != "" && req.ClientTokenAccessor == co.BorrowerClientTokenAccessor
+}
+
+func (b *backend) pathLibraryStatus(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	set, err := b.librarySet(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return logical.ErrorResponse("unknown library: %s", name), nil
+	}
+
+	status := make(map[string]interface{}, len(set.ServiceAccountNames))
+	for _, account := range set.ServiceAccountNames {
+		co, err := b.checkOut(ctx, req.Storage, account)
+		if err != nil {
+			return nil, err
+		}
+		accountStatus := map[string]interface{}{
+			"available": co.IsAvailable,
+		}
+		if !co.IsAvailable {
+			accountStatus["borrower_entity_id"] = co.BorrowerEntityID
+			accountStatus["borrower_client_token_accessor"] = co.BorrowerClientTokenAccessor
+		}
+		status[account] = accountStatus
+	}
+
+	return &logical.Response{
+		Data: status,
+	}, nil
+}
+
+// currentCheckOut returns the check-out of the secret's service account, or
+// nil if the account has been checked in since the secret was issued.
+func (b *backend) currentCheckOut(ctx context.Context, req *logical.Request) (string, *checkOut, error) {
+	accountRaw, ok := req.Secret.InternalData["service_account_name"]
+	if !ok {
+		return "", nil, fmt.Errorf("secret is missing service_account_name internal data")
+	}
+	account := accountRaw.(string)
+
+	co, err := b.checkOut(ctx, req.Storage, account)
+	if err != nil {
+		return account, nil, err
+	}
+	if co.IsAvailable || co.CheckOutID != req.Secret.InternalData["check_out_id"] {
+		return account, nil, nil
+	}
+	return account, co, nil
+}
+
+func (b *backend) secretServiceAccountRenew(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.checkOutLock.Lock()
+	defer b.checkOutLock.Unlock()
+
+	setNameRaw, ok := req.Secret.InternalData["set_name"]
+	if !ok {
+		return nil, fmt.Errorf("secret is missing set_name internal data")
+	}
+	set, err := b.librarySet(ctx, req.Storage, setNameRaw.(string))
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, fmt.Errorf("error during renew: could not find library with name %q", setNameRaw)
+	}
+
+	account, co, err := b.currentCheckOut(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if co == nil {
+		return nil, fmt.Errorf("service account %q has already been checked in", account)
+	}
+
+	return framework.LeaseExtend(set.TTL, set.MaxTTL, b.System())(ctx, req, d)
+}
+
+func (b *backend) secretServiceAccountRevoke(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.checkOutLock.Lock()
+	defer b.checkOutLock.Unlock()
+
+	account, co, err := b.currentCheckOut(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if co == nil {
+		// The account was checked in already
+		return nil, nil
+	}
+
+	if err := b.checkIn(ctx, req.Storage, account); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+const pathLibraryHelpSyn = `
+Manage libraries of service accounts that can be checked out.
+`
+
+const pathLibraryHelpDesc = `
+This path lets you manage libraries of existing service accounts, which
+clients can check out for exclusive use and check back in. The passwords of
+the service accounts are rotated when they are added to a library and every
+time they are checked in.
+
+A check-out lasts for the library's ttl unless the client requests a shorter
+one, and can be renewed up to max_ttl. The account is checked in when the
+lease expires or is revoked.
+`
+
+const pathLibraryCheckOutHelpSyn = `
+Check out a service account from the library.
+`
+
+const pathLibraryCheckOutHelpDesc = `
+This path checks out the first available service account of the library,
+returning its username and password. An error is returned if all the accounts
+are checked out.
+`
+
+const pathLibraryCheckInHelpSyn = `
+Check service accounts back in to the library.
+`
+
+const pathLibraryCheckInHelpDesc = `
+This path checks in service accounts, rotating their passwords. Unless the
+library disables check-in enforcement, only the entity or token which checked
+out an account can check it in.
+`
+
+const pathLibraryManageCheckInHelpSyn = `
+Check service accounts back in to the library, regardless of who checked them out.
+`
+
+const pathLibraryManageCheckInHelpDesc = `
+This path checks in service accounts without enforcing that the caller checked
+them out, allowing operators to return accounts whose borrowers can no longer
+do so.
+`
+
+const pathLibraryStatusHelpSyn = `
+Check the status of the service accounts of a library.
+`
+
+const pathLibraryStatusHelpDesc = `
+This path returns whether each service account of the library is available
+and, for those that are checked out, who checked them out.
+`
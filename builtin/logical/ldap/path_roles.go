@@ -0,0 +1,260 @@
+package ldap
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/ldaputil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const rolePath = "role/"
+
+func pathRoles(b *backend) []*framework.Path {
+	return []*framework.Path{
+		&framework.Path{
+			Pattern: strings.TrimSuffix(rolePath, "/") + "/?$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ListOperation: b.pathRoleList,
+			},
+
+			HelpSynopsis:    pathRoleHelpSyn,
+			HelpDescription: pathRoleHelpDesc,
+		},
+		&framework.Path{
+			Pattern: rolePath + framework.GenericNameRegex("name"),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the role.",
+				},
+				"creation_ldif": {
+					Type:        framework.TypeString,
+					Description: "LDIF template applied to create the account.",
+				},
+				"deletion_ldif": {
+					Type:        framework.TypeString,
+					Description: "LDIF template applied to delete the account when its lease is revoked.",
+				},
+				"rollback_ldif": {
+					Type:        framework.TypeString,
+					Description: "LDIF template applied if the creation of the account fails partway. Optional.",
+				},
+				"default_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Default lease duration of the credentials. Defaults to the mount's default TTL.",
+				},
+				"max_ttl": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Maximum lease duration of the credentials. Defaults to the mount's maximum TTL.",
+				},
+			},
+
+			ExistenceCheck: b.pathRoleExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.pathRoleRead,
+				logical.CreateOperation: b.pathRoleCreateUpdate,
+				logical.UpdateOperation: b.pathRoleCreateUpdate,
+				logical.DeleteOperation: b.pathRoleDelete,
+			},
+
+			HelpSynopsis:    pathRoleHelpSyn,
+			HelpDescription: pathRoleHelpDesc,
+		},
+	}
+}
+
+type roleEntry struct {
+	CreationLDIF string        `json:"creation_ldif"`
+	DeletionLDIF string        `json:"deletion_ldif"`
+	RollbackLDIF string        `json:"rollback_ldif"`
+	DefaultTTL   time.Duration `json:"default_ttl"`
+	MaxTTL       time.Duration `json:"max_ttl"`
+}
+
+// ldifTemplateData is the data LDIF templates are rendered with.
+type ldifTemplateData struct {
+	Username    string
+	Password    string
+	DisplayName string
+	RoleName    string
+}
+
+// renderLDIF renders the LDIF template with the data and parses the result.
+func renderLDIF(tmpl string, data *ldifTemplateData) ([]*ldaputil.LDIFEntry, error) {
+	t, err := template.New("ldif").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, errwrap.Wrapf("error parsing template: {{err}}", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return nil, errwrap.Wrapf("error rendering template: {{err}}", err)
+	}
+
+	return ldaputil.ParseLDIF(buf.String())
+}
+
+func (b *backend) role(ctx context.Context, s logical.Storage, name string) (*roleEntry, error) {
+	entry, err := s.Get(ctx, rolePath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result roleEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *backend) pathRoleExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	role, err := b.role(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) pathRoleList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, rolePath)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	role, err := b.role(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"creation_ldif": role.CreationLDIF,
+			"deletion_ldif": role.DeletionLDIF,
+			"rollback_ldif": role.RollbackLDIF,
+			"default_ttl":   role.DefaultTTL.Seconds(),
+			"max_ttl":       role.MaxTTL.Seconds(),
+		},
+	}, nil
+}
+
+func (b *backend) pathRoleCreateUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	role, err := b.role(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		role = &roleEntry{}
+	}
+
+	if v, ok := data.GetOk("creation_ldif"); ok {
+		role.CreationLDIF = v.(string)
+	}
+	if v, ok := data.GetOk("deletion_ldif"); ok {
+		role.DeletionLDIF = v.(string)
+	}
+	if v, ok := data.GetOk("rollback_ldif"); ok {
+		role.RollbackLDIF = v.(string)
+	}
+	if v, ok := data.GetOk("default_ttl"); ok {
+		role.DefaultTTL = time.Duration(v.(int)) * time.Second
+	}
+	if v, ok := data.GetOk("max_ttl"); ok {
+		role.MaxTTL = time.Duration(v.(int)) * time.Second
+	}
+
+	if role.CreationLDIF == "" {
+		return logical.ErrorResponse("creation_ldif is required"), nil
+	}
+	if role.DeletionLDIF == "" {
+		return logical.ErrorResponse("deletion_ldif is required"), nil
+	}
+	if role.MaxTTL != 0 && role.DefaultTTL > role.MaxTTL {
+		return logical.ErrorResponse("default_ttl cannot be greater than max_ttl"), nil
+	}
+
+	// Render the templates with sample data so that mistakes are reported
+	// when writing the role rather than when generating credentials
+	sample := &ldifTemplateData{
+		Username:    "v_token_role_sample_1234567890",
+		Password:    "sample-password",
+		DisplayName: "token",
+		RoleName:    name,
+	}
+	for field, tmpl := range map[string]string{
+		"creation_ldif": role.CreationLDIF,
+		"deletion_ldif": role.DeletionLDIF,
+		"rollback_ldif": role.RollbackLDIF,
+	} {
+		if tmpl == "" {
+			continue
+		}
+		if _, err := renderLDIF(tmpl, sample); err != nil {
+			return logical.ErrorResponse("invalid %s: %s", field, err), nil
+		}
+	}
+
+	entry, err := logical.StorageEntryJSON(rolePath+name, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := req.Storage.Delete(ctx, rolePath+data.Get("name").(string)); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+const pathRoleHelpSyn = `
+Manage the roles that can be created with this backend.
+`
+
+const pathRoleHelpDesc = `
+This path lets you manage the dynamic roles that can be created with this
+backend. Reading credentials from a dynamic role creates a new account by
+applying the creation_ldif template; the account is deleted by applying the
+deletion_ldif template when the lease is revoked.
+
+The templates use the Go template syntax and are rendered with the following
+fields:
+
+  {{.Username}}     The generated username of the account
+  {{.Password}}     The generated password of the account, which is empty
+                    when rendering deletion_ldif
+  {{.DisplayName}}  The display name of the token requesting the credentials,
+                    reduced to letters, digits, "_" and "-"
+  {{.RoleName}}     The name of the role
+
+The "base64" function encodes a value for use in a base64 LDIF value ("::"),
+and "adPassword" encodes a password for the unicodePwd attribute of Active
+Directory, for example:
+
+  unicodePwd:: {{.Password | adPassword}}
+
+If creating the account fails partway, the rollback_ldif template is applied
+to clean up the entries that were created, if it is set.
+`
@@ -0,0 +1,418 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+)
+
+const staticRolePath = "static-role/"
+
+func pathStaticRoles(b *backend) []*framework.Path {
+	return []*framework.Path{
+		&framework.Path{
+			Pattern: strings.TrimSuffix(staticRolePath, "/") + "/?$",
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ListOperation: b.pathStaticRoleList,
+			},
+
+			HelpSynopsis:    pathStaticRoleHelpSyn,
+			HelpDescription: pathStaticRoleHelpDesc,
+		},
+		&framework.Path{
+			Pattern: staticRolePath + framework.GenericNameRegex("name"),
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the static role.",
+				},
+				"username": {
+					Type:        framework.TypeString,
+					Description: "The username of the existing account to manage. Required when creating the role, and cannot be changed.",
+				},
+				"dn": {
+					Type:        framework.TypeString,
+					Description: "The distinguished name of the account. If not set, it is looked up with the configured userattr below userdn.",
+				},
+				"rotation_period": {
+					Type:        framework.TypeDurationSecond,
+					Description: fmt.Sprintf("How often the password of the account is rotated. Required when creating the role; must be at least %d seconds.", queueTickSeconds),
+				},
+			},
+
+			ExistenceCheck: b.pathStaticRoleExistenceCheck,
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.ReadOperation:   b.pathStaticRoleRead,
+				logical.CreateOperation: b.pathStaticRoleCreateUpdate,
+				logical.UpdateOperation: b.pathStaticRoleCreateUpdate,
+				logical.DeleteOperation: b.pathStaticRoleDelete,
+			},
+
+			HelpSynopsis:    pathStaticRoleHelpSyn,
+			HelpDescription: pathStaticRoleHelpDesc,
+		},
+	}
+}
+
+func pathStaticCreds(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-cred/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static role.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathStaticCredsRead,
+		},
+
+		HelpSynopsis:    pathStaticCredsHelpSyn,
+		HelpDescription: pathStaticCredsHelpDesc,
+	}
+}
+
+func pathRotateRole(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "rotate-role/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the static role.",
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRotateRoleUpdate,
+		},
+
+		HelpSynopsis:    pathRotateRoleHelpSyn,
+		HelpDescription: pathRotateRoleHelpDesc,
+	}
+}
+
+type staticRole struct {
+	// Username and DN identify the managed account
+	Username string `json:"username"`
+	DN       string `json:"dn"`
+
+	// Password is the current password of the account
+	Password string `json:"password"`
+
+	// LastVaultRotation represents the last time Vault rotated the password
+	LastVaultRotation time.Time `json:"last_vault_rotation"`
+
+	// RotationPeriod is the time between each rotation. It is compared to
+	// the LastVaultRotation to determine if a password needs to be rotated.
+	RotationPeriod time.Duration `json:"rotation_period"`
+}
+
+// NextRotationTime calculates the next rotation by adding the rotation period
+// to the last known vault rotation
+func (r *staticRole) NextRotationTime() time.Time {
+	return r.LastVaultRotation.Add(r.RotationPeriod)
+}
+
+// PasswordTTL calculates the approximate time remaining until the password is
+// no longer valid.
+func (r *staticRole) PasswordTTL() time.Duration {
+	ttl := time.Until(r.NextRotationTime()).Round(time.Second)
+	if ttl < 0 {
+		ttl = time.Duration(0)
+	}
+	return ttl
+}
+
+func (b *backend) staticRole(ctx context.Context, s logical.Storage, name string) (*staticRole, error) {
+	entry, err := s.Get(ctx, staticRolePath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result staticRole
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (b *backend) pathStaticRoleExistenceCheck(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	role, err := b.staticRole(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+func (b *backend) pathStaticRoleList(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, staticRolePath)
+	if err != nil {
+		return nil, err
+	}
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathStaticRoleRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	role, err := b.staticRole(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"username":        role.Username,
+			"dn":              role.DN,
+			"rotation_period": role.RotationPeriod.Seconds(),
+		},
+	}
+	if !role.LastVaultRotation.IsZero() {
+		resp.Data["last_vault_rotation"] = role.LastVaultRotation
+	}
+	return resp, nil
+}
+
+func (b *backend) pathStaticRoleCreateUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Grab the exclusive lock as well potentially pop and re-push the queue item
+	// for this role
+	lock := locksutil.LockForKey(b.roleLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	role, err := b.staticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		if req.Operation == logical.UpdateOperation {
+			return logical.ErrorResponse("static role %q not found", name), nil
+		}
+		role = &staticRole{}
+	}
+	createRole := req.Operation == logical.CreateOperation
+
+	username := data.Get("username").(string)
+	switch {
+	case createRole && username == "":
+		return logical.ErrorResponse("username is a required field to create a static role"), nil
+	case !createRole && username != "" && username != role.Username:
+		return logical.ErrorResponse("cannot update static role username"), nil
+	case createRole:
+		role.Username = username
+	}
+
+	dn := data.Get("dn").(string)
+	if !createRole && dn != "" && dn != role.DN {
+		return logical.ErrorResponse("cannot update static role dn"), nil
+	}
+
+	rotationPeriodSecondsRaw, ok := data.GetOk("rotation_period")
+	if !ok && createRole {
+		return logical.ErrorResponse("rotation_period is required to create static roles"), nil
+	}
+	if ok {
+		rotationPeriodSeconds := rotationPeriodSecondsRaw.(int)
+		if rotationPeriodSeconds < queueTickSeconds {
+			// The rotation period must be at least that of the queue tick,
+			// otherwise we won't be able to rotate in time
+			return logical.ErrorResponse(fmt.Sprintf("rotation_period must be %d seconds or more", queueTickSeconds)), nil
+		}
+		role.RotationPeriod = time.Duration(rotationPeriodSeconds) * time.Second
+	}
+
+	// lvr represents the role's LastVaultRotation
+	lvr := role.LastVaultRotation
+
+	switch req.Operation {
+	case logical.CreateOperation:
+		if owner, err := b.accountOwner(ctx, req.Storage, role.Username); err != nil {
+			return nil, err
+		} else if owner != "" {
+			return logical.ErrorResponse("account %q is already managed by %s", role.Username, owner), nil
+		}
+
+		if dn == "" {
+			cfg, conn, err := b.connect(ctx, req.Storage)
+			if err != nil {
+				return nil, err
+			}
+			dn, err = accountDN(cfg, conn, role.Username)
+			conn.Close()
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+		}
+		role.DN = dn
+
+		// setStaticAccount saves the role to storage
+		resp, err := b.setStaticAccount(ctx, req.Storage, &setStaticAccountInput{
+			RoleName: name,
+			Role:     role,
+		})
+		if err != nil {
+			return nil, err
+		}
+		lvr = resp.RotationTime
+
+	case logical.UpdateOperation:
+		entry, err := logical.StorageEntryJSON(staticRolePath+name, role)
+		if err != nil {
+			return nil, err
+		}
+		if err := req.Storage.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		// Remove the previous version of the item from the queue
+		b.popFromRotationQueueByKey(name)
+	}
+
+	// Add their rotation to the queue
+	if err := b.pushItem(&queue.Item{
+		Key:      name,
+		Priority: lvr.Add(role.RotationPeriod).Unix(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathStaticRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	// Grab the exclusive lock
+	lock := locksutil.LockForKey(b.roleLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Remove the item from the queue
+	_, _ = b.popFromRotationQueueByKey(name)
+
+	if err := req.Storage.Delete(ctx, staticRolePath+name); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (b *backend) pathStaticCredsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	role, err := b.staticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("unknown static role: %s", name), nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"username":            role.Username,
+			"dn":                  role.DN,
+			"password":            role.Password,
+			"ttl":                 role.PasswordTTL().Seconds(),
+			"rotation_period":     role.RotationPeriod.Seconds(),
+			"last_vault_rotation": role.LastVaultRotation,
+		},
+	}, nil
+}
+
+func (b *backend) pathRotateRoleUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	lock := locksutil.LockForKey(b.roleLocks, name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	role, err := b.staticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse("no static role found for role name"), nil
+	}
+
+	item, err := b.popFromRotationQueueByKey(name)
+	if err != nil {
+		item = &queue.Item{
+			Key: name,
+		}
+	}
+
+	var rotateErr error
+	resp, err := b.setStaticAccount(ctx, req.Storage, &setStaticAccountInput{
+		RoleName: name,
+		Role:     role,
+	})
+	if err != nil {
+		b.Logger().Warn("unable to rotate credentials in rotate-role", "role", name, "error", err)
+		rotateErr = err
+
+		// Update the priority to re-try this rotation and re-add the item to
+		// the queue
+		item.Priority = time.Now().Add(10 * time.Second).Unix()
+
+		// Preserve the WALID if it was returned
+		if resp != nil && resp.WALID != "" {
+			item.Value = resp.WALID
+		}
+	} else {
+		item.Priority = resp.RotationTime.Add(role.RotationPeriod).Unix()
+	}
+
+	// Add their rotation to the queue
+	if err := b.pushItem(item); err != nil {
+		return nil, err
+	}
+
+	return nil, rotateErr
+}
+
+const pathStaticRoleHelpSyn = `
+Manage the static roles that can be created with this backend.
+`
+
+const pathStaticRoleHelpDesc = `
+This path lets you manage the static roles that can be created with this
+backend. A static role maps to an existing account in the directory, whose
+password is rotated every rotation_period. Creating a static role rotates the
+password of the account immediately.
+
+The account is identified by its username; its DN is looked up with the
+configured userattr below userdn, unless given with the "dn" parameter.
+`
+
+const pathStaticCredsHelpSyn = `
+Request the credentials of a static role.
+`
+
+const pathStaticCredsHelpDesc = `
+This path reads the current credentials of the account of a static role. The
+same password is returned until it is rotated, which happens every
+rotation_period; the "ttl" field gives the time remaining until then.
+`
+
+const pathRotateRoleHelpSyn = `
+Request to rotate the credentials of a static role.
+`
+
+const pathRotateRoleHelpDesc = `
+This path attempts to rotate the password of the account of the given static
+role. The next scheduled rotation is pushed back by a rotation_period.
+`
@@ -0,0 +1,447 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/hashicorp/vault/sdk/queue"
+)
+
+const (
+	// Interval to check the queue for items needing rotation
+	queueTickSeconds  = 5
+	queueTickInterval = queueTickSeconds * time.Second
+
+	// WAL storage key used for static account rotations
+	staticWALKey = "staticRotationKey"
+)
+
+// populateQueue loads the priority queue with the existing static roles. This
+// occurs at initialization, after any WAL entries of failed or interrupted
+// rotations have been loaded.
+func (b *backend) populateQueue(ctx context.Context, s logical.Storage) {
+	log := b.Logger()
+	log.Info("populating role rotation queue")
+
+	// Build map of role name / wal entries
+	walMap, err := b.loadStaticWALs(ctx, s)
+	if err != nil {
+		log.Warn("unable to load rotation WALs", "error", err)
+	}
+
+	roles, err := s.List(ctx, staticRolePath)
+	if err != nil {
+		log.Warn("unable to list role for enqueueing", "error", err)
+		return
+	}
+
+	for _, roleName := range roles {
+		select {
+		case <-ctx.Done():
+			log.Info("rotation queue restore cancelled")
+			return
+		default:
+		}
+
+		role, err := b.staticRole(ctx, s, roleName)
+		if err != nil {
+			log.Warn("unable to read static role", "error", err, "role", roleName)
+			continue
+		}
+		if role == nil {
+			continue
+		}
+
+		item := queue.Item{
+			Key:      roleName,
+			Priority: role.NextRotationTime().Unix(),
+		}
+
+		// A WAL entry newer than the role is an unfinished rotation, which
+		// is retried right away with its password
+		if walEntry := walMap[roleName]; walEntry != nil {
+			if !walEntry.LastVaultRotation.IsZero() && walEntry.LastVaultRotation.Before(role.LastVaultRotation) {
+				if err := framework.DeleteWAL(ctx, s, walEntry.walID); err != nil {
+					log.Warn("unable to delete WAL", "error", err, "WAL ID", walEntry.walID)
+				}
+			} else {
+				log.Info("adjusting priority for role", "role", roleName)
+				item.Value = walEntry.walID
+				item.Priority = time.Now().Unix()
+			}
+		}
+
+		if err := b.pushItem(&item); err != nil {
+			log.Warn("unable to enqueue item", "error", err, "role", roleName)
+		}
+	}
+}
+
+// runTicker kicks off a periodic ticker that invokes the automatic credential
+// rotation method every queueTickInterval.
+func (b *backend) runTicker(ctx context.Context, s logical.Storage) {
+	b.Logger().Info("starting periodic ticker")
+	tick := time.NewTicker(queueTickInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			b.rotateCredentials(ctx, s)
+
+		case <-ctx.Done():
+			b.Logger().Info("stopping periodic ticker")
+			return
+		}
+	}
+}
+
+// setCredentialsWAL is used to store information in a WAL that can retry a
+// credential rotation in the event of partial failure.
+type setCredentialsWAL struct {
+	NewPassword string `json:"new_password"`
+	RoleName    string `json:"role_name"`
+	Username    string `json:"username"`
+
+	LastVaultRotation time.Time `json:"last_vault_rotation"`
+
+	walID string
+}
+
+// rotateCredentials pops the items of the priority queue, rotating the
+// password of each static role until it encounters the first one that does
+// not yet need rotation.
+func (b *backend) rotateCredentials(ctx context.Context, s logical.Storage) {
+	for b.rotateCredential(ctx, s) {
+	}
+}
+
+func (b *backend) rotateCredential(ctx context.Context, s logical.Storage) bool {
+	// Quit rotating credentials if shutdown has started
+	select {
+	case <-ctx.Done():
+		return false
+	default:
+	}
+	item, err := b.popFromRotationQueue()
+	if err != nil {
+		if err != queue.ErrEmpty {
+			b.Logger().Error("error popping item from queue", "err", err)
+		}
+		return false
+	}
+
+	if item == nil {
+		return false
+	}
+
+	// Role writes and manual rotations take the same lock
+	lock := locksutil.LockForKey(b.roleLocks, item.Key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	role, err := b.staticRole(ctx, s, item.Key)
+	if err != nil {
+		b.Logger().Error("unable to load role", "role", item.Key, "error", err)
+		item.Priority = time.Now().Add(10 * time.Second).Unix()
+		if err := b.pushItem(item); err != nil {
+			b.Logger().Error("unable to push item on to queue", "error", err)
+		}
+		return true
+	}
+	if role == nil {
+		b.Logger().Warn("role not found", "role", item.Key)
+		return true
+	}
+
+	// The queue is ordered by rotation time, so nothing else is due either
+	if time.Now().Unix() < item.Priority {
+		if err := b.pushItem(item); err != nil {
+			b.Logger().Error("unable to push item on to queue", "error", err)
+		}
+		return false
+	}
+
+	input := &setStaticAccountInput{
+		RoleName: item.Key,
+		Role:     role,
+	}
+
+	// Retry with the password of an unfinished rotation, whose WAL ID
+	// populateQueue or a previous failure stored in the item
+	if walID, ok := item.Value.(string); ok {
+		walEntry, err := b.findStaticWAL(ctx, s, walID)
+		if err != nil {
+			b.Logger().Error("error finding static WAL", "error", err)
+		}
+		if walEntry != nil && walEntry.NewPassword != "" {
+			input.Password = walEntry.NewPassword
+			input.WALID = walID
+		}
+	}
+
+	resp, err := b.setStaticAccount(ctx, s, input)
+	if err != nil {
+		b.Logger().Error("unable to rotate credentials in periodic function", "role", item.Key, "error", err)
+		// Back off before retrying, keeping the WAL entry of the new
+		// password
+		item.Priority = time.Now().Add(10 * time.Second).Unix()
+		if resp != nil && resp.WALID != "" {
+			item.Value = resp.WALID
+		}
+
+		if err := b.pushItem(item); err != nil {
+			b.Logger().Error("unable to push item on to queue", "error", err)
+		}
+		return true
+	}
+
+	lvr := resp.RotationTime
+	if lvr.IsZero() {
+		lvr = time.Now()
+	}
+
+	item.Value = nil
+	item.Priority = lvr.Add(role.RotationPeriod).Unix()
+	if err := b.pushItem(item); err != nil {
+		b.Logger().Warn("unable to push item on to queue", "error", err)
+	}
+	return true
+}
+
+// findStaticWAL loads a static rotation WAL entry by ID. It returns nil if
+// there is no such entry, or it is of another kind.
+func (b *backend) findStaticWAL(ctx context.Context, s logical.Storage, id string) (*setCredentialsWAL, error) {
+	wal, err := framework.GetWAL(ctx, s, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if wal == nil || wal.Kind != staticWALKey {
+		return nil, nil
+	}
+
+	data := wal.Data.(map[string]interface{})
+	walEntry := setCredentialsWAL{
+		walID:       id,
+		NewPassword: data["new_password"].(string),
+		RoleName:    data["role_name"].(string),
+		Username:    data["username"].(string),
+	}
+	lvr, err := time.Parse(time.RFC3339, data["last_vault_rotation"].(string))
+	if err != nil {
+		return nil, err
+	}
+	walEntry.LastVaultRotation = lvr
+
+	return &walEntry, nil
+}
+
+type setStaticAccountInput struct {
+	RoleName string
+	Role     *staticRole
+	Password string
+	WALID    string
+}
+
+type setStaticAccountOutput struct {
+	RotationTime time.Time
+
+	// WALID is set when the rotation failed after writing its WAL entry
+	WALID string
+}
+
+// setStaticAccount sets a new password for the account of a static role and
+// stores the role. The new password is recorded in a WAL entry before it is
+// set, so that it is not lost if storing the role fails.
+//
+// This method does not perform any operations on the priority queue. Those
+// tasks must be handled outside of this method.
+func (b *backend) setStaticAccount(ctx context.Context, s logical.Storage, input *setStaticAccountInput) (*setStaticAccountOutput, error) {
+	if input == nil || input.Role == nil || input.RoleName == "" {
+		return nil, errors.New("input was empty when attempting to set credentials for static account")
+	}
+	output := &setStaticAccountOutput{WALID: input.WALID}
+
+	cfg, conn, err := b.connect(ctx, s)
+	if err != nil {
+		return output, err
+	}
+	defer conn.Close()
+
+	// Retried rotations reuse the password of their WAL entry
+	newPassword := input.Password
+	if newPassword == "" {
+		newPassword, err = generatePassword(cfg)
+		if err != nil {
+			return output, err
+		}
+	}
+
+	if output.WALID == "" {
+		output.WALID, err = framework.PutWAL(ctx, s, staticWALKey, &setCredentialsWAL{
+			RoleName:          input.RoleName,
+			Username:          input.Role.Username,
+			NewPassword:       newPassword,
+			LastVaultRotation: input.Role.LastVaultRotation,
+		})
+		if err != nil {
+			return output, errwrap.Wrapf("error writing WAL entry: {{err}}", err)
+		}
+	}
+
+	if err := setPassword(cfg, conn, input.Role.DN, newPassword); err != nil {
+		return output, err
+	}
+
+	// Store updated role information
+	lvr := time.Now()
+	input.Role.LastVaultRotation = lvr
+	input.Role.Password = newPassword
+	output.RotationTime = lvr
+
+	entry, err := logical.StorageEntryJSON(staticRolePath+input.RoleName, input.Role)
+	if err != nil {
+		return output, err
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return output, err
+	}
+
+	if err := framework.DeleteWAL(ctx, s, output.WALID); err != nil {
+		return output, err
+	}
+
+	return &setStaticAccountOutput{RotationTime: lvr}, nil
+}
+
+// initQueue populates the rotation queue and starts rotating the passwords
+// of static roles, on the nodes which write to the mount's storage. It runs
+// in its own goroutine, started by Factory.
+func (b *backend) initQueue(ctx context.Context, conf *logical.BackendConfig) {
+	replicationState := conf.System.ReplicationState()
+	if (conf.System.LocalMount() || !replicationState.HasState(consts.ReplicationPerformanceSecondary)) &&
+		!replicationState.HasState(consts.ReplicationDRSecondary) &&
+		!replicationState.HasState(consts.ReplicationPerformanceStandby) {
+		b.Logger().Info("initializing ldap rotation queue")
+
+		// Storage is read-only while the mount is being set up. Rotations
+		// write WAL entries and roles, so wait until a probe WAL entry can be
+		// written before starting them.
+	READONLY_LOOP:
+		for {
+			select {
+			case <-ctx.Done():
+				b.Logger().Info("queue initialization canceled")
+				return
+			default:
+			}
+
+			walID, err := framework.PutWAL(ctx, conf.StorageView, staticWALKey, &setCredentialsWAL{RoleName: "vault-readonlytest"})
+			if walID != "" {
+				defer framework.DeleteWAL(ctx, conf.StorageView, walID)
+			}
+			switch {
+			case err == nil:
+				break READONLY_LOOP
+			case err.Error() == logical.ErrSetupReadOnly.Error():
+				time.Sleep(10 * time.Millisecond)
+			default:
+				b.Logger().Error("failed to write probe WAL entry; not starting rotations", "error", err)
+				return
+			}
+		}
+
+		// Load roles and populate queue with static accounts
+		b.populateQueue(ctx, conf.StorageView)
+
+		// Launch ticker
+		go b.runTicker(ctx, conf.StorageView)
+	}
+}
+
+// loadStaticWALs reads WAL entries and returns a map of roles and their
+// setCredentialsWAL, if found.
+func (b *backend) loadStaticWALs(ctx context.Context, s logical.Storage) (map[string]*setCredentialsWAL, error) {
+	keys, err := framework.ListWAL(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	walMap := make(map[string]*setCredentialsWAL)
+	for _, walID := range keys {
+		walEntry, err := b.findStaticWAL(ctx, s, walID)
+		if err != nil {
+			b.Logger().Error("error loading static WAL", "id", walID, "error", err)
+			continue
+		}
+		if walEntry == nil {
+			continue
+		}
+
+		// Verify the static role still exists
+		role, err := b.staticRole(ctx, s, walEntry.RoleName)
+		if err != nil {
+			b.Logger().Warn("unable to read static role", "error", err, "role", walEntry.RoleName)
+			continue
+		}
+		if role == nil {
+			if err := framework.DeleteWAL(ctx, s, walID); err != nil {
+				b.Logger().Warn("unable to delete WAL", "error", err, "WAL ID", walID)
+			}
+			continue
+		}
+
+		walMap[walEntry.RoleName] = walEntry
+	}
+	return walMap, nil
+}
+
+// pushItem pushes an item on the rotation queue, if the backend has one.
+func (b *backend) pushItem(item *queue.Item) error {
+	b.RLock()
+	defer b.RUnlock()
+
+	if b.credRotationQueue != nil {
+		return b.credRotationQueue.Push(item)
+	}
+
+	b.Logger().Warn("no queue found during push item")
+	return nil
+}
+
+// popFromRotationQueue pops the next item of the rotation queue, if the
+// backend has one.
+func (b *backend) popFromRotationQueue() (*queue.Item, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.credRotationQueue != nil {
+		return b.credRotationQueue.Pop()
+	}
+	return nil, queue.ErrEmpty
+}
+
+// popFromRotationQueueByKey removes the item of the given role from the
+// rotation queue, if the backend has one.
+func (b *backend) popFromRotationQueueByKey(name string) (*queue.Item, error) {
+	b.RLock()
+	defer b.RUnlock()
+	if b.credRotationQueue != nil {
+		item, err := b.credRotationQueue.PopByKey(name)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			return item, nil
+		}
+	}
+	return nil, queue.ErrEmpty
+}
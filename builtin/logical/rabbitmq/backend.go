@@ -30,6 +30,7 @@ func Backend() *backend {
 		PathsSpecial: &logical.Paths{
 			SealWrapStorage: []string{
 				"config/connection",
+				staticRolePath,
 			},
 		},
 
@@ -39,15 +40,21 @@ func Backend() *backend {
 			pathListRoles(&b),
 			pathCreds(&b),
 			pathRoles(&b),
+			pathConfigRotateRoot(&b),
+			pathListStaticRoles(&b),
+			pathStaticRoles(&b),
+			pathStaticCreds(&b),
+			pathRotateRole(&b),
 		},
 
 		Secrets: []*framework.Secret{
 			secretCreds(&b),
 		},
 
-		Clean:       b.resetClient,
-		Invalidate:  b.invalidate,
-		BackendType: logical.TypeLogical,
+		Clean:        b.resetClient,
+		Invalidate:   b.invalidate,
+		PeriodicFunc: b.rotateExpiredStaticRoles,
+		BackendType:  logical.TypeLogical,
 	}
 
 	return &b
@@ -58,6 +65,9 @@ type backend struct {
 
 	client *rabbithole.Client
 	lock   sync.RWMutex
+
+	// roleLock serializes changes to static roles and their rotations
+	roleLock sync.Mutex
 }
 
 // DB returns the database connection.
@@ -138,7 +148,8 @@ func (b *backend) Lease(ctx context.Context, s logical.Storage) (*configLease, e
 }
 
 const backendHelp = `
-The RabbitMQ backend dynamically generates RabbitMQ users.
+The RabbitMQ backend dynamically generates RabbitMQ users, and rotates
+the passwords of users with fixed usernames through static roles.
 
 After mounting this backend, configure it using the endpoints within
 the "config/" path.
@@ -0,0 +1,90 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+	rabbithole "github.com/michaelklishin/rabbit-hole"
+)
+
+func pathConfigRotateRoot(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/rotate-root",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathConfigRotateRootUpdate,
+		},
+
+		HelpSynopsis:    pathConfigRotateRootHelpSyn,
+		HelpDescription: pathConfigRotateRootHelpDesc,
+	}
+}
+
+func (b *backend) pathConfigRotateRootUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Hold the client lock, so that no client is created with the old
+	// password while it's being replaced.
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	entry, err := req.Storage.Get(ctx, "config/connection")
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return logical.ErrorResponse("configure the client connection with config/connection first"), nil
+	}
+
+	var connConfig connectionConfig
+	if err := entry.DecodeJSON(&connConfig); err != nil {
+		return nil, err
+	}
+
+	client, err := rabbithole.NewClient(connConfig.URI, connConfig.Username, connConfig.Password)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to create client: {{err}}", err)
+	}
+
+	// Setting the password also sets the tags, so keep the current ones
+	user, err := client.GetUser(connConfig.Username)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("failed to read user %q: {{err}}", connConfig.Username), err)
+	}
+
+	password, err := uuid.GenerateUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkResponse(client.PutUser(connConfig.Username, rabbithole.UserSettings{
+		Password: password,
+		Tags:     user.Tags,
+	})); err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("failed to set the password of user %q: {{err}}", connConfig.Username), err)
+	}
+
+	connConfig.Password = password
+	entry, err = logical.StorageEntryJSON("config/connection", connConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	// Reset the client connection
+	b.client = nil
+
+	return nil, nil
+}
+
+const pathConfigRotateRootHelpSyn = `
+Request to rotate the password of the user Vault uses to talk to RabbitMQ.
+`
+
+const pathConfigRotateRootHelpDesc = `
+This path sets a new password for the user configured at "config/connection",
+which only Vault knows afterwards. The tags of the user are left unchanged.
+`
@@ -76,40 +76,12 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 		return nil, fmt.Errorf("failed to create a new user with the generated credentials")
 	}
 
-	// If the role had vhost permissions specified, assign those permissions
-	// to the created username for respective vhosts.
-	for vhost, permission := range role.VHosts {
-		if _, err := client.UpdatePermissionsIn(vhost, username, rabbithole.Permissions{
-			Configure: permission.Configure,
-			Write:     permission.Write,
-			Read:      permission.Read,
-		}); err != nil {
-			outerErr := errwrap.Wrapf(fmt.Sprintf("failed to update permissions to the %q user: {{err}}", username), err)
-			// Delete the user because it's in an unknown state
-			if _, rmErr := client.DeleteUser(username); rmErr != nil {
-				return nil, multierror.Append(errwrap.Wrapf("failed to delete user: {{err}}", rmErr), outerErr)
-			}
-			return nil, outerErr
-		}
-	}
-
-	// If the role had vhost topic permissions specified, assign those permissions
-	// to the created username for respective vhosts and exchange.
-	for vhost, permissions := range role.VHostTopics {
-		for exchange, permission := range permissions {
-			if _, err := client.UpdateTopicPermissionsIn(vhost, username, rabbithole.TopicPermissions{
-				Exchange: exchange,
-				Write:    permission.Write,
-				Read:     permission.Read,
-			}); err != nil {
-				outerErr := errwrap.Wrapf(fmt.Sprintf("failed to update topic permissions to the %q user: {{err}}", username), err)
-				// Delete the user because it's in an unknown state
-				if _, rmErr := client.DeleteUser(username); rmErr != nil {
-					return nil, multierror.Append(errwrap.Wrapf("failed to delete user: {{err}}", rmErr), outerErr)
-				}
-				return nil, outerErr
-			}
+	if err := applyPermissions(client, username, role.VHosts, role.VHostTopics); err != nil {
+		// Delete the user because it's in an unknown state
+		if _, rmErr := client.DeleteUser(username); rmErr != nil {
+			return nil, multierror.Append(errwrap.Wrapf("failed to delete user: {{err}}", rmErr), err)
 		}
+		return nil, err
 	}
 
 	// Return the secret
@@ -134,6 +106,37 @@ func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, d *fr
 	return resp, nil
 }
 
+// applyPermissions grants the vhost and topic permissions to the user.
+func applyPermissions(client *rabbithole.Client, username string, vhosts map[string]vhostPermission, vhostTopics map[string]map[string]vhostTopicPermission) error {
+	// If the role had vhost permissions specified, assign those permissions
+	// to the username for respective vhosts.
+	for vhost, permission := range vhosts {
+		if _, err := client.UpdatePermissionsIn(vhost, username, rabbithole.Permissions{
+			Configure: permission.Configure,
+			Write:     permission.Write,
+			Read:      permission.Read,
+		}); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("failed to update permissions to the %q user: {{err}}", username), err)
+		}
+	}
+
+	// If the role had vhost topic permissions specified, assign those permissions
+	// to the username for respective vhosts and exchange.
+	for vhost, permissions := range vhostTopics {
+		for exchange, permission := range permissions {
+			if _, err := client.UpdateTopicPermissionsIn(vhost, username, rabbithole.TopicPermissions{
+				Exchange: exchange,
+				Write:    permission.Write,
+				Read:     permission.Read,
+			}); err != nil {
+				return errwrap.Wrapf(fmt.Sprintf("failed to update topic permissions to the %q user: {{err}}", username), err)
+			}
+		}
+	}
+
+	return nil
+}
+
 const pathRoleCreateReadHelpSyn = `
 Request RabbitMQ credentials for a certain role.
 `
@@ -126,18 +126,9 @@ func (b *backend) pathRoleUpdate(ctx context.Context, req *logical.Request, d *f
 		return logical.ErrorResponse("both tags and vhosts not specified"), nil
 	}
 
-	var vhosts map[string]vhostPermission
-	if len(rawVHosts) > 0 {
-		if err := jsonutil.DecodeJSON([]byte(rawVHosts), &vhosts); err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("failed to unmarshal vhosts: %s", err)), nil
-		}
-	}
-
-	var vhostTopics map[string]map[string]vhostTopicPermission
-	if len(rawVHostTopics) > 0 {
-		if err := jsonutil.DecodeJSON([]byte(rawVHostTopics), &vhostTopics); err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("failed to unmarshal vhost_topics: %s", err)), nil
-		}
+	vhosts, vhostTopics, err := decodePermissions(rawVHosts, rawVHostTopics)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
 	}
 
 	// Store it
@@ -156,6 +147,26 @@ func (b *backend) pathRoleUpdate(ctx context.Context, req *logical.Request, d *f
 	return nil, nil
 }
 
+// decodePermissions decodes the JSON-encoded vhost and topic permissions of a
+// role.
+func decodePermissions(rawVHosts, rawVHostTopics string) (map[string]vhostPermission, map[string]map[string]vhostTopicPermission, error) {
+	var vhosts map[string]vhostPermission
+	if len(rawVHosts) > 0 {
+		if err := jsonutil.DecodeJSON([]byte(rawVHosts), &vhosts); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal vhosts: %s", err)
+		}
+	}
+
+	var vhostTopics map[string]map[string]vhostTopicPermission
+	if len(rawVHostTopics) > 0 {
+		if err := jsonutil.DecodeJSON([]byte(rawVHostTopics), &vhostTopics); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal vhost_topics: %s", err)
+		}
+	}
+
+	return vhosts, vhostTopics, nil
+}
+
 // Role that defines the capabilities of the credentials issued against it.
 // Maps are used because the names of vhosts and exchanges will vary widely.
 // VHosts is a map with a vhost name as key and the permissions as value.
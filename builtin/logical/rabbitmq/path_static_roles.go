@@ -0,0 +1,373 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	staticRolePath = "static-role/"
+
+	// minRotationPeriod is the shortest rotation period of static roles.
+	// Expired roles are rotated by the periodic function of the backend,
+	// which runs about once a minute.
+	minRotationPeriod = time.Minute
+)
+
+func pathListStaticRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-roles/?$",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathStaticRoleList,
+		},
+		HelpSynopsis:    pathStaticRoleHelpSyn,
+		HelpDescription: pathStaticRoleHelpDesc,
+	}
+}
+
+func pathStaticRoles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-roles/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the static role.",
+			},
+			"username": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the RabbitMQ user whose password is rotated. It's created if it doesn't exist, and can't be changed after the role is created.",
+			},
+			"rotation_period": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "Period after which the password of the user is rotated. Must be at least one minute.",
+			},
+			"tags": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Comma-separated list of tags of the user.",
+			},
+			"vhosts": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "A map of virtual hosts to permissions.",
+			},
+			"vhost_topics": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "A nested map of virtual hosts and exchanges to topic permissions.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation:   b.pathStaticRoleRead,
+			logical.CreateOperation: b.pathStaticRoleWrite,
+			logical.UpdateOperation: b.pathStaticRoleWrite,
+			logical.DeleteOperation: b.pathStaticRoleDelete,
+		},
+		ExistenceCheck:  b.pathStaticRoleExistenceCheck,
+		HelpSynopsis:    pathStaticRoleHelpSyn,
+		HelpDescription: pathStaticRoleHelpDesc,
+	}
+}
+
+func pathStaticCreds(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "static-creds/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the static role.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathStaticCredsRead,
+		},
+		HelpSynopsis:    pathStaticCredsHelpSyn,
+		HelpDescription: pathStaticCredsHelpDesc,
+	}
+}
+
+func pathRotateRole(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "rotate-role/" + framework.GenericNameRegex("name"),
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the static role.",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRotateRoleUpdate,
+		},
+		HelpSynopsis:    pathRotateRoleHelpSyn,
+		HelpDescription: pathRotateRoleHelpDesc,
+	}
+}
+
+// Static role whose user has a fixed username and a password which is rotated
+// every RotationPeriod.
+type staticRoleEntry struct {
+	Username          string                                     `json:"username"`
+	RotationPeriod    time.Duration                              `json:"rotation_period"`
+	Tags              string                                     `json:"tags"`
+	VHosts            map[string]vhostPermission                 `json:"vhosts"`
+	VHostTopics       map[string]map[string]vhostTopicPermission `json:"vhost_topics"`
+	Password          string                                     `json:"password"`
+	LastVaultRotation time.Time                                  `json:"last_vault_rotation"`
+}
+
+// NextRotationTime returns the time at which the password is due for
+// rotation.
+func (r *staticRoleEntry) NextRotationTime() time.Time {
+	return r.LastVaultRotation.Add(r.RotationPeriod)
+}
+
+// Reads the static role configuration from the storage
+func (b *backend) StaticRole(ctx context.Context, s logical.Storage, n string) (*staticRoleEntry, error) {
+	entry, err := s.Get(ctx, staticRolePath+n)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result staticRoleEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func setStaticRole(ctx context.Context, s logical.Storage, n string, role *staticRoleEntry) error {
+	entry, err := logical.StorageEntryJSON(staticRolePath+n, role)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, entry)
+}
+
+// Lists all the static roles registered with the backend
+func (b *backend) pathStaticRoleList(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	roles, err := req.Storage.List(ctx, staticRolePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(roles), nil
+}
+
+func (b *backend) pathStaticRoleExistenceCheck(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+	role, err := b.StaticRole(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return false, err
+	}
+	return role != nil, nil
+}
+
+// Reads an existing static role
+func (b *backend) pathStaticRoleRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	role, err := b.StaticRole(ctx, req.Storage, d.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"username":            role.Username,
+			"rotation_period":     int64(role.RotationPeriod.Seconds()),
+			"tags":                role.Tags,
+			"vhosts":              role.VHosts,
+			"vhost_topics":        role.VHostTopics,
+			"last_vault_rotation": role.LastVaultRotation,
+		},
+	}, nil
+}
+
+// Registers a new static role with the backend, or updates an existing one.
+// New roles take over their user right away by setting its password.
+func (b *backend) pathStaticRoleWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.roleLock.Lock()
+	defer b.roleLock.Unlock()
+
+	role, err := b.StaticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	isCreate := role == nil
+	if isCreate {
+		role = &staticRoleEntry{}
+	}
+	previous := *role
+
+	if usernameRaw, ok := d.GetOk("username"); ok {
+		username := usernameRaw.(string)
+		if !isCreate && username != role.Username {
+			return logical.ErrorResponse("username of static role %q can't be changed", name), nil
+		}
+		role.Username = username
+	}
+	if role.Username == "" {
+		return logical.ErrorResponse("missing username"), nil
+	}
+
+	if rotationPeriodRaw, ok := d.GetOk("rotation_period"); ok {
+		role.RotationPeriod = time.Duration(rotationPeriodRaw.(int)) * time.Second
+	}
+	if role.RotationPeriod < minRotationPeriod {
+		return logical.ErrorResponse(fmt.Sprintf("rotation_period must be at least %d seconds", int(minRotationPeriod.Seconds()))), nil
+	}
+
+	if tagsRaw, ok := d.GetOk("tags"); ok {
+		role.Tags = tagsRaw.(string)
+	}
+	vhosts, vhostTopics, err := decodePermissions(d.Get("vhosts").(string), d.Get("vhost_topics").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if _, ok := d.GetOk("vhosts"); ok {
+		role.VHosts = vhosts
+	}
+	if _, ok := d.GetOk("vhost_topics"); ok {
+		role.VHostTopics = vhostTopics
+	}
+	if role.Tags == "" && len(role.VHosts) == 0 {
+		return logical.ErrorResponse("both tags and vhosts not specified"), nil
+	}
+
+	client, err := b.Client(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	if isCreate {
+		if err := b.rotateStaticRole(ctx, req.Storage, name, role); err != nil {
+			return logical.ErrorResponse("failed to set the password of user %q: %s", role.Username, err), nil
+		}
+	} else {
+		// Update the tags, which requires setting the password again
+		if err := checkResponse(client.PutUser(role.Username, userSettings(role))); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("failed to update user %q: {{err}}", role.Username), err)
+		}
+		if err := setStaticRole(ctx, req.Storage, name, role); err != nil {
+			return nil, err
+		}
+	}
+
+	// Revoke the permissions the role no longer grants, then grant the ones
+	// it does.
+	for vhost := range previous.VHosts {
+		if _, ok := role.VHosts[vhost]; !ok {
+			if _, err := client.ClearPermissionsIn(vhost, role.Username); err != nil {
+				return nil, errwrap.Wrapf(fmt.Sprintf("failed to clear permissions of the %q user: {{err}}", role.Username), err)
+			}
+		}
+	}
+	for vhost := range previous.VHostTopics {
+		if _, err := client.ClearTopicPermissionsIn(vhost, role.Username); err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("failed to clear topic permissions of the %q user: {{err}}", role.Username), err)
+		}
+	}
+	if err := applyPermissions(client, role.Username, role.VHosts, role.VHostTopics); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// Deletes a static role. The user is left in place with its current password.
+func (b *backend) pathStaticRoleDelete(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.roleLock.Lock()
+	defer b.roleLock.Unlock()
+
+	return nil, req.Storage.Delete(ctx, staticRolePath+d.Get("name").(string))
+}
+
+// Returns the current credentials of a static role
+func (b *backend) pathStaticCredsRead(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	role, err := b.StaticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown static role: %s", name)), nil
+	}
+
+	ttl := time.Until(role.NextRotationTime())
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"username":            role.Username,
+			"password":            role.Password,
+			"last_vault_rotation": role.LastVaultRotation,
+			"rotation_period":     int64(role.RotationPeriod.Seconds()),
+			"ttl":                 int64(ttl.Seconds()),
+		},
+	}, nil
+}
+
+// Rotates the password of a static role right away
+func (b *backend) pathRotateRoleUpdate(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	name := d.Get("name").(string)
+
+	b.roleLock.Lock()
+	defer b.roleLock.Unlock()
+
+	role, err := b.StaticRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown static role: %s", name)), nil
+	}
+
+	if err := b.rotateStaticRole(ctx, req.Storage, name, role); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+const pathStaticRoleHelpSyn = `
+Manage the static roles of this backend, whose users have fixed usernames.
+`
+
+const pathStaticRoleHelpDesc = `
+This path lets you manage static roles. A static role takes over a RabbitMQ
+user with a fixed username, creating it if it doesn't exist, and rotates its
+password every "rotation_period". The "tags", "vhosts" and "vhost_topics"
+parameters are the same as those of dynamic roles, and are applied to the user
+whenever the role is written.
+
+Deleting a static role leaves the user in place with its current password.
+`
+
+const pathStaticCredsHelpSyn = `
+Read the current credentials of a static role.
+`
+
+const pathStaticCredsHelpDesc = `
+This path returns the username and current password of the user of a static
+role, along with the number of seconds until the password is rotated. The
+credentials aren't leased; they stay valid until the next rotation.
+`
+
+const pathRotateRoleHelpSyn = `
+Rotate the password of a static role right away.
+`
+
+const pathRotateRoleHelpDesc = `
+This path sets a new password for the user of a static role, and restarts its
+rotation period.
+`
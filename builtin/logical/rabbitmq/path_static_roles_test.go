@@ -0,0 +1,322 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	rabbithole "github.com/michaelklishin/rabbit-hole"
+)
+
+// fakeManagementAPI implements the parts of the RabbitMQ management HTTP API
+// used by the backend, keeping users and permissions in memory.
+type fakeManagementAPI struct {
+	sync.Mutex
+	users             map[string]rabbithole.UserSettings
+	permissions       map[string]rabbithole.Permissions
+	topicPermissions  map[string]rabbithole.TopicPermissions
+	requireAuthFrom   string
+	unauthorizedCalls int
+}
+
+func newFakeManagementAPI(adminUser, adminPassword string) *fakeManagementAPI {
+	return &fakeManagementAPI{
+		users: map[string]rabbithole.UserSettings{
+			adminUser: {Name: adminUser, Password: adminPassword, Tags: "administrator"},
+		},
+		permissions:      map[string]rabbithole.Permissions{},
+		topicPermissions: map[string]rabbithole.TopicPermissions{},
+		requireAuthFrom:  adminUser,
+	}
+}
+
+func (f *fakeManagementAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	username, password, _ := r.BasicAuth()
+	if admin := f.users[f.requireAuthFrom]; username != admin.Name || password != admin.Password {
+		f.unauthorizedCalls++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/api/"), "/")
+	for i, part := range parts {
+		parts[i], _ = url.PathUnescape(part)
+	}
+
+	switch {
+	case len(parts) == 2 && parts[0] == "users" && r.Method == http.MethodGet:
+		user, ok := f.users[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(rabbithole.UserInfo{Name: user.Name, Tags: user.Tags})
+	case len(parts) == 2 && parts[0] == "users" && r.Method == http.MethodPut:
+		var settings rabbithole.UserSettings
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		settings.Name = parts[1]
+		f.users[parts[1]] = settings
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 2 && parts[0] == "users" && r.Method == http.MethodDelete:
+		delete(f.users, parts[1])
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[0] == "permissions" && r.Method == http.MethodPut:
+		var permissions rabbithole.Permissions
+		json.NewDecoder(r.Body).Decode(&permissions)
+		f.permissions[parts[1]+"/"+parts[2]] = permissions
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[0] == "permissions" && r.Method == http.MethodDelete:
+		delete(f.permissions, parts[1]+"/"+parts[2])
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[0] == "topic-permissions" && r.Method == http.MethodPut:
+		var permissions rabbithole.TopicPermissions
+		json.NewDecoder(r.Body).Decode(&permissions)
+		f.topicPermissions[parts[1]+"/"+parts[2]+"/"+permissions.Exchange] = permissions
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[0] == "topic-permissions" && r.Method == http.MethodDelete:
+		for key := range f.topicPermissions {
+			if strings.HasPrefix(key, parts[1]+"/"+parts[2]+"/") {
+				delete(f.topicPermissions, key)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeManagementAPI) user(name string) (rabbithole.UserSettings, bool) {
+	f.Lock()
+	defer f.Unlock()
+	user, ok := f.users[name]
+	return user, ok
+}
+
+func (f *fakeManagementAPI) hasPermissions(vhost, username string) bool {
+	f.Lock()
+	defer f.Unlock()
+	_, ok := f.permissions[vhost+"/"+username]
+	return ok
+}
+
+func (f *fakeManagementAPI) topicPermission(vhost, username, exchange string) (rabbithole.TopicPermissions, bool) {
+	f.Lock()
+	defer f.Unlock()
+	permissions, ok := f.topicPermissions[vhost+"/"+username+"/"+exchange]
+	return permissions, ok
+}
+
+func testFakeBackend(t *testing.T, api *fakeManagementAPI) (*backend, logical.Storage, func()) {
+	server := httptest.NewServer(api)
+
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	b := Backend()
+	if err := b.Setup(context.Background(), config); err != nil {
+		server.Close()
+		t.Fatal(err)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/connection",
+		Storage:   config.StorageView,
+		Data: map[string]interface{}{
+			"connection_uri":    server.URL,
+			"username":          "vault",
+			"password":          "initial",
+			"verify_connection": false,
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		server.Close()
+		t.Fatalf("failed to configure connection: resp: %#v, err: %v", resp, err)
+	}
+
+	return b, config.StorageView, server.Close
+}
+
+func TestBackend_StaticRoles(t *testing.T) {
+	api := newFakeManagementAPI("vault", "initial")
+	api.users["app"] = rabbithole.UserSettings{Name: "app", Password: "unknown", Tags: ""}
+	b, s, cleanup := testFakeBackend(t, api)
+	defer cleanup()
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		resp, err := b.HandleRequest(context.Background(), &logical.Request{
+			Operation: op,
+			Path:      path,
+			Storage:   s,
+			Data:      data,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := request(logical.CreateOperation, "static-roles/app", map[string]interface{}{
+		"username":        "app",
+		"rotation_period": 30,
+		"vhosts":          testVHosts,
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error for short rotation_period, got: %#v", resp)
+	}
+
+	resp = request(logical.CreateOperation, "static-roles/app", map[string]interface{}{
+		"username":        "app",
+		"rotation_period": 3600,
+		"tags":            "monitoring",
+		"vhosts":          testVHosts,
+		"vhost_topics":    testVHostTopics,
+	})
+	if resp != nil && resp.IsError() {
+		t.Fatalf("failed to create static role: %#v", resp)
+	}
+
+	resp = request(logical.ReadOperation, "static-creds/app", nil)
+	if resp.IsError() {
+		t.Fatalf("failed to read static creds: %#v", resp)
+	}
+	password := resp.Data["password"].(string)
+	if user, _ := api.user("app"); resp.Data["username"] != "app" || password == "unknown" || user.Password != password || user.Tags != "monitoring" {
+		t.Fatalf("bad static creds: %#v, user: %#v", resp.Data, user)
+	}
+	if ttl := resp.Data["ttl"].(int64); ttl <= 3500 || ttl > 3600 {
+		t.Fatalf("bad ttl: %d", ttl)
+	}
+	if !api.hasPermissions("/", "app") {
+		t.Fatal("expected permissions in / to be granted")
+	}
+	if permissions, ok := api.topicPermission("/", "app", "amq.topic"); !ok || permissions.Write != ".*" {
+		t.Fatalf("bad topic permissions: %#v", permissions)
+	}
+
+	resp = request(logical.UpdateOperation, "static-roles/app", map[string]interface{}{
+		"username": "other",
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error changing username, got: %#v", resp)
+	}
+
+	// Moving the role to another vhost revokes the permissions in the old one
+	resp = request(logical.UpdateOperation, "static-roles/app", map[string]interface{}{
+		"vhosts":       `{"orders": {"configure": "", "write": ".*", "read": ".*"}}`,
+		"vhost_topics": `{"orders": {"events": {"write": "", "read": ".*"}}}`,
+	})
+	if resp != nil && resp.IsError() {
+		t.Fatalf("failed to update static role: %#v", resp)
+	}
+	if api.hasPermissions("/", "app") || !api.hasPermissions("orders", "app") {
+		t.Fatal("expected permissions to move from / to orders")
+	}
+	if _, ok := api.topicPermission("/", "app", "amq.topic"); ok {
+		t.Fatal("expected topic permissions in / to be revoked")
+	}
+	if _, ok := api.topicPermission("orders", "app", "events"); !ok {
+		t.Fatal("expected topic permissions in orders to be granted")
+	}
+	if user, _ := api.user("app"); user.Password != password {
+		t.Fatal("expected update to keep the password")
+	}
+
+	resp = request(logical.UpdateOperation, "rotate-role/app", nil)
+	if resp != nil && resp.IsError() {
+		t.Fatalf("failed to rotate static role: %#v", resp)
+	}
+	resp = request(logical.ReadOperation, "static-creds/app", nil)
+	if user, _ := api.user("app"); resp.Data["password"] == password || user.Password != resp.Data["password"] {
+		t.Fatalf("expected a new password, got: %#v", resp.Data)
+	}
+	password = resp.Data["password"].(string)
+
+	// The periodic function leaves roles alone until they expire
+	if err := b.rotateExpiredStaticRoles(context.Background(), &logical.Request{Storage: s}); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := api.user("app"); user.Password != password {
+		t.Fatal("expected periodic function to keep the password")
+	}
+
+	role, err := b.StaticRole(context.Background(), s, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	role.LastVaultRotation = time.Now().Add(-2 * time.Hour)
+	if err := setStaticRole(context.Background(), s, "app", role); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.rotateExpiredStaticRoles(context.Background(), &logical.Request{Storage: s}); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ := api.user("app"); user.Password == password {
+		t.Fatal("expected periodic function to rotate the expired password")
+	}
+
+	resp = request(logical.ListOperation, "static-roles/", nil)
+	if keys := resp.Data["keys"].([]string); len(keys) != 1 || keys[0] != "app" {
+		t.Fatalf("bad static role list: %v", keys)
+	}
+
+	// Deleting the role leaves the user in place
+	request(logical.DeleteOperation, "static-roles/app", nil)
+	if resp = request(logical.ReadOperation, "static-roles/app", nil); resp != nil {
+		t.Fatalf("expected static role to be deleted, got: %#v", resp)
+	}
+	if _, ok := api.user("app"); !ok {
+		t.Fatal("expected user to be kept")
+	}
+}
+
+func TestBackend_ConfigRotateRoot(t *testing.T) {
+	api := newFakeManagementAPI("vault", "initial")
+	b, s, cleanup := testFakeBackend(t, api)
+	defer cleanup()
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "config/rotate-root",
+		Storage:   s,
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to rotate root credentials: resp: %#v, err: %v", resp, err)
+	}
+
+	user, _ := api.user("vault")
+	if user.Password == "initial" || user.Tags != "administrator" {
+		t.Fatalf("bad user after rotation: %#v", user)
+	}
+
+	// The backend keeps working with the new password
+	resp, err = b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.CreateOperation,
+		Path:      "static-roles/app",
+		Storage:   s,
+		Data: map[string]interface{}{
+			"username":        "app",
+			"rotation_period": 3600,
+			"tags":            "monitoring",
+		},
+	})
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("failed to create static role after rotation: resp: %#v, err: %v", resp, err)
+	}
+	if api.unauthorizedCalls != 0 {
+		t.Fatalf("expected no unauthorized calls, got %d", api.unauthorizedCalls)
+	}
+}
@@ -0,0 +1,99 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	multierror "github.com/hashicorp/go-multierror"
+	uuid "github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+	rabbithole "github.com/michaelklishin/rabbit-hole"
+)
+
+// Rotates the passwords of the static roles whose rotation period has
+// passed. This is the periodic function of the backend.
+func (b *backend) rotateExpiredStaticRoles(ctx context.Context, req *logical.Request) error {
+	if !b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary|consts.ReplicationPerformanceStandby) {
+		return nil
+	}
+
+	names, err := req.Storage.List(ctx, staticRolePath)
+	if err != nil {
+		return err
+	}
+
+	b.roleLock.Lock()
+	defer b.roleLock.Unlock()
+
+	var errs *multierror.Error
+	now := time.Now()
+	for _, name := range names {
+		role, err := b.StaticRole(ctx, req.Storage, name)
+		if err != nil {
+			errs = multierror.Append(errs, errwrap.Wrapf(fmt.Sprintf("failed to read static role %q: {{err}}", name), err))
+			continue
+		}
+		if role == nil || now.Before(role.NextRotationTime()) {
+			continue
+		}
+
+		if err := b.rotateStaticRole(ctx, req.Storage, name, role); err != nil {
+			b.Logger().Error("failed to rotate password of static role", "role", name, "username", role.Username, "error", err)
+			errs = multierror.Append(errs, err)
+		}
+	}
+	return errs.ErrorOrNil()
+}
+
+// Sets a new password for the user of a static role and stores it in the
+// role. The caller must hold roleLock.
+func (b *backend) rotateStaticRole(ctx context.Context, s logical.Storage, name string, role *staticRoleEntry) error {
+	client, err := b.Client(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	password, err := uuid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+
+	settings := userSettings(role)
+	settings.Password = password
+	if err := checkResponse(client.PutUser(role.Username, settings)); err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("failed to set the password of user %q: {{err}}", role.Username), err)
+	}
+
+	role.Password = password
+	role.LastVaultRotation = time.Now()
+	return setStaticRole(ctx, s, name, role)
+}
+
+// Returns an error if a request to the management API failed. The client
+// doesn't check the status of the responses of requests which change state.
+func checkResponse(res *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("error %d from RabbitMQ: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// Returns the settings of the user of a static role.
+func userSettings(role *staticRoleEntry) rabbithole.UserSettings {
+	return rabbithole.UserSettings{
+		Password: role.Password,
+		Tags:     role.Tags,
+	}
+}
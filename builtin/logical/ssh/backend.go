@@ -15,6 +15,9 @@ type backend struct {
 	view      logical.Storage
 	salt      *salt.Salt
 	saltMutex sync.RWMutex
+
+	// caMutex serializes changes to the CA keys
+	caMutex sync.Mutex
 }
 
 func Factory(ctx context.Context, conf *logical.BackendConfig) (logical.Backend, error) {
@@ -38,6 +41,7 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			Unauthenticated: []string{
 				"verify",
 				"public_key",
+				"known_hosts",
 			},
 
 			LocalStorage: []string{
@@ -62,6 +66,8 @@ func Backend(conf *logical.BackendConfig) (*backend, error) {
 			pathConfigCA(&b),
 			pathSign(&b),
 			pathFetchPublicKey(&b),
+			pathFetchKnownHosts(&b),
+			pathConfigRotateCA(&b),
 		},
 
 		Secrets: []*framework.Secret{
@@ -2,16 +2,23 @@ package ssh
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/errwrap"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -22,12 +29,21 @@ const (
 	caPublicKeyStoragePathDeprecated  = "public_key"
 	caPrivateKeyStoragePath           = "config/ca_private_key"
 	caPrivateKeyStoragePathDeprecated = "config/ca_bundle"
+	caPreviousKeysStoragePath         = "config/ca_previous_public_keys"
 )
 
 type keyStorageEntry struct {
 	Key string `json:"key" structs:"key" mapstructure:"key"`
 }
 
+// previousCAKey is the public key of a CA which was rotated out. It's still
+// trusted until the certificates it signed have expired.
+type previousCAKey struct {
+	Key          string    `json:"key"`
+	RetiredAt    time.Time `json:"retired_at"`
+	TrustedUntil time.Time `json:"trusted_until"`
+}
+
 func pathConfigCA(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: "config/ca",
@@ -45,6 +61,17 @@ func pathConfigCA(b *backend) *framework.Path {
 				Description: `Generate SSH key pair internally rather than use the private_key and public_key fields.`,
 				Default:     true,
 			},
+			"key_type": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Type of the generated key pair; one of "rsa", "ec" or "ed25519".`,
+				Default:     "rsa",
+			},
+			"key_bits": &framework.FieldSchema{
+				Type: framework.TypeInt,
+				Description: `Size of the generated key pair; 2048, 3072 or 4096 for "rsa" keys, and
+256, 384 or 521 for "ec" keys. Defaults to 4096 for "rsa" keys and 256 for
+"ec" keys, and is ignored for "ed25519" keys.`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -73,9 +100,23 @@ func (b *backend) pathConfigCARead(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse("keys haven't been configured yet"), nil
 	}
 
+	previousKeys, err := previousCAKeys(ctx, req.Storage)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read previous CA public keys: {{err}}", err)
+	}
+	previous := make([]map[string]interface{}, 0, len(previousKeys))
+	for _, key := range previousKeys {
+		previous = append(previous, map[string]interface{}{
+			"public_key":    key.Key,
+			"retired_at":    key.RetiredAt,
+			"trusted_until": key.TrustedUntil,
+		})
+	}
+
 	response := &logical.Response{
 		Data: map[string]interface{}{
-			"public_key": publicKeyEntry.Key,
+			"public_key":           publicKeyEntry.Key,
+			"previous_public_keys": previous,
 		},
 	}
 
@@ -83,15 +124,71 @@ func (b *backend) pathConfigCARead(ctx context.Context, req *logical.Request, da
 }
 
 func (b *backend) pathConfigCADelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	b.caMutex.Lock()
+	defer b.caMutex.Unlock()
+
 	if err := req.Storage.Delete(ctx, caPrivateKeyStoragePath); err != nil {
 		return nil, err
 	}
 	if err := req.Storage.Delete(ctx, caPublicKeyStoragePath); err != nil {
 		return nil, err
 	}
+	if err := req.Storage.Delete(ctx, caPreviousKeysStoragePath); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
+// previousCAKeys returns the public keys of the CAs which were rotated out and
+// are still trusted.
+func previousCAKeys(ctx context.Context, s logical.Storage) ([]previousCAKey, error) {
+	entry, err := s.Get(ctx, caPreviousKeysStoragePath)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var keys []previousCAKey
+	if err := entry.DecodeJSON(&keys); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	trusted := keys[:0]
+	for _, key := range keys {
+		if now.Before(key.TrustedUntil) {
+			trusted = append(trusted, key)
+		}
+	}
+	return trusted, nil
+}
+
+// trustedCAPublicKeys returns the public key of the current CA followed by
+// those of the previous CAs which are still trusted. It returns nothing if no
+// CA is configured.
+func trustedCAPublicKeys(ctx context.Context, s logical.Storage) ([]string, error) {
+	publicKeyEntry, err := caKey(ctx, s, caPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if publicKeyEntry == nil || publicKeyEntry.Key == "" {
+		return nil, nil
+	}
+
+	previousKeys, err := previousCAKeys(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{strings.TrimSpace(publicKeyEntry.Key)}
+	for _, key := range previousKeys {
+		keys = append(keys, strings.TrimSpace(key.Key))
+	}
+	return strutil.RemoveDuplicatesStable(keys, false), nil
+}
+
 func caKey(ctx context.Context, storage logical.Storage, keyType string) (*keyStorageEntry, error) {
 	var path, deprecatedPath string
 	switch keyType {
@@ -145,61 +242,13 @@ func caKey(ctx context.Context, storage logical.Storage, keyType string) (*keySt
 }
 
 func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	var err error
-	publicKey := data.Get("public_key").(string)
-	privateKey := data.Get("private_key").(string)
-
-	var generateSigningKey bool
-
-	generateSigningKeyRaw, ok := data.GetOk("generate_signing_key")
-	switch {
-	// explicitly set true
-	case ok && generateSigningKeyRaw.(bool):
-		if publicKey != "" || privateKey != "" {
-			return logical.ErrorResponse("public_key and private_key must not be set when generate_signing_key is set to true"), nil
-		}
-
-		generateSigningKey = true
-
-	// explicitly set to false, or not set and we have both a public and private key
-	case ok, publicKey != "" && privateKey != "":
-		if publicKey == "" {
-			return logical.ErrorResponse("missing public_key"), nil
-		}
-
-		if privateKey == "" {
-			return logical.ErrorResponse("missing private_key"), nil
-		}
-
-		_, err := ssh.ParsePrivateKey([]byte(privateKey))
-		if err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("Unable to parse private_key as an SSH private key: %v", err)), nil
-		}
-
-		_, err = parsePublicSSHKey(publicKey)
-		if err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("Unable to parse public_key as an SSH public key: %v", err)), nil
-		}
-
-	// not set and no public/private key provided so generate
-	case publicKey == "" && privateKey == "":
-		generateSigningKey = true
-
-	// not set, but one or the other supplied
-	default:
-		return logical.ErrorResponse("only one of public_key and private_key set; both must be set to use, or both must be blank to auto-generate"), nil
-	}
-
-	if generateSigningKey {
-		publicKey, privateKey, err = generateSSHKeyPair()
-		if err != nil {
-			return nil, err
-		}
+	publicKey, privateKey, generateSigningKey, errResp, err := caKeyPair(data)
+	if errResp != nil || err != nil {
+		return errResp, err
 	}
 
-	if publicKey == "" || privateKey == "" {
-		return nil, fmt.Errorf("failed to generate or parse the keys")
-	}
+	b.caMutex.Lock()
+	defer b.caMutex.Unlock()
 
 	publicKeyEntry, err := caKey(ctx, req.Storage, caPublicKey)
 	if err != nil {
@@ -265,19 +314,164 @@ func (b *backend) pathConfigCAUpdate(ctx context.Context, req *logical.Request,
 	return nil, nil
 }
 
-func generateSSHKeyPair() (string, string, error) {
-	privateSeed, err := rsa.GenerateKey(rand.Reader, 4096)
-	if err != nil {
-		return "", "", err
+// caKeyPair returns the CA key pair given by the "public_key" and
+// "private_key" fields, or generates one of "key_type" and "key_bits" if
+// "generate_signing_key" asks for it. The returned bool reports whether the key pair was generated.
+func caKeyPair(data *framework.FieldData) (string, string, bool, *logical.Response, error) {
+	var err error
+	publicKey := data.Get("public_key").(string)
+	privateKey := data.Get("private_key").(string)
+
+	var generateSigningKey bool
+
+	generateSigningKeyRaw, ok := data.GetOk("generate_signing_key")
+	switch {
+	// explicitly set true
+	case ok && generateSigningKeyRaw.(bool):
+		if publicKey != "" || privateKey != "" {
+			return "", "", false, logical.ErrorResponse("public_key and private_key must not be set when generate_signing_key is set to true"), nil
+		}
+
+		generateSigningKey = true
+
+	// explicitly set to false, or not set and we have both a public and private key
+	case ok, publicKey != "" && privateKey != "":
+		if publicKey == "" {
+			return "", "", false, logical.ErrorResponse("missing public_key"), nil
+		}
+
+		if privateKey == "" {
+			return "", "", false, logical.ErrorResponse("missing private_key"), nil
+		}
+
+		_, err := ssh.ParsePrivateKey([]byte(privateKey))
+		if err != nil {
+			return "", "", false, logical.ErrorResponse(fmt.Sprintf("Unable to parse private_key as an SSH private key: %v", err)), nil
+		}
+
+		_, err = parsePublicSSHKey(publicKey)
+		if err != nil {
+			return "", "", false, logical.ErrorResponse(fmt.Sprintf("Unable to parse public_key as an SSH public key: %v", err)), nil
+		}
+
+	// not set and no public/private key provided so generate
+	case publicKey == "" && privateKey == "":
+		generateSigningKey = true
+
+	// not set, but one or the other supplied
+	default:
+		return "", "", false, logical.ErrorResponse("only one of public_key and private_key set; both must be set to use, or both must be blank to auto-generate"), nil
+	}
+
+	if generateSigningKey {
+		keyType := data.Get("key_type").(string)
+		keyBits := data.Get("key_bits").(int)
+		if err := validateCAKeyParams(keyType, &keyBits); err != nil {
+			return "", "", false, logical.ErrorResponse(err.Error()), nil
+		}
+
+		publicKey, privateKey, err = generateSSHKeyPair(keyType, keyBits)
+		if err != nil {
+			return "", "", false, nil, err
+		}
 	}
 
-	privateBlock := &pem.Block{
-		Type:    "RSA PRIVATE KEY",
-		Headers: nil,
-		Bytes:   x509.MarshalPKCS1PrivateKey(privateSeed),
+	if publicKey == "" || privateKey == "" {
+		return "", "", false, nil, fmt.Errorf("failed to generate or parse the keys")
+	}
+
+	return publicKey, privateKey, generateSigningKey, nil, nil
+}
+
+// validateCAKeyParams checks the type and size of a CA key pair to generate,
+// setting the size to the default of the type if it's zero.
+func validateCAKeyParams(keyType string, keyBits *int) error {
+	switch keyType {
+	case "rsa":
+		switch *keyBits {
+		case 0:
+			*keyBits = 4096
+		case 2048, 3072, 4096:
+		default:
+			return fmt.Errorf("unsupported key_bits %d for rsa keys; must be 2048, 3072 or 4096", *keyBits)
+		}
+	case "ec":
+		switch *keyBits {
+		case 0:
+			*keyBits = 256
+		case 256, 384, 521:
+		default:
+			return fmt.Errorf("unsupported key_bits %d for ec keys; must be 256, 384 or 521", *keyBits)
+		}
+	case "ed25519":
+		*keyBits = 0
+	default:
+		return fmt.Errorf("unsupported key_type %q; must be rsa, ec or ed25519", keyType)
+	}
+	return nil
+}
+
+func generateSSHKeyPair(keyType string, keyBits int) (string, string, error) {
+	var privateSeed crypto.Signer
+	var privateBlock *pem.Block
+	switch keyType {
+	case "rsa":
+		rsaKey, err := rsa.GenerateKey(rand.Reader, keyBits)
+		if err != nil {
+			return "", "", err
+		}
+		privateSeed = rsaKey
+		privateBlock = &pem.Block{
+			Type:    "RSA PRIVATE KEY",
+			Headers: nil,
+			Bytes:   x509.MarshalPKCS1PrivateKey(rsaKey),
+		}
+	case "ec":
+		var curve elliptic.Curve
+		switch keyBits {
+		case 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return "", "", fmt.Errorf("unsupported key size %d for ec keys", keyBits)
+		}
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		marshaled, err := x509.MarshalECPrivateKey(ecKey)
+		if err != nil {
+			return "", "", err
+		}
+		privateSeed = ecKey
+		privateBlock = &pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: marshaled,
+		}
+	case "ed25519":
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return "", "", err
+		}
+		// The SSH library can't marshal keys in the OpenSSH format, but
+		// it parses PKCS #8 ones
+		marshaled, err := x509.MarshalPKCS8PrivateKey(edKey)
+		if err != nil {
+			return "", "", err
+		}
+		privateSeed = edKey
+		privateBlock = &pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: marshaled,
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported key type %q", keyType)
 	}
 
-	public, err := ssh.NewPublicKey(&privateSeed.PublicKey)
+	public, err := ssh.NewPublicKey(privateSeed.Public())
 	if err != nil {
 		return "", "", err
 	}
@@ -2,9 +2,11 @@ package ssh
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestSSH_ConfigCAStorageUpgrade(t *testing.T) {
@@ -168,3 +170,110 @@ func TestSSH_ConfigCAUpdateDelete(t *testing.T) {
 		t.Fatalf("bad: err: %v, resp:%v", err, resp)
 	}
 }
+
+func TestSSH_ConfigRotateCA(t *testing.T) {
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+
+	b, err := Factory(context.Background(), config)
+	if err != nil {
+		t.Fatalf("Cannot create backend: %s", err)
+	}
+
+	request := func(op logical.Operation, path string, data map[string]interface{}) *logical.Response {
+		t.Helper()
+		return testRequest(t, b, config.StorageView, op, path, data)
+	}
+	rawBody := func(resp *logical.Response) string {
+		t.Helper()
+		if resp == nil {
+			t.Fatal("expected a response")
+		}
+		return string(resp.Data[logical.HTTPRawBody].([]byte))
+	}
+
+	// Rotating requires a CA to rotate
+	resp := request(logical.UpdateOperation, "config/rotate-ca", nil)
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error rotating unconfigured CA, got: %#v", resp)
+	}
+	if resp = request(logical.ReadOperation, "known_hosts", nil); resp != nil {
+		t.Fatalf("expected no known_hosts without a CA, got: %#v", resp)
+	}
+
+	resp = request(logical.UpdateOperation, "config/ca", map[string]interface{}{
+		"public_key":  publicKey,
+		"private_key": privateKey,
+	})
+	if resp != nil && resp.IsError() {
+		t.Fatalf("failed to configure CA: %#v", resp)
+	}
+	oldKey := strings.TrimSpace(publicKey)
+
+	knownHosts := rawBody(request(logical.ReadOperation, "known_hosts", map[string]interface{}{
+		"hosts": "*.example.com,example.com",
+	}))
+	if knownHosts != "@cert-authority *.example.com,example.com "+oldKey+"\n" {
+		t.Fatalf("bad known_hosts: %q", knownHosts)
+	}
+
+	resp = request(logical.UpdateOperation, "config/rotate-ca", map[string]interface{}{
+		"overlap_period": "1h",
+	})
+	if resp == nil || resp.IsError() {
+		t.Fatalf("failed to rotate CA: %#v", resp)
+	}
+	newKey := strings.TrimSpace(resp.Data["public_key"].(string))
+
+	// Both CAs are trusted during the overlap, and the new one signs
+	if keys := rawBody(request(logical.ReadOperation, "public_key", nil)); keys != newKey+"\n"+oldKey+"\n" {
+		t.Fatalf("bad public keys: %q", keys)
+	}
+	knownHosts = rawBody(request(logical.ReadOperation, "known_hosts", nil))
+	if knownHosts != "@cert-authority * "+newKey+"\n@cert-authority * "+oldKey+"\n" {
+		t.Fatalf("bad known_hosts: %q", knownHosts)
+	}
+	resp = request(logical.ReadOperation, "config/ca", nil)
+	if strings.TrimSpace(resp.Data["public_key"].(string)) != newKey {
+		t.Fatalf("bad CA public key: %#v", resp.Data)
+	}
+	if previous := resp.Data["previous_public_keys"].([]map[string]interface{}); len(previous) != 1 || previous[0]["public_key"] != publicKey {
+		t.Fatalf("bad previous CA public keys: %#v", previous)
+	}
+	privateKeyEntry, err := caKey(context.Background(), config.StorageView, caPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(privateKeyEntry.Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey()))); key != newKey {
+		t.Fatalf("CA private key doesn't match the new public key")
+	}
+
+	// The previous CA is dropped once the overlap is over
+	resp = request(logical.UpdateOperation, "config/rotate-ca", map[string]interface{}{
+		"overlap_period": 0,
+	})
+	if resp == nil || resp.IsError() {
+		t.Fatalf("failed to rotate CA: %#v", resp)
+	}
+	newestKey := strings.TrimSpace(resp.Data["public_key"].(string))
+	if keys := rawBody(request(logical.ReadOperation, "public_key", nil)); keys != newestKey+"\n"+oldKey+"\n" {
+		t.Fatalf("bad public keys after second rotation: %q", keys)
+	}
+
+	// Deleting the CA deletes the previous keys as well
+	request(logical.DeleteOperation, "config/ca", nil)
+	if resp = request(logical.ReadOperation, "public_key", nil); resp != nil {
+		t.Fatalf("expected no public key after deleting CA, got: %#v", resp)
+	}
+	entry, err := config.StorageView.Get(context.Background(), caPreviousKeysStoragePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != nil {
+		t.Fatal("expected previous CA public keys to be deleted")
+	}
+}
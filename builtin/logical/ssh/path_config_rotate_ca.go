@@ -0,0 +1,154 @@
+package ssh
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathConfigRotateCA(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/rotate-ca",
+		Fields: map[string]*framework.FieldSchema{
+			"private_key": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Private half of the new SSH key that will be used to sign certificates.`,
+			},
+			"public_key": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Public half of the new SSH key that will be used to sign certificates.`,
+			},
+			"generate_signing_key": &framework.FieldSchema{
+				Type:        framework.TypeBool,
+				Description: `Generate the new SSH key pair internally rather than use the private_key and public_key fields.`,
+				Default:     true,
+			},
+			"key_type": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: `Type of the generated key pair; one of "rsa", "ec" or "ed25519".`,
+				Default:     "rsa",
+			},
+			"key_bits": &framework.FieldSchema{
+				Type: framework.TypeInt,
+				Description: `Size of the generated key pair; 2048, 3072 or 4096 for "rsa" keys, and
+256, 384 or 521 for "ec" keys. Defaults to 4096 for "rsa" keys and 256 for
+"ec" keys, and is ignored for "ed25519" keys.`,
+			},
+			"overlap_period": &framework.FieldSchema{
+				Type: framework.TypeDurationSecond,
+				Description: `How long the previous CA key stays trusted after the rotation. It should
+be at least the longest TTL of the certificates it signed. Defaults to the
+max TTL of the mount.`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathConfigRotateCAUpdate,
+		},
+
+		HelpSynopsis: `Replace the SSH private key used for signing certificates.`,
+		HelpDescription: `This replaces the CA key pair of this mount with a new one, which signs all
+certificates from then on. The public key of the previous CA is still
+returned by the "public_key" and "known_hosts" endpoints for the duration
+of "overlap_period", so the certificates it signed remain trusted until
+they expire.`,
+	}
+}
+
+func (b *backend) pathConfigRotateCAUpdate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	overlapPeriod := time.Duration(data.Get("overlap_period").(int)) * time.Second
+	if overlapPeriod < 0 {
+		return logical.ErrorResponse("overlap_period must not be negative"), nil
+	}
+	if _, ok := data.GetOk("overlap_period"); !ok {
+		overlapPeriod = b.System().MaxLeaseTTL()
+	}
+
+	publicKey, privateKey, generateSigningKey, errResp, err := caKeyPair(data)
+	if errResp != nil || err != nil {
+		return errResp, err
+	}
+
+	b.caMutex.Lock()
+	defer b.caMutex.Unlock()
+
+	publicKeyEntry, err := caKey(ctx, req.Storage, caPublicKey)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read CA public key: {{err}}", err)
+	}
+	if publicKeyEntry == nil || publicKeyEntry.Key == "" {
+		return logical.ErrorResponse("keys haven't been configured yet; configure them with config/ca"), nil
+	}
+
+	// Keep trusting the current CA before it's replaced, so that the
+	// certificates it signed stay valid whatever happens next.
+	previousKeys, err := previousCAKeys(ctx, req.Storage)
+	if err != nil {
+		return nil, errwrap.Wrapf("failed to read previous CA public keys: {{err}}", err)
+	}
+	now := time.Now()
+	previousKeys = append(previousKeys, previousCAKey{
+		Key:          publicKeyEntry.Key,
+		RetiredAt:    now,
+		TrustedUntil: now.Add(overlapPeriod),
+	})
+	entry, err := logical.StorageEntryJSON(caPreviousKeysStoragePath, previousKeys)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, errwrap.Wrapf("failed to store previous CA public keys: {{err}}", err)
+	}
+
+	// Store the new public key first, so that it's trusted as soon as it
+	// signs anything.
+	entry, err = logical.StorageEntryJSON(caPublicKeyStoragePath, &keyStorageEntry{
+		Key: publicKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		return nil, errwrap.Wrapf("failed to store CA public key: {{err}}", err)
+	}
+
+	entry, err = logical.StorageEntryJSON(caPrivateKeyStoragePath, &keyStorageEntry{
+		Key: privateKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, entry); err != nil {
+		var mErr *multierror.Error
+
+		mErr = multierror.Append(mErr, errwrap.Wrapf("failed to store CA private key: {{err}}", err))
+
+		// The previous private key still signs certificates, so restore the
+		// matching public key
+		entry, restoreErr := logical.StorageEntryJSON(caPublicKeyStoragePath, publicKeyEntry)
+		if restoreErr == nil {
+			restoreErr = req.Storage.Put(ctx, entry)
+		}
+		if restoreErr != nil {
+			mErr = multierror.Append(mErr, errwrap.Wrapf("failed to restore previous CA public key: {{err}}", restoreErr))
+		}
+
+		return nil, mErr
+	}
+
+	if generateSigningKey {
+		response := &logical.Response{
+			Data: map[string]interface{}{
+				"public_key": publicKey,
+			},
+		}
+
+		return response, nil
+	}
+
+	return nil, nil
+}
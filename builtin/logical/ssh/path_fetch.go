@@ -2,6 +2,8 @@ package ssh
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -15,27 +17,81 @@ func pathFetchPublicKey(b *backend) *framework.Path {
 			logical.ReadOperation: b.pathFetchPublicKey,
 		},
 
-		HelpSynopsis:    `Retrieve the public key.`,
-		HelpDescription: `This allows the public key, that this backend has been configured with, to be fetched.`,
+		HelpSynopsis: `Retrieve the public key.`,
+		HelpDescription: `This allows the public key, that this backend has been configured with, to be fetched.
+The public keys of previous CAs which are still trusted after a rotation follow
+it, one per line.`,
+	}
+}
+
+func pathFetchKnownHosts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `known_hosts`,
+
+		Fields: map[string]*framework.FieldSchema{
+			"hosts": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: `Host name patterns the CA is trusted for. Defaults to "*".`,
+			},
+		},
+
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathFetchKnownHosts,
+		},
+
+		HelpSynopsis: `Retrieve known_hosts lines trusting the CA for host certificates.`,
+		HelpDescription: `This returns a "@cert-authority" line for the public key of the CA, and for
+each previous CA which is still trusted after a rotation, ready to be added to
+the known_hosts file of SSH clients. The "hosts" parameter restricts the hosts
+the CA is trusted for.`,
 	}
 }
 
 func (b *backend) pathFetchPublicKey(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
-	publicKeyEntry, err := caKey(ctx, req.Storage, caPublicKey)
+	keys, err := trustedCAPublicKeys(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	return rawTextResponse(strings.Join(keys, "\n") + "\n"), nil
+}
+
+func (b *backend) pathFetchKnownHosts(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	hosts := data.Get("hosts").([]string)
+	if len(hosts) == 0 {
+		hosts = []string{"*"}
+	}
+	for _, host := range hosts {
+		if strings.ContainsAny(host, " \t\r\n") {
+			return logical.ErrorResponse("host pattern %q must not contain whitespace", host), nil
+		}
+	}
+
+	keys, err := trustedCAPublicKeys(ctx, req.Storage)
 	if err != nil {
 		return nil, err
 	}
-	if publicKeyEntry == nil || publicKeyEntry.Key == "" {
+	if len(keys) == 0 {
 		return nil, nil
 	}
 
-	response := &logical.Response{
+	var knownHosts strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&knownHosts, "@cert-authority %s %s\n", strings.Join(hosts, ","), key)
+	}
+
+	return rawTextResponse(knownHosts.String()), nil
+}
+
+func rawTextResponse(body string) *logical.Response {
+	return &logical.Response{
 		Data: map[string]interface{}{
 			logical.HTTPContentType: "text/plain",
-			logical.HTTPRawBody:     []byte(publicKeyEntry.Key),
+			logical.HTTPRawBody:     []byte(body),
 			logical.HTTPStatusCode:  200,
 		},
 	}
-
-	return response, nil
 }
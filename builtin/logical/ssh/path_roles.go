@@ -10,8 +10,11 @@ import (
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/cidrutil"
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
 	"github.com/hashicorp/vault/sdk/helper/parseutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ssh"
 )
 
 const (
@@ -38,6 +41,7 @@ type sshRole struct {
 	InstallScript          string            `mapstructure:"install_script" json:"install_script"`
 	AllowedUsers           string            `mapstructure:"allowed_users" json:"allowed_users"`
 	AllowedDomains         string            `mapstructure:"allowed_domains" json:"allowed_domains"`
+	AllowedDomainsTemplate bool              `mapstructure:"allowed_domains_template" json:"allowed_domains_template"`
 	KeyOptionSpecs         string            `mapstructure:"key_option_specs" json:"key_option_specs"`
 	MaxTTL                 string            `mapstructure:"max_ttl" json:"max_ttl"`
 	TTL                    string            `mapstructure:"ttl" json:"ttl"`
@@ -52,6 +56,7 @@ type sshRole struct {
 	AllowUserKeyIDs        bool              `mapstructure:"allow_user_key_ids" json:"allow_user_key_ids"`
 	KeyIDFormat            string            `mapstructure:"key_id_format" json:"key_id_format"`
 	AllowedUserKeyLengths  map[string]int    `mapstructure:"allowed_user_key_lengths" json:"allowed_user_key_lengths"`
+	AlgorithmSigner        string            `mapstructure:"algorithm_signer" json:"algorithm_signer"`
 }
 
 func pathListRoles(b *backend) *framework.Path {
@@ -190,6 +195,16 @@ func pathRoles(b *backend) *framework.Path {
 				valid host. If only certain domains are allowed, then this list enforces it.
 				`,
 			},
+			"allowed_domains_template": &framework.FieldSchema{
+				Type: framework.TypeBool,
+				Description: `
+				[Not applicable for Dynamic type] [Not applicable for OTP type] [Optional for CA type]
+				If set, "allowed_domains" can contain identity template policies, such as
+				"{{identity.entity.metadata.domain}}", which are rendered with the entity of the
+				requesting token. Entries which can't be rendered for the entity are ignored.
+				`,
+				Default: false,
+			},
 			"key_option_specs": &framework.FieldSchema{
 				Type: framework.TypeString,
 				Description: `
@@ -319,8 +334,19 @@ func pathRoles(b *backend) *framework.Path {
 				Description: `
                                 [Not applicable for Dynamic type] [Not applicable for OTP type] [Optional for CA type]
                                 If set, allows the enforcement of key types and minimum key sizes to be signed.
+                                Keys are "rsa", "dsa", "ecdsa" and "ed25519"; key types which aren't listed are
+                                rejected. A size of 0 allows any size of the type.
                                 `,
 			},
+			"algorithm_signer": &framework.FieldSchema{
+				Type: framework.TypeString,
+				Description: `
+				[Not applicable for Dynamic type] [Not applicable for OTP type] [Optional for CA type]
+				Signature algorithm used to sign certificates with an RSA CA key; one of "ssh-rsa",
+				"rsa-sha2-256" or "rsa-sha2-512". Defaults to "ssh-rsa", which uses SHA-1 and is
+				rejected by recent versions of OpenSSH.
+				`,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -486,6 +512,7 @@ func (b *backend) createCARole(allowedUsers, defaultUser string, data *framework
 		AllowHostCertificates:  data.Get("allow_host_certificates").(bool),
 		AllowedUsers:           allowedUsers,
 		AllowedDomains:         data.Get("allowed_domains").(string),
+		AllowedDomainsTemplate: data.Get("allowed_domains_template").(bool),
 		DefaultUser:            defaultUser,
 		AllowBareDomains:       data.Get("allow_bare_domains").(bool),
 		AllowSubdomains:        data.Get("allow_subdomains").(bool),
@@ -498,6 +525,18 @@ func (b *backend) createCARole(allowedUsers, defaultUser string, data *framework
 		return nil, logical.ErrorResponse("Either 'allow_user_certificates' or 'allow_host_certificates' must be set to 'true'")
 	}
 
+	if role.AllowedDomainsTemplate {
+		for _, domain := range strutil.ParseStringSlice(role.AllowedDomains, ",") {
+			if _, _, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+				Mode:              identitytpl.ACLTemplating,
+				ValidityCheckOnly: true,
+				String:            domain,
+			}); err != nil {
+				return nil, logical.ErrorResponse(fmt.Sprintf("invalid template %q in allowed_domains: %s", domain, err))
+			}
+		}
+	}
+
 	defaultCriticalOptions := convertMapToStringValue(data.Get("default_critical_options").(map[string]interface{}))
 	defaultExtensions := convertMapToStringValue(data.Get("default_extensions").(map[string]interface{}))
 	allowedUserKeyLengths, err := convertMapToIntValue(data.Get("allowed_user_key_lengths").(map[string]interface{}))
@@ -505,6 +544,24 @@ func (b *backend) createCARole(allowedUsers, defaultUser string, data *framework
 		return nil, logical.ErrorResponse(fmt.Sprintf("error processing allowed_user_key_lengths: %s", err.Error()))
 	}
 
+	for keyType, keyBits := range allowedUserKeyLengths {
+		switch keyType {
+		case "rsa", "dsa", "ecdsa", "ed25519":
+		default:
+			return nil, logical.ErrorResponse(fmt.Sprintf("unsupported key type %q in allowed_user_key_lengths; must be rsa, dsa, ecdsa or ed25519", keyType))
+		}
+		if keyBits < 0 {
+			return nil, logical.ErrorResponse(fmt.Sprintf("minimum size of %s keys in allowed_user_key_lengths must not be negative", keyType))
+		}
+	}
+
+	switch algorithmSigner := data.Get("algorithm_signer").(string); algorithmSigner {
+	case "", ssh.SigAlgoRSA, ssh.SigAlgoRSASHA2256, ssh.SigAlgoRSASHA2512:
+		role.AlgorithmSigner = algorithmSigner
+	default:
+		return nil, logical.ErrorResponse(fmt.Sprintf("unsupported algorithm_signer %q; must be %s, %s or %s", algorithmSigner, ssh.SigAlgoRSA, ssh.SigAlgoRSASHA2256, ssh.SigAlgoRSASHA2512))
+	}
+
 	if ttl != 0 && maxTTL != 0 && ttl > maxTTL {
 		return nil, logical.ErrorResponse(
 			`"ttl" value must be less than "max_ttl" when both are specified`)
@@ -566,6 +623,7 @@ func (b *backend) parseRole(role *sshRole) (map[string]interface{}, error) {
 		result = map[string]interface{}{
 			"allowed_users":            role.AllowedUsers,
 			"allowed_domains":          role.AllowedDomains,
+			"allowed_domains_template": role.AllowedDomainsTemplate,
 			"default_user":             role.DefaultUser,
 			"ttl":                      int64(ttl.Seconds()),
 			"max_ttl":                  int64(maxTTL.Seconds()),
@@ -582,6 +640,7 @@ func (b *backend) parseRole(role *sshRole) (map[string]interface{}, error) {
 			"default_critical_options": role.DefaultCriticalOptions,
 			"default_extensions":       role.DefaultExtensions,
 			"allowed_user_key_lengths": role.AllowedUserKeyLengths,
+			"algorithm_signer":         role.AlgorithmSigner,
 		}
 	case KeyTypeDynamic:
 		result = map[string]interface{}{
@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/identitytpl"
 	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -133,7 +135,11 @@ func (b *backend) pathSignCertificate(ctx context.Context, req *logical.Request,
 
 	var parsedPrincipals []string
 	if certificateType == ssh.HostCert {
-		parsedPrincipals, err = b.calculateValidPrincipals(data, "", role.AllowedDomains, validateValidPrincipalForHosts(role))
+		allowedDomains, err := b.calculateAllowedDomains(req, role)
+		if err != nil {
+			return nil, err
+		}
+		parsedPrincipals, err = b.calculateValidPrincipals(data, "", allowedDomains, validateValidPrincipalForHosts(role))
 		if err != nil {
 			return logical.ErrorResponse(err.Error()), nil
 		}
@@ -171,6 +177,9 @@ func (b *backend) pathSignCertificate(ctx context.Context, req *logical.Request,
 	if err != nil {
 		return nil, errwrap.Wrapf("failed to parse stored CA private key: {{err}}", err)
 	}
+	if role.AlgorithmSigner != "" && signer.PublicKey().Type() != ssh.KeyAlgoRSA {
+		return logical.ErrorResponse(fmt.Sprintf("algorithm_signer %q of the role requires an RSA CA key, but the CA key is of type %s", role.AlgorithmSigner, signer.PublicKey().Type())), nil
+	}
 
 	cBundle := creationBundle{
 		KeyID:           keyID,
@@ -238,6 +247,55 @@ func (b *backend) calculateValidPrincipals(data *framework.FieldData, defaultPri
 	}
 }
 
+// calculateAllowedDomains returns the allowed domains of the role, with their
+// templates rendered for the entity of the request if the role allows them.
+// Domains whose templates can't be rendered for the entity are left out.
+func (b *backend) calculateAllowedDomains(req *logical.Request, role *sshRole) (string, error) {
+	if !role.AllowedDomainsTemplate {
+		return role.AllowedDomains, nil
+	}
+
+	var entity *logical.Entity
+	var groups []*logical.Group
+	if req.EntityID != "" {
+		var err error
+		entity, err = b.System().EntityInfo(req.EntityID)
+		if err != nil {
+			return "", errwrap.Wrapf("error looking up entity: {{err}}", err)
+		}
+		groups, err = b.System().GroupsForEntity(req.EntityID)
+		if err != nil {
+			return "", errwrap.Wrapf("error looking up groups of entity: {{err}}", err)
+		}
+	}
+
+	var allowedDomains []string
+	for _, domain := range strutil.ParseStringSlice(role.AllowedDomains, ",") {
+		subst, rendered, err := identitytpl.PopulateString(identitytpl.PopulateStringInput{
+			Mode:   identitytpl.ACLTemplating,
+			String: domain,
+			Entity: entity,
+			Groups: groups,
+		})
+		switch err {
+		case nil:
+		case identitytpl.ErrNoEntityAttachedToToken, identitytpl.ErrNoGroupsAttachedToToken, identitytpl.ErrTemplateValueNotFound:
+			continue
+		default:
+			return "", errwrap.Wrapf(fmt.Sprintf("error rendering allowed domain %q: {{err}}", domain), err)
+		}
+		// Don't let identity values widen the role beyond a single domain
+		if subst && strings.ContainsAny(rendered, "*,") {
+			continue
+		}
+		if rendered != "" {
+			allowedDomains = append(allowedDomains, rendered)
+		}
+	}
+
+	return strings.Join(allowedDomains, ","), nil
+}
+
 func validateValidPrincipalForHosts(role *sshRole) func([]string, string) bool {
 	return func(allowedPrincipals []string, validPrincipal string) bool {
 		for _, allowedPrincipal := range allowedPrincipals {
@@ -412,42 +470,23 @@ func (b *backend) validateSignedKeyRequirements(publickey ssh.PublicKey, role *s
 				kstr = "ecdsa"
 				kbits = k.Curve.Params().BitSize
 			case ed25519.PublicKey:
+				// ed25519 public keys are always 256 bits in length
 				kstr = "ed25519"
+				kbits = 256
 			default:
-				return fmt.Errorf("public key type of %s is not allowed", kstr)
+				return fmt.Errorf("public key type of %T is not allowed", k)
 			}
 		default:
 			return fmt.Errorf("pubkey not suitable for crypto (expected ssh.CryptoPublicKey but found %T)", k)
 		}
 
-		if value, ok := role.AllowedUserKeyLengths[kstr]; ok {
-			var pass bool
-			switch kstr {
-			case "rsa":
-				if kbits == value {
-					pass = true
-				}
-			case "dsa":
-				if kbits == value {
-					pass = true
-				}
-			case "ecdsa":
-				if kbits == value {
-					pass = true
-				}
-			case "ed25519":
-				// ed25519 public keys are always 256 bits in length,
-				// so there is no need to inspect their value
-				pass = true
-			}
-
-			if !pass {
-				return fmt.Errorf("key is of an invalid size: %v", kbits)
-			}
-
-		} else {
+		minBits, ok := role.AllowedUserKeyLengths[kstr]
+		if !ok {
 			return fmt.Errorf("key type of %s is not allowed", kstr)
 		}
+		if kbits < minBits {
+			return fmt.Errorf("key is of an invalid size: %v", kbits)
+		}
 	}
 	return nil
 }
@@ -484,10 +523,34 @@ func (b *creationBundle) sign() (retCert *ssh.Certificate, retErr error) {
 		},
 	}
 
-	err = certificate.SignCert(rand.Reader, b.Signer)
+	if b.Role.AlgorithmSigner == "" {
+		err = certificate.SignCert(rand.Reader, b.Signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signed SSH key")
+		}
+		return certificate, nil
+	}
+
+	// SignCert always uses the default algorithm of the key, so sign the
+	// certificate the same way with the algorithm of the role instead
+	algorithmSigner, ok := b.Signer.(ssh.AlgorithmSigner)
+	if !ok {
+		return nil, fmt.Errorf("failed to generate signed SSH key: CA key doesn't support algorithm %q", b.Role.AlgorithmSigner)
+	}
+	certificate.Nonce = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, certificate.Nonce); err != nil {
+		return nil, err
+	}
+	certificate.SignatureKey = b.Signer.PublicKey()
+
+	// The signed data is the marshaled certificate without its signature,
+	// which is empty and marshaled as a zero length.
+	out := certificate.Marshal()
+	signature, err := algorithmSigner.SignWithAlgorithm(rand.Reader, out[:len(out)-4], b.Role.AlgorithmSigner)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate signed SSH key")
 	}
+	certificate.Signature = signature
 
 	return certificate, nil
 }
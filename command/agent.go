@@ -15,6 +15,8 @@ import (
 	"sync"
 	"time"
 
+	metrics "github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
 	"github.com/hashicorp/errwrap"
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
@@ -31,10 +33,15 @@ import (
 	"github.com/hashicorp/vault/command/agent/auth/kubernetes"
 	"github.com/hashicorp/vault/command/agent/cache"
 	agentConfig "github.com/hashicorp/vault/command/agent/config"
+	agentexec "github.com/hashicorp/vault/command/agent/exec"
+	"github.com/hashicorp/vault/command/agent/health"
 	"github.com/hashicorp/vault/command/agent/sink"
 	"github.com/hashicorp/vault/command/agent/sink/file"
 	"github.com/hashicorp/vault/command/agent/sink/inmem"
+	ksink "github.com/hashicorp/vault/command/agent/sink/kubernetes"
+	"github.com/hashicorp/vault/command/agent/sink/socket"
 	"github.com/hashicorp/vault/command/agent/template"
+	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/gatedwriter"
 	"github.com/hashicorp/vault/sdk/helper/logging"
@@ -241,6 +248,11 @@ func (c *AgentCommand) Run(args []string) int {
 		}
 	})
 
+	if exitAfterAuth && config.Exec != nil {
+		c.UI.Error("exit_after_auth cannot be used with an exec block")
+		return 1
+	}
+
 	c.setStringFlag(f, config.Vault.Address, &StringVar{
 		Name:    flagNameAddress,
 		Target:  &c.flagAddress,
@@ -335,28 +347,34 @@ func (c *AgentCommand) Run(args []string) int {
 	var namespace string
 	if config.AutoAuth != nil {
 		for _, sc := range config.AutoAuth.Sinks {
+			config := &sink.SinkConfig{
+				Logger:  c.logger.Named("sink." + sc.Type),
+				Config:  sc.Config,
+				Client:  client,
+				WrapTTL: sc.WrapTTL,
+				DHType:  sc.DHType,
+				DHPath:  sc.DHPath,
+				AAD:     sc.AAD,
+			}
+			var s sink.Sink
+			var err error
 			switch sc.Type {
 			case "file":
-				config := &sink.SinkConfig{
-					Logger:  c.logger.Named("sink.file"),
-					Config:  sc.Config,
-					Client:  client,
-					WrapTTL: sc.WrapTTL,
-					DHType:  sc.DHType,
-					DHPath:  sc.DHPath,
-					AAD:     sc.AAD,
-				}
-				s, err := file.NewFileSink(config)
-				if err != nil {
-					c.UI.Error(errwrap.Wrapf("Error creating file sink: {{err}}", err).Error())
-					return 1
-				}
-				config.Sink = s
-				sinks = append(sinks, config)
+				s, err = file.NewFileSink(config)
+			case "kubernetes":
+				s, err = ksink.NewKubernetesSink(config)
+			case "socket":
+				s, err = socket.NewSocketSink(config)
 			default:
 				c.UI.Error(fmt.Sprintf("Unknown sink type %q", sc.Type))
 				return 1
 			}
+			if err != nil {
+				c.UI.Error(errwrap.Wrapf(fmt.Sprintf("Error creating %s sink: {{err}}", sc.Type), err).Error())
+				return 1
+			}
+			config.Sink = s
+			sinks = append(sinks, config)
 		}
 
 		// Check if a default namespace has been set
@@ -415,8 +433,22 @@ func (c *AgentCommand) Run(args []string) int {
 	default:
 	}
 
-	// Parse agent listener configurations
-	if config.Cache != nil && len(config.Listeners) != 0 {
+	// status records the state of the agent's subsystems for the health and
+	// metrics endpoints
+	status := health.NewStatus(&health.StatusConfig{
+		AutoAuth: method != nil,
+		Cache:    config.Cache != nil,
+	})
+
+	metricsHelper, err := c.setupTelemetry()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error initializing telemetry: %s", err))
+		return 1
+	}
+
+	var leaseCache *cache.LeaseCache
+	var cacheHandler http.Handler
+	if config.Cache != nil {
 		cacheLogger := c.logger.Named("cache")
 
 		// Create the API proxier
@@ -431,11 +463,16 @@ func (c *AgentCommand) Run(args []string) int {
 
 		// Create the lease cache proxier and set its underlying proxier to
 		// the API proxier.
-		leaseCache, err := cache.NewLeaseCache(&cache.LeaseCacheConfig{
+		leaseCache, err = cache.NewLeaseCache(&cache.LeaseCacheConfig{
 			Client:      client,
 			BaseContext: ctx,
 			Proxier:     apiProxy,
 			Logger:      cacheLogger.Named("leasecache"),
+			Status:      status,
+
+			StaticSecretTTL:          config.Cache.StaticSecretTTL,
+			StaticSecretPaths:        config.Cache.StaticSecretPaths,
+			StaticSecretPollInterval: config.Cache.StaticSecretPollInterval,
 		})
 		if err != nil {
 			c.UI.Error(fmt.Sprintf("Error creating lease cache: %v", err))
@@ -459,8 +496,13 @@ func (c *AgentCommand) Run(args []string) int {
 		}
 
 		// Create the request handler
-		cacheHandler := cache.Handler(ctx, cacheLogger, leaseCache, inmemSink)
+		cacheHandler = cache.Handler(ctx, cacheLogger, leaseCache, inmemSink)
+	}
 
+	// Parse agent listener configurations. The health and metrics endpoints
+	// are served whether or not caching is enabled, so that an agent only
+	// running auto-auth can be monitored.
+	if len(config.Listeners) != 0 {
 		var listeners []net.Listener
 		for i, lnConfig := range config.Listeners {
 			ln, tlsConf, err := cache.StartListener(lnConfig)
@@ -471,24 +513,29 @@ func (c *AgentCommand) Run(args []string) int {
 
 			listeners = append(listeners, ln)
 
-			// Parse 'require_request_header' listener config option, and wrap
-			// the request handler if necessary
-			muxHandler := cacheHandler
-			if v, ok := lnConfig.Config[agentConfig.RequireRequestHeader]; ok {
-				switch v {
-				case true:
-					muxHandler = verifyRequestHeader(muxHandler)
-				case false /* noop */ :
-				default:
-					c.UI.Error(fmt.Sprintf("Invalid value for 'require_request_header': %v", v))
-					return 1
+			mux := http.NewServeMux()
+			mux.Handle(consts.AgentPathHealth, health.Handler(status))
+			mux.Handle(consts.AgentPathMetrics, health.MetricsHandler(status, metricsHelper))
+
+			if leaseCache != nil {
+				// Parse 'require_request_header' listener config option, and
+				// wrap the request handler if necessary
+				muxHandler := cacheHandler
+				if v, ok := lnConfig.Config[agentConfig.RequireRequestHeader]; ok {
+					switch v {
+					case true:
+						muxHandler = verifyRequestHeader(muxHandler)
+					case false /* noop */ :
+					default:
+						c.UI.Error(fmt.Sprintf("Invalid value for 'require_request_header': %v", v))
+						return 1
+					}
 				}
-			}
 
-			// Create a muxer and add paths relevant for the lease cache layer
-			mux := http.NewServeMux()
-			mux.Handle(consts.AgentPathCacheClear, leaseCache.HandleCacheClear(ctx))
-			mux.Handle("/", muxHandler)
+				// Add paths relevant for the lease cache layer
+				mux.Handle(consts.AgentPathCacheClear, leaseCache.HandleCacheClear(ctx))
+				mux.Handle("/", muxHandler)
+			}
 
 			scheme := "https://"
 			if tlsConf == nil {
@@ -509,7 +556,7 @@ func (c *AgentCommand) Run(args []string) int {
 				ReadHeaderTimeout: 10 * time.Second,
 				ReadTimeout:       30 * time.Second,
 				IdleTimeout:       5 * time.Minute,
-				ErrorLog:          cacheLogger.StandardLogger(nil),
+				ErrorLog:          c.logger.Named("listener").StandardLogger(nil),
 			}
 
 			go server.Serve(ln)
@@ -528,7 +575,8 @@ func (c *AgentCommand) Run(args []string) int {
 	// TODO: implement support for SIGHUP reloading of configuration
 	// signal.Notify(c.signalCh)
 
-	var ssDoneCh, ahDoneCh, tsDoneCh chan struct{}
+	var ssDoneCh, ahDoneCh, tsDoneCh, esDoneCh chan struct{}
+	var esExitCh chan int
 	// Start auto-auth and sink servers
 	if method != nil {
 		enableTokenCh := len(config.Templates) > 0
@@ -538,6 +586,8 @@ func (c *AgentCommand) Run(args []string) int {
 			WrapTTL:                      config.AutoAuth.Method.WrapTTL,
 			EnableReauthOnNewCredentials: config.AutoAuth.EnableReauthOnNewCredentials,
 			EnableTemplateTokenCh:        enableTokenCh,
+			EnableExecTokenCh:            config.Exec != nil,
+			Status:                       status,
 		})
 		ahDoneCh = ah.DoneCh
 
@@ -545,22 +595,44 @@ func (c *AgentCommand) Run(args []string) int {
 			Logger:        c.logger.Named("sink.server"),
 			Client:        client,
 			ExitAfterAuth: exitAfterAuth,
+			Status:        status,
 		})
 		ssDoneCh = ss.DoneCh
 
 		ts := template.NewServer(&template.ServerConfig{
-			Logger:        c.logger.Named("template.server"),
-			LogLevel:      level,
-			LogWriter:     c.logWriter,
-			VaultConf:     config.Vault,
-			Namespace:     namespace,
-			ExitAfterAuth: exitAfterAuth,
+			Logger:         c.logger.Named("template.server"),
+			LogLevel:       level,
+			LogWriter:      c.logWriter,
+			Client:         client,
+			TemplateConfig: config.TemplateConfig,
+			Owners:         config.TemplateOwners,
+			VaultConf:      config.Vault,
+			Namespace:      namespace,
+			ExitAfterAuth:  exitAfterAuth,
+			Status:         status,
 		})
 		tsDoneCh = ts.DoneCh
 
 		go ah.Run(ctx, method)
 		go ss.Run(ctx, ah.OutputCh, sinks)
 		go ts.Run(ctx, ah.TemplateTokenCh, config.Templates)
+
+		if config.Exec != nil {
+			es := agentexec.NewServer(&agentexec.ServerConfig{
+				Logger:       c.logger.Named("exec.server"),
+				LogLevel:     level,
+				LogWriter:    c.logWriter,
+				VaultConf:    config.Vault,
+				Namespace:    namespace,
+				ExecConf:     config.Exec,
+				EnvTemplates: config.EnvTemplates,
+				Status:       status,
+			})
+			esDoneCh = es.DoneCh
+			esExitCh = es.ExitCh
+
+			go es.Run(ctx, ah.ExecTokenCh)
+		}
 	}
 
 	// Server configuration output
@@ -591,6 +663,7 @@ func (c *AgentCommand) Run(args []string) int {
 		}
 	}()
 
+	exitCode := 0
 	select {
 	case <-ssDoneCh:
 		// This will happen if we exit-on-auth
@@ -599,6 +672,21 @@ func (c *AgentCommand) Run(args []string) int {
 		if tsDoneCh != nil {
 			<-tsDoneCh
 		}
+	case code := <-esExitCh:
+		// The supervised child process exited; the agent exits with its
+		// exit code
+		c.logger.Info("child process exited, exiting", "exit_code", code)
+		exitCode = code
+		cancelFunc()
+		if ahDoneCh != nil {
+			<-ahDoneCh
+		}
+		if ssDoneCh != nil {
+			<-ssDoneCh
+		}
+		if tsDoneCh != nil {
+			<-tsDoneCh
+		}
 	case <-c.ShutdownCh:
 		c.UI.Output("==> Vault agent shutdown triggered")
 		cancelFunc()
@@ -612,9 +700,43 @@ func (c *AgentCommand) Run(args []string) int {
 		if tsDoneCh != nil {
 			<-tsDoneCh
 		}
+		if esDoneCh != nil {
+			<-esDoneCh
+		}
+	}
+
+	return exitCode
+}
+
+// setupTelemetry is used to setup the telemetry sub-systems and returns the
+// metrics helper serving the agent's metrics endpoint.
+func (c *AgentCommand) setupTelemetry() (*metricsutil.MetricsHelper, error) {
+	// Aggregate on 10 second intervals for 1 minute. Unlike the server, the
+	// metrics are not dumped on SIGUSR1, which may be forwarded to a child
+	// process in exec mode.
+	inm := metrics.NewInmemSink(10*time.Second, time.Minute)
+
+	metricsConf := metrics.DefaultConfig("vault")
+	metricsConf.EnableHostname = false
+
+	fanout := metrics.FanoutSink{inm}
+
+	// The agent emits few metrics, and rarely updated counters such as auth
+	// failures should not disappear from Prometheus, so they never expire.
+	prometheusEnabled := true
+	sink, err := prometheus.NewPrometheusSinkFrom(prometheus.PrometheusOpts{})
+	if err != nil {
+		c.logger.Warn("failed to register prometheus sink; prometheus metrics are disabled", "error", err)
+		prometheusEnabled = false
+	} else {
+		fanout = append(fanout, sink)
+	}
+
+	if _, err := metrics.NewGlobal(metricsConf, fanout); err != nil {
+		return nil, err
 	}
 
-	return 0
+	return metricsutil.NewMetricsHelper(inm, prometheusEnabled), nil
 }
 
 // verifyRequestHeader wraps an http.Handler inside a Handler that checks for
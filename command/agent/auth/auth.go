@@ -2,12 +2,14 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"net/http"
 	"time"
 
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/command/agent/health"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 )
 
@@ -33,12 +35,15 @@ type AuthHandler struct {
 	DoneCh                       chan struct{}
 	OutputCh                     chan string
 	TemplateTokenCh              chan string
+	ExecTokenCh                  chan string
 	logger                       hclog.Logger
 	client                       *api.Client
 	random                       *rand.Rand
 	wrapTTL                      time.Duration
 	enableReauthOnNewCredentials bool
 	enableTemplateTokenCh        bool
+	enableExecTokenCh            bool
+	status                       *health.Status
 }
 
 type AuthHandlerConfig struct {
@@ -47,6 +52,10 @@ type AuthHandlerConfig struct {
 	WrapTTL                      time.Duration
 	EnableReauthOnNewCredentials bool
 	EnableTemplateTokenCh        bool
+	EnableExecTokenCh            bool
+
+	// Status, if set, records authentication and renewal events
+	Status *health.Status
 }
 
 func NewAuthHandler(conf *AuthHandlerConfig) *AuthHandler {
@@ -56,12 +65,15 @@ func NewAuthHandler(conf *AuthHandlerConfig) *AuthHandler {
 		// has been shut down, during agent shutdown, we won't block
 		OutputCh:                     make(chan string, 1),
 		TemplateTokenCh:              make(chan string, 1),
+		ExecTokenCh:                  make(chan string, 1),
 		logger:                       conf.Logger,
 		client:                       conf.Client,
 		random:                       rand.New(rand.NewSource(int64(time.Now().Nanosecond()))),
 		wrapTTL:                      conf.WrapTTL,
 		enableReauthOnNewCredentials: conf.EnableReauthOnNewCredentials,
 		enableTemplateTokenCh:        conf.EnableTemplateTokenCh,
+		enableExecTokenCh:            conf.EnableExecTokenCh,
+		status:                       conf.Status,
 	}
 
 	return ah
@@ -85,6 +97,7 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 		close(ah.OutputCh)
 		close(ah.DoneCh)
 		close(ah.TemplateTokenCh)
+		close(ah.ExecTokenCh)
 		ah.logger.Info("auth handler stopped")
 	}()
 
@@ -125,6 +138,7 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 		path, header, data, err := am.Authenticate(ctx, ah.client)
 		if err != nil {
 			ah.logger.Error("error getting path or data from method", "error", err, "backoff", backoff.Seconds())
+			ah.status.AuthFailed(err)
 			backoffOrQuit(ctx, backoff)
 			continue
 		}
@@ -134,6 +148,7 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 			wrapClient, err := ah.client.Clone()
 			if err != nil {
 				ah.logger.Error("error creating client for wrapped call", "error", err, "backoff", backoff.Seconds())
+				ah.status.AuthFailed(err)
 				backoffOrQuit(ctx, backoff)
 				continue
 			}
@@ -152,6 +167,7 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 		// Check errors/sanity
 		if err != nil {
 			ah.logger.Error("error authenticating", "error", err, "backoff", backoff.Seconds())
+			ah.status.AuthFailed(err)
 			backoffOrQuit(ctx, backoff)
 			continue
 		}
@@ -160,25 +176,33 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 		case ah.wrapTTL > 0:
 			if secret.WrapInfo == nil {
 				ah.logger.Error("authentication returned nil wrap info", "backoff", backoff.Seconds())
+				ah.status.AuthFailed(errors.New("authentication returned nil wrap info"))
 				backoffOrQuit(ctx, backoff)
 				continue
 			}
 			if secret.WrapInfo.Token == "" {
 				ah.logger.Error("authentication returned empty wrapped client token", "backoff", backoff.Seconds())
+				ah.status.AuthFailed(errors.New("authentication returned empty wrapped client token"))
 				backoffOrQuit(ctx, backoff)
 				continue
 			}
 			wrappedResp, err := jsonutil.EncodeJSON(secret.WrapInfo)
 			if err != nil {
 				ah.logger.Error("failed to encode wrapinfo", "error", err, "backoff", backoff.Seconds())
+				ah.status.AuthFailed(err)
 				backoffOrQuit(ctx, backoff)
 				continue
 			}
 			ah.logger.Info("authentication successful, sending wrapped token to sinks and pausing")
+			// The TTL of the wrapped token is not known to the agent
+			ah.status.AuthSucceeded(0)
 			ah.OutputCh <- string(wrappedResp)
 			if ah.enableTemplateTokenCh {
 				ah.TemplateTokenCh <- string(wrappedResp)
 			}
+			if ah.enableExecTokenCh {
+				ah.ExecTokenCh <- string(wrappedResp)
+			}
 
 			am.CredSuccess()
 
@@ -195,19 +219,25 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 		default:
 			if secret == nil || secret.Auth == nil {
 				ah.logger.Error("authentication returned nil auth info", "backoff", backoff.Seconds())
+				ah.status.AuthFailed(errors.New("authentication returned nil auth info"))
 				backoffOrQuit(ctx, backoff)
 				continue
 			}
 			if secret.Auth.ClientToken == "" {
 				ah.logger.Error("authentication returned empty client token", "backoff", backoff.Seconds())
+				ah.status.AuthFailed(errors.New("authentication returned empty client token"))
 				backoffOrQuit(ctx, backoff)
 				continue
 			}
 			ah.logger.Info("authentication successful, sending token to sinks")
+			ah.status.AuthSucceeded(time.Duration(secret.Auth.LeaseDuration) * time.Second)
 			ah.OutputCh <- secret.Auth.ClientToken
 			if ah.enableTemplateTokenCh {
 				ah.TemplateTokenCh <- secret.Auth.ClientToken
 			}
+			if ah.enableExecTokenCh {
+				ah.ExecTokenCh <- secret.Auth.ClientToken
+			}
 
 			am.CredSuccess()
 		}
@@ -221,6 +251,7 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 		})
 		if err != nil {
 			ah.logger.Error("error creating lifetime watcher, backing off and retrying", "error", err, "backoff", backoff.Seconds())
+			ah.status.RenewalFailed(err)
 			backoffOrQuit(ctx, backoff)
 			continue
 		}
@@ -241,11 +272,15 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) {
 				ah.logger.Info("lifetime watcher done channel triggered")
 				if err != nil {
 					ah.logger.Error("error renewing token", "error", err)
+					ah.status.RenewalFailed(err)
 				}
 				break LifetimeWatcherLoop
 
-			case <-watcher.RenewCh():
+			case renewal := <-watcher.RenewCh():
 				ah.logger.Info("renewed auth token")
+				if renewal != nil && renewal.Secret != nil && renewal.Secret.Auth != nil {
+					ah.status.TokenRenewed(time.Duration(renewal.Secret.Auth.LeaseDuration) * time.Second)
+				}
 
 			case <-credCh:
 				ah.logger.Info("auth method found new credentials, re-authenticating")
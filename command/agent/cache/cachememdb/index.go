@@ -41,6 +41,12 @@ type Index struct {
 	// Required: false, Unique: false
 	LeaseToken string
 
+	// StaticSecret indicates that the response held by this index carries
+	// neither a lease nor a token, and is evicted once its TTL passes or the
+	// secret at RequestPath changes.
+	// Required: false, Unique: false
+	StaticSecret bool
+
 	// Response is the serialized response object that the agent is caching.
 	Response []byte
 
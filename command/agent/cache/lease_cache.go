@@ -19,6 +19,7 @@ import (
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
 	cachememdb "github.com/hashicorp/vault/command/agent/cache/cachememdb"
+	"github.com/hashicorp/vault/command/agent/health"
 	"github.com/hashicorp/vault/helper/namespace"
 	nshelper "github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/base62"
@@ -78,6 +79,15 @@ type LeaseCache struct {
 	// idLocks is used during cache lookup to ensure that identical requests made
 	// in parallel won't trigger multiple renewal goroutines.
 	idLocks []*locksutil.LockEntry
+
+	// staticSecretTTL, staticSecretPaths and staticSecretPollInterval control
+	// the caching of KV responses, which carry neither a lease nor a token.
+	// Static secret caching is disabled if staticSecretTTL is zero.
+	staticSecretTTL          time.Duration
+	staticSecretPaths        []string
+	staticSecretPollInterval time.Duration
+
+	status *health.Status
 }
 
 // LeaseCacheConfig is the configuration for initializing a new
@@ -87,6 +97,21 @@ type LeaseCacheConfig struct {
 	BaseContext context.Context
 	Proxier     Proxier
 	Logger      hclog.Logger
+
+	// StaticSecretTTL enables caching of KV responses for at most this long.
+	StaticSecretTTL time.Duration
+
+	// StaticSecretPaths restricts static secret caching to request paths,
+	// relative to /v1/, with one of these prefixes.
+	StaticSecretPaths []string
+
+	// StaticSecretPollInterval is how often cached static secrets are read
+	// again, using the token that originally read them, to check whether they
+	// changed. Zero disables polling.
+	StaticSecretPollInterval time.Duration
+
+	// Status, if set, records cache hits and misses
+	Status *health.Status
 }
 
 // NewLeaseCache creates a new instance of a LeaseCache.
@@ -119,6 +144,12 @@ func NewLeaseCache(conf *LeaseCacheConfig) (*LeaseCache, error) {
 		baseCtxInfo: baseCtxInfo,
 		l:           &sync.RWMutex{},
 		idLocks:     locksutil.CreateLocks(),
+
+		staticSecretTTL:          conf.StaticSecretTTL,
+		staticSecretPaths:        conf.StaticSecretPaths,
+		staticSecretPollInterval: conf.StaticSecretPollInterval,
+
+		status: conf.Status,
 	}, nil
 }
 
@@ -184,6 +215,7 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 	}
 	if sendResp != nil {
 		c.logger.Debug("returning cached response", "path", req.Request.URL.Path)
+		c.status.CacheHit()
 		return sendResp, nil
 	}
 
@@ -203,10 +235,12 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 	// will be the one performing the cache write.
 	if sendResp != nil {
 		c.logger.Debug("returning cached response", "method", req.Request.Method, "path", req.Request.URL.Path)
+		c.status.CacheHit()
 		return sendResp, nil
 	}
 
 	c.logger.Debug("forwarding request", "method", req.Request.Method, "path", req.Request.URL.Path)
+	c.status.CacheMiss()
 
 	// Pass the request down and get a response
 	resp, err := c.proxier.Send(ctx, req)
@@ -214,6 +248,20 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 		return resp, err
 	}
 
+	// Writes through the agent invalidate the static secrets cached for the
+	// path they modify, so that subsequent reads observe them.
+	if c.staticSecretTTL > 0 && resp.Response.StatusCode < 300 {
+		switch req.Request.Method {
+		case http.MethodGet, "LIST":
+		default:
+			// The write has already happened upstream, so its response is
+			// returned regardless
+			if err := c.evictStaticSecrets(req); err != nil {
+				c.logger.Error("failed to evict static secrets", "path", req.Request.URL.Path, "error", err)
+			}
+		}
+	}
+
 	// If this is a non-2xx or if the returned response does not contain JSON payload,
 	// we skip caching
 	if resp.Response.StatusCode >= 300 || resp.Response.Header.Get("Content-Type") != "application/json" {
@@ -258,6 +306,11 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 		return resp, nil
 	}
 
+	// Static secrets are not renewable, and are instead cached for a fixed TTL
+	if c.cacheableStaticSecret(req, secret) {
+		return c.cacheStaticSecret(req, resp, index, secret)
+	}
+
 	// Short-circuit if the secret is not renewable
 	tokenRenewable, err := secret.TokenIsRenewable()
 	if err != nil {
@@ -326,22 +379,12 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 	}
 
 	// Serialize the response to store it in the cached index
-	var respBytes bytes.Buffer
-	err = resp.Response.Write(&respBytes)
+	index.Response, err = serializeResponse(resp)
 	if err != nil {
 		c.logger.Error("failed to serialize response", "error", err)
 		return nil, err
 	}
 
-	// Reset the response body for upper layers to read
-	if resp.Response.Body != nil {
-		resp.Response.Body.Close()
-	}
-	resp.Response.Body = ioutil.NopCloser(bytes.NewReader(resp.ResponseBody))
-
-	// Set the index's Response
-	index.Response = respBytes.Bytes()
-
 	// Store the index ID in the lifetimewatcher context
 	renewCtx := context.WithValue(renewCtxInfo.Ctx, contextIndexID, index.ID)
 
@@ -366,6 +409,22 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 	return resp, nil
 }
 
+// serializeResponse returns the wire format of the response, resetting its
+// body for upper layers to read.
+func serializeResponse(resp *SendResponse) ([]byte, error) {
+	var respBytes bytes.Buffer
+	if err := resp.Response.Write(&respBytes); err != nil {
+		return nil, err
+	}
+
+	if resp.Response.Body != nil {
+		resp.Response.Body.Close()
+	}
+	resp.Response.Body = ioutil.NopCloser(bytes.NewReader(resp.ResponseBody))
+
+	return respBytes.Bytes(), nil
+}
+
 func (c *LeaseCache) createCtxInfo(ctx context.Context) *cachememdb.ContextInfo {
 	if ctx == nil {
 		c.l.RLock()
@@ -0,0 +1,263 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	cachememdb "github.com/hashicorp/vault/command/agent/cache/cachememdb"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
+)
+
+// kvV2Segments are the path segments following the mount of a KV version 2
+// secrets engine, whose endpoints all modify the secret read from data/.
+var kvV2Segments = []string{"data", "metadata", "delete", "undelete", "destroy"}
+
+// cacheableStaticSecret returns true if static secret caching is enabled and
+// the response is a KV secret read by a token, on a configured path.
+func (c *LeaseCache) cacheableStaticSecret(req *SendRequest, secret *api.Secret) bool {
+	if c.staticSecretTTL <= 0 || req.Request.Method != http.MethodGet || req.Token == "" {
+		return false
+	}
+
+	if secret.LeaseID != "" || secret.Auth != nil || secret.Data == nil {
+		return false
+	}
+
+	// KV version 1 secrets carry a non-renewable lease duration, and version 2
+	// secrets carry their version metadata. Other responses without a lease,
+	// such as most sys/ endpoints, are not cached.
+	if _, ok := kvVersion(secret); !ok && (secret.LeaseDuration <= 0 || secret.Renewable) {
+		return false
+	}
+
+	if len(c.staticSecretPaths) == 0 {
+		return true
+	}
+	path := strings.TrimPrefix(req.Request.URL.Path, "/v1/")
+	for _, prefix := range c.staticSecretPaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheStaticSecret stores a static secret response in the cache. The entry
+// is tied to the context of the token that read it, so revoking that token
+// through the agent also evicts it.
+func (c *LeaseCache) cacheStaticSecret(req *SendRequest, resp *SendResponse, index *cachememdb.Index, secret *api.Secret) (*SendResponse, error) {
+	c.logger.Debug("processing static secret response", "method", req.Request.Method, "path", req.Request.URL.Path)
+
+	entry, err := c.db.Get(cachememdb.IndexNameToken, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	// Only tokens managed by the agent are known to be valid for as long as
+	// the response is served from the cache.
+	if entry == nil {
+		c.logger.Debug("pass-through static secret response; token not managed by agent", "method", req.Request.Method, "path", req.Request.URL.Path)
+		return resp, nil
+	}
+
+	index.Response, err = serializeResponse(resp)
+	if err != nil {
+		c.logger.Error("failed to serialize response", "error", err)
+		return nil, err
+	}
+	index.StaticSecret = true
+
+	ctxInfo := cachememdb.NewContextInfo(entry.RenewCtxInfo.Ctx)
+	ctx := context.WithValue(ctxInfo.Ctx, contextIndexID, index.ID)
+	index.RenewCtxInfo = &cachememdb.ContextInfo{
+		Ctx:        ctx,
+		CancelFunc: ctxInfo.CancelFunc,
+		DoneCh:     ctxInfo.DoneCh,
+	}
+
+	c.logger.Debug("storing static secret response into the cache", "method", req.Request.Method, "path", req.Request.URL.Path)
+	if err := c.db.Set(index); err != nil {
+		c.logger.Error("failed to cache the proxied response", "error", err)
+		return nil, err
+	}
+
+	go c.watchStaticSecret(ctx, index, req, secret)
+
+	return resp, nil
+}
+
+// watchStaticSecret evicts the cached static secret once its TTL passes, its
+// context is cancelled, or polling finds that it changed.
+func (c *LeaseCache) watchStaticSecret(ctx context.Context, index *cachememdb.Index, req *SendRequest, secret *api.Secret) {
+	defer func() {
+		// The index may already have been evicted and replaced by a write to
+		// its path, in which case the replacement is left alone.
+		current, err := c.db.Get(cachememdb.IndexNameID, index.ID)
+		if err != nil {
+			c.logger.Error("failed to get index", "id", index.ID, "error", err)
+			return
+		}
+		if current != index {
+			return
+		}
+		c.logger.Debug("evicting static secret from cache", "id", index.ID, "path", req.Request.URL.Path)
+		if err := c.db.Evict(cachememdb.IndexNameID, index.ID); err != nil {
+			c.logger.Error("failed to evict index", "id", index.ID, "error", err)
+		}
+	}()
+
+	expiry := time.NewTimer(c.staticSecretTTL)
+	defer expiry.Stop()
+
+	var client *api.Client
+	var pollCh <-chan time.Time
+	if c.staticSecretPollInterval > 0 {
+		var err error
+		client, err = c.client.Clone()
+		if err != nil {
+			c.logger.Error("failed to create API client to poll static secret", "error", err)
+			return
+		}
+		client.SetToken(req.Token)
+		client.SetHeaders(req.Request.Header)
+
+		ticker := time.NewTicker(c.staticSecretPollInterval)
+		defer ticker.Stop()
+		pollCh = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.logger.Debug("context cancelled; evicting static secret", "path", req.Request.URL.Path)
+			return
+		case <-index.RenewCtxInfo.DoneCh:
+			c.logger.Debug("done channel closed")
+			return
+		case <-expiry.C:
+			c.logger.Debug("static secret ttl expired", "path", req.Request.URL.Path)
+			return
+		case <-pollCh:
+			changed, err := staticSecretChanged(ctx, client, req, secret)
+			if err != nil {
+				// This includes the token losing access to the path
+				c.logger.Debug("failed to poll static secret; evicting", "path", req.Request.URL.Path, "error", err)
+				return
+			}
+			if changed {
+				c.logger.Debug("static secret changed", "path", req.Request.URL.Path)
+				return
+			}
+		}
+	}
+}
+
+// staticSecretChanged reads the secret again and reports whether it differs
+// from the cached one. KV version 2 secrets are compared by version.
+func staticSecretChanged(ctx context.Context, client *api.Client, req *SendRequest, secret *api.Secret) (bool, error) {
+	r := client.NewRequest(http.MethodGet, req.Request.URL.Path)
+	r.Params = req.Request.URL.Query()
+	resp, err := client.RawRequestWithContext(ctx, r)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	current, err := api.ParseSecret(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	if current == nil {
+		return true, nil
+	}
+
+	if version, ok := kvVersion(secret); ok {
+		currentVersion, ok := kvVersion(current)
+		return !ok || currentVersion != version, nil
+	}
+	return !reflect.DeepEqual(secret.Data, current.Data), nil
+}
+
+// evictStaticSecrets removes the static secrets cached for the path modified
+// by the request. Entries are evicted immediately, rather than by their
+// watchers, so that a read following the write is not served from the cache.
+func (c *LeaseCache) evictStaticSecrets(req *SendRequest) error {
+	namespace := req.Request.Header.Get(consts.NamespaceHeaderName)
+	if namespace == "" {
+		namespace = "root/"
+	}
+
+	for _, path := range kvDataPaths(req.Request.URL.Path) {
+		indexes, err := c.db.GetByPrefix(cachememdb.IndexNameRequestPath, namespace, path)
+		if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			// The lookup is by prefix, and also returns leased responses
+			if !index.StaticSecret || index.RequestPath != path {
+				continue
+			}
+			c.logger.Debug("evicting static secret after write", "path", path)
+			if err := c.db.Evict(cachememdb.IndexNameID, index.ID); err != nil {
+				return err
+			}
+			index.RenewCtxInfo.CancelFunc()
+		}
+	}
+
+	return nil
+}
+
+// kvDataPaths returns the request paths of the cached reads that a write to
+// path may modify: the path itself and, since the mount of a KV version 2
+// engine is not known, the data/ path of every segment that could follow it.
+// For example, a write to /v1/secret/metadata/foo modifies
+// /v1/secret/data/foo.
+func kvDataPaths(path string) []string {
+	paths := []string{path}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "data" {
+			continue
+		}
+		for _, kvSegment := range kvV2Segments {
+			if segment != kvSegment {
+				continue
+			}
+			dataSegments := make([]string, len(segments))
+			copy(dataSegments, segments)
+			dataSegments[i] = "data"
+			paths = append(paths, strings.Join(dataSegments, "/"))
+		}
+	}
+
+	return paths
+}
+
+// kvVersion returns the version of a KV version 2 secret, and whether the
+// secret has one.
+func kvVersion(secret *api.Secret) (int64, bool) {
+	if _, ok := secret.Data["data"]; !ok {
+		return 0, false
+	}
+	metadata, ok := secret.Data["metadata"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	versionRaw, ok := metadata["version"]
+	if !ok {
+		return 0, false
+	}
+	version, err := parseutil.ParseInt(versionRaw)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/command/agent/cache/cachememdb"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+)
+
+const (
+	testKVv1Response = `{"lease_duration": 2764800, "renewable": false, "data": {"foo": "bar"}}`
+	testKVv2Response = `{"lease_duration": 0, "renewable": false, "data": {"data": {"foo": "bar"}, "metadata": {"version": 1}}}`
+)
+
+func testNewStaticSecretCache(t *testing.T, client *api.Client, responses []*SendResponse, pollInterval time.Duration) *LeaseCache {
+	t.Helper()
+
+	if client == nil {
+		var err error
+		client, err = api.NewClient(api.DefaultConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lc, err := NewLeaseCache(&LeaseCacheConfig{
+		Client:                   client,
+		BaseContext:              context.Background(),
+		Proxier:                  newMockProxier(responses),
+		Logger:                   logging.NewVaultLogger(hclog.Trace).Named("cache.leasecache"),
+		StaticSecretTTL:          time.Minute,
+		StaticSecretPaths:        []string{"secret/", "kv/"},
+		StaticSecretPollInterval: pollInterval,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lc.RegisterAutoAuthToken("autoauthtoken"); err != nil {
+		t.Fatal(err)
+	}
+
+	return lc
+}
+
+func testSendStatic(t *testing.T, lc *LeaseCache, method, path, token string) *SendResponse {
+	t.Helper()
+
+	resp, err := lc.Send(context.Background(), &SendRequest{
+		Token:   token,
+		Request: httptest.NewRequest(method, "http://example.com"+path, nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestLeaseCache_StaticSecret(t *testing.T) {
+	responses := []*SendResponse{
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+		newTestSendResponse(http.StatusOK, testKVv1Response),
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+		newTestSendResponse(http.StatusOK, `{"data": {"initialized": true}}`),
+		newTestSendResponse(http.StatusOK, `{"data": {"initialized": true}}`),
+	}
+	lc := testNewStaticSecretCache(t, nil, responses, 0)
+	proxier := lc.proxier.(*mockProxier)
+
+	// KV version 2 and version 1 reads are cached
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken")
+	testSendStatic(t, lc, "GET", "/v1/kv/foo", "autoauthtoken")
+	if resp := testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken"); !resp.CacheMeta.Hit {
+		t.Fatal("expected cache hit")
+	}
+	if resp := testSendStatic(t, lc, "GET", "/v1/kv/foo", "autoauthtoken"); !resp.CacheMeta.Hit {
+		t.Fatal("expected cache hit")
+	}
+	if proxier.ResponseIndex() != 2 {
+		t.Fatalf("expected 2 proxied requests, got %d", proxier.ResponseIndex())
+	}
+
+	// Responses are cached per token, and only for tokens managed by the
+	// agent
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "othertoken")
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "othertoken")
+	if proxier.ResponseIndex() != 4 {
+		t.Fatalf("expected 4 proxied requests, got %d", proxier.ResponseIndex())
+	}
+
+	// Paths outside the configured prefixes are not cached
+	testSendStatic(t, lc, "GET", "/v1/other/data/foo", "autoauthtoken")
+	if proxier.ResponseIndex() != 5 {
+		t.Fatalf("expected 5 proxied requests, got %d", proxier.ResponseIndex())
+	}
+
+	// Responses that do not look like KV secrets are not cached
+	testSendStatic(t, lc, "GET", "/v1/secret/sys", "autoauthtoken")
+	testSendStatic(t, lc, "GET", "/v1/secret/sys", "autoauthtoken")
+	if proxier.ResponseIndex() != 7 {
+		t.Fatalf("expected 7 proxied requests, got %d", proxier.ResponseIndex())
+	}
+}
+
+func TestLeaseCache_StaticSecret_WriteEviction(t *testing.T) {
+	responses := []*SendResponse{
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+		newTestSendResponse(http.StatusNoContent, ""),
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+	}
+	lc := testNewStaticSecretCache(t, nil, responses, 0)
+
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken")
+	if index := testStaticIndex(t, lc, "/v1/secret/data/foo"); index == nil {
+		t.Fatal("expected static secret to be cached")
+	}
+
+	// Deleting the secret's metadata, with any token, evicts the secret
+	testSendStatic(t, lc, "DELETE", "/v1/secret/metadata/foo", "othertoken")
+	if index := testStaticIndex(t, lc, "/v1/secret/data/foo"); index != nil {
+		t.Fatal("expected static secret to be evicted")
+	}
+
+	if resp := testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken"); resp.CacheMeta != nil && resp.CacheMeta.Hit {
+		t.Fatal("expected cache miss")
+	}
+}
+
+func TestLeaseCache_StaticSecret_TokenEviction(t *testing.T) {
+	responses := []*SendResponse{
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+	}
+	lc := testNewStaticSecretCache(t, nil, responses, 0)
+
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken")
+	if index := testStaticIndex(t, lc, "/v1/secret/data/foo"); index == nil {
+		t.Fatal("expected static secret to be cached")
+	}
+
+	// Clearing the token evicts the secrets it read
+	if err := lc.handleCacheClear(context.Background(), &cacheClearInput{
+		Type:  "token",
+		Token: "autoauthtoken",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	waitForStaticEviction(t, lc, "/v1/secret/data/foo")
+}
+
+func TestLeaseCache_StaticSecret_TTL(t *testing.T) {
+	responses := []*SendResponse{
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+	}
+	lc := testNewStaticSecretCache(t, nil, responses, 0)
+	lc.staticSecretTTL = 50 * time.Millisecond
+
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken")
+	if index := testStaticIndex(t, lc, "/v1/secret/data/foo"); index == nil {
+		t.Fatal("expected static secret to be cached")
+	}
+	waitForStaticEviction(t, lc, "/v1/secret/data/foo")
+}
+
+func TestLeaseCache_StaticSecret_Poll(t *testing.T) {
+	var version int32 = 1
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&polls, 1)
+		if r.URL.Path != "/v1/secret/data/foo" || r.Header.Get("X-Vault-Token") != "autoauthtoken" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data": {"data": {"foo": "bar"}, "metadata": {"version": %d}}}`, atomic.LoadInt32(&version))
+	}))
+	defer ts.Close()
+
+	config := api.DefaultConfig()
+	config.Address = ts.URL
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	responses := []*SendResponse{
+		newTestSendResponse(http.StatusOK, testKVv2Response),
+	}
+	lc := testNewStaticSecretCache(t, client, responses, 10*time.Millisecond)
+
+	testSendStatic(t, lc, "GET", "/v1/secret/data/foo", "autoauthtoken")
+
+	// The secret stays cached while its version is unchanged
+	for atomic.LoadInt32(&polls) < 3 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if index := testStaticIndex(t, lc, "/v1/secret/data/foo"); index == nil {
+		t.Fatal("expected static secret to be cached")
+	}
+
+	atomic.StoreInt32(&version, 2)
+	waitForStaticEviction(t, lc, "/v1/secret/data/foo")
+}
+
+func TestCache_KVDataPaths(t *testing.T) {
+	tests := map[string][]string{
+		"/v1/secret/foo": {
+			"/v1/secret/foo",
+		},
+		"/v1/secret/data/foo": {
+			"/v1/secret/data/foo",
+		},
+		"/v1/secret/destroy/foo": {
+			"/v1/secret/destroy/foo",
+			"/v1/secret/data/foo",
+		},
+		"/v1/metadata/metadata/foo": {
+			"/v1/metadata/metadata/foo",
+			"/v1/data/metadata/foo",
+			"/v1/metadata/data/foo",
+		},
+	}
+	for path, expected := range tests {
+		if actual := kvDataPaths(path); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("bad paths for %q: expected %v, got %v", path, expected, actual)
+		}
+	}
+}
+
+func testStaticIndex(t *testing.T, lc *LeaseCache, path string) *cachememdb.Index {
+	t.Helper()
+
+	indexes, err := lc.db.GetByPrefix(cachememdb.IndexNameRequestPath, "root/", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, index := range indexes {
+		if index.StaticSecret && strings.HasPrefix(index.RequestPath, path) {
+			return index
+		}
+	}
+	return nil
+}
+
+func waitForStaticEviction(t *testing.T, lc *LeaseCache, path string) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if testStaticIndex(t, lc, path) == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected static secret at %q to be evicted", path)
+}
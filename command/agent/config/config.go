@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/user"
+	"strconv"
 	"strings"
 	"time"
 
 	ctconfig "github.com/hashicorp/consul-template/config"
+	ctsignals "github.com/hashicorp/consul-template/signals"
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/hcl"
@@ -20,13 +23,73 @@ import (
 
 // Config is the configuration for the vault server.
 type Config struct {
-	AutoAuth      *AutoAuth                  `hcl:"auto_auth"`
-	ExitAfterAuth bool                       `hcl:"exit_after_auth"`
-	PidFile       string                     `hcl:"pid_file"`
-	Listeners     []*Listener                `hcl:"listeners"`
-	Cache         *Cache                     `hcl:"cache"`
-	Vault         *Vault                     `hcl:"vault"`
-	Templates     []*ctconfig.TemplateConfig `hcl:"templates"`
+	AutoAuth       *AutoAuth                  `hcl:"auto_auth"`
+	ExitAfterAuth  bool                       `hcl:"exit_after_auth"`
+	PidFile        string                     `hcl:"pid_file"`
+	Listeners      []*Listener                `hcl:"listeners"`
+	Cache          *Cache                     `hcl:"cache"`
+	Vault          *Vault                     `hcl:"vault"`
+	Templates      []*ctconfig.TemplateConfig `hcl:"templates"`
+	TemplateConfig *TemplateConfig            `hcl:"-"`
+	TemplateOwners []*TemplateOwner           `hcl:"-"`
+	Exec           *Exec                      `hcl:"-"`
+	EnvTemplates   []*EnvTemplate             `hcl:"-"`
+}
+
+// DefaultCertRenewalThreshold is the default fraction of a certificate's
+// validity after which templates issue it again
+const DefaultCertRenewalThreshold = 0.9
+
+// TemplateConfig configures the behavior shared by all templates
+type TemplateConfig struct {
+	// CertRenewalThreshold is the fraction of a certificate's validity after
+	// which templates issue it again. Until then, the certificate issued
+	// first is reused.
+	CertRenewalThresholdRaw interface{} `hcl:"cert_renewal_threshold"`
+	CertRenewalThreshold    float64     `hcl:"-"`
+
+	// CertCachePath is the file issued certificates are persisted to, so
+	// that they are reused across restarts of the agent.
+	CertCachePath string `hcl:"cert_cache_path"`
+}
+
+// TemplateOwner is the ownership set on the destination of a template after
+// it is rendered. A UID or GID of -1 leaves it unchanged.
+type TemplateOwner struct {
+	Destination string
+	User        string
+	Group       string
+	UID         int
+	GID         int
+}
+
+// Exec configures a child process that Vault Agent supervises, passing it the
+// rendered env_template values as environment variables.
+type Exec struct {
+	Command        []string      `hcl:"command"`
+	OnSecretChange string        `hcl:"on_secret_change"`
+	ChangeSignal   os.Signal     `hcl:"-"`
+	StopSignal     os.Signal     `hcl:"-"`
+	KillTimeoutRaw interface{}   `hcl:"kill_timeout"`
+	KillTimeout    time.Duration `hcl:"-"`
+}
+
+const (
+	// The values of Exec.OnSecretChange
+	ExecOnSecretChangeRestart = "restart"
+	ExecOnSecretChangeSignal  = "signal"
+	ExecOnSecretChangeNone    = "none"
+
+	// ExecDefaultKillTimeout is how long the child process is given to exit
+	// after being sent the stop signal before it is killed.
+	ExecDefaultKillTimeout = 30 * time.Second
+)
+
+// EnvTemplate is a template whose rendered contents are passed to the exec
+// child process in the environment variable Name.
+type EnvTemplate struct {
+	Name     string
+	Template *ctconfig.TemplateConfig
 }
 
 // Vault contains configuration for connnecting to Vault servers
@@ -44,6 +107,20 @@ type Vault struct {
 // Cache contains any configuration needed for Cache mode
 type Cache struct {
 	UseAutoAuthToken bool `hcl:"use_auto_auth_token"`
+
+	// StaticSecretTTL enables caching of KV responses, which carry neither a
+	// lease nor a token, for at most this long.
+	StaticSecretTTLRaw interface{}   `hcl:"static_secret_ttl"`
+	StaticSecretTTL    time.Duration `hcl:"-"`
+
+	// StaticSecretPaths restricts static secret caching to request paths with
+	// one of these prefixes.
+	StaticSecretPaths []string `hcl:"static_secret_paths"`
+
+	// StaticSecretPollInterval is how often cached static secrets are checked
+	// for a new version. Zero disables polling.
+	StaticSecretPollIntervalRaw interface{}   `hcl:"static_secret_poll_interval"`
+	StaticSecretPollInterval    time.Duration `hcl:"-"`
 }
 
 // Listener contains configuration for any Vault Agent listeners
@@ -138,6 +215,25 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, errwrap.Wrapf("error parsing 'template': {{err}}", err)
 	}
 
+	if err := parseTemplateConfig(&result, list); err != nil {
+		return nil, errwrap.Wrapf("error parsing 'template_config': {{err}}", err)
+	}
+
+	if err := parseExec(&result, list); err != nil {
+		return nil, errwrap.Wrapf("error parsing 'exec': {{err}}", err)
+	}
+
+	if err := parseEnvTemplates(&result, list); err != nil {
+		return nil, errwrap.Wrapf("error parsing 'env_template': {{err}}", err)
+	}
+
+	if len(result.EnvTemplates) > 0 && result.Exec == nil {
+		return nil, fmt.Errorf("env_template requires an exec block")
+	}
+	if result.Exec != nil && result.AutoAuth == nil {
+		return nil, fmt.Errorf("exec requires auto_auth to be configured")
+	}
+
 	if result.Cache != nil {
 		if len(result.Listeners) < 1 {
 			return nil, fmt.Errorf("at least one listener required when cache enabled")
@@ -219,6 +315,24 @@ func parseCache(result *Config, list *ast.ObjectList) error {
 		return err
 	}
 
+	if c.StaticSecretTTLRaw != nil {
+		if c.StaticSecretTTL, err = parseutil.ParseDurationSecond(c.StaticSecretTTLRaw); err != nil {
+			return errwrap.Wrapf("error parsing static_secret_ttl: {{err}}", err)
+		}
+		c.StaticSecretTTLRaw = nil
+	}
+
+	if c.StaticSecretPollIntervalRaw != nil {
+		if c.StaticSecretPollInterval, err = parseutil.ParseDurationSecond(c.StaticSecretPollIntervalRaw); err != nil {
+			return errwrap.Wrapf("error parsing static_secret_poll_interval: {{err}}", err)
+		}
+		c.StaticSecretPollIntervalRaw = nil
+	}
+
+	if c.StaticSecretTTL == 0 && (len(c.StaticSecretPaths) > 0 || c.StaticSecretPollInterval > 0) {
+		return errors.New("static_secret_paths and static_secret_poll_interval require static_secret_ttl")
+	}
+
 	result.Cache = &c
 	return nil
 }
@@ -459,29 +573,269 @@ func parseTemplates(result *Config, list *ast.ObjectList) error {
 			parsed["wait"] = wait[len(wait)-1]
 		}
 
-		var tc ctconfig.TemplateConfig
-
-		// Use mapstructure to populate the basic config fields
-		var md mapstructure.Metadata
-		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
-			DecodeHook: mapstructure.ComposeDecodeHookFunc(
-				ctconfig.StringToFileModeFunc(),
-				ctconfig.StringToWaitDurationHookFunc(),
-				mapstructure.StringToSliceHookFunc(","),
-				mapstructure.StringToTimeDurationHookFunc(),
-			),
-			ErrorUnused: true,
-			Metadata:    &md,
-			Result:      &tc,
-		})
+		// user and group are handled by Vault Agent rather than Consul
+		// Template
+		owner, err := parseTemplateOwner(parsed)
 		if err != nil {
-			return errors.New("mapstructure decoder creation failed")
+			return err
 		}
-		if err := decoder.Decode(parsed); err != nil {
+
+		tc, err := decodeTemplateConfig(parsed)
+		if err != nil {
 			return err
 		}
-		tcs = append(tcs, &tc)
+		tcs = append(tcs, tc)
+
+		if owner != nil {
+			if tc.Destination == nil || *tc.Destination == "" {
+				return errors.New("'user' and 'group' require a destination")
+			}
+			owner.Destination = *tc.Destination
+			result.TemplateOwners = append(result.TemplateOwners, owner)
+		}
 	}
 	result.Templates = tcs
 	return nil
 }
+
+// parseTemplateOwner removes the user and group keys from the parsed
+// template stanza, and resolves them to a UID and GID. Both may be given as
+// names or numeric IDs.
+func parseTemplateOwner(parsed map[string]interface{}) (*TemplateOwner, error) {
+	userRaw, hasUser := parsed["user"]
+	groupRaw, hasGroup := parsed["group"]
+	delete(parsed, "user")
+	delete(parsed, "group")
+	if !hasUser && !hasGroup {
+		return nil, nil
+	}
+
+	owner := &TemplateOwner{
+		UID: -1,
+		GID: -1,
+	}
+
+	if hasUser {
+		owner.User = fmt.Sprint(userRaw)
+		uid, err := strconv.Atoi(owner.User)
+		if err != nil {
+			u, err := user.Lookup(owner.User)
+			if err != nil {
+				return nil, errwrap.Wrapf("error looking up 'user': {{err}}", err)
+			}
+			if uid, err = strconv.Atoi(u.Uid); err != nil {
+				return nil, fmt.Errorf("user %q does not have a numeric uid", owner.User)
+			}
+		}
+		if uid < 0 {
+			return nil, fmt.Errorf("invalid uid %d", uid)
+		}
+		owner.UID = uid
+	}
+
+	if hasGroup {
+		owner.Group = fmt.Sprint(groupRaw)
+		gid, err := strconv.Atoi(owner.Group)
+		if err != nil {
+			g, err := user.LookupGroup(owner.Group)
+			if err != nil {
+				return nil, errwrap.Wrapf("error looking up 'group': {{err}}", err)
+			}
+			if gid, err = strconv.Atoi(g.Gid); err != nil {
+				return nil, fmt.Errorf("group %q does not have a numeric gid", owner.Group)
+			}
+		}
+		if gid < 0 {
+			return nil, fmt.Errorf("invalid gid %d", gid)
+		}
+		owner.GID = gid
+	}
+
+	return owner, nil
+}
+
+func parseTemplateConfig(result *Config, list *ast.ObjectList) error {
+	name := "template_config"
+
+	templateConfigList := list.Filter(name)
+	if len(templateConfigList.Items) == 0 {
+		return nil
+	}
+
+	if len(templateConfigList.Items) > 1 {
+		return fmt.Errorf("at most one %q block is allowed", name)
+	}
+
+	item := templateConfigList.Items[0]
+
+	var tc TemplateConfig
+	if err := hcl.DecodeObject(&tc, item.Val); err != nil {
+		return err
+	}
+
+	tc.CertRenewalThreshold = DefaultCertRenewalThreshold
+	if tc.CertRenewalThresholdRaw != nil {
+		var err error
+		switch threshold := tc.CertRenewalThresholdRaw.(type) {
+		case float64:
+			tc.CertRenewalThreshold = threshold
+		case int:
+			tc.CertRenewalThreshold = float64(threshold)
+		case string:
+			tc.CertRenewalThreshold, err = strconv.ParseFloat(threshold, 64)
+		default:
+			err = fmt.Errorf("unexpected type %T", threshold)
+		}
+		if err != nil {
+			return errwrap.Wrapf("error parsing cert_renewal_threshold: {{err}}", err)
+		}
+		tc.CertRenewalThresholdRaw = nil
+	}
+	if tc.CertRenewalThreshold <= 0 || tc.CertRenewalThreshold > 1 {
+		return errors.New("cert_renewal_threshold must be greater than 0 and at most 1")
+	}
+
+	result.TemplateConfig = &tc
+	return nil
+}
+
+// decodeTemplateConfig populates a consul-template TemplateConfig from the
+// parsed HCL of a template stanza.
+func decodeTemplateConfig(parsed map[string]interface{}) (*ctconfig.TemplateConfig, error) {
+	var tc ctconfig.TemplateConfig
+
+	// Use mapstructure to populate the basic config fields
+	var md mapstructure.Metadata
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			ctconfig.StringToFileModeFunc(),
+			ctconfig.StringToWaitDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+			mapstructure.StringToTimeDurationHookFunc(),
+		),
+		ErrorUnused: true,
+		Metadata:    &md,
+		Result:      &tc,
+	})
+	if err != nil {
+		return nil, errors.New("mapstructure decoder creation failed")
+	}
+	if err := decoder.Decode(parsed); err != nil {
+		return nil, err
+	}
+	return &tc, nil
+}
+
+func parseExec(result *Config, list *ast.ObjectList) error {
+	name := "exec"
+
+	execList := list.Filter(name)
+	if len(execList.Items) == 0 {
+		return nil
+	}
+
+	if len(execList.Items) > 1 {
+		return fmt.Errorf("at most one %q block is allowed", name)
+	}
+
+	item := execList.Items[0]
+
+	var shadow struct {
+		Exec         `hcl:",squash"`
+		ChangeSignal string `hcl:"change_signal"`
+		StopSignal   string `hcl:"stop_signal"`
+	}
+	if err := hcl.DecodeObject(&shadow, item.Val); err != nil {
+		return err
+	}
+	e := shadow.Exec
+
+	if len(e.Command) == 0 || e.Command[0] == "" {
+		return errors.New("'command' must be specified")
+	}
+
+	switch e.OnSecretChange {
+	case "":
+		e.OnSecretChange = ExecOnSecretChangeRestart
+	case ExecOnSecretChangeRestart, ExecOnSecretChangeSignal, ExecOnSecretChangeNone:
+	default:
+		return fmt.Errorf("invalid value %q for 'on_secret_change'", e.OnSecretChange)
+	}
+
+	var err error
+	if shadow.ChangeSignal == "" {
+		shadow.ChangeSignal = "SIGHUP"
+	}
+	if e.ChangeSignal, err = ctsignals.Parse(shadow.ChangeSignal); err != nil {
+		return errwrap.Wrapf("invalid 'change_signal': {{err}}", err)
+	}
+	if shadow.StopSignal == "" {
+		shadow.StopSignal = "SIGTERM"
+	}
+	if e.StopSignal, err = ctsignals.Parse(shadow.StopSignal); err != nil {
+		return errwrap.Wrapf("invalid 'stop_signal': {{err}}", err)
+	}
+
+	e.KillTimeout = ExecDefaultKillTimeout
+	if e.KillTimeoutRaw != nil {
+		if e.KillTimeout, err = parseutil.ParseDurationSecond(e.KillTimeoutRaw); err != nil {
+			return errwrap.Wrapf("invalid 'kill_timeout': {{err}}", err)
+		}
+		e.KillTimeoutRaw = nil
+	}
+
+	result.Exec = &e
+	return nil
+}
+
+func parseEnvTemplates(result *Config, list *ast.ObjectList) error {
+	name := "env_template"
+
+	envTemplateList := list.Filter(name)
+	if len(envTemplateList.Items) < 1 {
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	var ets []*EnvTemplate
+
+	for _, item := range envTemplateList.Items {
+		if len(item.Keys) != 1 {
+			return errors.New("env_template requires the environment variable name as its label")
+		}
+		envName := item.Keys[0].Token.Value().(string)
+		if envName == "" || strings.Contains(envName, "=") {
+			return fmt.Errorf("invalid environment variable name %q", envName)
+		}
+		if _, ok := seen[envName]; ok {
+			return fmt.Errorf("duplicate env_template %q", envName)
+		}
+		seen[envName] = struct{}{}
+
+		var parsed map[string]interface{}
+		if err := hcl.DecodeObject(&parsed, item.Val); err != nil {
+			return fmt.Errorf("error decoding config: %s", err)
+		}
+
+		// Rendered values are only held in memory, so the options controlling
+		// how a template is written to disk or acted upon don't apply.
+		for _, key := range []string{"destination", "create_dest_dirs", "command", "command_timeout", "perms", "backup", "wait"} {
+			if _, ok := parsed[key]; ok {
+				return fmt.Errorf("env_template %q: %q is not supported", envName, key)
+			}
+		}
+
+		tc, err := decodeTemplateConfig(parsed)
+		if err != nil {
+			return multierror.Prefix(err, fmt.Sprintf("env_template.%s", envName))
+		}
+
+		ets = append(ets, &EnvTemplate{
+			Name:     envName,
+			Template: tc,
+		})
+	}
+
+	result.EnvTemplates = ets
+	return nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"syscall"
 	"testing"
 	"time"
 
@@ -287,6 +288,52 @@ func TestLoadConfigFile_AgentCache_AutoAuth_NoSink(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFile_AgentCache_StaticSecrets(t *testing.T) {
+	config, err := LoadConfig("./test-fixtures/config-cache-static-secrets.hcl")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &Config{
+		AutoAuth: &AutoAuth{
+			Method: &Method{
+				Type:      "aws",
+				MountPath: "auth/aws",
+				Config: map[string]interface{}{
+					"role": "foobar",
+				},
+			},
+		},
+		Cache: &Cache{
+			UseAutoAuthToken:         true,
+			StaticSecretTTL:          5 * time.Minute,
+			StaticSecretPaths:        []string{"secret/", "kv/data/shared/"},
+			StaticSecretPollInterval: 30 * time.Second,
+		},
+		Listeners: []*Listener{
+			&Listener{
+				Type: "tcp",
+				Config: map[string]interface{}{
+					"address":     "127.0.0.1:8300",
+					"tls_disable": true,
+				},
+			},
+		},
+		PidFile: "./pidfile",
+	}
+
+	if diff := deep.Equal(config, expected); diff != nil {
+		t.Fatal(diff)
+	}
+}
+
+func TestLoadConfigFile_Bad_AgentCache_StaticSecretsNoTTL(t *testing.T) {
+	_, err := LoadConfig("./test-fixtures/bad-config-cache-static-secrets-no-ttl.hcl")
+	if err == nil {
+		t.Fatal("LoadConfig should return an error when static_secret_paths is set without static_secret_ttl")
+	}
+}
+
 // TestLoadConfigFile_Template tests template definitions in Vault Agent
 func TestLoadConfigFile_Template(t *testing.T) {
 	testCases := map[string]struct {
@@ -389,3 +436,121 @@ func TestLoadConfigFile_Template(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadConfigFile_Exec(t *testing.T) {
+	config, err := LoadConfig("./test-fixtures/config-exec.hcl")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &Config{
+		AutoAuth: &AutoAuth{
+			Method: &Method{
+				Type:      "aws",
+				MountPath: "auth/aws",
+				Namespace: "my-namespace/",
+				Config: map[string]interface{}{
+					"role": "foobar",
+				},
+			},
+			Sinks: []*Sink{
+				&Sink{
+					Type:   "file",
+					DHType: "curve25519",
+					DHPath: "/tmp/file-foo-dhpath",
+					AAD:    "foobar",
+					Config: map[string]interface{}{
+						"path": "/tmp/file-foo",
+					},
+				},
+			},
+		},
+		Exec: &Exec{
+			Command:        []string{"/usr/bin/app", "-port", "8080"},
+			OnSecretChange: ExecOnSecretChangeSignal,
+			ChangeSignal:   syscall.SIGUSR1,
+			StopSignal:     syscall.SIGTERM,
+			KillTimeout:    10 * time.Second,
+		},
+		EnvTemplates: []*EnvTemplate{
+			&EnvTemplate{
+				Name: "DB_USERNAME",
+				Template: &ctconfig.TemplateConfig{
+					Contents:      pointerutil.StringPtr(`{{ with secret "database/creds/app" }}{{ .Data.username }}{{ end }}`),
+					ErrMissingKey: pointerutil.BoolPtr(true),
+				},
+			},
+			&EnvTemplate{
+				Name: "DB_PASSWORD",
+				Template: &ctconfig.TemplateConfig{
+					Contents: pointerutil.StringPtr(`{{ with secret "database/creds/app" }}{{ .Data.password }}{{ end }}`),
+				},
+			},
+		},
+		PidFile: "./pidfile",
+	}
+
+	if diff := deep.Equal(config, expected); diff != nil {
+		t.Fatal(diff)
+	}
+}
+
+func TestLoadConfigFile_Bad_EnvTemplate_Destination(t *testing.T) {
+	_, err := LoadConfig("./test-fixtures/bad-config-env_template-destination.hcl")
+	if err == nil {
+		t.Fatal("LoadConfig should return an error when an env_template has a destination")
+	}
+}
+
+func TestLoadConfigFile_Bad_EnvTemplate_NoExec(t *testing.T) {
+	_, err := LoadConfig("./test-fixtures/bad-config-env_template-no-exec.hcl")
+	if err == nil {
+		t.Fatal("LoadConfig should return an error when env_template is used without exec")
+	}
+}
+
+func TestLoadConfigFile_Template_Owner(t *testing.T) {
+	config, err := LoadConfig("./test-fixtures/config-template-owner.hcl")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expectedTemplates := []*ctconfig.TemplateConfig{
+		&ctconfig.TemplateConfig{
+			Source:      pointerutil.StringPtr("/path/on/disk/to/template.ctmpl"),
+			Destination: pointerutil.StringPtr("/path/on/disk/where/template/will/render.txt"),
+			Perms:       pointerutil.FileModePtr(0640),
+		},
+	}
+	if diff := deep.Equal(config.Templates, expectedTemplates); diff != nil {
+		t.Fatal(diff)
+	}
+
+	expectedOwners := []*TemplateOwner{
+		&TemplateOwner{
+			Destination: "/path/on/disk/where/template/will/render.txt",
+			User:        "0",
+			Group:       "0",
+			UID:         0,
+			GID:         0,
+		},
+	}
+	if diff := deep.Equal(config.TemplateOwners, expectedOwners); diff != nil {
+		t.Fatal(diff)
+	}
+
+	expectedTemplateConfig := &TemplateConfig{
+		CertRenewalThreshold: 0.75,
+		CertCachePath:        "/path/on/disk/to/cert-cache.json",
+	}
+	if diff := deep.Equal(config.TemplateConfig, expectedTemplateConfig); diff != nil {
+		t.Fatal(diff)
+	}
+}
+
+func TestLoadConfigFile_Bad_TemplateConfig_Threshold(t *testing.T) {
+	_, err := LoadConfig("./test-fixtures/bad-config-template_config-threshold.hcl")
+	if err == nil {
+		t.Fatal("LoadConfig should return an error when cert_renewal_threshold is greater than 1")
+	}
+}
@@ -0,0 +1,22 @@
+pid_file = "./pidfile"
+
+auto_auth {
+	method {
+		type = "aws"
+		config = {
+			role = "foobar"
+		}
+	}
+}
+
+cache {
+	use_auto_auth_token = true
+	static_secret_ttl = "5m"
+	static_secret_paths = ["secret/", "kv/data/shared/"]
+	static_secret_poll_interval = 30
+}
+
+listener "tcp" {
+    address = "127.0.0.1:8300"
+    tls_disable = true
+}
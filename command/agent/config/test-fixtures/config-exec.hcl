@@ -0,0 +1,40 @@
+pid_file = "./pidfile"
+
+auto_auth {
+  method {
+    type      = "aws"
+    namespace = "/my-namespace"
+
+    config = {
+      role = "foobar"
+    }
+  }
+
+  sink {
+    type = "file"
+
+    config = {
+      path = "/tmp/file-foo"
+    }
+
+    aad     = "foobar"
+    dh_type = "curve25519"
+    dh_path = "/tmp/file-foo-dhpath"
+  }
+}
+
+env_template "DB_USERNAME" {
+  contents             = "{{ with secret \"database/creds/app\" }}{{ .Data.username }}{{ end }}"
+  error_on_missing_key = true
+}
+
+env_template "DB_PASSWORD" {
+  contents = "{{ with secret \"database/creds/app\" }}{{ .Data.password }}{{ end }}"
+}
+
+exec {
+  command          = ["/usr/bin/app", "-port", "8080"]
+  on_secret_change = "signal"
+  change_signal    = "SIGUSR1"
+  kill_timeout     = "10s"
+}
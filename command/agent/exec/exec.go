@@ -0,0 +1,376 @@
+// Package exec runs a child process on behalf of Vault Agent, passing it the
+// rendered contents of env_template stanzas as environment variables. The
+// child is restarted or signalled when the rendered values change, signals
+// received by the agent are forwarded to it, and its exit code becomes the
+// agent's exit code.
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul-template/child"
+	ctconfig "github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/manager"
+	cttemplate "github.com/hashicorp/consul-template/template"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/config"
+	"github.com/hashicorp/vault/command/agent/health"
+	"github.com/hashicorp/vault/command/agent/template"
+)
+
+// ServerConfig is a config struct for setting up the basic parts of the
+// Server
+type ServerConfig struct {
+	Logger    hclog.Logger
+	VaultConf *config.Vault
+	ExecConf  *config.Exec
+	Namespace string
+
+	// EnvTemplates are rendered and passed to the child process.
+	EnvTemplates []*config.EnvTemplate
+
+	// Stdin, Stdout and Stderr are connected to the child process. They
+	// default to the agent's own standard streams.
+	Stdin          io.Reader
+	Stdout, Stderr io.Writer
+
+	// LogLevel and LogWriter configure the internal Consul Template Runner's
+	// logger, as for the template server.
+	LogLevel  hclog.Level
+	LogWriter io.Writer
+
+	// Status, if set, records env template renders and errors
+	Status *health.Status
+}
+
+// Server renders the env templates and supervises the child process
+type Server struct {
+	config *ServerConfig
+	logger hclog.Logger
+
+	// ExitCh receives the exit code of the child process if it exits on its
+	// own. It is closed when Run returns.
+	ExitCh chan int
+
+	// DoneCh is closed when Run returns.
+	DoneCh chan struct{}
+
+	// envNames maps the consul-template ID of each env template to the
+	// environment variables it is rendered into. Templates with identical
+	// contents share an ID.
+	envNames map[string][]string
+
+	child   *child.Child
+	lastEnv map[string]string
+}
+
+// NewServer returns a new configured server
+func NewServer(conf *ServerConfig) *Server {
+	if conf.Stdin == nil {
+		conf.Stdin = os.Stdin
+	}
+	if conf.Stdout == nil {
+		conf.Stdout = os.Stdout
+	}
+	if conf.Stderr == nil {
+		conf.Stderr = os.Stderr
+	}
+
+	return &Server{
+		config: conf,
+		logger: conf.Logger,
+		ExitCh: make(chan int, 1),
+		DoneCh: make(chan struct{}),
+	}
+}
+
+// Run renders the env templates using the tokens received on incoming, and
+// starts the child process once all of them have been rendered. It returns
+// when the child exits or ctx is done, in which case the child is stopped.
+func (s *Server) Run(ctx context.Context, incoming chan string) {
+	s.logger.Info("starting exec server")
+	defer func() {
+		s.stopChild()
+		s.logger.Info("exec server stopped")
+		close(s.ExitCh)
+		close(s.DoneCh)
+	}()
+
+	if incoming == nil {
+		panic("incoming channel is nil")
+	}
+
+	var templates []*ctconfig.TemplateConfig
+	s.envNames = make(map[string][]string, len(s.config.EnvTemplates))
+	for _, et := range s.config.EnvTemplates {
+		id, err := templateID(et.Template)
+		if err != nil {
+			s.logger.Error("failed to parse env template", "name", et.Name, "error", err)
+			s.ExitCh <- 1
+			return
+		}
+		s.envNames[id] = append(s.envNames[id], et.Name)
+		templates = append(templates, et.Template)
+	}
+
+	runnerConfig, err := template.NewRunnerConfig(&template.ServerConfig{
+		Logger:    s.logger,
+		VaultConf: s.config.VaultConf,
+		Namespace: s.config.Namespace,
+		LogLevel:  s.config.LogLevel,
+		LogWriter: s.config.LogWriter,
+	}, templates)
+	if err != nil {
+		s.logger.Error("exec server failed to generate runner config", "error", err)
+		s.ExitCh <- 1
+		return
+	}
+
+	// Forward signals received by the agent to the child.
+	sigCh := make(chan os.Signal, 1)
+	if len(forwardedSignals) > 0 {
+		// Notify with no signals would relay all of them
+		signal.Notify(sigCh, forwardedSignals...)
+		defer signal.Stop(sigCh)
+	}
+
+	// Without env templates there is nothing to wait for.
+	if len(templates) == 0 {
+		if err := s.startChild(nil); err != nil {
+			s.logger.Error("failed to start child process", "error", err)
+			s.ExitCh <- 1
+			return
+		}
+	}
+
+	var runner *manager.Runner
+	var runnerErrCh <-chan error
+	var renderCh <-chan struct{}
+	stopRunner := func() {
+		if runner != nil {
+			runner.Stop()
+		}
+	}
+	defer func() { stopRunner() }()
+
+	latestToken := ""
+	for {
+		var childExitCh <-chan int
+		if s.child != nil {
+			childExitCh = s.child.ExitCh()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case token, ok := <-incoming:
+			if !ok {
+				incoming = nil
+				continue
+			}
+			if token == latestToken || len(templates) == 0 {
+				continue
+			}
+			s.logger.Info("exec server received new token")
+			latestToken = token
+
+			stopRunner()
+			runnerConfig = runnerConfig.Merge(&ctconfig.Config{
+				Vault: &ctconfig.VaultConfig{
+					Token: &latestToken,
+				},
+			})
+
+			// Dry mode keeps the rendered values in memory rather than
+			// writing them to disk.
+			runner, err = manager.NewRunner(runnerConfig, true)
+			if err != nil {
+				s.logger.Error("exec server failed with new Vault token", "error", err)
+				runner = nil
+				continue
+			}
+			runner.SetOutStream(ioutil.Discard)
+			runnerErrCh = runner.ErrCh
+			renderCh = runner.RenderEventCh()
+			go runner.Start()
+
+		case err := <-runnerErrCh:
+			s.logger.Error("exec server error", "error", err.Error())
+			s.config.Status.TemplateRendered(err)
+			s.ExitCh <- 1
+			return
+
+		case <-renderCh:
+			env, ok := s.renderedEnv(runner.RenderEvents())
+			if !ok {
+				// Not all templates have been rendered yet
+				continue
+			}
+			s.config.Status.TemplateRendered(nil)
+			if err := s.updateChild(env); err != nil {
+				s.logger.Error("failed to start child process", "error", err)
+				s.ExitCh <- 1
+				return
+			}
+
+		case sig := <-sigCh:
+			if s.child != nil {
+				if err := s.child.Signal(sig); err != nil {
+					s.logger.Warn("failed to forward signal to child process", "signal", sig, "error", err)
+				}
+			}
+
+		case code := <-childExitCh:
+			s.logger.Info("child process exited", "exit_code", code)
+			s.child = nil
+			s.ExitCh <- code
+			return
+		}
+	}
+}
+
+// renderedEnv returns the environment variables rendered by the runner, and
+// whether every env template has been rendered.
+func (s *Server) renderedEnv(events map[string]*manager.RenderEvent) (map[string]string, bool) {
+	env := make(map[string]string, len(s.config.EnvTemplates))
+	for id, names := range s.envNames {
+		// Events are recreated on every pass, and only carry contents when
+		// the template rendered in that pass.
+		event, ok := events[id]
+		if !ok || !event.WouldRender {
+			return nil, false
+		}
+		for _, name := range names {
+			env[name] = string(event.Contents)
+		}
+	}
+	return env, true
+}
+
+// updateChild starts the child with the given environment, or applies the
+// configured on_secret_change behavior if it is already running and the
+// environment changed.
+func (s *Server) updateChild(env map[string]string) error {
+	if s.child == nil {
+		return s.startChild(env)
+	}
+
+	if envEqual(env, s.lastEnv) {
+		return nil
+	}
+
+	switch s.config.ExecConf.OnSecretChange {
+	case config.ExecOnSecretChangeRestart:
+		s.logger.Info("rendered values changed, restarting child process")
+		s.stopChild()
+		return s.startChild(env)
+
+	case config.ExecOnSecretChangeSignal:
+		s.logger.Info("rendered values changed, signalling child process", "signal", s.config.ExecConf.ChangeSignal)
+		// The running process keeps its environment; the new values are
+		// passed if it is later restarted.
+		s.lastEnv = env
+		return s.child.Signal(s.config.ExecConf.ChangeSignal)
+
+	default:
+		s.lastEnv = env
+		return nil
+	}
+}
+
+func (s *Server) startChild(env map[string]string) error {
+	command := s.config.ExecConf.Command
+
+	c, err := child.New(&child.NewInput{
+		Stdin:       s.config.Stdin,
+		Stdout:      s.config.Stdout,
+		Stderr:      s.config.Stderr,
+		Command:     command[0],
+		Args:        command[1:],
+		Env:         childEnv(os.Environ(), env),
+		KillSignal:  s.config.ExecConf.StopSignal,
+		KillTimeout: s.config.ExecConf.KillTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	s.logger.Info("started child process", "command", c.Command(), "pid", c.Pid())
+	s.child = c
+	s.lastEnv = env
+	return nil
+}
+
+// stopChild sends the stop signal to the child, if it is running, and waits
+// for it to exit, killing it after the kill timeout.
+func (s *Server) stopChild() {
+	if s.child == nil {
+		return
+	}
+	s.child.Stop()
+	s.child = nil
+}
+
+// childEnv returns base with the rendered values added, replacing any
+// variables of the same name.
+func childEnv(base []string, rendered map[string]string) []string {
+	ret := make([]string, 0, len(base)+len(rendered))
+	for _, kv := range base {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if _, ok := rendered[name]; ok {
+			continue
+		}
+		ret = append(ret, kv)
+	}
+
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ret = append(ret, fmt.Sprintf("%s=%s", name, rendered[name]))
+	}
+	return ret
+}
+
+func envEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if other, ok := b[k]; !ok || other != v {
+			return false
+		}
+	}
+	return true
+}
+
+// templateID returns the ID consul-template assigns to the template, which
+// keys its render events.
+func templateID(tc *ctconfig.TemplateConfig) (string, error) {
+	tmpl, err := cttemplate.NewTemplate(&cttemplate.NewTemplateInput{
+		Source:            ctconfig.StringVal(tc.Source),
+		Contents:          ctconfig.StringVal(tc.Contents),
+		ErrMissingKey:     ctconfig.BoolVal(tc.ErrMissingKey),
+		LeftDelim:         ctconfig.StringVal(tc.LeftDelim),
+		RightDelim:        ctconfig.StringVal(tc.RightDelim),
+		FunctionBlacklist: tc.FunctionBlacklist,
+		SandboxPath:       ctconfig.StringVal(tc.SandboxPath),
+	})
+	if err != nil {
+		return "", err
+	}
+	return tmpl.ID(), nil
+}
@@ -0,0 +1,208 @@
+// +build !windows
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	ctconfig "github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/config"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/hashicorp/vault/sdk/helper/pointerutil"
+)
+
+func TestServerRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, jsonResponse)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "agent-exec-tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	outFile := filepath.Join(tmpDir, "out")
+
+	server := NewServer(&ServerConfig{
+		Logger: logging.NewVaultLogger(hclog.Trace),
+		VaultConf: &config.Vault{
+			Address: ts.URL,
+		},
+		ExecConf: &config.Exec{
+			Command:        []string{"/bin/sh", "-c", fmt.Sprintf(`echo "$DB_USERNAME:$DB_PASSWORD:$OTHER" > %s; exit 3`, outFile)},
+			OnSecretChange: config.ExecOnSecretChangeRestart,
+			StopSignal:     syscall.SIGTERM,
+			KillTimeout:    time.Second,
+		},
+		EnvTemplates: []*config.EnvTemplate{
+			{
+				Name: "DB_USERNAME",
+				Template: &ctconfig.TemplateConfig{
+					Contents: pointerutil.StringPtr(`{{ with secret "kv/myapp/config" }}{{ .Data.data.username }}{{ end }}`),
+				},
+			},
+			{
+				Name: "DB_PASSWORD",
+				Template: &ctconfig.TemplateConfig{
+					Contents: pointerutil.StringPtr(`{{ with secret "kv/myapp/config" }}{{ .Data.data.password }}{{ end }}`),
+				},
+			},
+		},
+		LogLevel:  hclog.Trace,
+		LogWriter: hclog.DefaultOutput,
+	})
+
+	os.Setenv("OTHER", "inherited")
+	defer os.Unsetenv("OTHER")
+
+	tokenCh := make(chan string, 1)
+	go server.Run(context.Background(), tokenCh)
+	tokenCh <- "test"
+
+	select {
+	case code := <-server.ExitCh:
+		if code != 3 {
+			t.Fatalf("expected exit code 3, got %d", code)
+		}
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for child to exit")
+	}
+	<-server.DoneCh
+
+	out, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(out)); got != "appuser:password:inherited" {
+		t.Fatalf("unexpected child environment: %q", got)
+	}
+}
+
+func TestServer_UpdateChild(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "agent-exec-tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	newServer := func(onChange, script string) *Server {
+		return NewServer(&ServerConfig{
+			Logger: logging.NewVaultLogger(hclog.Trace),
+			ExecConf: &config.Exec{
+				Command:        []string{"/bin/sh", "-c", script},
+				OnSecretChange: onChange,
+				ChangeSignal:   syscall.SIGUSR1,
+				StopSignal:     syscall.SIGTERM,
+				KillTimeout:    5 * time.Second,
+			},
+		})
+	}
+
+	t.Run("restart", func(t *testing.T) {
+		outFile := filepath.Join(tmpDir, "restart")
+		s := newServer(config.ExecOnSecretChangeRestart, fmt.Sprintf(`echo "$SECRET" >> %s; exec sleep 60`, outFile))
+		defer s.stopChild()
+
+		if err := s.updateChild(map[string]string{"SECRET": "one"}); err != nil {
+			t.Fatal(err)
+		}
+		waitForFile(t, outFile, "one\n")
+		pid := s.child.Pid()
+
+		// Unchanged values leave the child alone
+		if err := s.updateChild(map[string]string{"SECRET": "one"}); err != nil {
+			t.Fatal(err)
+		}
+		if s.child.Pid() != pid {
+			t.Fatal("child restarted without a change")
+		}
+
+		if err := s.updateChild(map[string]string{"SECRET": "two"}); err != nil {
+			t.Fatal(err)
+		}
+		waitForFile(t, outFile, "one\ntwo\n")
+		if s.child.Pid() == pid {
+			t.Fatal("expected child to be restarted")
+		}
+	})
+
+	t.Run("signal", func(t *testing.T) {
+		outFile := filepath.Join(tmpDir, "signal")
+		s := newServer(config.ExecOnSecretChangeSignal, fmt.Sprintf(`trap 'echo "usr1 $SECRET" >> %s' USR1; echo "start $SECRET" >> %s; while true; do sleep 0.1; done`, outFile, outFile))
+		defer s.stopChild()
+
+		if err := s.updateChild(map[string]string{"SECRET": "one"}); err != nil {
+			t.Fatal(err)
+		}
+		waitForFile(t, outFile, "start one\n")
+		pid := s.child.Pid()
+
+		if err := s.updateChild(map[string]string{"SECRET": "two"}); err != nil {
+			t.Fatal(err)
+		}
+		// The running process keeps its original environment
+		waitForFile(t, outFile, "start one\nusr1 one\n")
+		if s.child.Pid() != pid {
+			t.Fatal("child restarted in signal mode")
+		}
+	})
+}
+
+func TestChildEnv(t *testing.T) {
+	env := childEnv([]string{"A=1", "B=2", "C=3=4"}, map[string]string{"B": "new", "D": "x=y"})
+	expected := []string{"A=1", "C=3=4", "B=new", "D=x=y"}
+	if strings.Join(env, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, env)
+	}
+}
+
+func waitForFile(t *testing.T, path, expected string) {
+	t.Helper()
+
+	var contents string
+	for i := 0; i < 100; i++ {
+		data, _ := ioutil.ReadFile(path)
+		contents = string(data)
+		if contents == expected {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("expected %q in %s, got %q", expected, path, contents)
+}
+
+var jsonResponse = `
+{
+  "request_id": "8af096e9-518c-7351-eff5-5ba20554b21f",
+  "lease_id": "",
+  "renewable": false,
+  "lease_duration": 0,
+  "data": {
+    "data": {
+      "password": "password",
+      "username": "appuser"
+    },
+    "metadata": {
+      "created_time": "2019-10-07T22:18:44.233247Z",
+      "deletion_time": "",
+      "destroyed": false,
+      "version": 3
+    }
+  },
+  "wrap_info": null,
+  "warnings": null,
+  "auth": null
+}
+`
@@ -0,0 +1,18 @@
+// +build !windows
+
+package exec
+
+import (
+	"os"
+	"syscall"
+)
+
+// forwardedSignals are passed on to the child process. SIGINT and SIGTERM
+// shut down the agent, which then stops the child using its stop signal.
+var forwardedSignals = []os.Signal{
+	syscall.SIGHUP,
+	syscall.SIGQUIT,
+	syscall.SIGUSR1,
+	syscall.SIGUSR2,
+	syscall.SIGWINCH,
+}
@@ -0,0 +1,10 @@
+// +build windows
+
+package exec
+
+import "os"
+
+// forwardedSignals are passed on to the child process. Windows has no
+// signals to forward besides interrupt, which shuts down the agent and stops
+// the child.
+var forwardedSignals = []os.Signal{}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/vault/helper/metricsutil"
+	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Handler returns a handler for the health endpoint. It responds with a 200
+// if the agent is healthy, and a 503 otherwise, along with the state of its
+// subsystems.
+func Handler(status *Status) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			logical.RespondError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		health := status.Health()
+		body, err := jsonutil.EncodeJSON(health)
+		if err != nil {
+			logical.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		code := http.StatusOK
+		if !health.Healthy {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		w.Write(body)
+	})
+}
+
+// MetricsHandler returns a handler for the metrics endpoint. As with the
+// server's sys/metrics endpoint, the in-memory metrics are returned as JSON
+// unless the Prometheus format is requested, through the format query
+// parameter or the Accept header.
+func MetricsHandler(status *Status, helper *metricsutil.MetricsHelper) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			logical.RespondError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		if helper == nil {
+			logical.RespondError(w, http.StatusNotFound, errors.New("metrics are not enabled"))
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = metricsutil.FormatFromRequest(&logical.Request{
+				Headers: r.Header,
+			})
+		}
+
+		// Gauges derived from the current state are refreshed on every
+		// request rather than on a timer.
+		status.EmitGauges()
+
+		resp := helper.ResponseForFormat(format)
+		contentType, _ := resp.Data[logical.HTTPContentType].(string)
+		code, _ := resp.Data[logical.HTTPStatusCode].(int)
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(code)
+		switch body := resp.Data[logical.HTTPRawBody].(type) {
+		case []byte:
+			w.Write(body)
+		case string:
+			w.Write([]byte(body))
+		}
+	})
+}
@@ -0,0 +1,345 @@
+// Package health tracks the state of the Vault Agent subsystems and serves it
+// on the agent's health and metrics endpoints. The auth handler, sink server,
+// template server and cache report events to a shared Status, which both
+// records them for the health endpoint and emits them as telemetry.
+package health
+
+import (
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+)
+
+// StatusConfig is the configuration for creating a new Status
+type StatusConfig struct {
+	// AutoAuth indicates that auto-auth is configured, in which case the
+	// agent is only healthy while it holds an unexpired token.
+	AutoAuth bool
+
+	// Cache indicates that caching is configured, and cache statistics are
+	// reported.
+	Cache bool
+}
+
+// Status holds the state of the agent's subsystems. A nil *Status is valid,
+// and discards all events.
+type Status struct {
+	l sync.RWMutex
+
+	autoAuth bool
+	cache    bool
+
+	authenticated       bool
+	lastAuthTime        time.Time
+	lastRenewalTime     time.Time
+	tokenExpireTime     time.Time
+	authFailures        uint64
+	consecutiveFailures uint64
+	lastAuthError       string
+
+	sinkWrites        uint64
+	sinkFailures      uint64
+	failingSinks      map[string]struct{}
+	lastSinkError     string
+	templateRenders   uint64
+	templateErrors    uint64
+	templateFailing   bool
+	lastTemplateError string
+
+	cacheHits   uint64
+	cacheMisses uint64
+
+	now func() time.Time
+}
+
+// NewStatus returns a new Status
+func NewStatus(conf *StatusConfig) *Status {
+	return &Status{
+		autoAuth:     conf.AutoAuth,
+		cache:        conf.Cache,
+		failingSinks: make(map[string]struct{}),
+		now:          time.Now,
+	}
+}
+
+// AuthSucceeded records a successful authentication yielding a token valid for
+// ttl. A zero ttl indicates that the token does not expire, or that it was
+// wrapped and its TTL is unknown.
+func (s *Status) AuthSucceeded(ttl time.Duration) {
+	if s == nil {
+		return
+	}
+	metrics.IncrCounter([]string{"agent", "auth", "success"}, 1)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.authenticated = true
+	s.lastAuthTime = s.now()
+	s.setTokenTTL(ttl)
+	s.consecutiveFailures = 0
+	s.lastAuthError = ""
+}
+
+// AuthFailed records a failed authentication attempt. The agent remains
+// authenticated with its previous token, if any, until that token expires.
+func (s *Status) AuthFailed(err error) {
+	if s == nil {
+		return
+	}
+	metrics.IncrCounter([]string{"agent", "auth", "failure"}, 1)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.authFailures++
+	s.consecutiveFailures++
+	if err != nil {
+		s.lastAuthError = err.Error()
+	}
+}
+
+// TokenRenewed records a renewal of the auto-auth token, extending it by ttl.
+func (s *Status) TokenRenewed(ttl time.Duration) {
+	if s == nil {
+		return
+	}
+	metrics.IncrCounter([]string{"agent", "auth", "renewal"}, 1)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.lastRenewalTime = s.now()
+	s.setTokenTTL(ttl)
+}
+
+// RenewalFailed records that the auto-auth token could no longer be renewed.
+// The agent re-authenticates afterwards.
+func (s *Status) RenewalFailed(err error) {
+	if s == nil {
+		return
+	}
+	metrics.IncrCounter([]string{"agent", "auth", "renewal_failure"}, 1)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err != nil {
+		s.lastAuthError = err.Error()
+	}
+}
+
+// setTokenTTL must be called with the lock held.
+func (s *Status) setTokenTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		s.tokenExpireTime = time.Time{}
+		return
+	}
+	s.tokenExpireTime = s.now().Add(ttl)
+}
+
+// SinkWritten records the outcome of writing a token to the sink identified
+// by name. The agent is unhealthy while the last write to any sink failed.
+func (s *Status) SinkWritten(name string, err error) {
+	if s == nil {
+		return
+	}
+
+	if err != nil {
+		metrics.IncrCounter([]string{"agent", "sink", "failure"}, 1)
+	} else {
+		metrics.IncrCounter([]string{"agent", "sink", "success"}, 1)
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err != nil {
+		s.sinkFailures++
+		s.failingSinks[name] = struct{}{}
+		s.lastSinkError = err.Error()
+		return
+	}
+	s.sinkWrites++
+	delete(s.failingSinks, name)
+}
+
+// TemplateRendered records the outcome of rendering templates.
+func (s *Status) TemplateRendered(err error) {
+	if s == nil {
+		return
+	}
+
+	if err != nil {
+		metrics.IncrCounter([]string{"agent", "template", "error"}, 1)
+	} else {
+		metrics.IncrCounter([]string{"agent", "template", "render"}, 1)
+	}
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err != nil {
+		s.templateErrors++
+		s.templateFailing = true
+		s.lastTemplateError = err.Error()
+		return
+	}
+	s.templateRenders++
+	s.templateFailing = false
+}
+
+// CacheHit records a request served from the cache.
+func (s *Status) CacheHit() {
+	if s == nil {
+		return
+	}
+	metrics.IncrCounter([]string{"agent", "cache", "hit"}, 1)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.cacheHits++
+}
+
+// CacheMiss records a request forwarded to the Vault server by the cache.
+func (s *Status) CacheMiss() {
+	if s == nil {
+		return
+	}
+	metrics.IncrCounter([]string{"agent", "cache", "miss"}, 1)
+
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.cacheMisses++
+}
+
+// EmitGauges sets the gauges derived from the current state, such as the
+// remaining TTL of the auto-auth token, which change without any event.
+func (s *Status) EmitGauges() {
+	if s == nil {
+		return
+	}
+	h := s.Health()
+
+	if h.Auth != nil {
+		var authenticated float32
+		if h.Auth.Authenticated {
+			authenticated = 1
+		}
+		metrics.SetGauge([]string{"agent", "auth", "authenticated"}, authenticated)
+		metrics.SetGauge([]string{"agent", "auth", "token_ttl"}, float32(h.Auth.TokenTTL))
+	}
+	if h.Cache != nil {
+		metrics.SetGauge([]string{"agent", "cache", "hit_rate"}, float32(h.Cache.HitRate))
+	}
+}
+
+// Health returns a snapshot of the current state.
+func (s *Status) Health() *HealthResponse {
+	s.l.RLock()
+	defer s.l.RUnlock()
+
+	now := s.now()
+	resp := &HealthResponse{
+		Healthy: true,
+		Sinks: &SinkHealth{
+			Writes:    s.sinkWrites,
+			Failures:  s.sinkFailures,
+			Failing:   len(s.failingSinks),
+			LastError: s.lastSinkError,
+		},
+		Templates: &TemplateHealth{
+			Renders:   s.templateRenders,
+			Errors:    s.templateErrors,
+			LastError: s.lastTemplateError,
+		},
+	}
+
+	if s.autoAuth {
+		auth := &AuthHealth{
+			Authenticated:       s.authenticated,
+			LastAuthTime:        formatTime(s.lastAuthTime),
+			LastRenewalTime:     formatTime(s.lastRenewalTime),
+			TokenExpireTime:     formatTime(s.tokenExpireTime),
+			Failures:            s.authFailures,
+			ConsecutiveFailures: s.consecutiveFailures,
+			LastError:           s.lastAuthError,
+		}
+		if !s.tokenExpireTime.IsZero() {
+			if remaining := s.tokenExpireTime.Sub(now); remaining > 0 {
+				auth.TokenTTL = int64(remaining.Seconds())
+			} else {
+				// The token expired without being renewed or replaced
+				auth.Authenticated = false
+			}
+		}
+		resp.Auth = auth
+		if !auth.Authenticated {
+			resp.Healthy = false
+		}
+	}
+
+	if s.cache {
+		cache := &CacheHealth{
+			Hits:   s.cacheHits,
+			Misses: s.cacheMisses,
+		}
+		if total := s.cacheHits + s.cacheMisses; total > 0 {
+			cache.HitRate = float64(s.cacheHits) / float64(total)
+		}
+		resp.Cache = cache
+	}
+
+	if len(s.failingSinks) > 0 || s.templateFailing {
+		resp.Healthy = false
+	}
+
+	return resp
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// HealthResponse is the response of the agent's health endpoint
+type HealthResponse struct {
+	Healthy   bool            `json:"healthy"`
+	Auth      *AuthHealth     `json:"auth,omitempty"`
+	Sinks     *SinkHealth     `json:"sinks"`
+	Templates *TemplateHealth `json:"templates"`
+	Cache     *CacheHealth    `json:"cache,omitempty"`
+}
+
+// AuthHealth is the state of auto-auth. TokenTTL is the number of seconds the
+// auto-auth token remains valid for, or zero if it does not expire.
+type AuthHealth struct {
+	Authenticated       bool   `json:"authenticated"`
+	LastAuthTime        string `json:"last_auth_time,omitempty"`
+	LastRenewalTime     string `json:"last_renewal_time,omitempty"`
+	TokenExpireTime     string `json:"token_expire_time,omitempty"`
+	TokenTTL            int64  `json:"token_ttl"`
+	Failures            uint64 `json:"failures"`
+	ConsecutiveFailures uint64 `json:"consecutive_failures"`
+	LastError           string `json:"last_error,omitempty"`
+}
+
+// SinkHealth is the state of the sinks auto-auth tokens are written to.
+// Failing is the number of sinks the last write to failed.
+type SinkHealth struct {
+	Writes    uint64 `json:"writes"`
+	Failures  uint64 `json:"failures"`
+	Failing   int    `json:"failing"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// TemplateHealth is the state of template rendering
+type TemplateHealth struct {
+	Renders   uint64 `json:"renders"`
+	Errors    uint64 `json:"errors"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// CacheHealth holds the cache statistics
+type CacheHealth struct {
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
@@ -0,0 +1,256 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/armon/go-metrics/prometheus"
+	"github.com/go-test/deep"
+	"github.com/hashicorp/vault/helper/metricsutil"
+)
+
+func testStatus(conf *StatusConfig) (*Status, *time.Time) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewStatus(conf)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestStatus_Auth(t *testing.T) {
+	s, now := testStatus(&StatusConfig{AutoAuth: true})
+
+	// Not healthy until authenticated
+	if s.Health().Healthy {
+		t.Fatal("expected agent to be unhealthy before authenticating")
+	}
+
+	s.AuthFailed(errors.New("permission denied"))
+	s.AuthFailed(errors.New("permission denied"))
+	h := s.Health()
+	expected := &AuthHealth{
+		Failures:            2,
+		ConsecutiveFailures: 2,
+		LastError:           "permission denied",
+	}
+	if diff := deep.Equal(h.Auth, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	s.AuthSucceeded(time.Hour)
+	*now = now.Add(10 * time.Minute)
+	h = s.Health()
+	if !h.Healthy {
+		t.Fatal("expected agent to be healthy")
+	}
+	expected = &AuthHealth{
+		Authenticated:   true,
+		LastAuthTime:    "2020-01-01T00:00:00Z",
+		TokenExpireTime: "2020-01-01T01:00:00Z",
+		TokenTTL:        3000,
+		Failures:        2,
+	}
+	if diff := deep.Equal(h.Auth, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	// Renewals extend the token
+	s.TokenRenewed(time.Hour)
+	if h := s.Health(); h.Auth.TokenTTL != 3600 || h.Auth.LastRenewalTime != "2020-01-01T00:10:00Z" {
+		t.Fatalf("unexpected auth state after renewal: %#v", h.Auth)
+	}
+
+	// Once the token expires the agent is no longer authenticated
+	*now = now.Add(2 * time.Hour)
+	if h := s.Health(); h.Healthy || h.Auth.Authenticated || h.Auth.TokenTTL != 0 {
+		t.Fatalf("expected expired token to be unhealthy: %#v", h.Auth)
+	}
+
+	// Tokens without a TTL do not expire
+	s.AuthSucceeded(0)
+	*now = now.Add(24 * time.Hour)
+	if h := s.Health(); !h.Healthy || h.Auth.TokenExpireTime != "" {
+		t.Fatalf("expected token without TTL to stay valid: %#v", h.Auth)
+	}
+}
+
+func TestStatus_SinksTemplatesCache(t *testing.T) {
+	s, _ := testStatus(&StatusConfig{Cache: true})
+
+	h := s.Health()
+	if !h.Healthy || h.Auth != nil {
+		t.Fatalf("expected healthy agent without auth state: %#v", h)
+	}
+
+	s.SinkWritten("file", errors.New("disk full"))
+	if h := s.Health(); h.Healthy || h.Sinks.Failures != 1 || h.Sinks.Failing != 1 || h.Sinks.LastError != "disk full" {
+		t.Fatalf("expected failing sink to be unhealthy: %#v", h.Sinks)
+	}
+
+	// A successful write to another sink doesn't clear the failure
+	s.SinkWritten("socket", nil)
+	if h := s.Health(); h.Healthy || h.Sinks.Writes != 1 || h.Sinks.Failing != 1 {
+		t.Fatalf("expected failing sink to stay unhealthy: %#v", h.Sinks)
+	}
+
+	s.SinkWritten("file", nil)
+	if h := s.Health(); !h.Healthy || h.Sinks.Writes != 2 || h.Sinks.Failing != 0 {
+		t.Fatalf("expected sink to recover: %#v", h.Sinks)
+	}
+
+	s.TemplateRendered(errors.New("missing key"))
+	if h := s.Health(); h.Healthy || h.Templates.Errors != 1 {
+		t.Fatalf("expected failing template to be unhealthy: %#v", h.Templates)
+	}
+	s.TemplateRendered(nil)
+	if h := s.Health(); !h.Healthy || h.Templates.Renders != 1 {
+		t.Fatalf("expected template to recover: %#v", h.Templates)
+	}
+
+	s.CacheHit()
+	s.CacheHit()
+	s.CacheHit()
+	s.CacheMiss()
+	expected := &CacheHealth{
+		Hits:    3,
+		Misses:  1,
+		HitRate: 0.75,
+	}
+	if diff := deep.Equal(s.Health().Cache, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	// A nil status discards events
+	var nilStatus *Status
+	nilStatus.AuthSucceeded(time.Hour)
+	nilStatus.CacheHit()
+	nilStatus.EmitGauges()
+}
+
+func TestHandler(t *testing.T) {
+	s, _ := testStatus(&StatusConfig{AutoAuth: true})
+	ts := httptest.NewServer(Handler(s))
+	defer ts.Close()
+
+	get := func() (int, *HealthResponse) {
+		t.Helper()
+		resp, err := http.Get(ts.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		health := new(HealthResponse)
+		if err := json.NewDecoder(resp.Body).Decode(health); err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, health
+	}
+
+	if code, health := get(); code != http.StatusServiceUnavailable || health.Healthy {
+		t.Fatalf("expected 503, got %d: %#v", code, health)
+	}
+
+	s.AuthSucceeded(time.Hour)
+	if code, health := get(); code != http.StatusOK || !health.Healthy || health.Auth.TokenTTL != 3600 {
+		t.Fatalf("expected 200, got %d: %#v", code, health)
+	}
+
+	resp, err := http.Post(ts.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", resp.StatusCode)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	inm := metrics.NewInmemSink(10*time.Second, time.Minute)
+	sink, err := prometheus.NewPrometheusSinkFrom(prometheus.PrometheusOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metricsConf := metrics.DefaultConfig("vault")
+	metricsConf.EnableHostname = false
+	if _, err := metrics.NewGlobal(metricsConf, metrics.FanoutSink{inm, sink}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewStatus(&StatusConfig{AutoAuth: true, Cache: true})
+	s.AuthSucceeded(time.Hour)
+	s.CacheHit()
+
+	ts := httptest.NewServer(MetricsHandler(s, metricsutil.NewMetricsHelper(inm, true)))
+	defer ts.Close()
+
+	get := func(url, accept string) (string, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Header.Get("Content-Type"), string(body)
+	}
+
+	contentType, body := get(ts.URL, "")
+	if contentType != "application/json" {
+		t.Fatalf("unexpected content type %q", contentType)
+	}
+	for _, name := range []string{"vault.agent.auth.success", "vault.agent.auth.token_ttl", "vault.agent.cache.hit"} {
+		if !strings.Contains(body, name) {
+			t.Fatalf("expected %q in metrics: %s", name, body)
+		}
+	}
+
+	for _, tc := range []struct {
+		url    string
+		accept string
+	}{
+		{ts.URL + "?format=prometheus", ""},
+		{ts.URL, metricsutil.OpenMetricsMIMEType},
+	} {
+		contentType, body := get(tc.url, tc.accept)
+		if !strings.HasPrefix(contentType, "text/plain") {
+			t.Fatalf("unexpected content type %q", contentType)
+		}
+		for _, name := range []string{"vault_agent_auth_success", "vault_agent_auth_token_ttl", "vault_agent_cache_hit_rate"} {
+			if !strings.Contains(body, name) {
+				t.Fatalf("expected %q in metrics: %s", name, body)
+			}
+		}
+	}
+
+	// Without a helper, metrics are not served
+	noMetrics := httptest.NewServer(MetricsHandler(s, nil))
+	defer noMetrics.Close()
+	resp, err := http.Get(noMetrics.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", resp.StatusCode)
+	}
+}
@@ -119,3 +119,43 @@ func TestSinkServerRetry(t *testing.T) {
 	cancelFunc()
 	<-ss.DoneCh
 }
+
+type closingSink struct {
+	closed uint32
+}
+
+func (c *closingSink) WriteToken(token string) error {
+	return nil
+}
+
+func (c *closingSink) Close() error {
+	atomic.StoreUint32(&c.closed, 1)
+	return nil
+}
+
+func TestSinkServerClosesSinks(t *testing.T) {
+	log := logging.NewVaultLogger(hclog.Trace)
+
+	c := &closingSink{}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	ss := sink.NewSinkServer(&sink.SinkServerConfig{
+		Logger: log.Named("sink.server"),
+	})
+
+	in := make(chan string)
+	go ss.Run(ctx, in, []*sink.SinkConfig{&sink.SinkConfig{Sink: c}})
+
+	in <- "token"
+	if atomic.LoadUint32(&c.closed) != 0 {
+		t.Fatal("expected sink to stay open while the server runs")
+	}
+
+	// Shutting down closes the sinks
+	cancelFunc()
+	<-ss.DoneCh
+	if atomic.LoadUint32(&c.closed) != 1 {
+		t.Fatal("expected sink to be closed")
+	}
+}
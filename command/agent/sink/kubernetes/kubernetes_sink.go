@@ -0,0 +1,117 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/errwrap"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/sink"
+	"github.com/hashicorp/vault/serviceregistration/kubernetes/client"
+)
+
+const defaultKey = "token"
+
+// kubernetesSink is a Sink implementation that writes a token to a key of a
+// Kubernetes secret, creating the secret if it does not exist
+type kubernetesSink struct {
+	logger    hclog.Logger
+	client    *client.Client
+	namespace string
+	name      string
+	key       string
+}
+
+// NewKubernetesSink creates a new Kubernetes secret sink with the given
+// configuration. The agent must be running in a pod whose service account is
+// allowed to get, create and patch the secret.
+func NewKubernetesSink(conf *sink.SinkConfig) (sink.Sink, error) {
+	if conf.Logger == nil {
+		return nil, errors.New("nil logger provided")
+	}
+
+	conf.Logger.Info("creating kubernetes sink")
+
+	k := &kubernetesSink{
+		logger: conf.Logger,
+		key:    defaultKey,
+	}
+
+	nameRaw, ok := conf.Config["name"]
+	if !ok {
+		return nil, errors.New("'name' not specified for kubernetes sink")
+	}
+	if k.name, ok = nameRaw.(string); !ok || k.name == "" {
+		return nil, errors.New("could not parse 'name' as string")
+	}
+
+	if keyRaw, ok := conf.Config["key"]; ok {
+		if k.key, ok = keyRaw.(string); !ok || k.key == "" {
+			return nil, errors.New("could not parse 'key' as string")
+		}
+	}
+
+	if namespaceRaw, ok := conf.Config["namespace"]; ok {
+		if k.namespace, ok = namespaceRaw.(string); !ok || k.namespace == "" {
+			return nil, errors.New("could not parse 'namespace' as string")
+		}
+	} else {
+		// Default to the namespace of the pod the agent is running in
+		namespace, err := client.InClusterNamespace()
+		if err != nil {
+			return nil, err
+		}
+		k.namespace = namespace
+	}
+
+	var err error
+	if k.client, err = client.New(conf.Logger); err != nil {
+		return nil, err
+	}
+
+	if err := k.WriteToken(""); err != nil {
+		return nil, errwrap.Wrapf("error during write check: {{err}}", err)
+	}
+
+	k.logger.Info("kubernetes sink configured", "namespace", k.namespace, "name", k.name, "key", k.key)
+
+	return k, nil
+}
+
+// WriteToken implements the Server interface and writes the token to the key
+// of the secret, leaving its other keys untouched. If a blank token is passed
+// in, it checks that the secret can be read but does not write to it.
+func (k *kubernetesSink) WriteToken(token string) error {
+	k.logger.Trace("enter write_token", "namespace", k.namespace, "name", k.name)
+	defer k.logger.Trace("exit write_token", "namespace", k.namespace, "name", k.name)
+
+	if token == "" {
+		_, err := k.client.GetSecret(k.namespace, k.name)
+		if _, ok := err.(*client.ErrNotFound); ok {
+			return nil
+		}
+		return err
+	}
+
+	data := map[string][]byte{
+		k.key: []byte(token),
+	}
+
+	err := k.client.PatchSecretData(k.namespace, k.name, data)
+	if _, ok := err.(*client.ErrNotFound); ok {
+		k.logger.Debug("secret not found, creating it", "namespace", k.namespace, "name", k.name)
+		err = k.client.CreateSecret(k.namespace, &client.Secret{
+			Metadata: &client.Metadata{
+				Name: k.name,
+			},
+			Type: "Opaque",
+			Data: data,
+		})
+	}
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("error writing to secret %s/%s: {{err}}", k.namespace, k.name), err)
+	}
+
+	k.logger.Info("token written", "namespace", k.namespace, "name", k.name)
+	return nil
+}
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/sink"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	kubetest "github.com/hashicorp/vault/serviceregistration/kubernetes/testing"
+)
+
+func TestKubernetesSink(t *testing.T) {
+	testState, closeFunc := kubetest.Server(t)
+	defer closeFunc()
+
+	config := &sink.SinkConfig{
+		Logger: logging.NewVaultLogger(hclog.Trace).Named("sink.kubernetes"),
+		Config: map[string]interface{}{
+			"name": "vault-token",
+		},
+	}
+	s, err := NewKubernetesSink(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The write check does not create the secret
+	if data := testState.Secret("vault-token"); data != nil {
+		t.Fatalf("expected no secret, got %v", data)
+	}
+
+	// The first write creates the secret in the pod's namespace
+	if err := s.WriteToken("s.first"); err != nil {
+		t.Fatal(err)
+	}
+	if data := testState.Secret("vault-token"); string(data["token"]) != "s.first" {
+		t.Fatalf("unexpected secret data: %v", data)
+	}
+
+	// Later writes update the key in place
+	if err := s.WriteToken("s.second"); err != nil {
+		t.Fatal(err)
+	}
+	if data := testState.Secret("vault-token"); string(data["token"]) != "s.second" {
+		t.Fatalf("unexpected secret data: %v", data)
+	}
+
+	// The service account token is read again after the kubelet rotates it
+	if err := testState.RotateToken("rotated-token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteToken("s.third"); err != nil {
+		t.Fatal(err)
+	}
+	if data := testState.Secret("vault-token"); string(data["token"]) != "s.third" {
+		t.Fatalf("unexpected secret data: %v", data)
+	}
+
+	// Other keys of the secret are left untouched
+	other, err := NewKubernetesSink(&sink.SinkConfig{
+		Logger: config.Logger,
+		Config: map[string]interface{}{
+			"namespace": kubetest.ExpectedNamespace,
+			"name":      "vault-token",
+			"key":       "wrapped",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.WriteToken("wrapped-token"); err != nil {
+		t.Fatal(err)
+	}
+	data := testState.Secret("vault-token")
+	if string(data["token"]) != "s.third" || string(data["wrapped"]) != "wrapped-token" {
+		t.Fatalf("unexpected secret data: %v", data)
+	}
+}
+
+func TestKubernetesSink_Config(t *testing.T) {
+	_, closeFunc := kubetest.Server(t)
+	defer closeFunc()
+
+	logger := logging.NewVaultLogger(hclog.Trace)
+
+	for name, config := range map[string]map[string]interface{}{
+		"no name":   {},
+		"bad key":   {"name": "vault-token", "key": 1},
+		"empty key": {"name": "vault-token", "key": ""},
+	} {
+		if _, err := NewKubernetesSink(&sink.SinkConfig{
+			Logger: logger,
+			Config: config,
+		}); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
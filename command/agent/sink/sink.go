@@ -3,15 +3,18 @@ package sink
 import (
 	"context"
 	"errors"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"strconv"
 	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/errwrap"
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/command/agent/health"
 	"github.com/hashicorp/vault/helper/dhutil"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
 )
@@ -43,6 +46,9 @@ type SinkServerConfig struct {
 	Client        *api.Client
 	Context       context.Context
 	ExitAfterAuth bool
+
+	// Status, if set, records the outcome of sink writes
+	Status *health.Status
 }
 
 // SinkServer is responsible for pushing tokens to sinks
@@ -53,6 +59,7 @@ type SinkServer struct {
 	random        *rand.Rand
 	exitAfterAuth bool
 	remaining     *int32
+	status        *health.Status
 }
 
 func NewSinkServer(conf *SinkServerConfig) *SinkServer {
@@ -63,6 +70,7 @@ func NewSinkServer(conf *SinkServerConfig) *SinkServer {
 		random:        rand.New(rand.NewSource(int64(time.Now().Nanosecond()))),
 		exitAfterAuth: conf.ExitAfterAuth,
 		remaining:     new(int32),
+		status:        conf.Status,
 	}
 
 	return ss
@@ -99,6 +107,15 @@ func (ss *SinkServer) Run(ctx context.Context, incoming chan string, sinks []*Si
 
 	ss.logger.Info("starting sink server")
 	defer func() {
+		// Sinks holding resources, such as a listening socket, release them
+		// once no more tokens are written
+		for _, s := range sinks {
+			if closer, ok := s.Sink.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					ss.logger.Error("error closing sink", "error", err)
+				}
+			}
+		}
 		ss.logger.Info("sink server stopped")
 		close(ss.DoneCh)
 	}()
@@ -108,6 +125,13 @@ func (ss *SinkServer) Run(ctx context.Context, incoming chan string, sinks []*Si
 		token string
 	}
 	sinkCh := make(chan sinkToken, len(sinks))
+
+	// Sinks are told apart by their position in the configuration when
+	// reporting their status
+	sinkNames := make(map[*SinkConfig]string, len(sinks))
+	for i, s := range sinks {
+		sinkNames[s] = strconv.Itoa(i)
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -143,7 +167,9 @@ func (ss *SinkServer) Run(ctx context.Context, incoming chan string, sinks []*Si
 			default:
 			}
 
-			if err := writeSink(st.sink, st.token); err != nil {
+			err := writeSink(st.sink, st.token)
+			ss.status.SinkWritten(sinkNames[st.sink], err)
+			if err != nil {
 				backoff := 2*time.Second + time.Duration(ss.random.Int63()%int64(time.Second*2)-int64(time.Second))
 				ss.logger.Error("error returned by sink function, retrying", "error", err, "backoff", backoff.String())
 				select {
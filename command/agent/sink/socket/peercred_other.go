@@ -0,0 +1,14 @@
+// +build !linux
+
+package socket
+
+import (
+	"errors"
+	"net"
+)
+
+const peerCredentialsSupported = false
+
+func peerUID(conn *net.UnixConn) (int, error) {
+	return 0, errors.New("peer credentials are not supported on this platform")
+}
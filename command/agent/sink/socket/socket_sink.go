@@ -0,0 +1,193 @@
+package socket
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/sink"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
+)
+
+// connTimeout bounds how long a peer may take to receive the token
+const connTimeout = 5 * time.Second
+
+// socketSink is a Sink implementation that serves the token over a unix
+// domain socket. Each connection receives the latest token and is closed,
+// provided the UID of the connecting process is in the allowlist.
+type socketSink struct {
+	path        string
+	mode        os.FileMode
+	allowedUIDs map[int]struct{}
+	logger      hclog.Logger
+	listener    net.Listener
+
+	l     sync.RWMutex
+	token string
+}
+
+// NewSocketSink creates a new socket sink with the given configuration, and
+// starts listening on its socket
+func NewSocketSink(conf *sink.SinkConfig) (sink.Sink, error) {
+	if conf.Logger == nil {
+		return nil, errors.New("nil logger provided")
+	}
+
+	if !peerCredentialsSupported {
+		return nil, errors.New("socket sink is not supported on this platform")
+	}
+
+	conf.Logger.Info("creating socket sink")
+
+	s := &socketSink{
+		logger: conf.Logger,
+		mode:   0600,
+	}
+
+	pathRaw, ok := conf.Config["path"]
+	if !ok {
+		return nil, errors.New("'path' not specified for socket sink")
+	}
+	path, ok := pathRaw.(string)
+	if !ok {
+		return nil, errors.New("could not parse 'path' as string")
+	}
+	s.path = path
+
+	if modeRaw, ok := conf.Config["mode"]; ok {
+		mode, typeOK := modeRaw.(int)
+		if !typeOK {
+			return nil, errors.New("could not parse 'mode' as integer")
+		}
+		s.mode = os.FileMode(mode)
+	}
+
+	// Default to the UID the agent runs as
+	s.allowedUIDs = map[int]struct{}{
+		os.Getuid(): struct{}{},
+	}
+	if uidsRaw, ok := conf.Config["allowed_uids"]; ok {
+		uids, err := parseutil.ParseCommaStringSlice(uidsRaw)
+		if err != nil {
+			return nil, errwrap.Wrapf("could not parse 'allowed_uids': {{err}}", err)
+		}
+		if len(uids) == 0 {
+			return nil, errors.New("'allowed_uids' must not be empty")
+		}
+		s.allowedUIDs = make(map[int]struct{}, len(uids))
+		for _, uidStr := range uids {
+			uid, err := strconv.Atoi(uidStr)
+			if err != nil || uid < 0 {
+				return nil, fmt.Errorf("invalid uid %q in 'allowed_uids'", uidStr)
+			}
+			s.allowedUIDs[uid] = struct{}{}
+		}
+	}
+
+	if err := s.listen(); err != nil {
+		return nil, err
+	}
+	go s.serve()
+
+	s.logger.Info("socket sink configured", "path", s.path, "mode", s.mode)
+
+	return s, nil
+}
+
+// listen creates the socket, replacing a stale one left by a previous run.
+func (s *socketSink) listen() error {
+	if fi, err := os.Lstat(s.path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s exists and is not a socket", s.path)
+		}
+		if err := os.Remove(s.path); err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("error removing stale socket %s: {{err}}", s.path), err)
+		}
+	}
+
+	ln, err := net.Listen("unix", s.path)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("error listening on %s: {{err}}", s.path), err)
+	}
+	if err := os.Chmod(s.path, s.mode); err != nil {
+		ln.Close()
+		return errwrap.Wrapf(fmt.Sprintf("error setting mode of %s: {{err}}", s.path), err)
+	}
+
+	s.listener = ln
+	return nil
+}
+
+func (s *socketSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Temporary() {
+				s.logger.Warn("error accepting connection", "error", err)
+				continue
+			}
+			s.logger.Debug("socket sink stopped", "path", s.path, "error", err)
+			return
+		}
+		go s.handle(conn.(*net.UnixConn))
+	}
+}
+
+// handle writes the token to the peer if its UID is allowed. Peers that are
+// not allowed, or connect before a token is available, receive nothing.
+func (s *socketSink) handle(conn *net.UnixConn) {
+	defer conn.Close()
+
+	uid, err := peerUID(conn)
+	if err != nil {
+		s.logger.Warn("error reading peer credentials", "error", err)
+		return
+	}
+	if _, ok := s.allowedUIDs[uid]; !ok {
+		s.logger.Warn("rejected connection from peer with disallowed uid", "uid", uid)
+		return
+	}
+
+	s.l.RLock()
+	token := s.token
+	s.l.RUnlock()
+	if token == "" {
+		s.logger.Debug("no token available yet", "uid", uid)
+		return
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(connTimeout))
+	if _, err := conn.Write([]byte(token)); err != nil {
+		s.logger.Warn("error writing token to peer", "uid", uid, "error", err)
+		return
+	}
+	s.logger.Trace("token served", "uid", uid)
+}
+
+// Close stops serving the token and removes the socket. It is called when the
+// sink server stops.
+func (s *socketSink) Close() error {
+	return s.listener.Close()
+}
+
+// WriteToken implements the Server interface and replaces the token served
+// on the socket. A blank token, used as a write check, is ignored since the
+// socket is created when the sink is.
+func (s *socketSink) WriteToken(token string) error {
+	if token == "" {
+		return nil
+	}
+
+	s.l.Lock()
+	s.token = token
+	s.l.Unlock()
+
+	s.logger.Info("token updated", "path", s.path)
+	return nil
+}
@@ -0,0 +1,170 @@
+// +build linux
+
+package socket
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/command/agent/sink"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+)
+
+func testSocketSink(t *testing.T, config map[string]interface{}) *socketSink {
+	t.Helper()
+
+	s, err := NewSocketSink(&sink.SinkConfig{
+		Logger: logging.NewVaultLogger(hclog.Trace).Named("sink.socket"),
+		Config: config,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.(*socketSink)
+}
+
+func readToken(t *testing.T, path string) string {
+	t.Helper()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	token, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(token)
+}
+
+func TestSocketSink(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vault-agent-socket-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "token.sock")
+
+	s := testSocketSink(t, map[string]interface{}{
+		"path": path,
+		"mode": 0660,
+	})
+	defer s.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != 0660 {
+		t.Fatalf("unexpected socket mode %v", fi.Mode())
+	}
+
+	// Nothing is served until a token is written
+	if token := readToken(t, path); token != "" {
+		t.Fatalf("expected no token, got %q", token)
+	}
+
+	if err := s.WriteToken("s.first"); err != nil {
+		t.Fatal(err)
+	}
+	if token := readToken(t, path); token != "s.first" {
+		t.Fatalf("expected token, got %q", token)
+	}
+
+	if err := s.WriteToken("s.second"); err != nil {
+		t.Fatal(err)
+	}
+	if token := readToken(t, path); token != "s.second" {
+		t.Fatalf("expected token, got %q", token)
+	}
+
+	// Closing the sink removes the socket
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket to be removed, got %v", err)
+	}
+}
+
+func TestSocketSink_DefaultMode(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vault-agent-socket-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "token.sock")
+
+	s := testSocketSink(t, map[string]interface{}{
+		"path": path,
+	})
+	defer s.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("expected socket to only be accessible by its owner, got %v", fi.Mode())
+	}
+}
+
+func TestSocketSink_AllowedUIDs(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "vault-agent-socket-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	path := filepath.Join(tmpDir, "token.sock")
+
+	// A stale socket is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	s := testSocketSink(t, map[string]interface{}{
+		"path":         path,
+		"allowed_uids": []interface{}{os.Getuid() + 1},
+	})
+	defer s.Close()
+
+	if err := s.WriteToken("s.token"); err != nil {
+		t.Fatal(err)
+	}
+	if token := readToken(t, path); token != "" {
+		t.Fatalf("expected disallowed peer to receive nothing, got %q", token)
+	}
+
+	// Regular files are not replaced
+	filePath := filepath.Join(tmpDir, "file")
+	if err := ioutil.WriteFile(filePath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSocketSink(&sink.SinkConfig{
+		Logger: logging.NewVaultLogger(hclog.Trace),
+		Config: map[string]interface{}{
+			"path": filePath,
+		},
+	}); err == nil {
+		t.Fatal("expected error for existing file")
+	}
+
+	if _, err := NewSocketSink(&sink.SinkConfig{
+		Logger: logging.NewVaultLogger(hclog.Trace),
+		Config: map[string]interface{}{
+			"path":         filepath.Join(tmpDir, "other.sock"),
+			"allowed_uids": []interface{}{"root"},
+		},
+	}); err == nil {
+		t.Fatal("expected error for invalid uid")
+	}
+}
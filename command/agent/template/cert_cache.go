@@ -0,0 +1,367 @@
+package template
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	hclog "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// certCacheConfig is the configuration for creating a new certCache
+type certCacheConfig struct {
+	Logger hclog.Logger
+	Client *api.Client
+
+	// Threshold is the fraction of a certificate's validity after which it is
+	// issued again.
+	Threshold float64
+
+	// Path, if set, is the file the cache is persisted to, so that
+	// certificates survive restarts of the agent.
+	Path string
+}
+
+// certCache is a local proxy between the Consul Template runner and Vault. It
+// caches responses to writes that issue certificates, such as pki/issue, and
+// serves them again until the configured fraction of the certificate's
+// validity has elapsed. Without it, every restart of the runner, which
+// happens whenever the agent obtains a new token, issues new certificates and
+// re-renders the templates using them.
+type certCache struct {
+	logger    hclog.Logger
+	client    *api.Client
+	threshold float64
+	path      string
+
+	listener net.Listener
+	server   *http.Server
+
+	// renewCh receives when a cached certificate reaches its renewal time,
+	// and the templates using it need to fetch a new one.
+	renewCh chan struct{}
+
+	l       sync.Mutex
+	token   string
+	entries map[string]*certCacheEntry
+	timers  map[string]*time.Timer
+}
+
+// certCacheEntry is a cached response, and the time after which it is no
+// longer served.
+type certCacheEntry struct {
+	Response  json.RawMessage `json:"response"`
+	RenewTime time.Time       `json:"renew_time"`
+}
+
+// newCertCache loads the persisted cache, if any, and starts serving on a
+// loopback address.
+func newCertCache(conf *certCacheConfig) (*certCache, error) {
+	if conf.Threshold <= 0 || conf.Threshold > 1 {
+		return nil, fmt.Errorf("invalid certificate renewal threshold %v", conf.Threshold)
+	}
+
+	c := &certCache{
+		logger:    conf.Logger,
+		client:    conf.Client,
+		threshold: conf.Threshold,
+		path:      conf.Path,
+		renewCh:   make(chan struct{}, 1),
+		entries:   make(map[string]*certCacheEntry),
+		timers:    make(map[string]*time.Timer),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		c.stopTimers()
+		return nil, errwrap.Wrapf("error starting certificate cache listener: {{err}}", err)
+	}
+	c.listener = ln
+	c.server = &http.Server{
+		Handler:           c,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go c.server.Serve(ln)
+
+	return c, nil
+}
+
+// Address returns the address the runner uses to reach Vault.
+func (c *certCache) Address() string {
+	return "http://" + c.listener.Addr().String()
+}
+
+// SetToken sets the token requests must carry. As the proxy listens on a
+// loopback address, only the runner, which holds the agent's token, may use
+// it and be served cached certificates.
+func (c *certCache) SetToken(token string) {
+	c.l.Lock()
+	defer c.l.Unlock()
+	c.token = token
+}
+
+// Stop stops the proxy and the renewal timers.
+func (c *certCache) Stop() {
+	c.server.Close()
+	c.stopTimers()
+}
+
+func (c *certCache) stopTimers() {
+	c.l.Lock()
+	defer c.l.Unlock()
+	for _, timer := range c.timers {
+		timer.Stop()
+	}
+}
+
+func (c *certCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.l.Lock()
+	token := c.token
+	c.l.Unlock()
+	if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get(consts.AuthHeaderName)), []byte(token)) != 1 {
+		logical.RespondError(w, http.StatusForbidden, logical.ErrPermissionDenied)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		logical.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Certificates are only issued by writes
+	cacheable := r.Method == http.MethodPut || r.Method == http.MethodPost
+	var key string
+	if cacheable {
+		key = certCacheKey(r, body)
+		if resp := c.get(key); resp != nil {
+			c.logger.Debug("serving cached certificate", "path", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(resp)
+			return
+		}
+	}
+
+	req := c.client.NewRequest(r.Method, r.URL.Path)
+	req.ClientToken = token
+	req.Params = r.URL.Query()
+	req.BodyBytes = body
+	if req.Headers == nil {
+		req.Headers = make(http.Header)
+	}
+	for k, v := range r.Header {
+		if k == consts.AuthHeaderName {
+			continue
+		}
+		req.Headers[k] = v
+	}
+
+	// Errors are returned to the runner as is, along with the response
+	resp, err := c.client.RawRequestWithContext(r.Context(), req)
+	if resp == nil {
+		logical.RespondError(w, http.StatusBadGateway, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		logical.RespondError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	if cacheable && resp.StatusCode == http.StatusOK {
+		c.store(key, r.URL.Path, respBody)
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody)
+}
+
+// certCacheKey identifies a write by its namespace, path and parameters.
+func certCacheKey(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Header.Get(consts.NamespaceHeaderName)))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *certCache) get(key string) []byte {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !time.Now().Before(entry.RenewTime) {
+		return nil
+	}
+	return entry.Response
+}
+
+// store caches the response if it contains a certificate without a lease,
+// until the threshold of the certificate's validity.
+func (c *certCache) store(key, path string, body []byte) {
+	secret, err := api.ParseSecret(bytes.NewReader(body))
+	if err != nil || secret == nil || secret.LeaseID != "" {
+		return
+	}
+	certPEM, ok := secret.Data["certificate"].(string)
+	if !ok {
+		return
+	}
+	renewTime, err := certRenewTime(certPEM, c.threshold)
+	if err != nil {
+		c.logger.Warn("failed to parse issued certificate; not caching it", "path", path, "error", err)
+		return
+	}
+	if !time.Now().Before(renewTime) {
+		return
+	}
+
+	c.logger.Debug("caching issued certificate", "path", path, "renew_time", renewTime)
+
+	c.l.Lock()
+	defer c.l.Unlock()
+	entry := &certCacheEntry{
+		Response:  body,
+		RenewTime: renewTime,
+	}
+	c.entries[key] = entry
+	c.scheduleRenewal(key, entry)
+	c.persist()
+}
+
+// scheduleRenewal evicts the entry at its renewal time, and notifies the
+// server so the runner fetches a new certificate. It must be called with the
+// lock held.
+func (c *certCache) scheduleRenewal(key string, entry *certCacheEntry) {
+	if timer, ok := c.timers[key]; ok {
+		timer.Stop()
+	}
+	c.timers[key] = time.AfterFunc(time.Until(entry.RenewTime), func() {
+		c.l.Lock()
+		if c.entries[key] != entry {
+			c.l.Unlock()
+			return
+		}
+		delete(c.entries, key)
+		delete(c.timers, key)
+		c.persist()
+		c.l.Unlock()
+
+		c.logger.Debug("cached certificate reached its renewal time")
+		select {
+		case c.renewCh <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// certRenewTime returns the time after which a certificate is issued again.
+func certRenewTime(certPEM string, threshold float64) (time.Time, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return time.Time{}, errors.New("no PEM data found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	validity := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotBefore.Add(time.Duration(float64(validity) * threshold)), nil
+}
+
+// load reads the persisted cache, dropping entries past their renewal time.
+func (c *certCache) load() error {
+	if c.path == "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errwrap.Wrapf("error reading certificate cache: {{err}}", err)
+	}
+
+	var entries map[string]*certCacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return errwrap.Wrapf("error parsing certificate cache: {{err}}", err)
+	}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+	now := time.Now()
+	for key, entry := range entries {
+		if entry == nil || !now.Before(entry.RenewTime) {
+			continue
+		}
+		c.entries[key] = entry
+		c.scheduleRenewal(key, entry)
+	}
+	c.logger.Debug("loaded certificate cache", "path", c.path, "entries", len(c.entries))
+
+	return nil
+}
+
+// persist writes the cache to disk. As it holds private keys, the file is
+// only readable by the agent. It must be called with the lock held.
+func (c *certCache) persist() {
+	if c.path == "" {
+		return
+	}
+
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		c.logger.Error("failed to encode certificate cache", "error", err)
+		return
+	}
+
+	// Temporary files are created with 0600 permissions
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".tmp")
+	if err != nil {
+		c.logger.Error("failed to persist certificate cache", "error", err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		c.logger.Error("failed to persist certificate cache", "path", c.path, "error", err)
+	}
+}
@@ -0,0 +1,280 @@
+package template
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ctconfig "github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/command/agent/config"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/hashicorp/vault/sdk/helper/pointerutil"
+)
+
+// testPKIServer returns a server issuing a new certificate, valid from
+// notBefore to notAfter relative to the time it is issued, on every write to
+// pki/issue/, and the number of issued certificates.
+func testPKIServer(t *testing.T, notBefore, notAfter time.Duration) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var issued int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintln(w, `{"errors": ["permission denied"]}`)
+			return
+		}
+		if r.Method == http.MethodGet {
+			fmt.Fprintln(w, jsonResponse)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, "/v1/pki/issue/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		serial := atomic.AddInt32(&issued, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id":       "",
+			"lease_duration": 0,
+			"renewable":      false,
+			"data": map[string]interface{}{
+				"certificate":   testCertificate(t, int64(serial), notBefore, notAfter),
+				"serial_number": fmt.Sprintf("%d", serial),
+			},
+		})
+	}))
+
+	return ts, &issued
+}
+
+func testCertificate(t *testing.T, serial int64, notBefore, notAfter time.Duration) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		NotBefore:    now.Add(notBefore),
+		NotAfter:     now.Add(notAfter),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func testCertCache(t *testing.T, address string, threshold float64, path string) *certCache {
+	t.Helper()
+
+	apiConfig := api.DefaultConfig()
+	apiConfig.Address = address
+	client, err := api.NewClient(apiConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := newCertCache(&certCacheConfig{
+		Logger:    logging.NewVaultLogger(hclog.Trace),
+		Client:    client,
+		Threshold: threshold,
+		Path:      path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetToken("test")
+	return c
+}
+
+func testIssue(t *testing.T, c *certCache, token string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPut, c.Address()+"/v1/pki/issue/example", strings.NewReader(`{"common_name":"example.com"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := body.Data["serial_number"].(string)
+	return resp.StatusCode, serial
+}
+
+func TestCertCache(t *testing.T) {
+	ts, issued := testPKIServer(t, 0, time.Hour)
+	defer ts.Close()
+
+	c := testCertCache(t, ts.URL, 0.9, "")
+	defer c.Stop()
+
+	// The certificate is issued once, and served from the cache afterwards
+	for i := 0; i < 3; i++ {
+		if code, serial := testIssue(t, c, "test"); code != http.StatusOK || serial != "1" {
+			t.Fatalf("unexpected response %d %q", code, serial)
+		}
+	}
+	if atomic.LoadInt32(issued) != 1 {
+		t.Fatalf("expected 1 issued certificate, got %d", atomic.LoadInt32(issued))
+	}
+
+	// Only the agent's token may use the proxy
+	if code, _ := testIssue(t, c, "other"); code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", code)
+	}
+
+	// Reads are passed through
+	req, err := http.NewRequest(http.MethodGet, c.Address()+"/v1/kv/myapp/config", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Vault-Token", "test")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestCertCache_Renewal(t *testing.T) {
+	// The certificate reaches 90% of its validity two seconds after it is
+	// issued
+	ts, issued := testPKIServer(t, -16*time.Second, 4*time.Second)
+	defer ts.Close()
+
+	c := testCertCache(t, ts.URL, 0.9, "")
+	defer c.Stop()
+
+	if _, serial := testIssue(t, c, "test"); serial != "1" {
+		t.Fatalf("unexpected serial %q", serial)
+	}
+
+	// Once the threshold is reached, the server is notified and
+	// the certificate is issued again
+	select {
+	case <-c.renewCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for renewal")
+	}
+	if _, serial := testIssue(t, c, "test"); serial != "2" {
+		t.Fatalf("unexpected serial %q", serial)
+	}
+	if atomic.LoadInt32(issued) != 2 {
+		t.Fatalf("expected 2 issued certificates, got %d", atomic.LoadInt32(issued))
+	}
+}
+
+func TestServerRun_CertCache(t *testing.T) {
+	ts, issued := testPKIServer(t, 0, time.Hour)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "agent-tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dest := filepath.Join(tmpDir, "cert")
+
+	apiConfig := api.DefaultConfig()
+	apiConfig.Address = ts.URL
+	client, err := api.NewClient(apiConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each run stands for a restart of the agent, which reuses the persisted
+	// certificate
+	for i := 0; i < 2; i++ {
+		server := NewServer(&ServerConfig{
+			Logger: logging.NewVaultLogger(hclog.Trace),
+			Client: client,
+			TemplateConfig: &config.TemplateConfig{
+				CertRenewalThreshold: config.DefaultCertRenewalThreshold,
+				CertCachePath:        filepath.Join(tmpDir, "cert-cache.json"),
+			},
+			VaultConf: &config.Vault{
+				Address: ts.URL,
+			},
+			Owners: []*config.TemplateOwner{
+				{
+					Destination: dest,
+					UID:         os.Getuid(),
+					GID:         os.Getgid(),
+				},
+			},
+			LogLevel:      hclog.Trace,
+			LogWriter:     hclog.DefaultOutput,
+			ExitAfterAuth: true,
+		})
+
+		templateTokenCh := make(chan string, 1)
+		go server.Run(context.Background(), templateTokenCh, []*ctconfig.TemplateConfig{
+			{
+				Contents:    pointerutil.StringPtr(`{{ with secret "pki/issue/example" "common_name=example.com" }}{{ .Data.serial_number }}{{ end }}`),
+				Destination: pointerutil.StringPtr(dest),
+				Perms:       pointerutil.FileModePtr(0600),
+			},
+		})
+		templateTokenCh <- "test"
+		<-server.DoneCh
+
+		content, err := ioutil.ReadFile(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != "1" {
+			t.Fatalf("unexpected serial %q", content)
+		}
+		// The file is only written, and chowned, on the first run, as its
+		// contents are unchanged afterwards
+		if _, ok := server.chowned[dest]; ok != (i == 0) {
+			t.Fatalf("unexpected destination ownership state on run %d", i)
+		}
+	}
+
+	if atomic.LoadInt32(issued) != 1 {
+		t.Fatalf("expected 1 issued certificate, got %d", atomic.LoadInt32(issued))
+	}
+
+	fi, err := os.Stat(filepath.Join(tmpDir, "cert-cache.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("unexpected cache file mode %v", fi.Mode())
+	}
+}
@@ -8,13 +8,17 @@ package template
 import (
 	"context"
 	"io"
+	"os"
 	"strings"
+	"time"
 
 	ctconfig "github.com/hashicorp/consul-template/config"
 	ctlogging "github.com/hashicorp/consul-template/logging"
 	"github.com/hashicorp/consul-template/manager"
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/command/agent/config"
+	"github.com/hashicorp/vault/command/agent/health"
 	"github.com/hashicorp/vault/sdk/helper/pointerutil"
 )
 
@@ -22,10 +26,19 @@ import (
 // Server
 type ServerConfig struct {
 	Logger hclog.Logger
-	// Client        *api.Client
+
+	// Client, if set, is used to reach Vault through a local proxy that
+	// caches issued certificates, configured by TemplateConfig. Otherwise
+	// the runner connects to Vault directly.
+	Client         *api.Client
+	TemplateConfig *config.TemplateConfig
+
 	VaultConf     *config.Vault
 	ExitAfterAuth bool
 
+	// Owners is the ownership set on template destinations once rendered
+	Owners []*config.TemplateOwner
+
 	Namespace string
 
 	// LogLevel is needed to set the internal Consul Template Runner's log level
@@ -36,6 +49,9 @@ type ServerConfig struct {
 	// the same io.Writer that Vault Agent itself is using.
 	LogLevel  hclog.Level
 	LogWriter io.Writer
+
+	// Status, if set, records template renders and errors
+	Status *health.Status
 }
 
 // Server manages the Consul Template Runner which renders templates
@@ -55,9 +71,15 @@ type Server struct {
 	// from the runner in the event we're using exit after auth.
 	lookupMap map[string][]*ctconfig.TemplateConfig
 
+	// owners is the ownership of template destinations, indexed by
+	// destination, and chowned the time they were last chowned
+	owners  map[string]*config.TemplateOwner
+	chowned map[string]time.Time
+
 	DoneCh        chan struct{}
 	logger        hclog.Logger
 	exitAfterAuth bool
+	status        *health.Status
 }
 
 // NewServer returns a new configured server
@@ -67,6 +89,12 @@ func NewServer(conf *ServerConfig) *Server {
 		logger:        conf.Logger,
 		config:        conf,
 		exitAfterAuth: conf.ExitAfterAuth,
+		status:        conf.Status,
+		owners:        make(map[string]*config.TemplateOwner, len(conf.Owners)),
+		chowned:       make(map[string]time.Time),
+	}
+	for _, owner := range conf.Owners {
+		ts.owners[owner.Destination] = owner
 	}
 	return &ts
 }
@@ -97,15 +125,52 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 	// configuration
 	var runnerConfig *ctconfig.Config
 	var runnerConfigErr error
-	if runnerConfig, runnerConfigErr = newRunnerConfig(ts.config, templates); runnerConfigErr != nil {
+	if runnerConfig, runnerConfigErr = NewRunnerConfig(ts.config, templates); runnerConfigErr != nil {
 		ts.logger.Error("template server failed to generate runner config", "error", runnerConfigErr)
+		ts.status.TemplateRendered(runnerConfigErr)
 		return
 	}
 
+	// Route the runner's requests through the certificate cache
+	var certs *certCache
+	var certRenewCh <-chan struct{}
+	if ts.config.Client != nil {
+		certConf := &certCacheConfig{
+			Logger:    ts.logger.Named("cert_cache"),
+			Client:    ts.config.Client,
+			Threshold: config.DefaultCertRenewalThreshold,
+		}
+		if tc := ts.config.TemplateConfig; tc != nil {
+			certConf.Threshold = tc.CertRenewalThreshold
+			certConf.Path = tc.CertCachePath
+		}
+		var err error
+		if certs, err = newCertCache(certConf); err != nil {
+			ts.logger.Error("template server failed to create certificate cache", "error", err)
+			ts.status.TemplateRendered(err)
+			return
+		}
+		defer certs.Stop()
+		certRenewCh = certs.renewCh
+
+		address := certs.Address()
+		runnerConfig.Vault.Address = &address
+		runnerConfig.Vault.SSL = &ctconfig.SSLConfig{
+			Enabled:    pointerutil.BoolPtr(false),
+			Verify:     pointerutil.BoolPtr(false),
+			Cert:       pointerutil.StringPtr(""),
+			Key:        pointerutil.StringPtr(""),
+			CaCert:     pointerutil.StringPtr(""),
+			CaPath:     pointerutil.StringPtr(""),
+			ServerName: pointerutil.StringPtr(""),
+		}
+	}
+
 	var err error
 	ts.runner, err = manager.NewRunner(runnerConfig, false)
 	if err != nil {
 		ts.logger.Error("template server failed to create", "error", err)
+		ts.status.TemplateRendered(err)
 		return
 	}
 
@@ -135,28 +200,36 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 		case token := <-incoming:
 			if token != *latestToken {
 				ts.logger.Info("template server received new token")
-				ts.runner.Stop()
 				*latestToken = token
+				if certs != nil {
+					certs.SetToken(token)
+				}
 				ctv := ctconfig.Config{
 					Vault: &ctconfig.VaultConfig{
 						Token: latestToken,
 					},
 				}
 				runnerConfig = runnerConfig.Merge(&ctv)
-				var runnerErr error
-				ts.runner, runnerErr = manager.NewRunner(runnerConfig, false)
-				if runnerErr != nil {
-					ts.logger.Error("template server failed with new Vault token", "error", runnerErr)
-					continue
-				}
-				go ts.runner.Start()
+				ts.restartRunner(runnerConfig)
+			}
+		case <-certRenewCh:
+			// Restarting the runner makes it issue the certificate again, while
+			// those that have not reached their renewal time are served from
+			// the cache.
+			if *latestToken != "" {
+				ts.logger.Info("cached certificate reached its renewal time; restarting template runner")
+				ts.restartRunner(runnerConfig)
 			}
 		case err := <-ts.runner.ErrCh:
 			ts.logger.Error("template server error", "error", err.Error())
+			ts.status.TemplateRendered(err)
 			return
 		case <-ts.runner.TemplateRenderedCh():
+			ts.status.TemplateRendered(nil)
+
 			// A template has been rendered, figure out what to do
 			events := ts.runner.RenderEvents()
+			ts.chownDestinations(events)
 
 			// events are keyed by template ID, and can be matched up to the id's from
 			// the lookupMap
@@ -185,9 +258,53 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 	}
 }
 
-// newRunnerConfig returns a consul-template runner configuration, setting the
+// restartRunner replaces the runner with a new one using runnerConfig
+func (ts *Server) restartRunner(runnerConfig *ctconfig.Config) {
+	ts.runner.Stop()
+	runner, err := manager.NewRunner(runnerConfig, false)
+	if err != nil {
+		ts.logger.Error("template server failed with new Vault token", "error", err)
+		ts.status.TemplateRendered(err)
+		return
+	}
+	ts.runner = runner
+	go ts.runner.Start()
+}
+
+// chownDestinations sets the configured ownership on the destinations of
+// templates written to disk since they were last chowned. Consul Template
+// writes each render to a new file, owned by the agent.
+func (ts *Server) chownDestinations(events map[string]*manager.RenderEvent) {
+	if len(ts.owners) == 0 {
+		return
+	}
+
+	for _, event := range events {
+		if event.LastDidRender.IsZero() {
+			continue
+		}
+		for _, tc := range event.TemplateConfigs {
+			if tc.Destination == nil {
+				continue
+			}
+			dest := *tc.Destination
+			owner, ok := ts.owners[dest]
+			if !ok || !event.LastDidRender.After(ts.chowned[dest]) {
+				continue
+			}
+			if err := os.Chown(dest, owner.UID, owner.GID); err != nil {
+				ts.logger.Error("failed to set template destination ownership", "destination", dest, "error", err)
+				ts.status.TemplateRendered(err)
+				continue
+			}
+			ts.chowned[dest] = event.LastDidRender
+		}
+	}
+}
+
+// NewRunnerConfig returns a consul-template runner configuration, setting the
 // Vault and Consul configurations based on the clients configs.
-func newRunnerConfig(sc *ServerConfig, templates ctconfig.TemplateConfigs) (*ctconfig.Config, error) {
+func NewRunnerConfig(sc *ServerConfig, templates ctconfig.TemplateConfigs) (*ctconfig.Config, error) {
 	conf := ctconfig.DefaultConfig()
 	conf.Templates = templates.Copy()
 
@@ -2,12 +2,18 @@ package command
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/errwrap"
@@ -29,6 +35,10 @@ var _ cli.CommandAutocomplete = (*OperatorMigrateCommand)(nil)
 
 var errAbort = errors.New("Migration aborted")
 
+// migrationCheckpointInterval is the number of processed keys after which the
+// checkpoint file is written out.
+const migrationCheckpointInterval = 1000
+
 type OperatorMigrateCommand struct {
 	*BaseCommand
 
@@ -36,8 +46,41 @@ type OperatorMigrateCommand struct {
 	flagConfig       string
 	flagStart        string
 	flagReset        bool
+	flagMaxParallel  int
+	flagCheckpoint   string
+	flagDryRun       bool
+	flagVerify       bool
 	logger           log.Logger
 	ShutdownCh       chan struct{}
+
+	// checkpoint holds the keys already copied to the destination. It is nil
+	// when no checkpoint file is in use.
+	checkpoint *migrationCheckpoint
+	stats      migrationStats
+}
+
+// migrationCheckpoint records the checksum of every value written to the
+// destination. Rerunning a migration with the same checkpoint only copies keys
+// that are new or changed in the source and deletes keys that were removed
+// from it, which makes interrupted migrations resumable and allows a final
+// incremental sync.
+type migrationCheckpoint struct {
+	Started time.Time         `json:"started"`
+	Updated time.Time         `json:"updated"`
+	Keys    map[string]string `json:"keys"`
+
+	path  string
+	l     sync.Mutex
+	dirty int
+}
+
+// migrationStats counts what a migration pass did.
+type migrationStats struct {
+	copied   uint64
+	skipped  uint64
+	deleted  uint64
+	bytes    uint64
+	verified uint64
 }
 
 type migratorConfig struct {
@@ -62,6 +105,12 @@ Usage: vault operator migrate [options]
 
       $ vault operator migrate -config=migrate.hcl
 
+  Copy the bulk of the data while the source is still in use, then stop Vault
+  and sync only the keys that changed since:
+
+      $ vault operator migrate -config=migrate.hcl -checkpoint=migrate.ckpt
+      $ vault operator migrate -config=migrate.hcl -checkpoint=migrate.ckpt
+
   For more information, please see the documentation.
 
 ` + c.Flags().Help()
@@ -95,6 +144,37 @@ func (c *OperatorMigrateCommand) Flags() *FlagSets {
 		Usage:  "Reset the migration lock. No migration will occur.",
 	})
 
+	f.IntVar(&IntVar{
+		Name:    "max-parallel",
+		Target:  &c.flagMaxParallel,
+		Default: 10,
+		Usage:   "Maximum number of keys copied concurrently.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "checkpoint",
+		Target:     &c.flagCheckpoint,
+		Completion: complete.PredictFiles("*"),
+		Usage: "Path to a checkpoint file recording the keys already copied. " +
+			"Rerunning with the same checkpoint resumes an interrupted " +
+			"migration, or syncs only the keys changed since the last run.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:   "dry-run",
+		Target: &c.flagDryRun,
+		Usage: "Report the keys that would be copied or deleted without " +
+			"writing to the destination or the checkpoint.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "verify",
+		Target:  &c.flagVerify,
+		Default: true,
+		Usage: "After copying, compare the checksum of every key in the " +
+			"destination with the source.",
+	})
+
 	return set
 }
 
@@ -134,10 +214,18 @@ func (c *OperatorMigrateCommand) Run(args []string) int {
 		return 2
 	}
 
-	if c.flagReset {
+	switch {
+	case c.flagReset:
 		c.UI.Output("Success! Migration lock reset (if it was set).")
-	} else {
-		c.UI.Output("Success! All of the keys have been migrated.")
+	case c.flagDryRun:
+		c.UI.Output(fmt.Sprintf("Dry run complete: %d keys (%d bytes) would be copied, %d deleted and %d are unchanged.",
+			c.stats.copied, c.stats.bytes, c.stats.deleted, c.stats.skipped))
+	default:
+		c.UI.Output(fmt.Sprintf("Success! All of the keys have been migrated (%d copied, %d deleted, %d unchanged).",
+			c.stats.copied, c.stats.deleted, c.stats.skipped))
+		if c.flagVerify {
+			c.UI.Output(fmt.Sprintf("Verified %d keys.", c.stats.verified))
+		}
 	}
 
 	return 0
@@ -158,6 +246,19 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 		return nil
 	}
 
+	if c.flagCheckpoint != "" {
+		checkpoint, err := loadMigrationCheckpoint(c.flagCheckpoint)
+		if err != nil {
+			return errwrap.Wrapf("error loading checkpoint: {{err}}", err)
+		}
+		c.checkpoint = checkpoint
+	}
+
+	// A dry run only reads the source and the checkpoint
+	if c.flagDryRun {
+		return c.migrateAll(context.Background(), from, nil)
+	}
+
 	to, err := c.createDestinationBackend(config.StorageDestination.Type, config.StorageDestination.Config, config)
 	if err != nil {
 		return errwrap.Wrapf("error mounting 'storage_destination': {{err}}", err)
@@ -182,7 +283,11 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 
 	doneCh := make(chan error)
 	go func() {
-		doneCh <- c.migrateAll(ctx, from, to)
+		err := c.migrateAll(ctx, from, to)
+		if err == nil && c.flagVerify && ctx.Err() == nil {
+			err = c.verifyAll(ctx, from, to)
+		}
+		doneCh <- err
 	}()
 
 	select {
@@ -197,15 +302,35 @@ func (c *OperatorMigrateCommand) migrate(config *migratorConfig) error {
 	}
 }
 
-// migrateAll copies all keys in lexicographic order.
+// skipKey returns whether path is excluded from the migration.
+func (c *OperatorMigrateCommand) skipKey(path string) bool {
+	return path < c.flagStart || path == storageMigrationLock || path == vault.CoreLockPath
+}
+
+// migrateAll copies all keys from the source to the destination. If a
+// checkpoint is in use, keys whose value is unchanged since they were last
+// copied are skipped and keys that no longer exist in the source are deleted
+// from the destination. A nil destination performs a dry run.
 func (c *OperatorMigrateCommand) migrateAll(ctx context.Context, from physical.Backend, to physical.Backend) error {
-	return dfsScan(ctx, from, func(ctx context.Context, path string) error {
-		if path < c.flagStart || path == storageMigrationLock || path == vault.CoreLockPath {
+	// Persist the checkpoint even if the copy fails or is aborted so the
+	// next run can resume.
+	if c.checkpoint != nil && to != nil {
+		defer func() {
+			if err := c.checkpoint.save(); err != nil {
+				c.logger.Error("error writing checkpoint", "error", err)
+			}
+		}()
+	}
+
+	seen := make(map[string]struct{})
+	var seenLock sync.Mutex
+
+	err := c.scanParallel(ctx, from, func(ctx context.Context, path string) error {
+		if c.skipKey(path) {
 			return nil
 		}
 
 		entry, err := from.Get(ctx, path)
-
 		if err != nil {
 			return errwrap.Wrapf("error reading entry: {{err}}", err)
 		}
@@ -214,12 +339,266 @@ func (c *OperatorMigrateCommand) migrateAll(ctx context.Context, from physical.B
 			return nil
 		}
 
+		sum := migrationChecksum(entry.Value)
+		if c.checkpoint != nil {
+			seenLock.Lock()
+			seen[path] = struct{}{}
+			seenLock.Unlock()
+
+			if c.checkpoint.get(path) == sum {
+				atomic.AddUint64(&c.stats.skipped, 1)
+				return nil
+			}
+		}
+
+		atomic.AddUint64(&c.stats.copied, 1)
+		atomic.AddUint64(&c.stats.bytes, uint64(len(entry.Value)))
+		if to == nil {
+			c.logger.Info("would copy key", "path", path)
+			return nil
+		}
+
 		if err := to.Put(ctx, entry); err != nil {
 			return errwrap.Wrapf("error writing entry: {{err}}", err)
 		}
 		c.logger.Info("copied key", "path", path)
+
+		if c.checkpoint != nil {
+			if err := c.checkpoint.set(path, sum); err != nil {
+				return errwrap.Wrapf("error writing checkpoint: {{err}}", err)
+			}
+		}
+		return nil
+	})
+	if err != nil || ctx.Err() != nil || c.checkpoint == nil {
+		return err
+	}
+
+	// Remove keys that were copied by an earlier run but have since been
+	// deleted from the source.
+	for _, path := range c.checkpoint.paths() {
+		if c.skipKey(path) {
+			continue
+		}
+		if _, ok := seen[path]; ok {
+			continue
+		}
+
+		c.stats.deleted++
+		if to == nil {
+			c.logger.Info("would delete key", "path", path)
+			continue
+		}
+
+		if err := to.Delete(ctx, path); err != nil {
+			return errwrap.Wrapf("error deleting entry: {{err}}", err)
+		}
+		c.logger.Info("deleted key", "path", path)
+		if err := c.checkpoint.set(path, ""); err != nil {
+			return errwrap.Wrapf("error writing checkpoint: {{err}}", err)
+		}
+	}
+
+	return nil
+}
+
+// verifyAll compares the checksum of every migrated key in the destination
+// with the source.
+func (c *OperatorMigrateCommand) verifyAll(ctx context.Context, from physical.Backend, to physical.Backend) error {
+	var mismatched []string
+	var mismatchedLock sync.Mutex
+
+	err := c.scanParallel(ctx, from, func(ctx context.Context, path string) error {
+		if c.skipKey(path) {
+			return nil
+		}
+
+		src, err := from.Get(ctx, path)
+		if err != nil {
+			return errwrap.Wrapf("error reading source entry: {{err}}", err)
+		}
+		if src == nil {
+			return nil
+		}
+
+		dst, err := to.Get(ctx, path)
+		if err != nil {
+			return errwrap.Wrapf("error reading destination entry: {{err}}", err)
+		}
+
+		if dst == nil || migrationChecksum(src.Value) != migrationChecksum(dst.Value) {
+			c.logger.Error("checksum mismatch", "path", path)
+			mismatchedLock.Lock()
+			mismatched = append(mismatched, path)
+			mismatchedLock.Unlock()
+			return nil
+		}
+
+		atomic.AddUint64(&c.stats.verified, 1)
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return fmt.Errorf("verification failed for %d keys, first mismatch: %q", len(mismatched), mismatched[0])
+	}
+
+	return nil
+}
+
+// scanParallel invokes cb with every key from source using up to
+// -max-parallel concurrent workers. The first error stops the scan.
+func (c *OperatorMigrateCommand) scanParallel(ctx context.Context, source physical.Backend, cb func(ctx context.Context, path string) error) error {
+	workers := c.flagMaxParallel
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var once sync.Once
+	var firstErr error
+	setErr := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	paths := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				if err := cb(ctx, path); err != nil {
+					setErr(err)
+				}
+			}
+		}()
+	}
+
+	err := dfsScan(ctx, source, func(ctx context.Context, path string) error {
+		select {
+		case paths <- path:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	close(paths)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	return firstErr
+}
+
+func migrationChecksum(value []byte) string {
+	sum := sha256.Sum256(value)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadMigrationCheckpoint reads the checkpoint at path, or returns an empty
+// checkpoint if the file does not exist yet.
+func loadMigrationCheckpoint(path string) (*migrationCheckpoint, error) {
+	checkpoint := &migrationCheckpoint{
+		path: path,
+	}
+
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		checkpoint.Started = time.Now().UTC()
+		checkpoint.Keys = make(map[string]string)
+		return checkpoint, nil
+	case err != nil:
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, err
+	}
+	if checkpoint.Keys == nil {
+		checkpoint.Keys = make(map[string]string)
+	}
+	return checkpoint, nil
+}
+
+func (m *migrationCheckpoint) get(path string) string {
+	m.l.Lock()
+	defer m.l.Unlock()
+	return m.Keys[path]
+}
+
+// paths returns the sorted keys recorded in the checkpoint.
+func (m *migrationCheckpoint) paths() []string {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	ret := make([]string, 0, len(m.Keys))
+	for path := range m.Keys {
+		ret = append(ret, path)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// set records the checksum of path, or removes it if sum is empty, and
+// periodically writes the checkpoint out.
+func (m *migrationCheckpoint) set(path, sum string) error {
+	m.l.Lock()
+	if sum == "" {
+		delete(m.Keys, path)
+	} else {
+		m.Keys[path] = sum
+	}
+	m.dirty++
+	flush := m.dirty >= migrationCheckpointInterval
+	m.l.Unlock()
+
+	if flush {
+		return m.save()
+	}
+	return nil
+}
+
+// save atomically writes the checkpoint to its file.
+func (m *migrationCheckpoint) save() error {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	m.Updated = time.Now().UTC()
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(m.path), filepath.Base(m.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), m.path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	m.dirty = 0
+	return nil
 }
 
 func (c *OperatorMigrateCommand) newBackend(kind string, conf map[string]string) (physical.Backend, error) {
@@ -252,13 +631,22 @@ func (c *OperatorMigrateCommand) createDestinationBackend(kind string, conf map[
 		if err != nil {
 			return nil, errwrap.Wrapf("error parsing cluster address: {{err}}", err)
 		}
-		if err := raftStorage.Bootstrap(context.Background(), []raft.Peer{
-			{
-				ID:      raftStorage.NodeID(),
-				Address: parsedClusterAddr.Host,
-			},
-		}); err != nil {
-			return nil, errwrap.Wrapf("could not bootstrap clustered storage: {{err}}", err)
+
+		// A destination that was written by an earlier run, such as when
+		// resuming or syncing from a checkpoint, is already bootstrapped.
+		hasState, err := raftStorage.HasState()
+		if err != nil {
+			return nil, errwrap.Wrapf("could not check clustered storage state: {{err}}", err)
+		}
+		if !hasState {
+			if err := raftStorage.Bootstrap(context.Background(), []raft.Peer{
+				{
+					ID:      raftStorage.NodeID(),
+					Address: parsedClusterAddr.Host,
+				},
+			}); err != nil {
+				return nil, errwrap.Wrapf("could not bootstrap clustered storage: {{err}}", err)
+			}
 		}
 
 		if err := raftStorage.SetupCluster(context.Background(), raft.SetupOpts{
@@ -108,6 +108,186 @@ func TestMigration(t *testing.T) {
 		}
 	})
 
+	t.Run("Parallel", func(t *testing.T) {
+		data := generateData()
+
+		from, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storeData(from, data); err != nil {
+			t.Fatal(err)
+		}
+
+		to, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := OperatorMigrateCommand{
+			logger:          log.NewNullLogger(),
+			flagMaxParallel: 16,
+		}
+		if err := cmd.migrateAll(context.Background(), from, to); err != nil {
+			t.Fatal(err)
+		}
+		if err := compareStoredData(to, data, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := cmd.verifyAll(context.Background(), from, to); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Checkpoint", func(t *testing.T) {
+		data := generateData()
+
+		from, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storeData(from, data); err != nil {
+			t.Fatal(err)
+		}
+
+		to, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		folder := filepath.Join(os.TempDir(), testhelpers.RandomWithPrefix("migrator"))
+		if err := os.MkdirAll(folder, 0700); err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(folder)
+		checkpointPath := filepath.Join(folder, "migrate.ckpt")
+
+		run := func() *OperatorMigrateCommand {
+			t.Helper()
+			checkpoint, err := loadMigrationCheckpoint(checkpointPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmd := &OperatorMigrateCommand{
+				logger:          log.NewNullLogger(),
+				flagMaxParallel: 4,
+				checkpoint:      checkpoint,
+			}
+			if err := cmd.migrateAll(context.Background(), from, to); err != nil {
+				t.Fatal(err)
+			}
+			return cmd
+		}
+
+		cmd := run()
+		if err := compareStoredData(to, data, ""); err != nil {
+			t.Fatal(err)
+		}
+		if cmd.stats.copied == 0 || cmd.stats.skipped != 0 {
+			t.Fatalf("unexpected stats on first run: %#v", cmd.stats)
+		}
+
+		// Nothing changed, so a second run copies nothing
+		cmd = run()
+		if cmd.stats.copied != 0 || cmd.stats.deleted != 0 {
+			t.Fatalf("unexpected stats on unchanged run: %#v", cmd.stats)
+		}
+
+		// Change, add and remove keys in the source
+		var changed, removed string
+		for k := range data {
+			if k == storageMigrationLock || k == vault.CoreLockPath || k == "" || strings.HasSuffix(k, "/") {
+				continue
+			}
+			if changed == "" {
+				changed = k
+			} else if removed == "" && k != changed {
+				removed = k
+				break
+			}
+		}
+		data[changed] = []byte("changed")
+		data["added/key"] = []byte("added")
+		if err := storeData(from, map[string][]byte{changed: data[changed], "added/key": data["added/key"]}); err != nil {
+			t.Fatal(err)
+		}
+		if err := from.Delete(context.Background(), removed); err != nil {
+			t.Fatal(err)
+		}
+		delete(data, removed)
+
+		cmd = run()
+		if cmd.stats.copied != 2 || cmd.stats.deleted != 1 {
+			t.Fatalf("unexpected stats on incremental run: %#v", cmd.stats)
+		}
+		if err := compareStoredData(to, data, ""); err != nil {
+			t.Fatal(err)
+		}
+		if entry, err := to.Get(context.Background(), removed); err != nil || entry != nil {
+			t.Fatalf("expected %q to be deleted, got %v, %v", removed, entry, err)
+		}
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		data := generateData()
+
+		from, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storeData(from, data); err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := OperatorMigrateCommand{
+			logger: log.NewNullLogger(),
+		}
+		if err := cmd.migrateAll(context.Background(), from, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		// generateData adds four keys that are never migrated
+		if expected := uint64(len(data) - 4); cmd.stats.copied != expected {
+			t.Fatalf("expected %d keys to be reported, got %d", expected, cmd.stats.copied)
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		data := generateData()
+
+		from, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := storeData(from, data); err != nil {
+			t.Fatal(err)
+		}
+
+		to, err := physicalBackends["inmem"](map[string]string{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cmd := OperatorMigrateCommand{
+			logger: log.NewNullLogger(),
+		}
+		if err := cmd.migrateAll(context.Background(), from, to); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := to.Put(context.Background(), &physical.Entry{Key: "corrupt", Value: []byte("a")}); err != nil {
+			t.Fatal(err)
+		}
+		if err := from.Put(context.Background(), &physical.Entry{Key: "corrupt", Value: []byte("b")}); err != nil {
+			t.Fatal(err)
+		}
+
+		err = cmd.verifyAll(context.Background(), from, to)
+		if err == nil || !strings.Contains(err.Error(), "corrupt") {
+			t.Fatalf("expected verification error, got %v", err)
+		}
+	})
+
 	t.Run("Config parsing", func(t *testing.T) {
 		cmd := new(OperatorMigrateCommand)
 
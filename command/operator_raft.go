@@ -32,6 +32,14 @@ Usage: vault operator raft <subcommand> [options] [args]
 
       $ vault operator raft configuration
 
+  Lists the raft peers and whether they are voters:
+
+      $ vault operator raft list-peers
+
+  Promotes a non-voting node to a voter:
+
+      $ vault operator raft promote node1
+
   Removes a node from the raft cluster:
 
       $ vault operator raft remove-peer
@@ -76,7 +76,7 @@ func (c *OperatorRaftJoinCommand) Flags() *FlagSets {
 		Name:    "non-voter",
 		Target:  &c.flagNonVoter,
 		Default: false,
-		Usage:   "(Enterprise-only) This flag is used to make the server not participate in the Raft quorum, and have it only receive the data replication stream. This can be used to add read scalability to a cluster in cases where a high volume of reads to servers are needed.",
+		Usage:   "This flag is used to make the server not participate in the Raft quorum, and have it only receive the data replication stream. This can be used to add read scalability to a cluster in cases where a high volume of reads to servers are needed.",
 	})
 
 	return set
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var _ cli.Command = (*OperatorRaftListPeersCommand)(nil)
+var _ cli.CommandAutocomplete = (*OperatorRaftListPeersCommand)(nil)
+
+type OperatorRaftListPeersCommand struct {
+	*BaseCommand
+}
+
+func (c *OperatorRaftListPeersCommand) Synopsis() string {
+	return "Returns the raft peer set"
+}
+
+func (c *OperatorRaftListPeersCommand) Help() string {
+	helpText := `
+Usage: vault operator raft list-peers
+
+  Provides the details of all the peers in the raft cluster, including whether
+  each peer is a voter or a non-voter.
+
+	  $ vault operator raft list-peers
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorRaftListPeersCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+
+	return set
+}
+
+func (c *OperatorRaftListPeersCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictAnything
+}
+
+func (c *OperatorRaftListPeersCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *OperatorRaftListPeersCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	secret, err := client.Logical().Read("sys/storage/raft/configuration")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading the raft cluster configuration: %s", err))
+		return 2
+	}
+	if secret == nil {
+		c.UI.Error("No raft cluster configuration found")
+		return 2
+	}
+
+	switch Format(c.UI) {
+	case "table":
+	default:
+		return OutputSecret(c.UI, secret)
+	}
+
+	config, ok := secret.Data["config"].(map[string]interface{})
+	if !ok {
+		c.UI.Error("Unexpected raft configuration format")
+		return 2
+	}
+	servers, _ := config["servers"].([]interface{})
+
+	out := []string{"Node | Address | State | Voter"}
+	for _, raw := range servers {
+		server, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		state := "follower"
+		if leader, _ := server["leader"].(bool); leader {
+			state = "leader"
+		}
+		voter, _ := server["voter"].(bool)
+		out = append(out, fmt.Sprintf("%s | %s | %s | %t", server["node_id"], server["address"], state, voter))
+	}
+
+	c.UI.Output(tableOutput(out, nil))
+	return 0
+}
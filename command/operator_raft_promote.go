@@ -0,0 +1,89 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var _ cli.Command = (*OperatorRaftPromoteCommand)(nil)
+var _ cli.CommandAutocomplete = (*OperatorRaftPromoteCommand)(nil)
+
+type OperatorRaftPromoteCommand struct {
+	*BaseCommand
+}
+
+func (c *OperatorRaftPromoteCommand) Synopsis() string {
+	return "Promotes a non-voting node to a voter"
+}
+
+func (c *OperatorRaftPromoteCommand) Help() string {
+	helpText := `
+Usage: vault operator raft promote <server_id>
+
+  Promotes a non-voting peer of the raft cluster to a voting member.
+
+	  $ vault operator raft promote node1
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *OperatorRaftPromoteCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	return set
+}
+
+func (c *OperatorRaftPromoteCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictAnything
+}
+
+func (c *OperatorRaftPromoteCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *OperatorRaftPromoteCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	serverID := ""
+
+	args = f.Args()
+	switch len(args) {
+	case 1:
+		serverID = strings.TrimSpace(args[0])
+	default:
+		c.UI.Error(fmt.Sprintf("Incorrect arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	if len(serverID) == 0 {
+		c.UI.Error("Server id is required")
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	_, err = client.Logical().Write("sys/storage/raft/promote", map[string]interface{}{
+		"server_id": serverID,
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error promoting the raft peer: %s", err))
+		return 2
+	}
+
+	c.UI.Output("Peer promoted successfully!")
+
+	return 0
+}
@@ -224,6 +224,44 @@ path "secret" {
 		}
 	})
 
+	t.Run("bad_parameter_constraints", func(t *testing.T) {
+		t.Parallel()
+
+		policy := `path "secret/" {
+  capabilities = ["update"]
+  parameter_constraints "name" { regex = ["("] }
+}`
+
+		f, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(f.Name())
+		if _, err := f.Write([]byte(policy)); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPolicyFmtCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			f.Name(),
+		})
+		if exp := 1; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		stderr := ui.ErrorWriter.String()
+		expected := `error compiling regex for parameter "name"`
+		if !strings.Contains(stderr, expected) {
+			t.Errorf("expected %q to include %q", stderr, expected)
+		}
+	})
+
 	t.Run("no_tabs", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,425 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/vault/api"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/mitchellh/cli"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/posener/complete"
+)
+
+var _ cli.Command = (*PolicyTestCommand)(nil)
+var _ cli.CommandAutocomplete = (*PolicyTestCommand)(nil)
+
+type PolicyTestCommand struct {
+	*BaseCommand
+
+	flagPolicies       []string
+	flagPolicyFiles    []string
+	flagOperation      string
+	flagTestFile       string
+	flagRemoteAddr     string
+	flagTokenMetadata  map[string]string
+	flagEntityMetadata map[string]string
+	flagGroupMetadata  map[string]string
+	flagMFAValidated   bool
+
+	testStdin io.Reader // for tests
+}
+
+// policyTestSuite is the format of the files run with -test-file. Policies
+// set at the top level apply to the tests which don't set their own.
+type policyTestSuite struct {
+	Policies    []string          `hcl:"policies"`
+	PolicyFiles []string          `hcl:"policy_files"`
+	Tests       []*policyTestCase `hcl:"test"`
+}
+
+type policyTestCase struct {
+	Name           string                 `hcl:",key"`
+	Policies       []string               `hcl:"policies"`
+	PolicyFiles    []string               `hcl:"policy_files"`
+	Path           string                 `hcl:"path"`
+	Operation      string                 `hcl:"operation"`
+	Parameters     map[string]interface{} `hcl:"parameters"`
+	RemoteAddr     string                 `hcl:"remote_addr"`
+	TokenMetadata  map[string]string      `hcl:"token_metadata"`
+	EntityMetadata map[string]string      `hcl:"entity_metadata"`
+	GroupMetadata  map[string]string      `hcl:"group_metadata"`
+	MFAValidated   *bool                  `hcl:"mfa_validated"`
+	Allowed        bool                   `hcl:"allowed"`
+	MatchedPath    *string                `hcl:"matched_path"`
+	Capabilities   []string               `hcl:"capabilities"`
+}
+
+func (c *PolicyTestCommand) Synopsis() string {
+	return "Evaluates requests against ACL policies"
+}
+
+func (c *PolicyTestCommand) Help() string {
+	helpText := `
+Usage: vault policy test [options] [PATH [K=V...]]
+
+  Evaluates whether a request would be allowed by a set of ACL policies,
+  without issuing a token. Policies can be stored policies, given with -policy,
+  or local files, given with -policy-file. The output includes the path of the
+  matching policy rule and the resulting capabilities on the path. This uses
+  the /sys/policies/simulate endpoint.
+
+  Check whether the "ops" policy allows writing "secret/foo":
+
+      $ vault policy test -policy=ops -operation=update secret/foo value=bar
+
+  Check a local policy file before uploading it:
+
+      $ vault policy test -policy-file=./ops.hcl secret/foo
+
+  Check a policy with conditions for a request from a given address:
+
+      $ vault policy test -policy=ops -remote-addr=10.0.1.5 secret/foo
+
+  Run the tests of a test file, exiting with a non-zero status if any fail:
+
+      $ vault policy test -test-file=./ops_test.hcl
+
+  A test file contains "test" blocks giving the request and the expected
+  result. Policies and policy files can be set at the top level or in each
+  test, with policy file paths relative to the test file:
+
+      policy_files = ["ops.hcl"]
+
+      test "ops can issue internal certificates" {
+        path         = "pki/issue/internal"
+        operation    = "update"
+        parameters   = { common_name = "web.svc.internal" }
+        allowed      = true
+        matched_path = "pki/issue/*"
+        capabilities = ["update"]
+      }
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PolicyTestCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringSliceVar(&StringSliceVar{
+		Name:       "policy",
+		Target:     &c.flagPolicies,
+		Completion: c.PredictVaultPolicies(),
+		Usage: "Name of a stored policy to evaluate the request against. This " +
+			"can be specified multiple times.",
+	})
+
+	f.StringSliceVar(&StringSliceVar{
+		Name:       "policy-file",
+		Target:     &c.flagPolicyFiles,
+		Completion: complete.PredictFiles("*.hcl"),
+		Usage: "Path to a local policy file to evaluate the request against. " +
+			"This can be specified multiple times.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "operation",
+		Target:     &c.flagOperation,
+		Default:    "read",
+		Completion: complete.PredictSet("create", "read", "update", "delete", "list"),
+		Usage: "Operation of the request. This can be create, read, update, " +
+			"delete or list.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "remote-addr",
+		Target:     &c.flagRemoteAddr,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage:      "Client address of the request, for policy conditions on cidrs.",
+	})
+
+	f.StringMapVar(&StringMapVar{
+		Name:       "token-metadata",
+		Target:     &c.flagTokenMetadata,
+		Completion: complete.PredictAnything,
+		Usage: "Key=value login metadata of the token, for policy conditions on " +
+			"token_metadata. This can be specified multiple times.",
+	})
+
+	f.StringMapVar(&StringMapVar{
+		Name:       "entity-metadata",
+		Target:     &c.flagEntityMetadata,
+		Completion: complete.PredictAnything,
+		Usage: "Key=value metadata of the entity, for policy conditions on " +
+			"entity_metadata. This can be specified multiple times.",
+	})
+
+	f.StringMapVar(&StringMapVar{
+		Name:       "group-metadata",
+		Target:     &c.flagGroupMetadata,
+		Completion: complete.PredictAnything,
+		Usage: "Key=value metadata of a group of the entity, for policy " +
+			"conditions on group_metadata. This can be specified multiple times.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "mfa-validated",
+		Target:  &c.flagMFAValidated,
+		Default: false,
+		Usage: "Evaluate the request as made with a token issued by a login " +
+			"that validated MFA, for policy conditions on mfa_validated.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "test-file",
+		Target:     &c.flagTestFile,
+		Default:    "",
+		Completion: complete.PredictFiles("*.hcl"),
+		Usage: "Path to a local file of policy tests to run. When set, no " +
+			"request may be given as arguments.",
+	})
+
+	return set
+}
+
+func (c *PolicyTestCommand) AutocompleteArgs() complete.Predictor {
+	return c.PredictVaultFiles()
+}
+
+func (c *PolicyTestCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *PolicyTestCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	switch {
+	case c.flagTestFile != "" && len(args) > 0:
+		c.UI.Error(fmt.Sprintf("Too many arguments with -test-file (expected 0, got %d)", len(args)))
+		return 1
+	case c.flagTestFile == "" && len(args) < 1:
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1 or more, got %d)", len(args)))
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	if c.flagTestFile != "" {
+		return c.runTestFile(client)
+	}
+
+	inlinePolicies, err := readPolicyFiles("", c.flagPolicyFiles)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	stdin := (io.Reader)(os.Stdin)
+	if c.testStdin != nil {
+		stdin = c.testStdin
+	}
+	parameters, err := parseArgsData(stdin, args[1:])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to parse K=V data: %s", err))
+		return 1
+	}
+
+	result, err := client.Sys().SimulatePolicy(&api.PolicySimulateInput{
+		Policies:       c.flagPolicies,
+		InlinePolicies: inlinePolicies,
+		Path:           sanitizePath(args[0]),
+		Operation:      c.flagOperation,
+		Parameters:     parameters,
+		RemoteAddr:     c.flagRemoteAddr,
+		TokenMetadata:  c.flagTokenMetadata,
+		EntityMetadata: c.flagEntityMetadata,
+		GroupMetadata:  c.flagGroupMetadata,
+		MFAValidated:   c.flagMFAValidated,
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error simulating request: %s", err))
+		return 2
+	}
+
+	if Format(c.UI) != "table" {
+		return OutputData(c.UI, result)
+	}
+
+	for _, warning := range result.Warnings {
+		c.UI.Warn(fmt.Sprintf("WARNING! %s", warning))
+	}
+
+	c.UI.Output(tableOutput([]string{
+		"Key | Value",
+		fmt.Sprintf("allowed | %t", result.Allowed),
+		fmt.Sprintf("matched_path | %s", result.MatchedPath),
+		fmt.Sprintf("capabilities | %s", strings.Join(result.Capabilities, ",")),
+	}, nil))
+
+	names := make([]string, 0, len(result.Policies))
+	for name := range result.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := []string{"Policy | Allowed | Matched Path | Capabilities"}
+	for _, name := range names {
+		policyResult := result.Policies[name]
+		out = append(out, fmt.Sprintf("%s | %t | %s | %s",
+			name, policyResult.Allowed, policyResult.MatchedPath, strings.Join(policyResult.Capabilities, ",")))
+	}
+	c.UI.Output("")
+	c.UI.Output(tableOutput(out, nil))
+
+	return 0
+}
+
+func (c *PolicyTestCommand) runTestFile(client *api.Client) int {
+	path, err := homedir.Expand(strings.TrimSpace(c.flagTestFile))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to expand path: %s", err))
+		return 1
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading test file: %s", err))
+		return 1
+	}
+
+	var suite policyTestSuite
+	if err := hcl.Decode(&suite, string(b)); err != nil {
+		c.UI.Error(fmt.Sprintf("Error parsing test file: %s", err))
+		return 1
+	}
+	if len(suite.Tests) == 0 {
+		c.UI.Error("No tests found in test file")
+		return 1
+	}
+
+	dir := filepath.Dir(path)
+	var failed int
+	for _, tc := range suite.Tests {
+		policies, policyFiles := tc.Policies, tc.PolicyFiles
+		if len(policies) == 0 && len(policyFiles) == 0 {
+			policies, policyFiles = suite.Policies, suite.PolicyFiles
+		}
+		policies = append(policies, c.flagPolicies...)
+		policyFiles = append(policyFiles, c.flagPolicyFiles...)
+
+		inlinePolicies, err := readPolicyFiles(dir, policyFiles)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		input := &api.PolicySimulateInput{
+			Policies:       policies,
+			InlinePolicies: inlinePolicies,
+			Path:           sanitizePath(tc.Path),
+			Operation:      tc.Operation,
+			Parameters:     tc.Parameters,
+			RemoteAddr:     tc.RemoteAddr,
+			TokenMetadata:  tc.TokenMetadata,
+			EntityMetadata: tc.EntityMetadata,
+			GroupMetadata:  tc.GroupMetadata,
+			MFAValidated:   c.flagMFAValidated,
+		}
+		if input.Operation == "" {
+			input.Operation = c.flagOperation
+		}
+		if input.RemoteAddr == "" {
+			input.RemoteAddr = c.flagRemoteAddr
+		}
+		if input.TokenMetadata == nil {
+			input.TokenMetadata = c.flagTokenMetadata
+		}
+		if input.EntityMetadata == nil {
+			input.EntityMetadata = c.flagEntityMetadata
+		}
+		if input.GroupMetadata == nil {
+			input.GroupMetadata = c.flagGroupMetadata
+		}
+		if tc.MFAValidated != nil {
+			input.MFAValidated = *tc.MFAValidated
+		}
+
+		result, err := client.Sys().SimulatePolicy(input)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error simulating request for test %q: %s", tc.Name, err))
+			return 2
+		}
+
+		var failures []string
+		if result.Allowed != tc.Allowed {
+			failures = append(failures, fmt.Sprintf("expected allowed to be %t, got %t (matched path %q)", tc.Allowed, result.Allowed, result.MatchedPath))
+		}
+		if tc.MatchedPath != nil && result.MatchedPath != *tc.MatchedPath {
+			failures = append(failures, fmt.Sprintf("expected matched path %q, got %q", *tc.MatchedPath, result.MatchedPath))
+		}
+		if tc.Capabilities != nil && !strutil.EquivalentSlices(tc.Capabilities, result.Capabilities) {
+			failures = append(failures, fmt.Sprintf("expected capabilities %v, got %v", tc.Capabilities, result.Capabilities))
+		}
+
+		if len(failures) == 0 {
+			c.UI.Output(fmt.Sprintf("PASS: %s", tc.Name))
+			continue
+		}
+
+		failed++
+		c.UI.Error(fmt.Sprintf("FAIL: %s", tc.Name))
+		for _, failure := range failures {
+			c.UI.Error(fmt.Sprintf("  %s", failure))
+		}
+	}
+
+	c.UI.Output("")
+	c.UI.Output(fmt.Sprintf("%d passed, %d failed", len(suite.Tests)-failed, failed))
+	if failed > 0 {
+		return 2
+	}
+	return 0
+}
+
+// readPolicyFiles reads the given policy files, relative to dir unless
+// absolute.
+func readPolicyFiles(dir string, paths []string) ([]string, error) {
+	var ret []string
+	for _, path := range paths {
+		path, err := homedir.Expand(strings.TrimSpace(path))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to expand path: %s", err)
+		}
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading policy file: %s", err)
+		}
+		ret = append(ret, string(b))
+	}
+	return ret, nil
+}
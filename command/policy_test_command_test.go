@@ -0,0 +1,324 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func testPolicyTestCommand(tb testing.TB) (*cli.MockUi, *PolicyTestCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &PolicyTestCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func testPolicyTestFiles(tb testing.TB, files map[string]string) string {
+	tb.Helper()
+
+	dir, err := ioutil.TempDir("", "vault-policy-test")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPolicyTestCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"not_enough_args",
+			[]string{},
+			"Not enough arguments",
+			1,
+		},
+		{
+			"test_file_too_many_args",
+			[]string{"-test-file", "tests.hcl", "secret/foo"},
+			"Too many arguments",
+			1,
+		},
+		{
+			"bad_policy_file",
+			[]string{"-policy-file", "/not/a/real/path.hcl", "secret/foo"},
+			"Error reading policy file",
+			1,
+		},
+		{
+			"no_policies",
+			[]string{"secret/foo"},
+			"at least one of policies or inline_policies",
+			2,
+		},
+	}
+
+	t.Run("validations", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range cases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				client, closer := testVaultServer(t)
+				defer closer()
+
+				ui, cmd := testPolicyTestCommand(t)
+				cmd.client = client
+
+				code := cmd.Run(tc.args)
+				if code != tc.code {
+					t.Errorf("expected %d to be %d", code, tc.code)
+				}
+
+				combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+				if !strings.Contains(combined, tc.out) {
+					t.Errorf("expected %q to contain %q", combined, tc.out)
+				}
+			})
+		}
+	})
+
+	t.Run("stored_policy", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		if err := client.Sys().PutPolicy("ops", `path "secret/*" { capabilities = ["read", "update"] }`); err != nil {
+			t.Fatal(err)
+		}
+
+		ui, cmd := testPolicyTestCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-policy", "ops",
+			"-operation", "update",
+			"secret/foo",
+			"value=bar",
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		for _, expected := range []string{"allowed         true", "secret/*", "read,update"} {
+			if !strings.Contains(combined, expected) {
+				t.Errorf("expected %q to contain %q", combined, expected)
+			}
+		}
+	})
+
+	t.Run("policy_file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := testPolicyTestFiles(t, map[string]string{
+			"ops.hcl": `path "secret/foo" { capabilities = ["deny"] }`,
+		})
+		defer os.RemoveAll(dir)
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPolicyTestCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-policy-file", filepath.Join(dir, "ops.hcl"),
+			"secret/foo",
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "allowed         false"
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
+	t.Run("conditions", func(t *testing.T) {
+		t.Parallel()
+
+		dir := testPolicyTestFiles(t, map[string]string{
+			"ci.hcl": `
+path "secret/ci" {
+  capabilities = ["read"]
+  condition {
+    cidrs          = ["10.0.0.0/8"]
+    token_metadata = { source = "ci" }
+  }
+}`,
+			"tests.hcl": `
+policy_files = ["ci.hcl"]
+
+test "from the CI network" {
+  path           = "secret/ci"
+  token_metadata = { source = "ci" }
+  allowed        = true
+}
+
+test "from another network" {
+  path           = "secret/ci"
+  remote_addr    = "192.168.1.1"
+  token_metadata = { source = "ci" }
+  allowed        = false
+}`,
+		})
+		defer os.RemoveAll(dir)
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPolicyTestCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-remote-addr", "10.1.2.3",
+			"-test-file", filepath.Join(dir, "tests.hcl"),
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "2 passed, 0 failed"
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
+	t.Run("test_file", func(t *testing.T) {
+		t.Parallel()
+
+		dir := testPolicyTestFiles(t, map[string]string{
+			"ops.hcl": `
+path "pki/issue/*" {
+  capabilities = ["update"]
+  parameter_constraints "common_name" {
+    glob = ["*.svc.internal"]
+  }
+}`,
+			"pass.hcl": `
+policy_files = ["ops.hcl"]
+
+test "internal certificates" {
+  path         = "pki/issue/web"
+  operation    = "update"
+  parameters   = { common_name = "web.svc.internal" }
+  allowed      = true
+  matched_path = "pki/issue/*"
+  capabilities = ["update"]
+}
+
+test "external certificates" {
+  path       = "pki/issue/web"
+  operation  = "update"
+  parameters = { common_name = "web.example.com" }
+  allowed    = false
+}`,
+			"fail.hcl": `
+policy_files = ["ops.hcl"]
+
+test "passes" {
+  path    = "secret/foo"
+  allowed = false
+}
+
+test "fails" {
+  path    = "pki/issue/web"
+  allowed = true
+}`,
+		})
+		defer os.RemoveAll(dir)
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPolicyTestCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-test-file", filepath.Join(dir, "pass.hcl"),
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d: %s", code, exp, ui.ErrorWriter.String())
+		}
+
+		expected := "2 passed, 0 failed"
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+
+		ui, cmd = testPolicyTestCommand(t)
+		cmd.client = client
+
+		code = cmd.Run([]string{
+			"-test-file", filepath.Join(dir, "fail.hcl"),
+		})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		combined = ui.OutputWriter.String() + ui.ErrorWriter.String()
+		for _, expected := range []string{"PASS: passes", "FAIL: fails", "1 passed, 1 failed"} {
+			if !strings.Contains(combined, expected) {
+				t.Errorf("expected %q to contain %q", combined, expected)
+			}
+		}
+	})
+
+	t.Run("communication_failure", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServerBad(t)
+		defer closer()
+
+		ui, cmd := testPolicyTestCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-policy", "default",
+			"secret/foo",
+		})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "Error simulating request: "
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := testPolicyTestCommand(t)
+		assertNoTabs(t, cmd)
+	})
+}
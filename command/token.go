@@ -21,7 +21,7 @@ func (c *TokenCommand) Help() string {
 Usage: vault token <subcommand> [options] [args]
 
   This command groups subcommands for interacting with tokens. Users can
-  create, lookup, renew, and revoke tokens.
+  create, list, lookup, renew, and revoke tokens.
 
   Create a new token:
 
@@ -0,0 +1,257 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var _ cli.Command = (*TokenListCommand)(nil)
+var _ cli.CommandAutocomplete = (*TokenListCommand)(nil)
+
+type TokenListCommand struct {
+	*BaseCommand
+
+	flagPolicies   []string
+	flagRole       string
+	flagPathPrefix string
+	flagEntityID   string
+	flagMetadata   map[string]string
+	flagMinTTL     time.Duration
+	flagMaxTTL     time.Duration
+	flagLimit      int
+	flagAfter      string
+}
+
+func (c *TokenListCommand) Synopsis() string {
+	return "List tokens matching search criteria"
+}
+
+func (c *TokenListCommand) Help() string {
+	helpText := `
+Usage: vault token list [options]
+
+  Lists the accessors of the tokens matching the given criteria, along with
+  their policies, creation path and expiration. Token IDs are never returned.
+  This uses the /auth/token/search endpoint, which requires sudo capability.
+
+  List all tokens which have the "admin" policy:
+
+      $ vault token list -policy=admin
+
+  List tokens created through the "ci" role which expire within the hour:
+
+      $ vault token list -role=ci -max-ttl=1h
+
+  List tokens by metadata:
+
+      $ vault token list -metadata=team=ops
+
+  Results are returned in pages. When more results are available, the
+  accessor to continue from is printed and can be passed with -after.
+
+  For a full list of examples, please see the documentation.
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *TokenListCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringSliceVar(&StringSliceVar{
+		Name:       "policy",
+		Target:     &c.flagPolicies,
+		Completion: c.PredictVaultPolicies(),
+		Usage: "Only list tokens which have this policy. This can be specified " +
+			"multiple times, in which case tokens must have all the policies.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "role",
+		Target:     &c.flagRole,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage:      "Only list tokens created against this token role.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "path-prefix",
+		Target:     &c.flagPathPrefix,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage: "Only list tokens whose creation path starts with this prefix, " +
+			"such as \"auth/userpass/\".",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "entity-id",
+		Target:     &c.flagEntityID,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage:      "Only list tokens tied to this identity entity.",
+	})
+
+	f.StringMapVar(&StringMapVar{
+		Name:       "metadata",
+		Target:     &c.flagMetadata,
+		Completion: complete.PredictAnything,
+		Usage: "Only list tokens with this metadata, specified as key=value. " +
+			"This can be specified multiple times.",
+	})
+
+	f.DurationVar(&DurationVar{
+		Name:       "min-ttl",
+		Target:     &c.flagMinTTL,
+		Default:    0,
+		Completion: complete.PredictAnything,
+		Usage: "Only list tokens with at least this much time remaining. Tokens " +
+			"which never expire always match.",
+	})
+
+	f.DurationVar(&DurationVar{
+		Name:       "max-ttl",
+		Target:     &c.flagMaxTTL,
+		Default:    0,
+		Completion: complete.PredictAnything,
+		Usage: "Only list tokens expiring within this duration. Tokens which " +
+			"never expire never match.",
+	})
+
+	f.IntVar(&IntVar{
+		Name:       "limit",
+		Target:     &c.flagLimit,
+		Default:    100,
+		Completion: complete.PredictAnything,
+		Usage:      "Maximum number of tokens to return.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "after",
+		Target:     &c.flagAfter,
+		Default:    "",
+		Completion: complete.PredictAnything,
+		Usage:      "Only list tokens whose accessor sorts after this one.",
+	})
+
+	return set
+}
+
+func (c *TokenListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *TokenListCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *TokenListCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) > 0 {
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0, got %d)", len(args)))
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	data := map[string]interface{}{
+		"limit": c.flagLimit,
+	}
+	if len(c.flagPolicies) > 0 {
+		data["policies"] = c.flagPolicies
+	}
+	if c.flagRole != "" {
+		data["role"] = c.flagRole
+	}
+	if c.flagPathPrefix != "" {
+		data["path_prefix"] = c.flagPathPrefix
+	}
+	if c.flagEntityID != "" {
+		data["entity_id"] = c.flagEntityID
+	}
+	if len(c.flagMetadata) > 0 {
+		data["meta"] = c.flagMetadata
+	}
+	if c.flagMinTTL != 0 {
+		data["min_ttl"] = int64(c.flagMinTTL.Seconds())
+	}
+	if c.flagMaxTTL != 0 {
+		data["max_ttl"] = int64(c.flagMaxTTL.Seconds())
+	}
+	if c.flagAfter != "" {
+		data["after"] = c.flagAfter
+	}
+
+	secret, err := client.Logical().Write("auth/token/search", data)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error searching tokens: %s", err))
+		return 2
+	}
+	if secret == nil || secret.Data == nil {
+		c.UI.Error("No tokens found")
+		return 2
+	}
+
+	if Format(c.UI) != "table" {
+		return OutputSecret(c.UI, secret)
+	}
+
+	keys, _ := secret.Data["keys"].([]interface{})
+	if len(keys) == 0 {
+		c.UI.Error("No tokens found")
+		return 2
+	}
+	keyInfo, _ := secret.Data["key_info"].(map[string]interface{})
+
+	accessors := make([]string, 0, len(keys))
+	for _, key := range keys {
+		accessors = append(accessors, fmt.Sprint(key))
+	}
+	sort.Strings(accessors)
+
+	out := []string{"Accessor | Display Name | Path | Policies | Expire Time"}
+	for _, accessor := range accessors {
+		info, _ := keyInfo[accessor].(map[string]interface{})
+
+		var policies []string
+		if raw, ok := info["policies"].([]interface{}); ok {
+			for _, policy := range raw {
+				policies = append(policies, fmt.Sprint(policy))
+			}
+		}
+
+		expireTime := "n/a"
+		if info["expire_time"] != nil {
+			expireTime = fmt.Sprint(info["expire_time"])
+		}
+
+		out = append(out, fmt.Sprintf("%s | %s | %s | %s | %s",
+			accessor, info["display_name"], info["path"], strings.Join(policies, ","), expireTime))
+	}
+	c.UI.Output(tableOutput(out, nil))
+
+	if next, ok := secret.Data["next"].(string); ok && next != "" {
+		c.UI.Output("")
+		c.UI.Output(fmt.Sprintf("More tokens are available. Run the same command with -after=%s to list them.", next))
+	}
+
+	return 0
+}
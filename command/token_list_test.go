@@ -0,0 +1,192 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/cli"
+)
+
+func testTokenListCommand(tb testing.TB) (*cli.MockUi, *TokenListCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &TokenListCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestTokenListCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"too_many_args",
+			[]string{"abcd1234"},
+			"Too many arguments",
+			1,
+		},
+		{
+			"no_matches",
+			[]string{"-policy", "nope"},
+			"No tokens found",
+			2,
+		},
+		{
+			"bad_limit",
+			[]string{"-limit", "0"},
+			"'limit' must be positive",
+			2,
+		},
+	}
+
+	t.Run("validations", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range cases {
+			tc := tc
+
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+
+				client, closer := testVaultServer(t)
+				defer closer()
+
+				ui, cmd := testTokenListCommand(t)
+				cmd.client = client
+
+				code := cmd.Run(tc.args)
+				if code != tc.code {
+					t.Errorf("expected %d to be %d", code, tc.code)
+				}
+
+				combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+				if !strings.Contains(combined, tc.out) {
+					t.Errorf("expected %q to contain %q", combined, tc.out)
+				}
+			})
+		}
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		_, accessor := testTokenAndAccessor(t, client)
+
+		ui, cmd := testTokenListCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-policy", "default",
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, accessor) {
+			t.Errorf("expected %q to contain %q", combined, accessor)
+		}
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		_, other := testTokenAndAccessor(t, client)
+		secret, err := client.Auth().Token().Create(&api.TokenCreateRequest{
+			Policies: []string{"default"},
+			Metadata: map[string]string{"team": "ops"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		accessor := secret.Auth.Accessor
+
+		ui, cmd := testTokenListCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-metadata", "team=ops",
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, accessor) {
+			t.Errorf("expected %q to contain %q", combined, accessor)
+		}
+		if strings.Contains(combined, other) {
+			t.Errorf("expected %q not to contain %q", combined, other)
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		testTokenAndAccessor(t, client)
+		testTokenAndAccessor(t, client)
+
+		ui, cmd := testTokenListCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-policy", "default",
+			"-limit", "1",
+		})
+		if exp := 0; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "More tokens are available"
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
+	t.Run("communication_failure", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServerBad(t)
+		defer closer()
+
+		ui, cmd := testTokenListCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+
+		expected := "Error searching tokens: "
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := testTokenListCommand(t)
+		assertNoTabs(t, cmd)
+	})
+}
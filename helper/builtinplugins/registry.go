@@ -42,6 +42,7 @@ import (
 	logicalAws "github.com/hashicorp/vault/builtin/logical/aws"
 	logicalCass "github.com/hashicorp/vault/builtin/logical/cassandra"
 	logicalConsul "github.com/hashicorp/vault/builtin/logical/consul"
+	logicalLdap "github.com/hashicorp/vault/builtin/logical/ldap"
 	logicalMongo "github.com/hashicorp/vault/builtin/logical/mongodb"
 	logicalMssql "github.com/hashicorp/vault/builtin/logical/mssql"
 	logicalMysql "github.com/hashicorp/vault/builtin/logical/mysql"
@@ -114,6 +115,7 @@ func newRegistry() *registry {
 			"gcp":        logicalGcp.Factory,
 			"gcpkms":     logicalGcpKms.Factory,
 			"kv":         logicalKv.Factory,
+			"ldap":       logicalLdap.Factory,
 			"mongodb":    logicalMongo.Factory,
 			"mssql":      logicalMssql.Factory,
 			"mysql":      logicalMysql.Factory,
@@ -80,7 +80,11 @@ func (b *backend) wrapLoginHandler(loginHandler framework.OperationFunc) framewo
 		// perform multi-factor authentication if type supported
 		handler, ok := handlers[mfa_config.Type]
 		if ok {
-			return handler(ctx, req, d, resp)
+			resp, err = handler(ctx, req, d, resp)
+			if err == nil && resp != nil && !resp.IsError() && resp.Auth != nil {
+				resp.Auth.MFAValidated = true
+			}
+			return resp, err
 		} else {
 			return resp, err
 		}
@@ -177,6 +177,7 @@ func TestLogical_StandbyRedirect(t *testing.T) {
 	testResponseBody(t, resp, &actual)
 	actualDataMap := actual["data"].(map[string]interface{})
 	delete(actualDataMap, "creation_time")
+	delete(actualDataMap, "last_used_time")
 	delete(actualDataMap, "accessor")
 	actual["data"] = actualDataMap
 	expected["request_id"] = actual["request_id"]
@@ -312,6 +312,7 @@ func TestSysGenerateRoot_Update_OTP(t *testing.T) {
 	testResponseBody(t, resp, &actual)
 
 	expected["creation_time"] = actual["data"].(map[string]interface{})["creation_time"]
+	expected["last_used_time"] = actual["data"].(map[string]interface{})["last_used_time"]
 	expected["accessor"] = actual["data"].(map[string]interface{})["accessor"]
 
 	if !reflect.DeepEqual(actual["data"], expected) {
@@ -411,6 +412,7 @@ func TestSysGenerateRoot_Update_PGP(t *testing.T) {
 	testResponseBody(t, resp, &actual)
 
 	expected["creation_time"] = actual["data"].(map[string]interface{})["creation_time"]
+	expected["last_used_time"] = actual["data"].(map[string]interface{})["last_used_time"]
 	expected["accessor"] = actual["data"].(map[string]interface{})["accessor"]
 
 	if diff := deep.Equal(actual["data"], expected); diff != nil {
@@ -168,6 +168,13 @@ func (b *RaftBackend) JoinConfig() ([]*LeaderJoinInfo, error) {
 	return leaderInfos, nil
 }
 
+// RetryJoinAsNonVoter returns whether this node should join the cluster as a
+// non-voter when it is joined via the retry_join configuration.
+func (b *RaftBackend) RetryJoinAsNonVoter() bool {
+	nonVoter, _ := strconv.ParseBool(b.conf["retry_join_as_non_voter"])
+	return nonVoter
+}
+
 // EnsurePath is used to make sure a path exists
 func EnsurePath(path string, dir bool) error {
 	if !dir {
@@ -184,6 +191,12 @@ func NewRaftBackend(conf map[string]string, logger log.Logger) (physical.Backend
 		return nil, fmt.Errorf("failed to create fsm: %v", err)
 	}
 
+	if nonVoterRaw, ok := conf["retry_join_as_non_voter"]; ok {
+		if _, err := strconv.ParseBool(nonVoterRaw); err != nil {
+			return nil, errwrap.Wrapf("failed to parse 'retry_join_as_non_voter': {{err}}", err)
+		}
+	}
+
 	path := os.Getenv(EnvVaultRaftPath)
 	if path == "" {
 		pathFromConfig, ok := conf["path"]
@@ -321,6 +334,10 @@ type RaftConfigurationResponse struct {
 type Peer struct {
 	ID      string `json:"id"`
 	Address string `json:"address"`
+
+	// NonVoter is true if the peer receives the replication stream but does
+	// not participate in the raft quorum.
+	NonVoter bool `json:"non_voter,omitempty"`
 }
 
 // NodeID returns the identifier of the node
@@ -374,9 +391,14 @@ func (b *RaftBackend) Bootstrap(ctx context.Context, peers []Peer) error {
 	}
 
 	for i, p := range peers {
+		suffrage := raft.Voter
+		if p.NonVoter {
+			suffrage = raft.Nonvoter
+		}
 		raftConfig.Servers[i] = raft.Server{
-			ID:      raft.ServerID(p.ID),
-			Address: raft.ServerAddress(p.Address),
+			Suffrage: suffrage,
+			ID:       raft.ServerID(p.ID),
+			Address:  raft.ServerAddress(p.Address),
 		}
 	}
 
@@ -385,6 +407,15 @@ func (b *RaftBackend) Bootstrap(ctx context.Context, peers []Peer) error {
 	return nil
 }
 
+// HasState returns whether the backend already holds raft state, i.e. whether
+// it has been bootstrapped before.
+func (b *RaftBackend) HasState() (bool, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	return raft.HasExistingState(b.logStore, b.stableStore, b.snapStore)
+}
+
 // SetRestoreCallback sets the callback to be used when a restoreCallbackOp is
 // processed through the FSM.
 func (b *RaftBackend) SetRestoreCallback(restoreCb restoreCallback) {
@@ -686,6 +717,91 @@ func (b *RaftBackend) AddPeer(ctx context.Context, peerID, clusterAddr string) e
 	return future.Error()
 }
 
+// AddNonVotingPeer adds a new server to the raft cluster that receives the
+// replication stream but does not participate in the quorum.
+func (b *RaftBackend) AddNonVotingPeer(ctx context.Context, peerID, clusterAddr string) error {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	if b.raft == nil {
+		return errors.New("raft storage is not initialized")
+	}
+
+	b.logger.Debug("adding non-voting raft peer", "node_id", peerID, "cluster_addr", clusterAddr)
+
+	future := b.raft.AddNonvoter(raft.ServerID(peerID), raft.ServerAddress(clusterAddr), 0, 0)
+	return future.Error()
+}
+
+// PromotePeer turns an existing non-voting peer into a voter.
+func (b *RaftBackend) PromotePeer(ctx context.Context, peerID string) error {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	if b.raft == nil {
+		return errors.New("raft storage is not initialized")
+	}
+
+	server, err := b.findServer(peerID)
+	if err != nil {
+		return err
+	}
+	if server.Suffrage == raft.Voter {
+		return fmt.Errorf("peer %q is already a voter", peerID)
+	}
+
+	b.logger.Debug("promoting raft peer", "node_id", peerID)
+
+	// Adding a voter with the ID of an existing server updates its suffrage
+	future := b.raft.AddVoter(server.ID, server.Address, 0, 0)
+	return future.Error()
+}
+
+// DemotePeer turns an existing voting peer into a non-voter. The leader
+// cannot be demoted.
+func (b *RaftBackend) DemotePeer(ctx context.Context, peerID string) error {
+	b.l.RLock()
+	defer b.l.RUnlock()
+
+	if b.raft == nil {
+		return errors.New("raft storage is not initialized")
+	}
+
+	if peerID == b.NodeID() {
+		return errors.New("cannot demote the leader node")
+	}
+
+	server, err := b.findServer(peerID)
+	if err != nil {
+		return err
+	}
+	if server.Suffrage != raft.Voter {
+		return fmt.Errorf("peer %q is not a voter", peerID)
+	}
+
+	b.logger.Debug("demoting raft peer", "node_id", peerID)
+
+	future := b.raft.DemoteVoter(server.ID, 0, 0)
+	return future.Error()
+}
+
+// findServer returns the server with the given ID from the latest raft
+// configuration. The caller must hold the read lock.
+func (b *RaftBackend) findServer(peerID string) (*raft.Server, error) {
+	future := b.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, err
+	}
+
+	for _, server := range future.Configuration().Servers {
+		if string(server.ID) == peerID {
+			return &server, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no peer found with ID %q", peerID)
+}
+
 // Peers returns all the servers present in the raft cluster
 func (b *RaftBackend) Peers(ctx context.Context) ([]Peer, error) {
 	b.l.RLock()
@@ -703,8 +819,9 @@ func (b *RaftBackend) Peers(ctx context.Context) ([]Peer, error) {
 	ret := make([]Peer, len(future.Configuration().Servers))
 	for i, s := range future.Configuration().Servers {
 		ret[i] = Peer{
-			ID:      string(s.ID),
-			Address: string(s.Address),
+			ID:       string(s.ID),
+			Address:  string(s.Address),
+			NonVoter: s.Suffrage == raft.Nonvoter,
 		}
 	}
 
@@ -715,6 +832,23 @@ func (b *RaftBackend) Peers(ctx context.Context) ([]Peer, error) {
 // to the provided writer. Seal access is used to encrypt the SHASUM file so we
 // can validate the snapshot was taken using the same master keys or not.
 func (b *RaftBackend) Snapshot(out *logical.HTTPResponseWriter, access *seal.Access) error {
+	return b.writeSnapshot(out, access, func(size int64) {
+		out.Header().Add("Content-Disposition", "attachment")
+		out.Header().Add("Content-Length", fmt.Sprintf("%d", size))
+		out.Header().Add("Content-Type", "application/gzip")
+	})
+}
+
+// SnapshotToWriter takes a raft snapshot and writes the archive to the
+// provided writer. It is used when the snapshot is not being streamed back over
+// HTTP, such as by automated snapshots.
+func (b *RaftBackend) SnapshotToWriter(out io.Writer, access *seal.Access) error {
+	return b.writeSnapshot(out, access, nil)
+}
+
+// writeSnapshot packages a raft snapshot and copies it to out. If sizeFunc is
+// not nil it is called with the archive size before any data is written.
+func (b *RaftBackend) writeSnapshot(out io.Writer, access *seal.Access, sizeFunc func(int64)) error {
 	b.l.RLock()
 	defer b.l.RUnlock()
 
@@ -741,9 +875,9 @@ func (b *RaftBackend) Snapshot(out *logical.HTTPResponseWriter, access *seal.Acc
 		return err
 	}
 
-	out.Header().Add("Content-Disposition", "attachment")
-	out.Header().Add("Content-Length", fmt.Sprintf("%d", size))
-	out.Header().Add("Content-Type", "application/gzip")
+	if sizeFunc != nil {
+		sizeFunc(size)
+	}
 	_, err = io.Copy(out, snap)
 	if err != nil {
 		return err
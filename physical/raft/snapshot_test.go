@@ -419,3 +419,99 @@ func TestRaft_Snapshot_Take_Restore(t *testing.T) {
 	time.Sleep(10 * time.Second)
 	compareFSMs(t, raft1.fsm, raft2.fsm)
 }
+
+func TestRaft_Snapshot_Inspect(t *testing.T) {
+	raft1, dir := getRaft(t, true, false)
+	defer os.RemoveAll(dir)
+
+	put := func(key, value string) {
+		t.Helper()
+		err := raft1.Put(context.Background(), &physical.Entry{
+			Key:   key,
+			Value: []byte(value),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		put(fmt.Sprintf("logical/abc/key-%d", i), "value")
+	}
+	for i := 0; i < 5; i++ {
+		put(fmt.Sprintf("sys/token/id/%d", i), "token")
+	}
+	put("core/mounts", "mounts")
+
+	var snap1 bytes.Buffer
+	if err := raft1.SnapshotToWriter(&snap1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	info1, err := InspectSnapshot(bytes.NewReader(snap1.Bytes()), &SnapshotInspectOpts{KeepKeyHashes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info1.Index == 0 || info1.Term == 0 {
+		t.Fatalf("bad metadata: %#v", info1)
+	}
+	if len(info1.Configuration) != 1 || !info1.Configuration[0].Voter {
+		t.Fatalf("bad configuration: %#v", info1.Configuration)
+	}
+
+	expected := map[string]int{
+		"core/":        1,
+		"logical/abc/": 10,
+		"sys/token/":   5,
+	}
+	for _, p := range info1.Prefixes {
+		if expected[p.Prefix] != p.Keys {
+			t.Fatalf("bad key count for %q: %d", p.Prefix, p.Keys)
+		}
+		delete(expected, p.Prefix)
+	}
+	if len(expected) != 0 {
+		t.Fatalf("missing prefixes: %v", expected)
+	}
+
+	// Change the data and compare a second snapshot with the first
+	put("logical/abc/key-0", "changed")
+	put("logical/def/key-0", "new")
+	if err := raft1.Delete(context.Background(), "sys/token/id/0"); err != nil {
+		t.Fatal(err)
+	}
+
+	var snap2 bytes.Buffer
+	if err := raft1.SnapshotToWriter(&snap2, nil); err != nil {
+		t.Fatal(err)
+	}
+	info2, err := InspectSnapshot(&snap2, &SnapshotInspectOpts{KeepKeyHashes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := DiffSnapshots(info1, info2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]SnapshotPrefixDiff)
+	for _, d := range diffs {
+		got[d.Prefix] = *d
+	}
+	if d := got["logical/abc/"]; d.Changed != 1 || d.Added != 0 || d.Removed != 0 {
+		t.Fatalf("bad diff: %#v", d)
+	}
+	if d := got["logical/def/"]; d.Added != 1 || d.KeysA != 0 || d.KeysB != 1 {
+		t.Fatalf("bad diff: %#v", d)
+	}
+	if d := got["sys/token/"]; d.Removed != 1 || d.KeysB != 4 {
+		t.Fatalf("bad diff: %#v", d)
+	}
+
+	// A corrupted archive must fail the integrity check
+	corrupt := snap1.Bytes()
+	corrupt[len(corrupt)/2] ^= 0xff
+	if _, err := InspectSnapshot(bytes.NewReader(corrupt), nil); err == nil {
+		t.Fatal("expected error inspecting corrupted snapshot")
+	}
+}
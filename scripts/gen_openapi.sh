@@ -52,6 +52,7 @@ vault secrets enable database
 vault secrets enable gcp
 vault secrets enable gcpkms
 vault secrets enable kv
+vault secrets enable ldap
 vault secrets enable mongodb
 vault secrets enable mssql
 vault secrets enable mysql
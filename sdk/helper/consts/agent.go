@@ -3,3 +3,10 @@ package consts
 // AgentPathCacheClear is the path that the agent will use as its cache-clear
 // endpoint.
 const AgentPathCacheClear = "/agent/v1/cache-clear"
+
+// AgentPathHealth is the path that the agent will use as its health endpoint.
+const AgentPathHealth = "/agent/v1/health"
+
+// AgentPathMetrics is the path that the agent will use as its metrics
+// endpoint.
+const AgentPathMetrics = "/agent/v1/metrics"
@@ -0,0 +1,81 @@
+package ldaputil
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntriesBeforePurge is the number of cached users above which
+// expired entries are removed when adding a new one.
+const maxCacheEntriesBeforePurge = 1024
+
+// CachedUser holds the results of the lookups performed for a user at login.
+type CachedUser struct {
+	BindDN string
+	UserDN string
+	Groups []string
+}
+
+// Cache holds the results of user DN and group lookups for the time
+// configured with cache_ttl, so that repeated logins of the same user don't
+// need to search the directory.
+type Cache struct {
+	l       sync.RWMutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	user    *CachedUser
+	expires time.Time
+}
+
+// NewCache returns an empty cache.
+func NewCache() *Cache {
+	return &Cache{
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Get returns the cached lookups for the user, if they haven't expired.
+func (c *Cache) Get(username string) (*CachedUser, bool) {
+	c.l.RLock()
+	defer c.l.RUnlock()
+
+	entry, ok := c.entries[username]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.user, true
+}
+
+// Put caches the lookups for the user for the given duration.
+func (c *Cache) Put(username string, user *CachedUser, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxCacheEntriesBeforePurge {
+		for key, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+	}
+
+	c.entries[username] = &cacheEntry{
+		user:    user,
+		expires: now.Add(ttl),
+	}
+}
+
+// Purge removes all the entries of the cache.
+func (c *Cache) Purge() {
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	c.entries = make(map[string]*cacheEntry)
+}
@@ -235,6 +235,100 @@ func (c *Client) performLdapFilterGroupsSearch(cfg *ConfigEntry, conn Connection
 	return result.Entries, nil
 }
 
+// nestedGroupsSearchBatchSize is the number of group DNs looked up in a
+// single search when resolving nested groups.
+const nestedGroupsSearchBatchSize = 50
+
+/*
+ * performLdapNestedGroupsSearch returns the groups which the given groups are
+ * transitively members of, excluding the given groups themselves.
+ *
+ * With the in_chain resolution, a single search using the Active Directory
+ * LDAP_MATCHING_RULE_IN_CHAIN matching rule returns all of them. With the
+ * iterative resolution, the groups referring to the groups found so far
+ * through their member or uniqueMember attributes are searched for, level by
+ * level, until no new group is found or cfg.NestedGroupMaxDepth is reached.
+ */
+func (c *Client) performLdapNestedGroupsSearch(cfg *ConfigEntry, conn Connection, entries []*ldap.Entry) ([]*ldap.Entry, error) {
+	if cfg.GroupDN == "" || len(entries) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(entries))
+	frontier := make([]string, 0, len(entries))
+	for _, e := range entries {
+		key := strings.ToLower(e.DN)
+		if !seen[key] {
+			seen[key] = true
+			frontier = append(frontier, e.DN)
+		}
+	}
+
+	var filterFormat string
+	depth := cfg.nestedGroupMaxDepth()
+	switch cfg.NestedGroupResolution {
+	case NestedGroupResolutionInChain:
+		filterFormat = "(member:1.2.840.113556.1.4.1941:=%s)"
+		depth = 1
+	case NestedGroupResolutionIterative:
+		filterFormat = "(|(member=%[1]s)(uniqueMember=%[1]s))"
+	default:
+		return nil, fmt.Errorf("invalid nested group resolution %q", cfg.NestedGroupResolution)
+	}
+
+	var nested []*ldap.Entry
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []string
+		for start := 0; start < len(frontier); start += nestedGroupsSearchBatchSize {
+			end := start + nestedGroupsSearchBatchSize
+			if end > len(frontier) {
+				end = len(frontier)
+			}
+
+			var filter strings.Builder
+			filter.WriteString("(|")
+			for _, dn := range frontier[start:end] {
+				fmt.Fprintf(&filter, filterFormat, ldap.EscapeFilter(dn))
+			}
+			filter.WriteString(")")
+
+			if c.Logger.IsDebug() {
+				c.Logger.Debug("searching nested groups", "groupdn", cfg.GroupDN, "level", level+1, "filter", filter.String())
+			}
+
+			result, err := conn.Search(&ldap.SearchRequest{
+				BaseDN: cfg.GroupDN,
+				Scope:  ldap.ScopeWholeSubtree,
+				Filter: filter.String(),
+				Attributes: []string{
+					cfg.GroupAttr,
+				},
+				SizeLimit: math.MaxInt32,
+			})
+			if err != nil {
+				return nil, errwrap.Wrapf("LDAP search for nested groups failed: {{err}}", err)
+			}
+
+			for _, e := range result.Entries {
+				key := strings.ToLower(e.DN)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				nested = append(nested, e)
+				next = append(next, e.DN)
+			}
+		}
+		frontier = next
+	}
+
+	if len(frontier) > 0 && cfg.NestedGroupResolution == NestedGroupResolutionIterative {
+		c.Logger.Warn("nested group resolution stopped at the maximum depth", "max_depth", depth)
+	}
+
+	return nested, nil
+}
+
 func sidBytesToString(b []byte) (string, error) {
 	reader := bytes.NewReader(b)
 
@@ -327,6 +421,8 @@ func (c *Client) performLdapTokenGroupsSearch(cfg *ConfigEntry, conn Connection,
  * The values of those attributes are converted to string SIDs, and then looked up to get ldap.Entry objects.
  * Otherwise, the search query is constructed according to cfg.GroupFilter, and run in context of cfg.GroupDN.
  * Groups will be resolved from the query results by following the attribute defined in cfg.GroupAttr.
+ * If cfg.NestedGroupResolution is set, the groups which those groups are transitively members of are
+ * added to the query results.
  *
  * cfg.GroupFilter is a go template and is compiled with the following context: [UserDN, Username]
  *    UserDN - The DN of the authenticated user
@@ -347,6 +443,11 @@ func (c *Client) GetLdapGroups(cfg *ConfigEntry, conn Connection, userDN string,
 		entries, err = c.performLdapTokenGroupsSearch(cfg, conn, userDN)
 	} else {
 		entries, err = c.performLdapFilterGroupsSearch(cfg, conn, userDN, username)
+		if err == nil && cfg.NestedGroupResolution != "" {
+			var nested []*ldap.Entry
+			nested, err = c.performLdapNestedGroupsSearch(cfg, conn, entries)
+			entries = append(entries, nested...)
+		}
 	}
 	if err != nil {
 		return nil, err
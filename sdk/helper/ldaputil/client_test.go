@@ -1,8 +1,11 @@
 package ldaputil
 
 import (
+	"sort"
+	"strings"
 	"testing"
 
+	"github.com/go-ldap/ldap/v3"
 	"github.com/hashicorp/go-hclog"
 )
 
@@ -82,3 +85,84 @@ func TestSIDBytesToString(t *testing.T) {
 		}
 	}
 }
+
+func TestGetLdapGroups_Nested(t *testing.T) {
+	// Group DNs and their members, with a membership cycle between the "all"
+	// and "dev" groups
+	members := map[string][]string{
+		"cn=dev,ou=groups,dc=example,dc=org": {"cn=alice,ou=users,dc=example,dc=org", "cn=all,ou=groups,dc=example,dc=org"},
+		"cn=eng,ou=groups,dc=example,dc=org": {"cn=dev,ou=groups,dc=example,dc=org"},
+		"cn=all,ou=groups,dc=example,dc=org": {"cn=eng,ou=groups,dc=example,dc=org"},
+		"cn=ops,ou=groups,dc=example,dc=org": {"cn=bob,ou=users,dc=example,dc=org"},
+	}
+	entry := func(dn string) *ldap.Entry {
+		return ldap.NewEntry(dn, map[string][]string{"cn": {strings.TrimPrefix(strings.Split(dn, ",")[0], "cn=")}})
+	}
+	var inChain func(dn string, seen map[string]bool)
+	inChain = func(dn string, seen map[string]bool) {
+		for group, groupMembers := range members {
+			for _, member := range groupMembers {
+				if member == dn && !seen[group] {
+					seen[group] = true
+					inChain(group, seen)
+				}
+			}
+		}
+	}
+	search := func(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+		result := &ldap.SearchResult{}
+		seen := make(map[string]bool)
+		for group, groupMembers := range members {
+			for _, member := range groupMembers {
+				if strings.Contains(req.Filter, "(member="+member+")") {
+					seen[group] = true
+				}
+				if strings.Contains(req.Filter, "(member:1.2.840.113556.1.4.1941:="+member+")") {
+					seen[group] = true
+					inChain(group, seen)
+				}
+			}
+		}
+		for group := range seen {
+			result.Entries = append(result.Entries, entry(group))
+		}
+		return result, nil
+	}
+
+	client := Client{
+		Logger: hclog.NewNullLogger(),
+		LDAP:   &fakeLDAP{},
+	}
+
+	tcases := []struct {
+		resolution string
+		maxDepth   int
+		expected   []string
+	}{
+		{"", 0, []string{"dev"}},
+		{NestedGroupResolutionIterative, 0, []string{"all", "dev", "eng"}},
+		{NestedGroupResolutionIterative, 1, []string{"dev", "eng"}},
+		{NestedGroupResolutionInChain, 0, []string{"all", "dev", "eng"}},
+	}
+	for _, tc := range tcases {
+		cfg := &ConfigEntry{
+			GroupDN:               "ou=groups,dc=example,dc=org",
+			GroupFilter:           "(member={{.UserDN}})",
+			GroupAttr:             "cn",
+			NestedGroupResolution: tc.resolution,
+			NestedGroupMaxDepth:   tc.maxDepth,
+		}
+		conn := &fakeConnection{search: search}
+		groups, err := client.GetLdapGroups(cfg, conn, "cn=alice,ou=users,dc=example,dc=org", "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(groups)
+		if strings.Join(groups, ",") != strings.Join(tc.expected, ",") {
+			t.Fatalf("resolution %q, max depth %d: expected %v, got %v", tc.resolution, tc.maxDepth, tc.expected, groups)
+		}
+		if tc.resolution == NestedGroupResolutionInChain && len(conn.searches) != 2 {
+			t.Fatalf("expected a single search for nested groups, got %v", conn.searches)
+		}
+	}
+}
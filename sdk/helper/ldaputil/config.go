@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/tlsutil"
@@ -14,6 +15,20 @@ import (
 	"github.com/hashicorp/errwrap"
 )
 
+const (
+	// NestedGroupResolutionInChain resolves nested groups with the Active
+	// Directory LDAP_MATCHING_RULE_IN_CHAIN matching rule.
+	NestedGroupResolutionInChain = "in_chain"
+
+	// NestedGroupResolutionIterative resolves nested groups by searching the
+	// groups referring to the groups found, level by level.
+	NestedGroupResolutionIterative = "iterative"
+
+	// DefaultNestedGroupMaxDepth is the default number of levels of nested
+	// groups resolved iteratively.
+	DefaultNestedGroupMaxDepth = 5
+)
+
 // ConfigFields returns all the config fields that can potentially be used by the LDAP client.
 // Not all fields will be used by every integration.
 func ConfigFields() map[string]*framework.FieldSchema {
@@ -178,6 +193,39 @@ Default: cn`,
 			Description: "Timeout, in seconds, for the connection when making requests against the server before returning back an error.",
 			Default:     "90s",
 		},
+
+		"connection_pool_size": {
+			Type:        framework.TypeInt,
+			Default:     0,
+			Description: "Maximum number of idle connections kept open to the server for reuse. Defaults to 0, which disables pooling.",
+		},
+
+		"connection_idle_timeout": {
+			Type:        framework.TypeDurationSecond,
+			Description: "Time after which an idle pooled connection is closed rather than reused. Defaults to 60s.",
+		},
+
+		"cache_ttl": {
+			Type:        framework.TypeDurationSecond,
+			Default:     0,
+			Description: "Time for which the user DN and group lookups of a user are cached after a successful login. Defaults to 0, which disables caching.",
+		},
+
+		"nested_group_resolution": {
+			Type:    framework.TypeString,
+			Default: "",
+			Description: `How to resolve the groups which the groups found by groupfilter are members of (optional).
+"in_chain" uses the Active Directory LDAP_MATCHING_RULE_IN_CHAIN matching rule, and
+"iterative" searches the groups with member or uniqueMember attributes referring to
+the groups found, up to nested_group_max_depth levels. Not used with use_token_groups.`,
+			AllowedValues: []interface{}{"", NestedGroupResolutionInChain, NestedGroupResolutionIterative},
+		},
+
+		"nested_group_max_depth": {
+			Type:        framework.TypeInt,
+			Default:     DefaultNestedGroupMaxDepth,
+			Description: "Maximum number of levels of nested groups resolved with the iterative nested_group_resolution. Defaults to 5.",
+		},
 	}
 }
 
@@ -312,6 +360,43 @@ func NewConfigEntry(existing *ConfigEntry, d *framework.FieldData) (*ConfigEntry
 		cfg.RequestTimeout = d.Get("request_timeout").(int)
 	}
 
+	if _, ok := d.Raw["connection_pool_size"]; ok || !hadExisting {
+		cfg.ConnectionPoolSize = d.Get("connection_pool_size").(int)
+		if cfg.ConnectionPoolSize < 0 {
+			return nil, errors.New("'connection_pool_size' cannot be negative")
+		}
+	}
+
+	if _, ok := d.Raw["connection_idle_timeout"]; ok || !hadExisting {
+		cfg.ConnectionIdleTimeout = d.Get("connection_idle_timeout").(int)
+		if cfg.ConnectionIdleTimeout < 0 {
+			return nil, errors.New("'connection_idle_timeout' cannot be negative")
+		}
+	}
+
+	if _, ok := d.Raw["cache_ttl"]; ok || !hadExisting {
+		cfg.CacheTTL = d.Get("cache_ttl").(int)
+		if cfg.CacheTTL < 0 {
+			return nil, errors.New("'cache_ttl' cannot be negative")
+		}
+	}
+
+	if _, ok := d.Raw["nested_group_resolution"]; ok || !hadExisting {
+		cfg.NestedGroupResolution = d.Get("nested_group_resolution").(string)
+		switch cfg.NestedGroupResolution {
+		case "", NestedGroupResolutionInChain, NestedGroupResolutionIterative:
+		default:
+			return nil, fmt.Errorf("invalid 'nested_group_resolution' %q", cfg.NestedGroupResolution)
+		}
+	}
+
+	if _, ok := d.Raw["nested_group_max_depth"]; ok || !hadExisting {
+		cfg.NestedGroupMaxDepth = d.Get("nested_group_max_depth").(int)
+		if cfg.NestedGroupMaxDepth < 1 {
+			return nil, errors.New("'nested_group_max_depth' must be at least 1")
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -335,6 +420,11 @@ type ConfigEntry struct {
 	UseTokenGroups           bool   `json:"use_token_groups"`
 	UsePre111GroupCNBehavior *bool  `json:"use_pre111_group_cn_behavior"`
 	RequestTimeout           int    `json:"request_timeout"`
+	ConnectionPoolSize       int    `json:"connection_pool_size"`
+	ConnectionIdleTimeout    int    `json:"connection_idle_timeout"`
+	CacheTTL                 int    `json:"cache_ttl"`
+	NestedGroupResolution    string `json:"nested_group_resolution"`
+	NestedGroupMaxDepth      int    `json:"nested_group_max_depth"`
 
 	// This json tag deviates from snake case because there was a past issue
 	// where the tag was being ignored, causing it to be jsonified as "CaseSensitiveNames".
@@ -343,6 +433,20 @@ type ConfigEntry struct {
 	CaseSensitiveNames *bool `json:"CaseSensitiveNames,omitempty"`
 }
 
+func (c *ConfigEntry) connectionIdleTimeout() time.Duration {
+	if c.ConnectionIdleTimeout <= 0 {
+		return DefaultConnectionIdleTimeout
+	}
+	return time.Duration(c.ConnectionIdleTimeout) * time.Second
+}
+
+func (c *ConfigEntry) nestedGroupMaxDepth() int {
+	if c.NestedGroupMaxDepth <= 0 {
+		return DefaultNestedGroupMaxDepth
+	}
+	return c.NestedGroupMaxDepth
+}
+
 func (c *ConfigEntry) Map() map[string]interface{} {
 	m := c.PasswordlessMap()
 	m["bindpass"] = c.BindPassword
@@ -367,6 +471,12 @@ func (c *ConfigEntry) PasswordlessMap() map[string]interface{} {
 		"tls_min_version":  c.TLSMinVersion,
 		"tls_max_version":  c.TLSMaxVersion,
 		"use_token_groups": c.UseTokenGroups,
+
+		"connection_pool_size":    c.ConnectionPoolSize,
+		"connection_idle_timeout": c.ConnectionIdleTimeout,
+		"cache_ttl":               c.CacheTTL,
+		"nested_group_resolution": c.NestedGroupResolution,
+		"nested_group_max_depth":  c.NestedGroupMaxDepth,
 	}
 	if c.CaseSensitiveNames != nil {
 		m["case_sensitive_names"] = *c.CaseSensitiveNames
@@ -10,8 +10,10 @@ import (
 // Connection provides the functionality of an LDAP connection,
 // but through an interface.
 type Connection interface {
+	Add(addRequest *ldap.AddRequest) error
 	Bind(username, password string) error
 	Close()
+	Del(delRequest *ldap.DelRequest) error
 	Modify(modifyRequest *ldap.ModifyRequest) error
 	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
 	StartTLS(config *tls.Config) error
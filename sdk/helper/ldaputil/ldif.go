@@ -0,0 +1,245 @@
+package ldaputil
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/errwrap"
+)
+
+// LDIFEntry is a change record parsed from LDIF, as described in RFC 2849.
+// Exactly one of its requests is set.
+type LDIFEntry struct {
+	Add    *ldap.AddRequest
+	Del    *ldap.DelRequest
+	Modify *ldap.ModifyRequest
+}
+
+// DN returns the distinguished name of the entry the change applies to.
+func (e *LDIFEntry) DN() string {
+	switch {
+	case e.Add != nil:
+		return e.Add.DN
+	case e.Del != nil:
+		return e.Del.DN
+	case e.Modify != nil:
+		return e.Modify.DN
+	}
+	return ""
+}
+
+type ldifLine struct {
+	attr  string
+	value string
+}
+
+/*
+ * ParseLDIF parses LDIF change records. Records without a changetype are
+ * treated as additions. The add, delete and modify change types are supported;
+ * values may be base64 encoded, but URL references and controls are not
+ * supported.
+ */
+func ParseLDIF(input string) ([]*LDIFEntry, error) {
+	records, err := ldifRecords(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*LDIFEntry
+	for i, record := range records {
+		if i == 0 && len(record) == 1 && record[0].attr == "version" {
+			if record[0].value != "1" {
+				return nil, fmt.Errorf("unsupported LDIF version %q", record[0].value)
+			}
+			continue
+		}
+
+		entry, err := parseLDIFRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no LDIF records found")
+	}
+
+	return entries, nil
+}
+
+// ldifRecords splits the input into records, unfolding continued lines and
+// decoding base64 values.
+func ldifRecords(input string) ([][]ldifLine, error) {
+	var records [][]ldifLine
+	var lines []string
+
+	flush := func() error {
+		if len(lines) == 0 {
+			return nil
+		}
+		var record []ldifLine
+		for _, line := range lines {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			if line == "-" {
+				record = append(record, ldifLine{attr: "-"})
+				continue
+			}
+
+			idx := strings.Index(line, ":")
+			if idx <= 0 {
+				return fmt.Errorf("invalid LDIF line %q", line)
+			}
+			attr, value := line[:idx], line[idx+1:]
+			switch {
+			case strings.HasPrefix(value, ":"):
+				decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value[1:]))
+				if err != nil {
+					return errwrap.Wrapf(fmt.Sprintf("invalid base64 value for attribute %q: {{err}}", attr), err)
+				}
+				value = string(decoded)
+			case strings.HasPrefix(value, "<"):
+				return fmt.Errorf("URL values are not supported for attribute %q", attr)
+			default:
+				value = strings.TrimLeft(value, " ")
+			}
+			record = append(record, ldifLine{attr: attr, value: value})
+		}
+		if len(record) > 0 {
+			records = append(records, record)
+		}
+		lines = nil
+		return nil
+	}
+
+	for _, line := range strings.Split(strings.Replace(input, "\r\n", "\n", -1), "\n") {
+		switch {
+		case strings.TrimSpace(line) == "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(line, " "):
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("invalid LDIF continuation line %q", line)
+			}
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func parseLDIFRecord(record []ldifLine) (*LDIFEntry, error) {
+	if !strings.EqualFold(record[0].attr, "dn") || record[0].value == "" {
+		return nil, fmt.Errorf("LDIF record must start with a dn, got %q", record[0].attr)
+	}
+	dn := record[0].value
+	record = record[1:]
+
+	if len(record) > 0 && strings.EqualFold(record[0].attr, "control") {
+		return nil, fmt.Errorf("controls are not supported in LDIF record for %q", dn)
+	}
+
+	changeType := "add"
+	if len(record) > 0 && strings.EqualFold(record[0].attr, "changetype") {
+		changeType = strings.ToLower(record[0].value)
+		record = record[1:]
+	}
+
+	switch changeType {
+	case "add":
+		if len(record) == 0 {
+			return nil, fmt.Errorf("LDIF record for %q has no attributes", dn)
+		}
+		req := ldap.NewAddRequest(dn, nil)
+		var order []string
+		values := make(map[string][]string)
+		for _, line := range record {
+			if line.attr == "-" {
+				return nil, fmt.Errorf("unexpected separator in LDIF record for %q", dn)
+			}
+			if _, ok := values[line.attr]; !ok {
+				order = append(order, line.attr)
+			}
+			values[line.attr] = append(values[line.attr], line.value)
+		}
+		for _, attr := range order {
+			req.Attribute(attr, values[attr])
+		}
+		return &LDIFEntry{Add: req}, nil
+
+	case "delete":
+		if len(record) > 0 {
+			return nil, fmt.Errorf("LDIF delete record for %q cannot have attributes", dn)
+		}
+		return &LDIFEntry{Del: ldap.NewDelRequest(dn, nil)}, nil
+
+	case "modify":
+		req := ldap.NewModifyRequest(dn, nil)
+		for len(record) > 0 {
+			op, attr := strings.ToLower(record[0].attr), record[0].value
+			record = record[1:]
+
+			var vals []string
+			for len(record) > 0 && record[0].attr != "-" {
+				if !strings.EqualFold(record[0].attr, attr) {
+					return nil, fmt.Errorf("LDIF record for %q modifies %q but has a value for %q", dn, attr, record[0].attr)
+				}
+				vals = append(vals, record[0].value)
+				record = record[1:]
+			}
+			if len(record) > 0 {
+				// Skip the separator
+				record = record[1:]
+			}
+
+			switch op {
+			case "add":
+				req.Add(attr, vals)
+			case "delete":
+				req.Delete(attr, vals)
+			case "replace":
+				req.Replace(attr, vals)
+			default:
+				return nil, fmt.Errorf("invalid modification %q in LDIF record for %q", op, dn)
+			}
+		}
+		if len(req.Changes) == 0 {
+			return nil, fmt.Errorf("LDIF modify record for %q has no modifications", dn)
+		}
+		return &LDIFEntry{Modify: req}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported changetype %q in LDIF record for %q", changeType, dn)
+	}
+}
+
+// ApplyLDIF performs the changes on the connection, in order. It stops at the
+// first change that fails.
+func ApplyLDIF(conn Connection, entries []*LDIFEntry) error {
+	for _, entry := range entries {
+		var err error
+		switch {
+		case entry.Add != nil:
+			err = conn.Add(entry.Add)
+		case entry.Del != nil:
+			err = conn.Del(entry.Del)
+		case entry.Modify != nil:
+			err = conn.Modify(entry.Modify)
+		}
+		if err != nil {
+			return errwrap.Wrapf(fmt.Sprintf("error applying LDIF change for %q: {{err}}", entry.DN()), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package ldaputil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+func TestParseLDIF(t *testing.T) {
+	input := `
+version: 1
+
+# Add the user
+dn: cn=alice,ou=users,dc=example,dc=org
+objectClass: person
+objectClass: inetOrgPerson
+cn: alice
+sn: Alice
+description: a long
+  description
+userPassword:: c2VjcmV0
+
+dn: cn=devs,ou=groups,dc=example,dc=org
+changetype: modify
+add: member
+member: cn=alice,ou=users,dc=example,dc=org
+-
+replace: description
+description: developers
+-
+delete: owner
+
+dn: cn=bob,ou=users,dc=example,dc=org
+changetype: delete
+`
+	entries, err := ParseLDIF(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+
+	add := ldap.NewAddRequest("cn=alice,ou=users,dc=example,dc=org", nil)
+	add.Attribute("objectClass", []string{"person", "inetOrgPerson"})
+	add.Attribute("cn", []string{"alice"})
+	add.Attribute("sn", []string{"Alice"})
+	add.Attribute("description", []string{"a long description"})
+	add.Attribute("userPassword", []string{"secret"})
+	if !reflect.DeepEqual(entries[0].Add, add) {
+		t.Fatalf("bad add request: %#v", entries[0].Add)
+	}
+
+	modify := ldap.NewModifyRequest("cn=devs,ou=groups,dc=example,dc=org", nil)
+	modify.Add("member", []string{"cn=alice,ou=users,dc=example,dc=org"})
+	modify.Replace("description", []string{"developers"})
+	modify.Delete("owner", nil)
+	if !reflect.DeepEqual(entries[1].Modify, modify) {
+		t.Fatalf("bad modify request: %#v", entries[1].Modify)
+	}
+
+	if entries[2].Del == nil || entries[2].DN() != "cn=bob,ou=users,dc=example,dc=org" {
+		t.Fatalf("bad delete request: %#v", entries[2])
+	}
+}
+
+func TestParseLDIF_Errors(t *testing.T) {
+	tcases := map[string]string{
+		"":                                 "no LDIF records found",
+		"cn: alice":                        "must start with a dn",
+		"dn: cn=alice\nchangetype: modrdn": `unsupported changetype "modrdn"`,
+		"dn: cn=alice\nchangetype: delete\ncn: a": "cannot have attributes",
+		"dn: cn=alice": "has no attributes",
+		"dn: cn=alice\njpegPhoto:< file:///a.jpg":        "URL values are not supported",
+		"dn: cn=alice\nuserPassword:: %%%":               "invalid base64 value",
+		"dn: cn=alice\nchangetype: modify\nadd: a\nb: c": `modifies "a" but has a value for "b"`,
+		"dn: cn=alice\nchangetype: modify\nmove: a":      `invalid modification "move"`,
+		"version: 2\n\ndn: cn=alice\ncn: alice":          "unsupported LDIF version",
+		"dn: cn=alice\ncontrol: 1.2.3":                   "controls are not supported",
+		"dn: cn=alice\nnot a line":                       "invalid LDIF line",
+	}
+
+	for input, expected := range tcases {
+		_, err := ParseLDIF(input)
+		if err == nil {
+			t.Fatalf("expected error for %q", input)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error for %q to contain %q, got %q", input, expected, err)
+		}
+	}
+}
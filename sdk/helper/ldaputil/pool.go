@@ -0,0 +1,133 @@
+package ldaputil
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultConnectionIdleTimeout is how long a connection is kept in a
+// ConnectionPool when the configuration doesn't set a timeout.
+const DefaultConnectionIdleTimeout = 60 * time.Second
+
+// ConnectionPool keeps idle LDAP connections for reuse, so that a connection
+// isn't dialed for every operation. The number of idle connections kept and
+// how long they are kept are taken from the configuration given to Get and
+// Put. A connection retrieved from the pool may have been bound by a previous
+// user, so callers must bind before performing any operation with it.
+type ConnectionPool struct {
+	l    sync.Mutex
+	key  string
+	idle []*idleConnection
+}
+
+type idleConnection struct {
+	conn     Connection
+	returned time.Time
+}
+
+// closingConnection is implemented by connections which can report that
+// they are being closed, such as those of the go-ldap library.
+type closingConnection interface {
+	IsClosing() bool
+}
+
+// NewConnectionPool returns an empty connection pool.
+func NewConnectionPool() *ConnectionPool {
+	return &ConnectionPool{}
+}
+
+// Get returns an idle connection for the configuration, or dials a new one
+// with the client when none is available.
+func (p *ConnectionPool) Get(c *Client, cfg *ConfigEntry) (Connection, error) {
+	if conn := p.get(cfg); conn != nil {
+		return conn, nil
+	}
+	return c.DialLDAP(cfg)
+}
+
+func (p *ConnectionPool) get(cfg *ConfigEntry) Connection {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	// Connections established for a different configuration can't be used
+	if key := poolKey(cfg); key != p.key {
+		p.closeIdle()
+		p.key = key
+		return nil
+	}
+
+	idleTimeout := cfg.connectionIdleTimeout()
+	for len(p.idle) > 0 {
+		// Use the most recently returned connection, which is the least
+		// likely to have been closed by the server
+		ic := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+
+		if time.Since(ic.returned) > idleTimeout {
+			ic.conn.Close()
+			continue
+		}
+		if cc, ok := ic.conn.(closingConnection); ok && cc.IsClosing() {
+			ic.conn.Close()
+			continue
+		}
+		return ic.conn
+	}
+
+	return nil
+}
+
+// Put returns a connection to the pool. It is closed instead if the pool
+// already holds as many idle connections as the configuration allows. Only
+// connections in a healthy state should be returned; others must be closed.
+func (p *ConnectionPool) Put(cfg *ConfigEntry, conn Connection) {
+	if conn == nil {
+		return
+	}
+
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	if poolKey(cfg) != p.key || len(p.idle) >= cfg.ConnectionPoolSize {
+		conn.Close()
+		return
+	}
+
+	p.idle = append(p.idle, &idleConnection{
+		conn:     conn,
+		returned: time.Now(),
+	})
+}
+
+// Close closes all the idle connections of the pool. Connections in use are
+// closed rather than pooled when they are returned.
+func (p *ConnectionPool) Close() {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	p.closeIdle()
+	p.key = ""
+}
+
+// Len returns the number of idle connections in the pool.
+func (p *ConnectionPool) Len() int {
+	p.l.Lock()
+	defer p.l.Unlock()
+
+	return len(p.idle)
+}
+
+func (p *ConnectionPool) closeIdle() {
+	for _, ic := range p.idle {
+		ic.conn.Close()
+	}
+	p.idle = nil
+}
+
+// poolKey identifies the configuration settings affecting how connections
+// are established.
+func poolKey(cfg *ConfigEntry) string {
+	return fmt.Sprintf("%s|%t|%t|%s|%s|%s|%d", cfg.Url, cfg.StartTLS, cfg.InsecureTLS,
+		cfg.TLSMinVersion, cfg.TLSMaxVersion, cfg.Certificate, cfg.RequestTimeout)
+}
@@ -0,0 +1,172 @@
+package ldaputil
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/hashicorp/go-hclog"
+)
+
+// fakeConnection is a Connection searching with a function given by tests.
+type fakeConnection struct {
+	closed   bool
+	closing  bool
+	searches []string
+	search   func(*ldap.SearchRequest) (*ldap.SearchResult, error)
+}
+
+func (f *fakeConnection) Add(addRequest *ldap.AddRequest) error          { return nil }
+func (f *fakeConnection) Del(delRequest *ldap.DelRequest) error          { return nil }
+func (f *fakeConnection) Bind(username, password string) error           { return nil }
+func (f *fakeConnection) Close()                                         { f.closed = true }
+func (f *fakeConnection) Modify(modifyRequest *ldap.ModifyRequest) error { return nil }
+func (f *fakeConnection) StartTLS(config *tls.Config) error              { return nil }
+func (f *fakeConnection) SetTimeout(timeout time.Duration)               {}
+func (f *fakeConnection) UnauthenticatedBind(username string) error      { return nil }
+func (f *fakeConnection) IsClosing() bool                                { return f.closing }
+func (f *fakeConnection) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	f.searches = append(f.searches, req.Filter)
+	if f.search == nil {
+		return &ldap.SearchResult{}, nil
+	}
+	return f.search(req)
+}
+
+// fakeLDAP dials fake connections.
+type fakeLDAP struct {
+	dials int
+}
+
+func (f *fakeLDAP) Dial(network, addr string) (Connection, error) {
+	f.dials++
+	return &fakeConnection{}, nil
+}
+
+func (f *fakeLDAP) DialTLS(network, addr string, config *tls.Config) (Connection, error) {
+	f.dials++
+	return &fakeConnection{}, nil
+}
+
+func TestConnectionPool(t *testing.T) {
+	fake := &fakeLDAP{}
+	client := &Client{
+		Logger: hclog.NewNullLogger(),
+		LDAP:   fake,
+	}
+	cfg := &ConfigEntry{
+		Url:                "ldap://127.0.0.1",
+		ConnectionPoolSize: 1,
+	}
+
+	pool := NewConnectionPool()
+	conn1, err := pool.Get(client, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn2, err := pool.Get(client, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fake.dials != 2 {
+		t.Fatalf("expected 2 dials, got %d", fake.dials)
+	}
+
+	// Only one idle connection is kept
+	pool.Put(cfg, conn1)
+	pool.Put(cfg, conn2)
+	if pool.Len() != 1 {
+		t.Fatalf("expected 1 idle connection, got %d", pool.Len())
+	}
+	if !conn2.(*fakeConnection).closed {
+		t.Fatal("expected connection above the pool size to be closed")
+	}
+
+	conn, err := pool.Get(client, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn != conn1 || fake.dials != 2 {
+		t.Fatal("expected idle connection to be reused")
+	}
+
+	// Connections being closed are not reused
+	conn1.(*fakeConnection).closing = true
+	pool.Put(cfg, conn1)
+	if conn, _ = pool.Get(client, cfg); conn == conn1 || fake.dials != 3 {
+		t.Fatal("expected closing connection not to be reused")
+	}
+
+	// Connections idle for too long are not reused
+	pool.Put(cfg, conn)
+	pool.idle[0].returned = time.Now().Add(-2 * DefaultConnectionIdleTimeout)
+	if conn, _ = pool.Get(client, cfg); fake.dials != 4 {
+		t.Fatal("expected expired connection not to be reused")
+	}
+
+	// Connections are not reused across configurations
+	pool.Put(cfg, conn)
+	other := &ConfigEntry{
+		Url:                "ldap://127.0.0.2",
+		ConnectionPoolSize: 1,
+	}
+	if _, err := pool.Get(client, other); err != nil {
+		t.Fatal(err)
+	}
+	if fake.dials != 5 || !conn.(*fakeConnection).closed {
+		t.Fatal("expected connection of a different configuration to be closed")
+	}
+
+	// Connections in use when the pool is closed are not pooled
+	conn, _ = pool.Get(client, other)
+	pool.Close()
+	pool.Put(other, conn)
+	if pool.Len() != 0 || !conn.(*fakeConnection).closed {
+		t.Fatal("expected connection returned after close to be closed")
+	}
+
+	// Without a pool size, connections are always closed
+	cfg.ConnectionPoolSize = 0
+	conn, _ = pool.Get(client, cfg)
+	pool.Put(cfg, conn)
+	if pool.Len() != 0 || !conn.(*fakeConnection).closed {
+		t.Fatal("expected connection to be closed")
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache()
+
+	if _, ok := cache.Get("alice"); ok {
+		t.Fatal("expected empty cache")
+	}
+
+	user := &CachedUser{
+		BindDN: "cn=alice,dc=example,dc=org",
+		UserDN: "cn=alice,dc=example,dc=org",
+		Groups: []string{"admins"},
+	}
+	cache.Put("alice", user, time.Minute)
+	if cached, ok := cache.Get("alice"); !ok || cached != user {
+		t.Fatalf("expected cached user, got %#v", cached)
+	}
+
+	// Entries are not cached without a TTL
+	cache.Put("bob", user, 0)
+	if _, ok := cache.Get("bob"); ok {
+		t.Fatal("expected bob not to be cached")
+	}
+
+	// Expired entries are not returned
+	cache.entries["alice"].expires = time.Now().Add(-time.Second)
+	if _, ok := cache.Get("alice"); ok {
+		t.Fatal("expected alice to be expired")
+	}
+
+	cache.Put("alice", user, time.Minute)
+	cache.Purge()
+	if _, ok := cache.Get("alice"); ok {
+		t.Fatal("expected alice to be purged")
+	}
+}
@@ -89,6 +89,21 @@ type Auth struct {
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs"`
 
+	// BindClientCert requests that the token only be usable by clients
+	// presenting the TLS client certificate that was presented at login
+	BindClientCert bool `json:"bind_client_cert"`
+
+	// BoundCertFingerprint is the fingerprint of the TLS client certificate
+	// the token is bound to. It is set by core at login when BindClientCert
+	// is requested.
+	BoundCertFingerprint string `json:"bound_cert_fingerprint"`
+
+	// MFAValidated is set by auth methods which validated a second factor at
+	// login. It is not carried over the plugin protocol, so only builtin auth
+	// methods can set it, and core trusts it as set. Only helper/mfa sets it;
+	// backends must never derive it from client-supplied data.
+	MFAValidated bool `json:"mfa_validated"`
+
 	// CreationPath is a path that the backend can return to use in the lease.
 	// This is currently only supported for the token store where roles may
 	// change the perceived path of the lease, even though they don't change
@@ -114,6 +114,14 @@ type TokenEntry struct {
 	// Explicit maximum TTL on the token
 	ExplicitMaxTTL time.Duration `json:"explicit_max_ttl" mapstructure:"explicit_max_ttl" structs:"explicit_max_ttl" sentinel:""`
 
+	// Time the token was last used, recorded coarsely. Zero if the token
+	// was never used, or its use has not yet been persisted.
+	LastUsedTime int64 `json:"last_used_time" mapstructure:"last_used_time" structs:"last_used_time" sentinel:""`
+
+	// If non-zero, the token is revoked once it has not been used for this
+	// long
+	MaxIdleTTL time.Duration `json:"max_idle_ttl" mapstructure:"max_idle_ttl" structs:"max_idle_ttl" sentinel:""`
+
 	// If set, the role that was used for parameters at creation time
 	Role string `json:"role" mapstructure:"role" structs:"role"`
 
@@ -133,6 +141,19 @@ type TokenEntry struct {
 	// The set of CIDRs that this token can be used with
 	BoundCIDRs []*sockaddr.SockAddrMarshaler `json:"bound_cidrs" sentinel:""`
 
+	// BoundCertFingerprint, if set, is the fingerprint of the TLS client
+	// certificate clients must present to use this token
+	BoundCertFingerprint string `json:"bound_cert_fingerprint" mapstructure:"bound_cert_fingerprint" structs:"bound_cert_fingerprint" sentinel:""`
+
+	// MFAValidated is set on tokens issued by a login which validated a
+	// second factor, and on their children
+	MFAValidated bool `json:"mfa_validated" mapstructure:"mfa_validated" structs:"mfa_validated" sentinel:""`
+
+	// LoginMeta is the metadata set by the auth method at login, which child
+	// tokens inherit. Unlike Meta, it can't be supplied by the caller creating
+	// a token, so policy conditions are evaluated against it.
+	LoginMeta map[string]string `json:"login_meta" mapstructure:"login_meta" structs:"login_meta" sentinel:""`
+
 	// NamespaceID is the identifier of the namespace to which this token is
 	// confined to. Do not return this value over the API when the token is
 	// being looked up.
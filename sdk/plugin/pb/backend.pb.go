@@ -530,7 +530,13 @@ type Auth struct {
 	// TokenType is the type of token being requested
 	TokenType uint32 `sentinel:"" protobuf:"varint,17,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
 	// Whether the default policy should be added automatically by core
-	NoDefaultPolicy      bool     `sentinel:"" protobuf:"varint,18,opt,name=no_default_policy,json=noDefaultPolicy,proto3" json:"no_default_policy,omitempty"`
+	NoDefaultPolicy bool `sentinel:"" protobuf:"varint,18,opt,name=no_default_policy,json=noDefaultPolicy,proto3" json:"no_default_policy,omitempty"`
+	// BindClientCert requests that the token be bound to the TLS client
+	// certificate presented at login
+	BindClientCert bool `sentinel:"" protobuf:"varint,19,opt,name=bind_client_cert,json=bindClientCert,proto3" json:"bind_client_cert,omitempty"`
+	// BoundCertFingerprint is the fingerprint of the TLS client certificate
+	// the token is bound to
+	BoundCertFingerprint string   `sentinel:"" protobuf:"bytes,20,opt,name=bound_cert_fingerprint,json=boundCertFingerprint,proto3" json:"bound_cert_fingerprint,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -687,6 +693,20 @@ func (m *Auth) GetNoDefaultPolicy() bool {
 	return false
 }
 
+func (m *Auth) GetBindClientCert() bool {
+	if m != nil {
+		return m.BindClientCert
+	}
+	return false
+}
+
+func (m *Auth) GetBoundCertFingerprint() string {
+	if m != nil {
+		return m.BoundCertFingerprint
+	}
+	return ""
+}
+
 type TokenEntry struct {
 	ID                   string            `sentinel:"" protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	Accessor             string            `sentinel:"" protobuf:"bytes,2,opt,name=accessor,proto3" json:"accessor,omitempty"`
@@ -706,6 +726,9 @@ type TokenEntry struct {
 	NamespaceID          string            `sentinel:"" protobuf:"bytes,16,opt,name=namespace_id,json=namespaceID,proto3" json:"namespace_id,omitempty"`
 	CubbyholeID          string            `sentinel:"" protobuf:"bytes,17,opt,name=cubbyhole_id,json=cubbyholeId,proto3" json:"cubbyhole_id,omitempty"`
 	Type                 uint32            `sentinel:"" protobuf:"varint,18,opt,name=type,proto3" json:"type,omitempty"`
+	BoundCertFingerprint string            `sentinel:"" protobuf:"bytes,19,opt,name=bound_cert_fingerprint,json=boundCertFingerprint,proto3" json:"bound_cert_fingerprint,omitempty"`
+	LoginMeta            map[string]string `sentinel:"" protobuf:"bytes,20,rep,name=login_meta,json=loginMeta,proto3" json:"login_meta,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	MFAValidated         bool              `sentinel:"" protobuf:"varint,21,opt,name=mfa_validated,json=mfaValidated,proto3" json:"mfa_validated,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
 	XXX_unrecognized     []byte            `json:"-"`
 	XXX_sizecache        int32             `json:"-"`
@@ -862,6 +885,27 @@ func (m *TokenEntry) GetType() uint32 {
 	return 0
 }
 
+func (m *TokenEntry) GetBoundCertFingerprint() string {
+	if m != nil {
+		return m.BoundCertFingerprint
+	}
+	return ""
+}
+
+func (m *TokenEntry) GetLoginMeta() map[string]string {
+	if m != nil {
+		return m.LoginMeta
+	}
+	return nil
+}
+
+func (m *TokenEntry) GetMFAValidated() bool {
+	if m != nil {
+		return m.MFAValidated
+	}
+	return false
+}
+
 type LeaseOptions struct {
 	TTL                  int64                `sentinel:"" protobuf:"varint,1,opt,name=TTL,proto3" json:"TTL,omitempty"`
 	Renewable            bool                 `sentinel:"" protobuf:"varint,2,opt,name=renewable,proto3" json:"renewable,omitempty"`
@@ -2712,6 +2756,7 @@ func init() {
 	proto.RegisterType((*Auth)(nil), "pb.Auth")
 	proto.RegisterMapType((map[string]string)(nil), "pb.Auth.MetadataEntry")
 	proto.RegisterType((*TokenEntry)(nil), "pb.TokenEntry")
+	proto.RegisterMapType((map[string]string)(nil), "pb.TokenEntry.LoginMetaEntry")
 	proto.RegisterMapType((map[string]string)(nil), "pb.TokenEntry.MetaEntry")
 	proto.RegisterType((*LeaseOptions)(nil), "pb.LeaseOptions")
 	proto.RegisterType((*Secret)(nil), "pb.Secret")
@@ -2758,167 +2803,173 @@ func init() {
 func init() { proto.RegisterFile("sdk/plugin/pb/backend.proto", fileDescriptor_4dbf1dfe0c11846b) }
 
 var fileDescriptor_4dbf1dfe0c11846b = []byte{
-	// 2545 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x59, 0xdb, 0x72, 0x1b, 0xc7,
-	0xd1, 0x2e, 0x00, 0xc4, 0xa9, 0x71, 0x1e, 0xd2, 0xfa, 0x57, 0x90, 0xfc, 0x8b, 0x5e, 0x47, 0x32,
-	0xcd, 0xd8, 0xa0, 0x45, 0xc5, 0xb1, 0x9c, 0x54, 0xe2, 0xa2, 0x29, 0x4a, 0x66, 0x4c, 0xda, 0xac,
-	0x25, 0x14, 0xe7, 0x54, 0x05, 0x0f, 0x76, 0x87, 0xe0, 0x16, 0x17, 0xbb, 0x9b, 0xd9, 0x59, 0x8a,
-	0xc8, 0x4d, 0xde, 0x22, 0x6f, 0x90, 0xeb, 0xdc, 0xe6, 0x2e, 0x97, 0x71, 0xe5, 0x3e, 0xaf, 0x90,
-	0xe7, 0x48, 0x4d, 0xcf, 0xec, 0x09, 0x00, 0x6d, 0xb9, 0xca, 0xb9, 0xdb, 0xe9, 0xee, 0xe9, 0x99,
-	0xe9, 0xf9, 0xfa, 0xeb, 0x1e, 0x00, 0xee, 0x45, 0xce, 0xd5, 0x5e, 0xe8, 0xc5, 0x33, 0xd7, 0xdf,
-	0x0b, 0xa7, 0x7b, 0x53, 0x6a, 0x5f, 0x31, 0xdf, 0x19, 0x85, 0x3c, 0x10, 0x01, 0x29, 0x87, 0xd3,
-	0xe1, 0x83, 0x59, 0x10, 0xcc, 0x3c, 0xb6, 0x87, 0x92, 0x69, 0x7c, 0xb1, 0x27, 0xdc, 0x39, 0x8b,
-	0x04, 0x9d, 0x87, 0xca, 0x68, 0x38, 0x94, 0x1e, 0xbc, 0x60, 0xe6, 0xda, 0xd4, 0xdb, 0x73, 0x1d,
-	0xe6, 0x0b, 0x57, 0x2c, 0xb4, 0xce, 0xc8, 0xeb, 0xd4, 0x2a, 0x4a, 0x63, 0xd6, 0xa1, 0x7a, 0x34,
-	0x0f, 0xc5, 0xc2, 0xdc, 0x86, 0xda, 0x67, 0x8c, 0x3a, 0x8c, 0x93, 0x3b, 0x50, 0xbb, 0xc4, 0x2f,
-	0xa3, 0xb4, 0x5d, 0xd9, 0x69, 0x5a, 0x7a, 0x64, 0xfe, 0x1e, 0xe0, 0x4c, 0xce, 0x39, 0xe2, 0x3c,
-	0xe0, 0xe4, 0x2e, 0x34, 0x18, 0xe7, 0x13, 0xb1, 0x08, 0x99, 0x51, 0xda, 0x2e, 0xed, 0x74, 0xac,
-	0x3a, 0xe3, 0x7c, 0xbc, 0x08, 0x19, 0xf9, 0x3f, 0x90, 0x9f, 0x93, 0x79, 0x34, 0x33, 0xca, 0xdb,
-	0x25, 0xe9, 0x81, 0x71, 0x7e, 0x1a, 0xcd, 0x92, 0x39, 0x76, 0xe0, 0x30, 0xa3, 0xb2, 0x5d, 0xda,
-	0xa9, 0xe0, 0x9c, 0xc3, 0xc0, 0x61, 0xe6, 0x5f, 0x4a, 0x50, 0x3d, 0xa3, 0xe2, 0x32, 0x22, 0x04,
-	0x36, 0x78, 0x10, 0x08, 0xbd, 0x38, 0x7e, 0x93, 0x1d, 0xe8, 0xc5, 0x3e, 0x8d, 0xc5, 0xa5, 0x3c,
-	0x95, 0x4d, 0x05, 0x73, 0x8c, 0x32, 0xaa, 0x97, 0xc5, 0xe4, 0x6d, 0xe8, 0x78, 0x81, 0x4d, 0xbd,
-	0x49, 0x24, 0x02, 0x4e, 0x67, 0x72, 0x1d, 0x69, 0xd7, 0x46, 0xe1, 0xb9, 0x92, 0x91, 0x5d, 0x18,
-	0x44, 0x8c, 0x7a, 0x93, 0x57, 0x9c, 0x86, 0xa9, 0xe1, 0x86, 0x72, 0x28, 0x15, 0x5f, 0x71, 0x1a,
-	0x6a, 0x5b, 0xf3, 0x1f, 0x35, 0xa8, 0x5b, 0xec, 0x8f, 0x31, 0x8b, 0x04, 0xe9, 0x42, 0xd9, 0x75,
-	0xf0, 0xb4, 0x4d, 0xab, 0xec, 0x3a, 0x64, 0x04, 0xc4, 0x62, 0xa1, 0x27, 0x97, 0x76, 0x03, 0xff,
-	0xd0, 0x8b, 0x23, 0xc1, 0xb8, 0x3e, 0xf3, 0x1a, 0x0d, 0xb9, 0x0f, 0xcd, 0x20, 0x64, 0x1c, 0x65,
-	0x18, 0x80, 0xa6, 0x95, 0x09, 0xe4, 0xc1, 0x43, 0x2a, 0x2e, 0x8d, 0x0d, 0x54, 0xe0, 0xb7, 0x94,
-	0x39, 0x54, 0x50, 0xa3, 0xaa, 0x64, 0xf2, 0x9b, 0x98, 0x50, 0x8b, 0x98, 0xcd, 0x99, 0x30, 0x6a,
-	0xdb, 0xa5, 0x9d, 0xd6, 0x3e, 0x8c, 0xc2, 0xe9, 0xe8, 0x1c, 0x25, 0x96, 0xd6, 0x90, 0xfb, 0xb0,
-	0x21, 0xe3, 0x62, 0xd4, 0xd1, 0xa2, 0x21, 0x2d, 0x0e, 0x62, 0x71, 0x69, 0xa1, 0x94, 0xec, 0x43,
-	0x5d, 0xdd, 0x69, 0x64, 0x34, 0xb6, 0x2b, 0x3b, 0xad, 0x7d, 0x43, 0x1a, 0xe8, 0x53, 0x8e, 0x14,
-	0x0c, 0xa2, 0x23, 0x5f, 0xf0, 0x85, 0x95, 0x18, 0x92, 0xb7, 0xa0, 0x6d, 0x7b, 0x2e, 0xf3, 0xc5,
-	0x44, 0x04, 0x57, 0xcc, 0x37, 0x9a, 0xb8, 0xa3, 0x96, 0x92, 0x8d, 0xa5, 0x88, 0xec, 0xc3, 0x1b,
-	0x79, 0x93, 0x09, 0xb5, 0x6d, 0x16, 0x45, 0x01, 0x37, 0x00, 0x6d, 0x37, 0x73, 0xb6, 0x07, 0x5a,
-	0x25, 0xdd, 0x3a, 0x6e, 0x14, 0x7a, 0x74, 0x31, 0xf1, 0xe9, 0x9c, 0x19, 0x2d, 0xe5, 0x56, 0xcb,
-	0xbe, 0xa0, 0x73, 0x46, 0x1e, 0x40, 0x6b, 0x1e, 0xc4, 0xbe, 0x98, 0x84, 0x81, 0xeb, 0x0b, 0xa3,
-	0x8d, 0x16, 0x80, 0xa2, 0x33, 0x29, 0x21, 0x6f, 0x82, 0x1a, 0x29, 0x30, 0x76, 0x54, 0x5c, 0x51,
-	0x82, 0x70, 0x7c, 0x08, 0x5d, 0xa5, 0x4e, 0xf7, 0xd3, 0x45, 0x93, 0x0e, 0x4a, 0xd3, 0x9d, 0x7c,
-	0x00, 0x4d, 0xc4, 0x83, 0xeb, 0x5f, 0x04, 0x46, 0x0f, 0xe3, 0xb6, 0x99, 0x0b, 0x8b, 0xc4, 0xc4,
-	0xb1, 0x7f, 0x11, 0x58, 0x8d, 0x57, 0xfa, 0x8b, 0xfc, 0x02, 0xee, 0x15, 0xce, 0xcb, 0xd9, 0x9c,
-	0xba, 0xbe, 0xeb, 0xcf, 0x26, 0x71, 0xc4, 0x22, 0xa3, 0x8f, 0x08, 0x37, 0x72, 0xa7, 0xb6, 0x12,
-	0x83, 0x97, 0x11, 0x8b, 0xc8, 0x3d, 0x68, 0xaa, 0x24, 0x9d, 0xb8, 0x8e, 0x31, 0xc0, 0x2d, 0x35,
-	0x94, 0xe0, 0xd8, 0x21, 0xef, 0x40, 0x2f, 0x0c, 0x3c, 0xd7, 0x5e, 0x4c, 0x82, 0x6b, 0xc6, 0xb9,
-	0xeb, 0x30, 0x83, 0x6c, 0x97, 0x76, 0x1a, 0x56, 0x57, 0x89, 0xbf, 0xd4, 0xd2, 0x75, 0xa9, 0xb1,
-	0x89, 0x86, 0x2b, 0xa9, 0x31, 0x02, 0xb0, 0x03, 0xdf, 0x67, 0x36, 0xc2, 0x6f, 0x0b, 0x4f, 0xd8,
-	0x95, 0x27, 0x3c, 0x4c, 0xa5, 0x56, 0xce, 0x62, 0xf8, 0x1c, 0xda, 0x79, 0x28, 0x90, 0x3e, 0x54,
-	0xae, 0xd8, 0x42, 0xc3, 0x5f, 0x7e, 0x92, 0x6d, 0xa8, 0x5e, 0x53, 0x2f, 0x66, 0x08, 0x79, 0x0d,
-	0x44, 0x35, 0xc5, 0x52, 0x8a, 0x9f, 0x95, 0x9f, 0x96, 0xcc, 0xff, 0x54, 0x61, 0x43, 0x82, 0x8f,
-	0x7c, 0x08, 0x1d, 0x8f, 0xd1, 0x88, 0x4d, 0x82, 0x50, 0x2e, 0x10, 0xa1, 0xab, 0xd6, 0x7e, 0x5f,
-	0x4e, 0x3b, 0x91, 0x8a, 0x2f, 0x95, 0xdc, 0x6a, 0x7b, 0xb9, 0x91, 0x4c, 0x69, 0xd7, 0x17, 0x8c,
-	0xfb, 0xd4, 0x9b, 0x60, 0x32, 0xa8, 0x04, 0x6b, 0x27, 0xc2, 0x67, 0x32, 0x29, 0x96, 0x71, 0x54,
-	0x59, 0xc5, 0xd1, 0x10, 0x1a, 0x18, 0x3b, 0x97, 0x45, 0x3a, 0xd9, 0xd3, 0x31, 0xd9, 0x87, 0xc6,
-	0x9c, 0x09, 0xaa, 0x73, 0x4d, 0xa6, 0xc4, 0x9d, 0x24, 0x67, 0x46, 0xa7, 0x5a, 0xa1, 0x12, 0x22,
-	0xb5, 0x5b, 0xc9, 0x88, 0xda, 0x6a, 0x46, 0x0c, 0xa1, 0x91, 0x82, 0xae, 0xae, 0x6e, 0x38, 0x19,
-	0x4b, 0x9a, 0x0d, 0x19, 0x77, 0x03, 0xc7, 0x68, 0x20, 0x50, 0xf4, 0x48, 0x92, 0xa4, 0x1f, 0xcf,
-	0x15, 0x84, 0x9a, 0x8a, 0x24, 0xfd, 0x78, 0xbe, 0x8a, 0x18, 0x58, 0x42, 0xcc, 0x8f, 0xa0, 0x4a,
-	0x3d, 0x97, 0x46, 0x98, 0x42, 0xf2, 0x66, 0x35, 0xdf, 0x8f, 0x0e, 0xa4, 0xd4, 0x52, 0x4a, 0xf2,
-	0x04, 0x3a, 0x33, 0x1e, 0xc4, 0xe1, 0x04, 0x87, 0x2c, 0x32, 0xda, 0x78, 0xda, 0x65, 0xeb, 0x36,
-	0x1a, 0x1d, 0x28, 0x1b, 0x99, 0x81, 0xd3, 0x20, 0xf6, 0x9d, 0x89, 0xed, 0x3a, 0x3c, 0x32, 0x3a,
-	0x18, 0x3c, 0x40, 0xd1, 0xa1, 0x94, 0xc8, 0x14, 0x53, 0x29, 0x90, 0x06, 0xb8, 0x8b, 0x36, 0x1d,
-	0x94, 0x9e, 0x25, 0x51, 0xfe, 0x31, 0x0c, 0x92, 0xc2, 0x94, 0x59, 0xf6, 0xd0, 0xb2, 0x9f, 0x28,
-	0x52, 0xe3, 0x1d, 0xe8, 0xb3, 0x1b, 0x49, 0xa1, 0xae, 0x98, 0xcc, 0xe9, 0xcd, 0x44, 0x08, 0x4f,
-	0xa7, 0x54, 0x37, 0x91, 0x9f, 0xd2, 0x9b, 0xb1, 0xf0, 0x64, 0xfe, 0xab, 0xd5, 0x31, 0xff, 0x07,
-	0x58, 0x8c, 0x9a, 0x28, 0xc1, 0xfc, 0xdf, 0x85, 0x81, 0x1f, 0x4c, 0x1c, 0x76, 0x41, 0x63, 0x4f,
-	0xa8, 0x75, 0x17, 0x3a, 0x99, 0x7a, 0x7e, 0xf0, 0x4c, 0xc9, 0x71, 0xd9, 0xc5, 0xf0, 0xe7, 0xd0,
-	0x29, 0x5c, 0xf7, 0x1a, 0xd0, 0x6f, 0xe5, 0x41, 0xdf, 0xcc, 0x03, 0xfd, 0x5f, 0x1b, 0x00, 0x78,
-	0xef, 0x6a, 0xea, 0x72, 0xb5, 0xc8, 0x83, 0xa1, 0xbc, 0x06, 0x0c, 0x94, 0x33, 0x5f, 0x68, 0xe0,
-	0xea, 0xd1, 0xb7, 0x62, 0x36, 0xa9, 0x17, 0xd5, 0x5c, 0xbd, 0x78, 0x0f, 0x36, 0x24, 0x3e, 0x8d,
-	0x5a, 0x46, 0xeb, 0xd9, 0x8e, 0x10, 0xc9, 0x0a, 0xc5, 0x68, 0xb5, 0x92, 0x34, 0xf5, 0xd5, 0xa4,
-	0xc9, 0xa3, 0xb1, 0x51, 0x44, 0xe3, 0xdb, 0xd0, 0xb1, 0x39, 0xc3, 0xda, 0x35, 0x91, 0xcd, 0x88,
-	0x46, 0x6b, 0x3b, 0x11, 0x8e, 0xdd, 0x39, 0x93, 0xf1, 0x93, 0x17, 0x07, 0xa8, 0x92, 0x9f, 0x6b,
-	0xef, 0xb5, 0xb5, 0xf6, 0x5e, 0xb1, 0x13, 0xf0, 0x98, 0x66, 0x7c, 0xfc, 0xce, 0x65, 0x4d, 0xa7,
-	0x90, 0x35, 0x85, 0xd4, 0xe8, 0x2e, 0xa5, 0xc6, 0x12, 0x7e, 0x7b, 0x2b, 0xf8, 0x7d, 0x0b, 0xda,
-	0x32, 0x00, 0x51, 0x48, 0x6d, 0x26, 0x1d, 0xf4, 0x55, 0x20, 0x52, 0xd9, 0xb1, 0x83, 0xd9, 0x1e,
-	0x4f, 0xa7, 0x8b, 0xcb, 0xc0, 0x63, 0x19, 0x61, 0xb7, 0x52, 0xd9, 0xb1, 0x23, 0xf7, 0x8b, 0x08,
-	0x24, 0x88, 0x40, 0xfc, 0x1e, 0x7e, 0x04, 0xcd, 0x34, 0xea, 0xdf, 0x0b, 0x4c, 0x7f, 0x2b, 0x41,
-	0x3b, 0x4f, 0x8a, 0x72, 0xf2, 0x78, 0x7c, 0x82, 0x93, 0x2b, 0x96, 0xfc, 0x94, 0xed, 0x04, 0x67,
-	0x3e, 0x7b, 0x45, 0xa7, 0x9e, 0x72, 0xd0, 0xb0, 0x32, 0x81, 0xd4, 0xba, 0xbe, 0xcd, 0xd9, 0x3c,
-	0x41, 0x55, 0xc5, 0xca, 0x04, 0xe4, 0x63, 0x00, 0x37, 0x8a, 0x62, 0xa6, 0x6e, 0x6e, 0x03, 0x29,
-	0x63, 0x38, 0x52, 0x3d, 0xe6, 0x28, 0xe9, 0x31, 0x47, 0xe3, 0xa4, 0xc7, 0xb4, 0x9a, 0x68, 0x8d,
-	0x57, 0x7a, 0x07, 0x6a, 0xf2, 0x82, 0xc6, 0x27, 0x88, 0xbc, 0x8a, 0xa5, 0x47, 0xe6, 0x9f, 0xa1,
-	0xa6, 0xba, 0x90, 0xff, 0x29, 0xd1, 0xdf, 0x85, 0x86, 0xf2, 0xed, 0x3a, 0x3a, 0x57, 0xea, 0x38,
-	0x3e, 0x76, 0xcc, 0x6f, 0xca, 0xd0, 0xb0, 0x58, 0x14, 0x06, 0x7e, 0xc4, 0x72, 0x5d, 0x52, 0xe9,
-	0x3b, 0xbb, 0xa4, 0xf2, 0xda, 0x2e, 0x29, 0xe9, 0xbd, 0x2a, 0xb9, 0xde, 0x6b, 0x08, 0x0d, 0xce,
-	0x1c, 0x97, 0x33, 0x5b, 0xe8, 0x3e, 0x2d, 0x1d, 0x4b, 0xdd, 0x2b, 0xca, 0x65, 0x79, 0x8f, 0xb0,
-	0x86, 0x34, 0xad, 0x74, 0x4c, 0x1e, 0xe7, 0x9b, 0x0b, 0xd5, 0xb6, 0x6d, 0xa9, 0xe6, 0x42, 0x6d,
-	0x77, 0x4d, 0x77, 0xf1, 0x24, 0x6b, 0xd2, 0xea, 0x98, 0xcd, 0x77, 0xf3, 0x13, 0xd6, 0x77, 0x69,
-	0x3f, 0x58, 0xcd, 0xfe, 0xa6, 0x0c, 0xfd, 0xe5, 0xbd, 0xad, 0x41, 0xe0, 0x16, 0x54, 0x55, 0xed,
-	0xd3, 0xf0, 0x15, 0x2b, 0x55, 0xaf, 0xb2, 0x44, 0x74, 0x9f, 0x2c, 0x93, 0xc6, 0x77, 0x43, 0xaf,
-	0x48, 0x28, 0xef, 0x42, 0x5f, 0x86, 0x28, 0x64, 0x4e, 0xd6, 0xcf, 0x29, 0x06, 0xec, 0x69, 0x79,
-	0xda, 0xd1, 0xed, 0xc2, 0x20, 0x31, 0xcd, 0xb8, 0xa1, 0x56, 0xb0, 0x3d, 0x4a, 0x28, 0xe2, 0x0e,
-	0xd4, 0x2e, 0x02, 0x3e, 0xa7, 0x42, 0x93, 0xa0, 0x1e, 0x15, 0x48, 0x0e, 0xd9, 0xb6, 0xa1, 0x30,
-	0x99, 0x08, 0xe5, 0x9b, 0x45, 0x92, 0x4f, 0xfa, 0x9e, 0x40, 0x16, 0x6c, 0x58, 0x8d, 0xe4, 0x1d,
-	0x61, 0xfe, 0x06, 0x7a, 0x4b, 0x2d, 0xe4, 0x9a, 0x40, 0x66, 0xcb, 0x97, 0x0b, 0xcb, 0x17, 0x3c,
-	0x57, 0x96, 0x3c, 0xff, 0x16, 0x06, 0x9f, 0x51, 0xdf, 0xf1, 0x98, 0xf6, 0x7f, 0xc0, 0x67, 0x91,
-	0x2c, 0x86, 0xfa, 0x45, 0x33, 0xd1, 0xd5, 0xa7, 0x63, 0x35, 0xb5, 0xe4, 0xd8, 0x21, 0x0f, 0xa1,
-	0xce, 0x95, 0xb5, 0x06, 0x40, 0x2b, 0xd7, 0xe3, 0x5a, 0x89, 0xce, 0xfc, 0x1a, 0x48, 0xc1, 0xb5,
-	0x7c, 0xcc, 0x2c, 0xc8, 0x8e, 0x44, 0xbf, 0x02, 0x85, 0xce, 0xaa, 0x76, 0x1e, 0x93, 0x56, 0xaa,
-	0x25, 0xdb, 0x50, 0x61, 0x9c, 0xeb, 0x25, 0xb0, 0xc9, 0xcc, 0x9e, 0x8e, 0x96, 0x54, 0x99, 0x7d,
-	0xe8, 0x1e, 0xfb, 0xae, 0x70, 0xa9, 0xe7, 0xfe, 0x89, 0xc9, 0x9d, 0x9b, 0x4f, 0xa0, 0x97, 0x49,
-	0xd4, 0x82, 0xda, 0x4d, 0xe9, 0x76, 0x37, 0x3f, 0x81, 0xc1, 0x79, 0xc8, 0x6c, 0x97, 0x7a, 0xf8,
-	0x7a, 0x54, 0xd3, 0x1e, 0x40, 0x55, 0xde, 0x55, 0xc2, 0x3b, 0x4d, 0x9c, 0x88, 0x6a, 0x25, 0x37,
-	0xbf, 0x06, 0x43, 0x1d, 0xef, 0xe8, 0xc6, 0x8d, 0x04, 0xf3, 0x6d, 0x76, 0x78, 0xc9, 0xec, 0xab,
-	0x1f, 0x30, 0x80, 0xd7, 0x70, 0x77, 0xdd, 0x0a, 0xc9, 0xfe, 0x5a, 0xb6, 0x1c, 0x4d, 0x2e, 0x64,
-	0x09, 0xc2, 0x35, 0x1a, 0x16, 0xa0, 0xe8, 0xb9, 0x94, 0x48, 0x38, 0x30, 0x39, 0x2f, 0xd2, 0xb4,
-	0xae, 0x47, 0x49, 0x3c, 0x2a, 0xb7, 0xc7, 0xe3, 0xef, 0x25, 0x68, 0x9e, 0x33, 0x11, 0x87, 0x78,
-	0x96, 0x7b, 0xd0, 0x9c, 0xf2, 0xe0, 0x8a, 0xf1, 0xec, 0x28, 0x0d, 0x25, 0x38, 0x76, 0xc8, 0x63,
-	0xa8, 0x1d, 0x06, 0xfe, 0x85, 0x3b, 0xc3, 0xb7, 0xb4, 0xe6, 0x97, 0x74, 0xee, 0x48, 0xe9, 0x14,
-	0xbf, 0x68, 0x43, 0xb2, 0x0d, 0x2d, 0xfd, 0xcb, 0xc4, 0xcb, 0x97, 0xc7, 0xcf, 0x92, 0x26, 0x3b,
-	0x27, 0x1a, 0x7e, 0x0c, 0xad, 0xdc, 0xc4, 0xef, 0x55, 0xf1, 0xfe, 0x1f, 0x00, 0x57, 0x57, 0x31,
-	0xea, 0x67, 0x57, 0xdf, 0x54, 0x47, 0x7b, 0x00, 0x4d, 0xd9, 0xcf, 0x29, 0x75, 0x52, 0x6b, 0x4b,
-	0x59, 0xad, 0x35, 0x1f, 0xc2, 0xe0, 0xd8, 0xbf, 0xa6, 0x9e, 0xeb, 0x50, 0xc1, 0x3e, 0x67, 0x0b,
-	0x0c, 0xc1, 0xca, 0x0e, 0xcc, 0x73, 0x68, 0xeb, 0xc7, 0xfd, 0x6b, 0xed, 0xb1, 0xad, 0xf7, 0xf8,
-	0xed, 0xb9, 0xf8, 0x2e, 0xf4, 0xb4, 0xd3, 0x13, 0x57, 0x67, 0xa2, 0x6c, 0x55, 0x38, 0xbb, 0x70,
-	0x6f, 0xb4, 0x6b, 0x3d, 0x32, 0x9f, 0x42, 0x3f, 0x67, 0x9a, 0x1e, 0xe7, 0x8a, 0x2d, 0xa2, 0xe4,
-	0x47, 0x0f, 0xf9, 0x9d, 0x44, 0xa0, 0x9c, 0x45, 0xc0, 0x84, 0xae, 0x9e, 0xf9, 0x82, 0x89, 0x5b,
-	0x4e, 0xf7, 0x79, 0xba, 0x91, 0x17, 0x4c, 0x3b, 0x7f, 0x04, 0x55, 0x26, 0x4f, 0x9a, 0x2f, 0xc3,
-	0xf9, 0x08, 0x58, 0x4a, 0xbd, 0x66, 0xc1, 0xa7, 0xe9, 0x82, 0x67, 0xb1, 0x5a, 0xf0, 0x35, 0x7d,
-	0x99, 0x6f, 0xa7, 0xdb, 0x38, 0x8b, 0xc5, 0x6d, 0x37, 0xfa, 0x10, 0x06, 0xda, 0xe8, 0x19, 0xf3,
-	0x98, 0x60, 0xb7, 0x1c, 0xe9, 0x11, 0x90, 0x82, 0xd9, 0x6d, 0xee, 0xee, 0x43, 0x63, 0x3c, 0x3e,
-	0x49, 0xb5, 0x45, 0x8a, 0x35, 0x77, 0xa0, 0x3d, 0xa6, 0xb2, 0x95, 0x70, 0x94, 0x85, 0x01, 0x75,
-	0xa1, 0xc6, 0x3a, 0x01, 0x93, 0xa1, 0xb9, 0x0f, 0x5b, 0x87, 0xd4, 0xbe, 0x74, 0xfd, 0xd9, 0x33,
-	0x37, 0x92, 0xbd, 0x94, 0x9e, 0x31, 0x84, 0x86, 0xa3, 0x05, 0x7a, 0x4a, 0x3a, 0x36, 0xdf, 0x87,
-	0x37, 0x72, 0x3f, 0xf8, 0x9c, 0x0b, 0x9a, 0x6c, 0x73, 0x0b, 0xaa, 0x91, 0x1c, 0xe1, 0x8c, 0xaa,
-	0xa5, 0x06, 0xe6, 0x17, 0xb0, 0x95, 0x2f, 0xaf, 0xb2, 0xb3, 0xc1, 0xc3, 0x27, 0x3d, 0x47, 0x29,
-	0xd7, 0x73, 0xe8, 0xa3, 0x94, 0xb3, 0x6a, 0xd1, 0x87, 0xca, 0xaf, 0xbe, 0x1a, 0x6b, 0x0c, 0xca,
-	0x4f, 0xf3, 0x0f, 0x72, 0xf9, 0xa2, 0x3f, 0xb5, 0x7c, 0xa1, 0xf1, 0x28, 0xbd, 0x56, 0xe3, 0xb1,
-	0x0a, 0x83, 0xf7, 0x61, 0x70, 0xea, 0x05, 0xf6, 0xd5, 0x91, 0x9f, 0x8b, 0x86, 0x01, 0x75, 0xe6,
-	0xe7, 0x83, 0x91, 0x0c, 0xcd, 0x77, 0xa0, 0x77, 0x12, 0xd8, 0xd4, 0x3b, 0x0d, 0x62, 0x5f, 0xa4,
-	0x51, 0xc0, 0x5f, 0xe0, 0xb4, 0xa9, 0x1a, 0x98, 0xef, 0x43, 0x57, 0x17, 0x60, 0xff, 0x22, 0x48,
-	0x08, 0x2b, 0x2b, 0xd5, 0xa5, 0x62, 0x1b, 0x6f, 0x9e, 0x40, 0x2f, 0x33, 0x57, 0x7e, 0xdf, 0x81,
-	0x9a, 0x52, 0xeb, 0xb3, 0xf5, 0xd2, 0x77, 0xac, 0xb2, 0xb4, 0xb4, 0x7a, 0xcd, 0xa1, 0xce, 0x60,
-	0xeb, 0x85, 0x7c, 0xe4, 0x46, 0xcf, 0x03, 0xae, 0x8d, 0x75, 0xb6, 0xd4, 0xf0, 0xf1, 0xab, 0x92,
-	0x31, 0xff, 0x34, 0x46, 0x73, 0x4b, 0x6b, 0xd7, 0x78, 0x9c, 0x43, 0xf7, 0x0c, 0x7f, 0x5b, 0x3d,
-	0xf2, 0xaf, 0x95, 0xaf, 0x63, 0x20, 0xea, 0xd7, 0xd6, 0x09, 0xf3, 0xaf, 0x5d, 0x1e, 0xf8, 0xd8,
-	0x8c, 0x97, 0x74, 0xcb, 0x93, 0xf8, 0x4d, 0x27, 0x25, 0x16, 0xd6, 0x20, 0x5c, 0x16, 0xad, 0xbd,
-	0x15, 0xc8, 0x7e, 0xb9, 0x91, 0x35, 0x85, 0xb3, 0x79, 0x20, 0xd8, 0x84, 0x3a, 0x4e, 0x92, 0x16,
-	0xa0, 0x44, 0x07, 0x8e, 0xc3, 0xf7, 0xff, 0x5a, 0x81, 0xfa, 0xa7, 0x8a, 0xa9, 0xc9, 0x2f, 0xa1,
-	0x53, 0x28, 0xef, 0xe4, 0x0d, 0x6c, 0x03, 0x97, 0x9b, 0x89, 0xe1, 0x9d, 0x15, 0xb1, 0x3a, 0xd7,
-	0x07, 0xd0, 0xce, 0x57, 0x5d, 0x82, 0x15, 0x16, 0x7f, 0x47, 0x1e, 0xa2, 0xa7, 0xd5, 0x92, 0x7c,
-	0x0e, 0x5b, 0xeb, 0xea, 0x21, 0xb9, 0x9f, 0xad, 0xb0, 0x5a, 0x8b, 0x87, 0x6f, 0xde, 0xa6, 0x4d,
-	0xea, 0x68, 0xfd, 0xd0, 0x63, 0xd4, 0x8f, 0xc3, 0xfc, 0x0e, 0xb2, 0x4f, 0xf2, 0x18, 0x3a, 0x85,
-	0x8a, 0xa0, 0xce, 0xb9, 0x52, 0x24, 0xf2, 0x53, 0x1e, 0x41, 0x15, 0xab, 0x10, 0xe9, 0x14, 0xca,
-	0xe1, 0xb0, 0x9b, 0x0e, 0xd5, 0xda, 0x1f, 0x02, 0x64, 0xdd, 0x0a, 0x21, 0xca, 0x6f, 0xbe, 0x9f,
-	0x19, 0x6e, 0x16, 0x65, 0x49, 0x47, 0xb3, 0x81, 0x3f, 0x4a, 0xe4, 0xf6, 0x8b, 0x0b, 0xa5, 0x95,
-	0x6d, 0xff, 0xdf, 0x25, 0xa8, 0x27, 0x3f, 0x54, 0x3f, 0x86, 0x0d, 0x59, 0x23, 0xc8, 0x66, 0x8e,
-	0x66, 0x93, 0xfa, 0x32, 0xdc, 0x5a, 0x12, 0xaa, 0x05, 0x46, 0x50, 0x79, 0xc1, 0x84, 0xda, 0x50,
-	0xb1, 0x58, 0x0c, 0x37, 0x8b, 0xb2, 0xd4, 0xfe, 0x2c, 0x2e, 0xda, 0x6b, 0xae, 0x2f, 0xd8, 0xa7,
-	0x2c, 0xfe, 0x11, 0xd4, 0x14, 0x0b, 0xab, 0x58, 0xae, 0xf0, 0xb7, 0xc2, 0xcc, 0x2a, 0x5f, 0xef,
-	0xff, 0x73, 0x03, 0xe0, 0x7c, 0x11, 0x09, 0x36, 0xff, 0xb5, 0xcb, 0x5e, 0x91, 0x5d, 0xe8, 0xe9,
-	0x9f, 0x5e, 0xf0, 0x45, 0x28, 0x69, 0x2d, 0x17, 0x13, 0xec, 0x2b, 0x53, 0x32, 0x7f, 0x04, 0xad,
-	0x53, 0x7a, 0xf3, 0x3a, 0x76, 0x75, 0x4d, 0xf1, 0x79, 0x1b, 0xac, 0x51, 0x05, 0xea, 0xff, 0x29,
-	0xf4, 0x96, 0x08, 0x3e, 0x6f, 0x8f, 0xbf, 0x9a, 0xac, 0x2d, 0x00, 0x4f, 0xe5, 0xa3, 0xa8, 0x48,
-	0xf2, 0xf9, 0x89, 0xfa, 0x81, 0xb6, 0xae, 0x0a, 0xbc, 0x28, 0x3e, 0xa7, 0xf0, 0x25, 0x6b, 0x2c,
-	0xf3, 0x70, 0x52, 0x05, 0x86, 0x77, 0xd7, 0x69, 0xd2, 0xcc, 0xcb, 0x53, 0xf1, 0x4a, 0xe6, 0xad,
-	0xf2, 0xf4, 0x7b, 0x00, 0x19, 0x1b, 0xe7, 0xed, 0xf1, 0x7a, 0x97, 0x89, 0xfa, 0x43, 0x80, 0x8c,
-	0x63, 0x15, 0x2a, 0x8a, 0x14, 0xad, 0xa6, 0x2d, 0xf3, 0xf0, 0x2e, 0x34, 0x53, 0x16, 0xcb, 0xaf,
-	0x81, 0x0e, 0x96, 0x48, 0xf1, 0x13, 0xe8, 0x2d, 0x11, 0xef, 0xda, 0x75, 0x30, 0x3c, 0xeb, 0x18,
-	0xfa, 0xd3, 0xdd, 0xdf, 0xed, 0xcc, 0x5c, 0x71, 0x19, 0x4f, 0x47, 0x76, 0x30, 0xdf, 0xbb, 0xa4,
-	0xd1, 0xa5, 0x6b, 0x07, 0x3c, 0xdc, 0xbb, 0x96, 0x68, 0xda, 0x2b, 0xfc, 0x91, 0x36, 0xad, 0xe1,
-	0x83, 0xf2, 0xc9, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xe9, 0x9a, 0xc2, 0x92, 0x60, 0x1b, 0x00,
-	0x00,
+	// 2643 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x59, 0x5b, 0x73, 0xdb, 0xc6,
+	0xf5, 0x1f, 0x92, 0x12, 0x2f, 0x87, 0x57, 0xad, 0x68, 0xff, 0x61, 0xda, 0xf9, 0x9b, 0x41, 0x6a,
+	0x47, 0x71, 0x13, 0x2a, 0x96, 0x93, 0xc6, 0x69, 0xd3, 0x66, 0x1c, 0x59, 0x76, 0xd4, 0xc8, 0x89,
+	0x06, 0x62, 0x92, 0xde, 0x66, 0x10, 0x10, 0x58, 0x52, 0x18, 0x81, 0x00, 0xba, 0x58, 0xc8, 0x62,
+	0x5f, 0xfa, 0x2d, 0xfa, 0xde, 0x87, 0x3e, 0xf7, 0xb5, 0x6f, 0x7d, 0x6b, 0xf3, 0x05, 0xfa, 0x7d,
+	0x3a, 0x7b, 0x76, 0x71, 0x25, 0x95, 0x38, 0x33, 0xe9, 0xdb, 0xee, 0xef, 0x9c, 0x3d, 0x67, 0xf7,
+	0xec, 0xb9, 0x61, 0x01, 0xb7, 0x23, 0xe7, 0x62, 0x3f, 0xf4, 0xe2, 0x85, 0xeb, 0xef, 0x87, 0xb3,
+	0xfd, 0x99, 0x65, 0x5f, 0x50, 0xdf, 0x99, 0x84, 0x2c, 0xe0, 0x01, 0xa9, 0x86, 0xb3, 0xd1, 0xdd,
+	0x45, 0x10, 0x2c, 0x3c, 0xba, 0x8f, 0xc8, 0x2c, 0x9e, 0xef, 0x73, 0x77, 0x49, 0x23, 0x6e, 0x2d,
+	0x43, 0xc9, 0x34, 0x1a, 0x09, 0x09, 0x5e, 0xb0, 0x70, 0x6d, 0xcb, 0xdb, 0x77, 0x1d, 0xea, 0x73,
+	0x97, 0xaf, 0x14, 0x4d, 0xcb, 0xd3, 0xa4, 0x16, 0x49, 0xd1, 0x1b, 0xb0, 0x7d, 0xb4, 0x0c, 0xf9,
+	0x4a, 0x1f, 0x43, 0xfd, 0x53, 0x6a, 0x39, 0x94, 0x91, 0x9b, 0x50, 0x3f, 0xc7, 0x91, 0x56, 0x19,
+	0xd7, 0xf6, 0x5a, 0x86, 0x9a, 0xe9, 0xbf, 0x07, 0x38, 0x15, 0x6b, 0x8e, 0x18, 0x0b, 0x18, 0xb9,
+	0x05, 0x4d, 0xca, 0x98, 0xc9, 0x57, 0x21, 0xd5, 0x2a, 0xe3, 0xca, 0x5e, 0xd7, 0x68, 0x50, 0xc6,
+	0xa6, 0xab, 0x90, 0x92, 0xff, 0x03, 0x31, 0x34, 0x97, 0xd1, 0x42, 0xab, 0x8e, 0x2b, 0x42, 0x02,
+	0x65, 0xec, 0x45, 0xb4, 0x48, 0xd6, 0xd8, 0x81, 0x43, 0xb5, 0xda, 0xb8, 0xb2, 0x57, 0xc3, 0x35,
+	0x87, 0x81, 0x43, 0xf5, 0xbf, 0x54, 0x60, 0xfb, 0xd4, 0xe2, 0xe7, 0x11, 0x21, 0xb0, 0xc5, 0x82,
+	0x80, 0x2b, 0xe5, 0x38, 0x26, 0x7b, 0xd0, 0x8f, 0x7d, 0x2b, 0xe6, 0xe7, 0xe2, 0x54, 0xb6, 0xc5,
+	0xa9, 0xa3, 0x55, 0x91, 0x5c, 0x86, 0xc9, 0x1b, 0xd0, 0xf5, 0x02, 0xdb, 0xf2, 0xcc, 0x88, 0x07,
+	0xcc, 0x5a, 0x08, 0x3d, 0x82, 0xaf, 0x83, 0xe0, 0x99, 0xc4, 0xc8, 0x03, 0xd8, 0x89, 0xa8, 0xe5,
+	0x99, 0x2f, 0x99, 0x15, 0xa6, 0x8c, 0x5b, 0x52, 0xa0, 0x20, 0x7c, 0xcd, 0xac, 0x50, 0xf1, 0xea,
+	0xff, 0xac, 0x43, 0xc3, 0xa0, 0x7f, 0x8c, 0x69, 0xc4, 0x49, 0x0f, 0xaa, 0xae, 0x83, 0xa7, 0x6d,
+	0x19, 0x55, 0xd7, 0x21, 0x13, 0x20, 0x06, 0x0d, 0x3d, 0xa1, 0xda, 0x0d, 0xfc, 0x43, 0x2f, 0x8e,
+	0x38, 0x65, 0xea, 0xcc, 0x1b, 0x28, 0xe4, 0x0e, 0xb4, 0x82, 0x90, 0x32, 0xc4, 0xd0, 0x00, 0x2d,
+	0x23, 0x03, 0xc4, 0xc1, 0x43, 0x8b, 0x9f, 0x6b, 0x5b, 0x48, 0xc0, 0xb1, 0xc0, 0x1c, 0x8b, 0x5b,
+	0xda, 0xb6, 0xc4, 0xc4, 0x98, 0xe8, 0x50, 0x8f, 0xa8, 0xcd, 0x28, 0xd7, 0xea, 0xe3, 0xca, 0x5e,
+	0xfb, 0x00, 0x26, 0xe1, 0x6c, 0x72, 0x86, 0x88, 0xa1, 0x28, 0xe4, 0x0e, 0x6c, 0x09, 0xbb, 0x68,
+	0x0d, 0xe4, 0x68, 0x0a, 0x8e, 0x27, 0x31, 0x3f, 0x37, 0x10, 0x25, 0x07, 0xd0, 0x90, 0x77, 0x1a,
+	0x69, 0xcd, 0x71, 0x6d, 0xaf, 0x7d, 0xa0, 0x09, 0x06, 0x75, 0xca, 0x89, 0x74, 0x83, 0xe8, 0xc8,
+	0xe7, 0x6c, 0x65, 0x24, 0x8c, 0xe4, 0x75, 0xe8, 0xd8, 0x9e, 0x4b, 0x7d, 0x6e, 0xf2, 0xe0, 0x82,
+	0xfa, 0x5a, 0x0b, 0x77, 0xd4, 0x96, 0xd8, 0x54, 0x40, 0xe4, 0x00, 0x6e, 0xe4, 0x59, 0x4c, 0xcb,
+	0xb6, 0x69, 0x14, 0x05, 0x4c, 0x03, 0xe4, 0xdd, 0xcd, 0xf1, 0x3e, 0x51, 0x24, 0x21, 0xd6, 0x71,
+	0xa3, 0xd0, 0xb3, 0x56, 0xa6, 0x6f, 0x2d, 0xa9, 0xd6, 0x96, 0x62, 0x15, 0xf6, 0xb9, 0xb5, 0xa4,
+	0xe4, 0x2e, 0xb4, 0x97, 0x41, 0xec, 0x73, 0x33, 0x0c, 0x5c, 0x9f, 0x6b, 0x1d, 0xe4, 0x00, 0x84,
+	0x4e, 0x05, 0x42, 0x5e, 0x03, 0x39, 0x93, 0xce, 0xd8, 0x95, 0x76, 0x45, 0x04, 0xdd, 0xf1, 0x1e,
+	0xf4, 0x24, 0x39, 0xdd, 0x4f, 0x0f, 0x59, 0xba, 0x88, 0xa6, 0x3b, 0x79, 0x17, 0x5a, 0xe8, 0x0f,
+	0xae, 0x3f, 0x0f, 0xb4, 0x3e, 0xda, 0x6d, 0x37, 0x67, 0x16, 0xe1, 0x13, 0xc7, 0xfe, 0x3c, 0x30,
+	0x9a, 0x2f, 0xd5, 0x88, 0xfc, 0x12, 0x6e, 0x17, 0xce, 0xcb, 0xe8, 0xd2, 0x72, 0x7d, 0xd7, 0x5f,
+	0x98, 0x71, 0x44, 0x23, 0x6d, 0x80, 0x1e, 0xae, 0xe5, 0x4e, 0x6d, 0x24, 0x0c, 0x5f, 0x46, 0x34,
+	0x22, 0xb7, 0xa1, 0x25, 0x83, 0xd4, 0x74, 0x1d, 0x6d, 0x07, 0xb7, 0xd4, 0x94, 0xc0, 0xb1, 0x43,
+	0xde, 0x84, 0x7e, 0x18, 0x78, 0xae, 0xbd, 0x32, 0x83, 0x4b, 0xca, 0x98, 0xeb, 0x50, 0x8d, 0x8c,
+	0x2b, 0x7b, 0x4d, 0xa3, 0x27, 0xe1, 0x2f, 0x14, 0xba, 0x29, 0x34, 0x76, 0x91, 0xb1, 0x0c, 0x93,
+	0x09, 0x80, 0x1d, 0xf8, 0x3e, 0xb5, 0xd1, 0xfd, 0x86, 0x78, 0xc2, 0x9e, 0x38, 0xe1, 0x61, 0x8a,
+	0x1a, 0x39, 0x8e, 0xd1, 0x33, 0xe8, 0xe4, 0x5d, 0x81, 0x0c, 0xa0, 0x76, 0x41, 0x57, 0xca, 0xfd,
+	0xc5, 0x90, 0x8c, 0x61, 0xfb, 0xd2, 0xf2, 0x62, 0xaa, 0x55, 0x33, 0x47, 0x94, 0x4b, 0x0c, 0x49,
+	0xf8, 0x79, 0xf5, 0x71, 0x45, 0xff, 0x57, 0x1d, 0xb6, 0x84, 0xf3, 0x91, 0xf7, 0xa1, 0xeb, 0x51,
+	0x2b, 0xa2, 0x66, 0x10, 0x0a, 0x05, 0x11, 0x8a, 0x6a, 0x1f, 0x0c, 0xc4, 0xb2, 0x13, 0x41, 0xf8,
+	0x42, 0xe2, 0x46, 0xc7, 0xcb, 0xcd, 0x44, 0x48, 0xbb, 0x3e, 0xa7, 0xcc, 0xb7, 0x3c, 0x13, 0x83,
+	0x41, 0x06, 0x58, 0x27, 0x01, 0x9f, 0x8a, 0xa0, 0x28, 0xfb, 0x51, 0x6d, 0xdd, 0x8f, 0x46, 0xd0,
+	0x44, 0xdb, 0xb9, 0x34, 0x52, 0xc1, 0x9e, 0xce, 0xc9, 0x01, 0x34, 0x97, 0x94, 0x5b, 0x2a, 0xd6,
+	0x44, 0x48, 0xdc, 0x4c, 0x62, 0x66, 0xf2, 0x42, 0x11, 0x64, 0x40, 0xa4, 0x7c, 0x6b, 0x11, 0x51,
+	0x5f, 0x8f, 0x88, 0x11, 0x34, 0x53, 0xa7, 0x6b, 0xc8, 0x1b, 0x4e, 0xe6, 0x22, 0xcd, 0x86, 0x94,
+	0xb9, 0x81, 0xa3, 0x35, 0xd1, 0x51, 0xd4, 0x4c, 0x24, 0x49, 0x3f, 0x5e, 0x4a, 0x17, 0x6a, 0xc9,
+	0x24, 0xe9, 0xc7, 0xcb, 0x75, 0x8f, 0x81, 0x92, 0xc7, 0xfc, 0x04, 0xb6, 0x2d, 0xcf, 0xb5, 0x22,
+	0xad, 0xad, 0x6e, 0x56, 0xe5, 0xfb, 0xc9, 0x13, 0x81, 0x1a, 0x92, 0x48, 0x1e, 0x41, 0x77, 0xc1,
+	0x82, 0x38, 0x34, 0x71, 0x4a, 0x23, 0xad, 0x33, 0xae, 0x6d, 0xe0, 0xee, 0x20, 0xd3, 0x13, 0xc9,
+	0x23, 0x22, 0x70, 0x16, 0xc4, 0xbe, 0x63, 0xda, 0xae, 0xc3, 0x22, 0xad, 0x8b, 0xc6, 0x03, 0x84,
+	0x0e, 0x05, 0x22, 0x42, 0x4c, 0x86, 0x40, 0x6a, 0xe0, 0x1e, 0xf2, 0x74, 0x11, 0x3d, 0x4d, 0xac,
+	0xfc, 0x53, 0xd8, 0x49, 0x0a, 0x53, 0xc6, 0xd9, 0x47, 0xce, 0x41, 0x42, 0x48, 0x99, 0xf7, 0x60,
+	0x40, 0xaf, 0x44, 0x0a, 0x75, 0xb9, 0xb9, 0xb4, 0xae, 0x4c, 0xce, 0x3d, 0x15, 0x52, 0xbd, 0x04,
+	0x7f, 0x61, 0x5d, 0x4d, 0xb9, 0x27, 0xe2, 0x5f, 0x6a, 0xc7, 0xf8, 0xdf, 0xc1, 0x62, 0xd4, 0x42,
+	0x04, 0xe3, 0xff, 0x01, 0xec, 0xf8, 0x81, 0xe9, 0xd0, 0xb9, 0x15, 0x7b, 0x5c, 0xea, 0x5d, 0xa9,
+	0x60, 0xea, 0xfb, 0xc1, 0x53, 0x89, 0xa3, 0xda, 0x95, 0x50, 0x3a, 0x73, 0xc5, 0x41, 0xe5, 0xc5,
+	0xda, 0x94, 0x71, 0x15, 0x4e, 0x3d, 0x81, 0x1f, 0x22, 0x7c, 0x48, 0x19, 0x27, 0xef, 0xc1, 0x4d,
+	0x65, 0x13, 0xca, 0xb8, 0x39, 0x77, 0xfd, 0x05, 0x65, 0x21, 0x13, 0x09, 0x6a, 0x88, 0x17, 0x33,
+	0x94, 0xe6, 0xa1, 0x8c, 0x3f, 0xcb, 0x68, 0xa3, 0x5f, 0x40, 0xb7, 0xe0, 0x4e, 0x1b, 0x82, 0x6a,
+	0x98, 0x0f, 0xaa, 0x56, 0x3e, 0x90, 0xfe, 0x5a, 0x07, 0x40, 0xbf, 0x92, 0x4b, 0xcb, 0xd5, 0x28,
+	0xef, 0x6c, 0xd5, 0x0d, 0xce, 0x66, 0x31, 0xea, 0x73, 0x15, 0x18, 0x6a, 0xf6, 0x9d, 0x31, 0x91,
+	0xd4, 0xa3, 0xed, 0x5c, 0x3d, 0x7a, 0x1b, 0xb6, 0x84, 0xff, 0x6b, 0xf5, 0xac, 0x6c, 0x64, 0x3b,
+	0xc2, 0x48, 0xc1, 0x91, 0x81, 0x5c, 0x6b, 0x41, 0xd9, 0x58, 0x0f, 0xca, 0xbc, 0xb7, 0x37, 0x8b,
+	0xde, 0xfe, 0x06, 0x74, 0x6d, 0x46, 0xb1, 0x36, 0x9a, 0xa2, 0xd9, 0x51, 0xd1, 0xd0, 0x49, 0xc0,
+	0xa9, 0xbb, 0xa4, 0xc2, 0x7e, 0xc2, 0x31, 0x00, 0x49, 0x62, 0xb8, 0xd1, 0x6f, 0xda, 0x1b, 0xfd,
+	0x06, 0x3b, 0x0d, 0x8f, 0xaa, 0x8a, 0x82, 0xe3, 0x5c, 0x54, 0x76, 0x0b, 0x51, 0x59, 0x08, 0xbd,
+	0x5e, 0x29, 0xf4, 0x4a, 0xf1, 0xd1, 0x5f, 0x8b, 0x8f, 0xd7, 0xa1, 0x23, 0x0c, 0x10, 0x85, 0x96,
+	0x4d, 0x85, 0x80, 0x81, 0x34, 0x44, 0x8a, 0x1d, 0x3b, 0x98, 0x4d, 0xe2, 0xd9, 0x6c, 0x75, 0x1e,
+	0x78, 0x34, 0x2b, 0x08, 0xed, 0x14, 0x3b, 0x76, 0xc4, 0x7e, 0xd1, 0xc3, 0x09, 0x7a, 0x38, 0x8e,
+	0xbf, 0xc3, 0x0d, 0x77, 0xaf, 0x77, 0x43, 0xf2, 0x11, 0x80, 0x88, 0x77, 0xdf, 0xc4, 0xcb, 0x1c,
+	0xe2, 0x65, 0xbe, 0x56, 0xba, 0xcc, 0x13, 0xc1, 0x90, 0xdd, 0x68, 0xcb, 0x4b, 0xe6, 0xe2, 0x62,
+	0x96, 0x73, 0xcb, 0xbc, 0xb4, 0x3c, 0xd7, 0xc1, 0x82, 0x73, 0x03, 0x23, 0xa4, 0xb3, 0x9c, 0x5b,
+	0x5f, 0x25, 0xd8, 0xe8, 0x03, 0x68, 0xa5, 0x8b, 0x7f, 0x88, 0x97, 0x8f, 0x3e, 0x82, 0x5e, 0x51,
+	0xf5, 0x0f, 0x8a, 0x91, 0xbf, 0x57, 0xa0, 0x93, 0xaf, 0x25, 0x62, 0xf1, 0x74, 0x7a, 0x82, 0x8b,
+	0x6b, 0x86, 0x18, 0x8a, 0x2e, 0x8c, 0x51, 0x9f, 0xbe, 0xb4, 0x66, 0x9e, 0x14, 0xd0, 0x34, 0x32,
+	0x40, 0x50, 0x5d, 0xdf, 0x66, 0x74, 0x99, 0x04, 0x4b, 0xcd, 0xc8, 0x00, 0xf2, 0x21, 0x80, 0x1b,
+	0x45, 0x31, 0x95, 0x0e, 0xb9, 0x85, 0x99, 0x76, 0x34, 0x91, 0xad, 0xf9, 0x24, 0x69, 0xcd, 0x27,
+	0xd3, 0xa4, 0x35, 0x37, 0x5a, 0xc8, 0x2d, 0xe6, 0xc2, 0xb3, 0x84, 0xdf, 0x4d, 0x4f, 0x30, 0xa0,
+	0x6a, 0x86, 0x9a, 0xe9, 0x7f, 0x86, 0xba, 0x6c, 0xde, 0xfe, 0xa7, 0xf5, 0xf1, 0x16, 0x34, 0xa5,
+	0x6c, 0xd7, 0x51, 0x29, 0xa0, 0x81, 0xf3, 0x63, 0x47, 0xff, 0xb6, 0x0a, 0x4d, 0x83, 0x46, 0x61,
+	0xe0, 0x47, 0x34, 0xd7, 0x5c, 0x56, 0xbe, 0xb7, 0xb9, 0xac, 0x6e, 0x6c, 0x2e, 0x93, 0x96, 0xb5,
+	0x96, 0x6b, 0x59, 0x47, 0xd0, 0x64, 0xd4, 0x71, 0x19, 0xb5, 0xb9, 0x6a, 0x6f, 0xd3, 0xb9, 0xa0,
+	0xbd, 0xb4, 0x98, 0xe8, 0x8a, 0x22, 0x2c, 0xbd, 0x2d, 0x23, 0x9d, 0x93, 0x87, 0xf9, 0x9e, 0x4c,
+	0x76, 0xbb, 0x43, 0xd9, 0x93, 0xc9, 0xed, 0x6e, 0x68, 0xca, 0x1e, 0x65, 0xbd, 0x6d, 0x03, 0xfd,
+	0xfa, 0x56, 0x7e, 0xc1, 0xe6, 0xe6, 0xf6, 0x47, 0x6b, 0x75, 0xbe, 0xad, 0xc2, 0xa0, 0xbc, 0xb7,
+	0x0d, 0x1e, 0x38, 0x84, 0x6d, 0xd9, 0x32, 0x28, 0xf7, 0xe5, 0x6b, 0xcd, 0x42, 0xad, 0x94, 0xbf,
+	0x3f, 0x2e, 0xe7, 0xc2, 0xef, 0x77, 0xbd, 0x62, 0x9e, 0x7c, 0x0b, 0x06, 0xc2, 0x44, 0x21, 0x75,
+	0xb2, 0x36, 0x58, 0x26, 0xf6, 0xbe, 0xc2, 0xd3, 0x46, 0xf8, 0x01, 0xec, 0x24, 0xac, 0x59, 0xca,
+	0xab, 0x17, 0x78, 0x8f, 0x92, 0xcc, 0x77, 0x13, 0xea, 0xf3, 0x80, 0x2d, 0x2d, 0xae, 0x72, 0xbb,
+	0x9a, 0x15, 0x72, 0x37, 0x16, 0x91, 0xa6, 0xf4, 0xc9, 0x04, 0x14, 0x9f, 0x7a, 0x22, 0xa7, 0xa6,
+	0x9f, 0x61, 0x98, 0xdc, 0x9b, 0x46, 0x33, 0xf9, 0xfc, 0xd2, 0x7f, 0x03, 0xfd, 0x52, 0xe7, 0xbd,
+	0xc1, 0x90, 0x99, 0xfa, 0x6a, 0x41, 0x7d, 0x41, 0x72, 0xad, 0x24, 0xf9, 0xb7, 0xb0, 0xf3, 0xa9,
+	0xe5, 0x3b, 0x1e, 0x55, 0xf2, 0x9f, 0xb0, 0x45, 0x24, 0x7a, 0x08, 0xf5, 0x21, 0x68, 0xaa, 0xa2,
+	0xda, 0x35, 0x5a, 0x0a, 0x39, 0x76, 0xc8, 0x3d, 0x68, 0x30, 0xc9, 0xad, 0x1c, 0xa0, 0x9d, 0xfb,
+	0x34, 0x30, 0x12, 0x9a, 0xfe, 0x0d, 0x90, 0x82, 0x68, 0xf1, 0x0d, 0x28, 0x9a, 0x8a, 0x26, 0x53,
+	0x4e, 0xa1, 0xa2, 0xaa, 0x93, 0xf7, 0x49, 0x23, 0xa5, 0x92, 0x31, 0xd4, 0x28, 0x63, 0x5a, 0x35,
+	0xeb, 0xcd, 0xb3, 0x2f, 0x6e, 0x43, 0x90, 0xf4, 0x01, 0xf4, 0x8e, 0x7d, 0x97, 0xbb, 0x96, 0xe7,
+	0xfe, 0x89, 0x8a, 0x9d, 0xeb, 0x8f, 0xa0, 0x9f, 0x21, 0x52, 0xa1, 0x12, 0x53, 0xb9, 0x5e, 0xcc,
+	0x7b, 0xb0, 0x73, 0x16, 0x52, 0xdb, 0xb5, 0x3c, 0xfc, 0xe8, 0x96, 0xcb, 0xee, 0xc2, 0xb6, 0xb8,
+	0xab, 0x24, 0xef, 0xb4, 0x70, 0x21, 0x92, 0x25, 0xae, 0x7f, 0x03, 0x9a, 0x3c, 0xde, 0xd1, 0x95,
+	0x1b, 0x71, 0xea, 0xdb, 0xf4, 0xf0, 0x9c, 0xda, 0x17, 0x3f, 0xa2, 0x01, 0x2f, 0xe1, 0xd6, 0x26,
+	0x0d, 0xc9, 0xfe, 0xda, 0xb6, 0x98, 0x99, 0x73, 0x51, 0xd3, 0x50, 0x47, 0xd3, 0x00, 0x84, 0x9e,
+	0x09, 0x44, 0xb8, 0x03, 0x15, 0xeb, 0x22, 0x95, 0xd6, 0xd5, 0x2c, 0xb1, 0x47, 0xed, 0x7a, 0x7b,
+	0xfc, 0xa3, 0x02, 0xad, 0x33, 0xca, 0xe3, 0x10, 0xcf, 0x72, 0x1b, 0x5a, 0x33, 0x16, 0x5c, 0x50,
+	0x96, 0x1d, 0xa5, 0x29, 0x81, 0x63, 0x87, 0x3c, 0x84, 0xfa, 0x61, 0xe0, 0xcf, 0xdd, 0x85, 0x56,
+	0xcd, 0xf2, 0x4b, 0xba, 0x76, 0x22, 0x69, 0x32, 0xbf, 0x28, 0x46, 0x32, 0x86, 0xb6, 0x7a, 0xd0,
+	0xf9, 0xf2, 0xcb, 0xe3, 0xa7, 0xc9, 0xb7, 0x49, 0x0e, 0x1a, 0x7d, 0x08, 0xed, 0xdc, 0xc2, 0x1f,
+	0x54, 0xf1, 0xfe, 0x1f, 0x00, 0xb5, 0x4b, 0x1b, 0x0d, 0xb2, 0xab, 0x6f, 0xc9, 0xa3, 0xdd, 0x85,
+	0x96, 0x68, 0x83, 0x25, 0x39, 0x69, 0x21, 0x2a, 0x59, 0x0b, 0xa1, 0xdf, 0x83, 0x9d, 0x63, 0x3f,
+	0x29, 0xe6, 0x9f, 0xd1, 0x15, 0x9a, 0x60, 0x6d, 0x07, 0xfa, 0x19, 0x74, 0xd4, 0x9b, 0xc8, 0x2b,
+	0xed, 0xb1, 0xa3, 0xf6, 0xf8, 0xdd, 0xb1, 0xf8, 0x16, 0xf4, 0x95, 0xd0, 0x13, 0x57, 0x45, 0xa2,
+	0xe8, 0xc0, 0x18, 0x9d, 0xbb, 0x57, 0x4a, 0xb4, 0x9a, 0xe9, 0x8f, 0x61, 0x90, 0x63, 0x4d, 0x8f,
+	0x73, 0x41, 0x57, 0x51, 0xf2, 0x56, 0x24, 0xc6, 0x89, 0x05, 0xaa, 0x99, 0x05, 0x74, 0xe8, 0xa9,
+	0x95, 0xcf, 0x29, 0xbf, 0xe6, 0x74, 0x9f, 0xa5, 0x1b, 0x79, 0x4e, 0x95, 0xf0, 0xfb, 0xb0, 0x4d,
+	0xc5, 0x49, 0xf3, 0x65, 0x38, 0x6f, 0x01, 0x43, 0x92, 0x37, 0x28, 0x7c, 0x9c, 0x2a, 0x3c, 0x8d,
+	0xa5, 0xc2, 0x57, 0x94, 0xa5, 0xbf, 0x91, 0x6e, 0xe3, 0x34, 0xe6, 0xd7, 0xdd, 0xe8, 0x3d, 0xd8,
+	0x51, 0x4c, 0x4f, 0xa9, 0x47, 0x39, 0xbd, 0xe6, 0x48, 0xf7, 0x81, 0x14, 0xd8, 0xae, 0x13, 0x77,
+	0x07, 0x9a, 0xd3, 0xe9, 0x49, 0x4a, 0x2d, 0xa6, 0x58, 0x7d, 0x0f, 0x3a, 0x53, 0x4b, 0xb4, 0x12,
+	0x8e, 0xe4, 0xd0, 0xa0, 0xc1, 0xe5, 0x5c, 0x05, 0x60, 0x32, 0xd5, 0x0f, 0x60, 0x78, 0x68, 0xd9,
+	0xe7, 0xae, 0xbf, 0x78, 0xea, 0x46, 0xa2, 0x97, 0x52, 0x2b, 0x46, 0xd0, 0x74, 0x14, 0xa0, 0x96,
+	0xa4, 0x73, 0xfd, 0x1d, 0xb8, 0x91, 0x7b, 0x27, 0x3b, 0xe3, 0x56, 0xb2, 0xcd, 0x21, 0x6c, 0x47,
+	0x62, 0x86, 0x2b, 0xb6, 0x0d, 0x39, 0xd1, 0x3f, 0x87, 0x61, 0xbe, 0xbc, 0x8a, 0xce, 0x06, 0x0f,
+	0x9f, 0xf4, 0x1c, 0x95, 0x5c, 0xcf, 0xa1, 0x8e, 0x52, 0xcd, 0xaa, 0xc5, 0x00, 0x6a, 0xbf, 0xfe,
+	0x7a, 0xaa, 0x7c, 0x50, 0x0c, 0xf5, 0x3f, 0xc0, 0x8d, 0xb2, 0x3c, 0xa9, 0xbe, 0xd0, 0x78, 0x54,
+	0x5e, 0xa9, 0xf1, 0x58, 0x77, 0x83, 0x77, 0x60, 0xe7, 0x85, 0x17, 0xd8, 0x17, 0x47, 0x7e, 0xce,
+	0x1a, 0x1a, 0x34, 0xa8, 0x9f, 0x37, 0x46, 0x32, 0xd5, 0xdf, 0x84, 0xfe, 0x89, 0x78, 0xa5, 0x7c,
+	0x21, 0x9e, 0xa5, 0x52, 0x2b, 0xe0, 0xc3, 0xa5, 0x62, 0x95, 0x13, 0xfd, 0x1d, 0xe8, 0xa9, 0x02,
+	0xec, 0xcf, 0x83, 0x24, 0x61, 0x65, 0xa5, 0xba, 0x52, 0xfc, 0x3a, 0xd1, 0x4f, 0xa0, 0x9f, 0xb1,
+	0x4b, 0xb9, 0x6f, 0x42, 0x5d, 0x92, 0xd5, 0xd9, 0xfa, 0xe9, 0xe7, 0xbf, 0xe4, 0x34, 0x14, 0x79,
+	0xc3, 0xa1, 0x4e, 0x61, 0xf8, 0x5c, 0xbc, 0x0d, 0x44, 0xcf, 0x02, 0xa6, 0x98, 0x55, 0xb4, 0xd4,
+	0xf1, 0xcd, 0x40, 0x06, 0x63, 0xfe, 0x45, 0x01, 0xd9, 0x0d, 0x45, 0xdd, 0x20, 0x71, 0x09, 0xbd,
+	0x53, 0x7c, 0x92, 0x3e, 0xf2, 0x2f, 0xa5, 0xac, 0x63, 0x20, 0xf2, 0x91, 0xda, 0xa4, 0xfe, 0xa5,
+	0xcb, 0x02, 0x1f, 0x9b, 0xf1, 0x8a, 0x6a, 0x79, 0x12, 0xb9, 0xe9, 0xa2, 0x84, 0xc3, 0xd8, 0x09,
+	0xcb, 0xd0, 0xc6, 0x5b, 0x81, 0xec, 0xc1, 0x4b, 0xd4, 0x14, 0x46, 0x97, 0x01, 0xa7, 0xa6, 0xe5,
+	0x38, 0x49, 0x58, 0x80, 0x84, 0x9e, 0x38, 0x0e, 0x3b, 0xf8, 0x5b, 0x0d, 0x1a, 0x9f, 0xc8, 0x4c,
+	0x4d, 0x7e, 0x05, 0xdd, 0x42, 0x79, 0x27, 0x37, 0xb0, 0x0d, 0x2c, 0x37, 0x13, 0xa3, 0x9b, 0x6b,
+	0xb0, 0x3c, 0xd7, 0xbb, 0xd0, 0xc9, 0x57, 0x5d, 0x82, 0x15, 0x16, 0x9f, 0xdf, 0x47, 0x28, 0x69,
+	0xbd, 0x24, 0x9f, 0xc1, 0x70, 0x53, 0x3d, 0x24, 0x77, 0x32, 0x0d, 0xeb, 0xb5, 0x78, 0xf4, 0xda,
+	0x75, 0xd4, 0xa4, 0x8e, 0x36, 0x0e, 0x3d, 0x6a, 0xf9, 0x71, 0x98, 0xdf, 0x41, 0x36, 0x24, 0x0f,
+	0xa1, 0x5b, 0xa8, 0x08, 0xf2, 0x9c, 0x6b, 0x45, 0x22, 0xbf, 0xe4, 0x3e, 0x6c, 0x63, 0x15, 0x22,
+	0xdd, 0x42, 0x39, 0x1c, 0xf5, 0xd2, 0xa9, 0xd4, 0xfd, 0x3e, 0x40, 0xd6, 0xad, 0x10, 0x22, 0xe5,
+	0xe6, 0xfb, 0x99, 0xd1, 0x6e, 0x11, 0x4b, 0x3a, 0x9a, 0x2d, 0x7c, 0xcb, 0xc9, 0xed, 0x17, 0x15,
+	0xa5, 0x95, 0xed, 0xe0, 0x3f, 0x15, 0x68, 0x24, 0xef, 0xfb, 0x0f, 0x61, 0x4b, 0xd4, 0x08, 0xb2,
+	0x9b, 0x4b, 0xb3, 0x49, 0x7d, 0x19, 0x0d, 0x4b, 0xa0, 0x54, 0x30, 0x81, 0xda, 0x73, 0xca, 0x09,
+	0xc9, 0x11, 0x55, 0xb1, 0x18, 0xed, 0x16, 0xb1, 0x94, 0xff, 0x34, 0x2e, 0xf2, 0x9f, 0xc6, 0xeb,
+	0xfc, 0x69, 0x16, 0xff, 0x00, 0xea, 0x32, 0x0b, 0x93, 0x1b, 0x39, 0x72, 0x96, 0xbf, 0x47, 0x37,
+	0xd7, 0x60, 0x79, 0xae, 0x7f, 0x6f, 0x01, 0x9c, 0xad, 0x22, 0x4e, 0x97, 0x5f, 0xb9, 0xf4, 0x25,
+	0x79, 0x00, 0x7d, 0xf5, 0x62, 0x85, 0x5f, 0x84, 0x22, 0xad, 0xe5, 0x6c, 0x82, 0x7d, 0x65, 0x9a,
+	0xcc, 0xef, 0x43, 0xfb, 0x85, 0x75, 0xf5, 0x2a, 0x7c, 0x0d, 0x95, 0xe2, 0xf3, 0x3c, 0x58, 0xa3,
+	0x0a, 0xa9, 0xff, 0x67, 0xd0, 0x2f, 0x25, 0xf8, 0x3c, 0x3f, 0x3e, 0x06, 0x6d, 0x2c, 0x00, 0x8f,
+	0x61, 0x50, 0x4e, 0xf2, 0xf9, 0x85, 0xea, 0x03, 0x6d, 0x53, 0x15, 0x78, 0x0e, 0x83, 0x72, 0x7e,
+	0x26, 0x5a, 0x39, 0x0f, 0x27, 0x55, 0x60, 0x74, 0x6b, 0x13, 0x25, 0x8d, 0xbc, 0x7c, 0x2a, 0x5e,
+	0x8b, 0xbc, 0xf5, 0x3c, 0xfd, 0x36, 0x40, 0x96, 0x8d, 0xf3, 0xfc, 0x78, 0xbd, 0xe5, 0x44, 0xfd,
+	0x3e, 0x40, 0x96, 0x63, 0xa5, 0x57, 0x14, 0x53, 0xf4, 0x68, 0xb7, 0x88, 0xc9, 0x65, 0x0f, 0xa0,
+	0x95, 0x66, 0xb1, 0xbc, 0x0e, 0x14, 0x50, 0x4a, 0x8a, 0x1f, 0x43, 0xbf, 0x94, 0x78, 0x37, 0xea,
+	0x41, 0xf3, 0x6c, 0xca, 0xd0, 0x9f, 0x3c, 0xf8, 0xdd, 0xde, 0xc2, 0xe5, 0xe7, 0xf1, 0x6c, 0x62,
+	0x07, 0xcb, 0xfd, 0x73, 0x2b, 0x3a, 0x77, 0xed, 0x80, 0x85, 0xfb, 0x97, 0xc2, 0x9b, 0xf6, 0x0b,
+	0xff, 0x1f, 0x67, 0x75, 0xfc, 0xa0, 0x7c, 0xf4, 0xdf, 0x01, 0x00, 0xe4, 0xdf, 0x23, 0x88, 0x97,
+	0x1c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -237,6 +237,8 @@ func TestTranslation_Response(t *testing.T) {
 						Name:          "name",
 					},
 				},
+				BindClientCert:       true,
+				BoundCertFingerprint: "fingerprint",
 			},
 			WrapInfo: &wrapping.ResponseWrapInfo{
 				TTL:             time.Second,
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-cleanhttp"
+	log "github.com/hashicorp/go-hclog"
+)
+
+// defaultTimeout bounds every request made to the API server.
+const defaultTimeout = 10 * time.Second
+
+// ErrNotFound is returned when the requested object does not exist.
+type ErrNotFound struct {
+	debuggingInfo string
+}
+
+func (e *ErrNotFound) Error() string {
+	return e.debuggingInfo
+}
+
+// ErrUnexpectedStatusCode is returned when the API server responds with a
+// status code the client does not handle.
+type ErrUnexpectedStatusCode struct {
+	statusCode    int
+	debuggingInfo string
+}
+
+func (e *ErrUnexpectedStatusCode) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.statusCode, e.debuggingInfo)
+}
+
+// Client is a minimal client for the pod and secret endpoints of the
+// Kubernetes API. It avoids pulling in client-go, which is a very large
+// dependency, for the couple of calls service registration and the agent's
+// Kubernetes secret sink need.
+type Client struct {
+	logger     log.Logger
+	config     *Config
+	httpClient *http.Client
+}
+
+// New returns a client configured from the service account of the pod Vault
+// is running in.
+func New(logger log.Logger) (*Client, error) {
+	config, err := inClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs: config.CACertPool,
+	}
+
+	return &Client{
+		logger: logger,
+		config: config,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   defaultTimeout,
+		},
+	}, nil
+}
+
+// GetPod returns the pod with the given name in the given namespace.
+func (c *Client) GetPod(namespace, podName string) (*Pod, error) {
+	endpoint := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", namespace, podName)
+
+	req, err := http.NewRequest(http.MethodGet, c.config.Host+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pod := &Pod{}
+	if err := c.do(req, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// PatchPod applies the given JSON patches to the pod with the given name in
+// the given namespace.
+func (c *Client) PatchPod(namespace, podName string, patches ...*Patch) error {
+	if len(patches) == 0 {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", namespace, podName)
+
+	body, err := json.Marshal(patches)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, c.config.Host+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	return c.do(req, nil)
+}
+
+// do executes the request and decodes a successful response into ptrToReturnObj,
+// if it is not nil.
+func (c *Client) do(req *http.Request, ptrToReturnObj interface{}) error {
+	token, err := c.config.bearerToken()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errwrap.Wrapf(fmt.Sprintf("error calling %s %s: {{err}}", req.Method, req.URL.Path), err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusNotFound:
+		return &ErrNotFound{
+			debuggingInfo: sanitizedDebuggingInfo(req, resp),
+		}
+	default:
+		return &ErrUnexpectedStatusCode{
+			statusCode:    resp.StatusCode,
+			debuggingInfo: sanitizedDebuggingInfo(req, resp),
+		}
+	}
+
+	if ptrToReturnObj == nil {
+		// Drain the body so the connection can be reused.
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(ptrToReturnObj); err != nil {
+		return errwrap.Wrapf("error decoding response: {{err}}", err)
+	}
+	return nil
+}
+
+// sanitizedDebuggingInfo describes a failed request without including the
+// bearer token.
+func sanitizedDebuggingInfo(req *http.Request, resp *http.Response) string {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Sprintf("req method: %s, req url: %s, resp statuscode: %d, resp body: %s", req.Method, req.URL, resp.StatusCode, body)
+}
+
+// Pod is the subset of a Kubernetes pod object the client uses.
+type Pod struct {
+	Metadata *Metadata `json:"metadata,omitempty"`
+}
+
+// Metadata is the subset of a Kubernetes object's metadata the client uses.
+type Metadata struct {
+	Name   string            `json:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// PatchOperation is a JSON patch operation as defined in RFC 6902.
+type PatchOperation string
+
+const (
+	// Add creates a member, or replaces it if it already exists.
+	Add     PatchOperation = "add"
+	Replace PatchOperation = "replace"
+	Remove  PatchOperation = "remove"
+)
+
+// Patch is a single JSON patch operation.
+type Patch struct {
+	Operation PatchOperation `json:"op"`
+	Path      string         `json:"path"`
+	Value     interface{}    `json:"value,omitempty"`
+}
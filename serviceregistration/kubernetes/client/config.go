@@ -0,0 +1,84 @@
+package client
+
+import (
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-rootcerts"
+)
+
+const (
+	// These environment variables are set by Kubernetes in every pod.
+	EnvVarKubernetesServiceHost = "KUBERNETES_SERVICE_HOST"
+	EnvVarKubernetesServicePort = "KUBERNETES_SERVICE_PORT"
+)
+
+var (
+	// These paths are where Kubernetes mounts the service account of a pod.
+	// They are variables so they can be overridden in tests.
+	Scheme        = "https://"
+	TokenFile     = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	RootCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	NamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+	ErrNotInCluster = errors.New("unable to load in-cluster configuration, " + EnvVarKubernetesServiceHost + " and " + EnvVarKubernetesServicePort + " must be defined")
+)
+
+// Config holds the information needed to reach the Kubernetes API server.
+type Config struct {
+	Host       string
+	TokenFile  string
+	CACertPool *x509.CertPool
+}
+
+// bearerToken reads the service account token. It is read again for every
+// request, since the kubelet rotates projected service account tokens.
+func (c *Config) bearerToken() (string, error) {
+	token, err := ioutil.ReadFile(c.TokenFile)
+	if err != nil {
+		return "", errwrap.Wrapf("unable to read service account token: {{err}}", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// inClusterConfig returns a config using the service account Kubernetes
+// gives to pods. It is modeled after the equivalent function in client-go.
+func inClusterConfig() (*Config, error) {
+	host, port := os.Getenv(EnvVarKubernetesServiceHost), os.Getenv(EnvVarKubernetesServicePort)
+	if len(host) == 0 || len(port) == 0 {
+		return nil, ErrNotInCluster
+	}
+
+	config := &Config{
+		Host:      Scheme + net.JoinHostPort(host, port),
+		TokenFile: TokenFile,
+	}
+	if _, err := config.bearerToken(); err != nil {
+		return nil, err
+	}
+
+	pool, err := rootcerts.LoadCACerts(&rootcerts.Config{
+		CAFile: RootCAFile,
+	})
+	if err != nil {
+		return nil, errwrap.Wrapf("unable to load service account CA certificate: {{err}}", err)
+	}
+
+	config.CACertPool = pool
+
+	return config, nil
+}
+
+// InClusterNamespace returns the namespace of the pod Vault is running in.
+func InClusterNamespace() (string, error) {
+	namespace, err := ioutil.ReadFile(NamespaceFile)
+	if err != nil {
+		return "", errwrap.Wrapf("unable to read service account namespace: {{err}}", err)
+	}
+	return strings.TrimSpace(string(namespace)), nil
+}
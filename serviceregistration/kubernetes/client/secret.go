@@ -0,0 +1,82 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GetSecret returns the secret with the given name in the given namespace.
+func (c *Client) GetSecret(namespace, name string) (*Secret, error) {
+	endpoint := fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, name)
+
+	req, err := http.NewRequest(http.MethodGet, c.config.Host+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := &Secret{}
+	if err := c.do(req, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// CreateSecret creates the given secret in the given namespace.
+func (c *Client) CreateSecret(namespace string, secret *Secret) error {
+	endpoint := fmt.Sprintf("/api/v1/namespaces/%s/secrets", namespace)
+
+	if secret.APIVersion == "" {
+		secret.APIVersion = "v1"
+	}
+	if secret.Kind == "" {
+		secret.Kind = "Secret"
+	}
+
+	body, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.config.Host+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return c.do(req, nil)
+}
+
+// PatchSecretData sets the given keys in the data of the secret with the
+// given name in the given namespace, leaving its other keys untouched.
+func (c *Client) PatchSecretData(namespace, name string, data map[string][]byte) error {
+	endpoint := fmt.Sprintf("/api/v1/namespaces/%s/secrets/%s", namespace, name)
+
+	// A JSON merge patch, as defined in RFC 7386, merges the given data
+	// into the existing data.
+	body, err := json.Marshal(&Secret{
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, c.config.Host+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	return c.do(req, nil)
+}
+
+// Secret is the subset of a Kubernetes secret object the client uses. Data
+// values are base64 encoded on the wire, as the API server expects.
+type Secret struct {
+	APIVersion string            `json:"apiVersion,omitempty"`
+	Kind       string            `json:"kind,omitempty"`
+	Metadata   *Metadata         `json:"metadata,omitempty"`
+	Type       string            `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data,omitempty"`
+}
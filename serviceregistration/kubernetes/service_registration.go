@@ -0,0 +1,222 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	log "github.com/hashicorp/go-hclog"
+	sr "github.com/hashicorp/vault/serviceregistration"
+	"github.com/hashicorp/vault/serviceregistration/kubernetes/client"
+)
+
+const (
+	// Labels are placed in a pod's metadata.
+	labelVaultVersion = "vault-version"
+	labelActive       = "vault-active"
+	labelSealed       = "vault-sealed"
+	labelPerfStandby  = "vault-perf-standby"
+	labelInitialized  = "vault-initialized"
+
+	// This is the path to where these labels are applied.
+	pathToLabels = "/metadata/labels/"
+
+	// These environment variables take precedence over the namespace and
+	// pod_name parameters of the service_registration stanza.
+	EnvVarKubernetesNamespace = "VAULT_K8S_NAMESPACE"
+	EnvVarKubernetesPodName   = "VAULT_K8S_POD_NAME"
+
+	// retryInterval is how long to wait before retrying a failed update of
+	// the pod labels.
+	retryInterval = 5 * time.Second
+)
+
+// serviceRegistration is a ServiceRegistration that advertises the state of
+// Vault through labels on the pod it runs in.
+type serviceRegistration struct {
+	logger        log.Logger
+	namespace     string
+	podName       string
+	client        *client.Client
+	retryInterval time.Duration
+
+	// notifyCh is signalled whenever labels changes. It is buffered so
+	// notifications never block Core.
+	notifyCh chan struct{}
+
+	stateLock sync.Mutex
+	labels    map[string]string
+}
+
+// NewServiceRegistration constructs a Kubernetes-based ServiceRegistration.
+func NewServiceRegistration(config map[string]string, logger log.Logger, state sr.State, _ string) (sr.ServiceRegistration, error) {
+	namespace, err := getRequiredField(logger, config, EnvVarKubernetesNamespace, "namespace")
+	if err != nil {
+		return nil, err
+	}
+	podName, err := getRequiredField(logger, config, EnvVarKubernetesPodName, "pod_name")
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &serviceRegistration{
+		logger:        logger,
+		namespace:     namespace,
+		podName:       podName,
+		client:        c,
+		retryInterval: retryInterval,
+		notifyCh:      make(chan struct{}, 1),
+		labels: map[string]string{
+			labelVaultVersion: state.VaultVersion,
+			labelActive:       strconv.FormatBool(state.IsActive),
+			labelSealed:       strconv.FormatBool(state.IsSealed),
+			labelPerfStandby:  strconv.FormatBool(state.IsPerformanceStandby),
+			labelInitialized:  strconv.FormatBool(state.IsInitialized),
+		},
+	}
+
+	// Verify the pod can be reached and set the initial labels, so that a
+	// misconfiguration or missing RBAC permissions fail startup rather than
+	// only being logged.
+	pod, err := c.GetPod(namespace, podName)
+	if err != nil {
+		return nil, errwrap.Wrapf(fmt.Sprintf("unable to read pod %q in namespace %q: {{err}}", podName, namespace), err)
+	}
+
+	var patches []*client.Patch
+	if pod.Metadata == nil || pod.Metadata.Labels == nil {
+		// JSON patches can't add a member to an object that doesn't exist.
+		patches = append(patches, &client.Patch{
+			Operation: client.Add,
+			Path:      "/metadata/labels",
+			Value:     map[string]string{},
+		})
+	}
+	patches = append(patches, r.labelPatches()...)
+	if err := c.PatchPod(namespace, podName, patches...); err != nil {
+		return nil, errwrap.Wrapf("unable to set initial pod labels: {{err}}", err)
+	}
+
+	return r, nil
+}
+
+func (r *serviceRegistration) Run(shutdownCh <-chan struct{}, wait *sync.WaitGroup) error {
+	// 'server' command will wait for the below goroutine to complete
+	wait.Add(1)
+	go r.run(shutdownCh, wait)
+	return nil
+}
+
+func (r *serviceRegistration) run(shutdownCh <-chan struct{}, wait *sync.WaitGroup) {
+	defer wait.Done()
+
+	var retryCh <-chan time.Time
+	for {
+		select {
+		case <-r.notifyCh:
+		case <-retryCh:
+		case <-shutdownCh:
+			// Vault is going away, so it can no longer be active or serve
+			// requests.
+			r.stateLock.Lock()
+			r.labels[labelActive] = strconv.FormatBool(false)
+			r.labels[labelPerfStandby] = strconv.FormatBool(false)
+			r.labels[labelSealed] = strconv.FormatBool(true)
+			r.stateLock.Unlock()
+
+			r.logger.Info("shutting down kubernetes service registration")
+			if err := r.patchLabels(); err != nil {
+				r.logger.Warn("unable to set final pod labels", "error", err)
+			}
+			return
+		}
+
+		retryCh = nil
+		if err := r.patchLabels(); err != nil {
+			r.logger.Warn("unable to update pod labels, will retry", "error", err)
+			retryCh = time.After(r.retryInterval)
+		}
+	}
+}
+
+func (r *serviceRegistration) NotifyActiveStateChange(isActive bool) error {
+	r.setLabel(labelActive, isActive)
+	return nil
+}
+
+func (r *serviceRegistration) NotifySealedStateChange(isSealed bool) error {
+	r.setLabel(labelSealed, isSealed)
+	return nil
+}
+
+func (r *serviceRegistration) NotifyPerformanceStandbyStateChange(isStandby bool) error {
+	r.setLabel(labelPerfStandby, isStandby)
+	return nil
+}
+
+func (r *serviceRegistration) NotifyInitializedStateChange(isInitialized bool) error {
+	r.setLabel(labelInitialized, isInitialized)
+	return nil
+}
+
+// setLabel records the new value of a label and wakes the run loop to apply
+// it.
+func (r *serviceRegistration) setLabel(label string, value bool) {
+	r.stateLock.Lock()
+	r.labels[label] = strconv.FormatBool(value)
+	r.stateLock.Unlock()
+
+	select {
+	case r.notifyCh <- struct{}{}:
+	default:
+		// An update is already pending and will pick up this change.
+	}
+}
+
+// labelPatches returns patches setting every label to its current value.
+// "add" is used rather than "replace" because it also creates labels that are
+// missing, such as when they were removed from the pod by someone else.
+func (r *serviceRegistration) labelPatches() []*client.Patch {
+	r.stateLock.Lock()
+	defer r.stateLock.Unlock()
+
+	patches := make([]*client.Patch, 0, len(r.labels))
+	for _, label := range []string{labelVaultVersion, labelActive, labelSealed, labelPerfStandby, labelInitialized} {
+		patches = append(patches, &client.Patch{
+			Operation: client.Add,
+			Path:      pathToLabels + label,
+			Value:     r.labels[label],
+		})
+	}
+	return patches
+}
+
+func (r *serviceRegistration) patchLabels() error {
+	return r.client.PatchPod(r.namespace, r.podName, r.labelPatches()...)
+}
+
+// getRequiredField returns the value of the environment variable if set,
+// falling back to the config parameter.
+func getRequiredField(logger log.Logger, config map[string]string, envVar, configParam string) (string, error) {
+	value := ""
+	switch {
+	case os.Getenv(envVar) != "":
+		value = os.Getenv(envVar)
+	case config[configParam] != "":
+		value = config[configParam]
+	default:
+		return "", fmt.Errorf(`%s must be provided via %q or the %q config parameter`, configParam, envVar, configParam)
+	}
+	if logger.IsDebug() {
+		logger.Debug(fmt.Sprintf("%q: %q", configParam, value))
+	}
+	return value, nil
+}
@@ -0,0 +1,150 @@
+package kubernetes
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	sr "github.com/hashicorp/vault/serviceregistration"
+	kubetest "github.com/hashicorp/vault/serviceregistration/kubernetes/testing"
+)
+
+var testVersion = "version 1"
+
+func TestServiceRegistration(t *testing.T) {
+	testState, closeFunc := kubetest.Server(t)
+	defer closeFunc()
+
+	config := map[string]string{
+		"namespace": kubetest.ExpectedNamespace,
+		"pod_name":  kubetest.ExpectedPodName,
+	}
+	logger := hclog.New(&hclog.LoggerOptions{
+		Level: hclog.Trace,
+	})
+	state := sr.State{
+		VaultVersion:         testVersion,
+		IsInitialized:        true,
+		IsSealed:             true,
+		IsActive:             true,
+		IsPerformanceStandby: true,
+	}
+	reg, err := NewServiceRegistration(config, logger, state, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The initial state is applied by the factory, creating the labels
+	// object since the pod starts without one.
+	expectLabels(t, testState, map[string]string{
+		labelVaultVersion: testVersion,
+		labelActive:       "true",
+		labelSealed:       "true",
+		labelPerfStandby:  "true",
+		labelInitialized:  "true",
+	})
+
+	shutdownCh := make(chan struct{})
+	wait := &sync.WaitGroup{}
+	if err := reg.Run(shutdownCh, wait); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.NotifyActiveStateChange(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.NotifySealedStateChange(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.NotifyPerformanceStandbyStateChange(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.NotifyInitializedStateChange(false); err != nil {
+		t.Fatal(err)
+	}
+	expectLabels(t, testState, map[string]string{
+		labelVaultVersion: testVersion,
+		labelActive:       "false",
+		labelSealed:       "false",
+		labelPerfStandby:  "false",
+		labelInitialized:  "false",
+	})
+
+	// Failed updates are retried.
+	reg.(*serviceRegistration).retryInterval = 10 * time.Millisecond
+	testState.FailPatches(true)
+	if err := reg.NotifyActiveStateChange(true); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if testState.Labels()[labelActive] != "false" {
+		t.Fatal("expected patch to fail")
+	}
+	testState.FailPatches(false)
+	expectLabels(t, testState, map[string]string{
+		labelVaultVersion: testVersion,
+		labelActive:       "true",
+		labelSealed:       "false",
+		labelPerfStandby:  "false",
+		labelInitialized:  "false",
+	})
+
+	// On shutdown the pod is marked as no longer serving.
+	close(shutdownCh)
+	wait.Wait()
+	expectLabels(t, testState, map[string]string{
+		labelVaultVersion: testVersion,
+		labelActive:       "false",
+		labelSealed:       "true",
+		labelPerfStandby:  "false",
+		labelInitialized:  "false",
+	})
+}
+
+func TestServiceRegistration_Config(t *testing.T) {
+	_, closeFunc := kubetest.Server(t)
+	defer closeFunc()
+
+	logger := hclog.NewNullLogger()
+
+	// Both fields are required
+	if _, err := NewServiceRegistration(map[string]string{"namespace": kubetest.ExpectedNamespace}, logger, sr.State{}, ""); err == nil {
+		t.Fatal("expected error without pod_name")
+	}
+
+	// An unknown pod fails the factory
+	if _, err := NewServiceRegistration(map[string]string{
+		"namespace": kubetest.ExpectedNamespace,
+		"pod_name":  "unknown",
+	}, logger, sr.State{}, ""); err == nil {
+		t.Fatal("expected error for unknown pod")
+	}
+
+	// The environment takes precedence over the config
+	os.Setenv(EnvVarKubernetesPodName, kubetest.ExpectedPodName)
+	defer os.Unsetenv(EnvVarKubernetesPodName)
+	if _, err := NewServiceRegistration(map[string]string{
+		"namespace": kubetest.ExpectedNamespace,
+		"pod_name":  "unknown",
+	}, logger, sr.State{}, ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectLabels waits for the pod labels to reach the expected state.
+func expectLabels(t *testing.T, testState *kubetest.State, expected map[string]string) {
+	t.Helper()
+
+	var labels map[string]string
+	for i := 0; i < 100; i++ {
+		labels = testState.Labels()
+		if reflect.DeepEqual(labels, expected) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expected labels %v, got %v", expected, labels)
+}
@@ -0,0 +1,342 @@
+package testing
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/serviceregistration/kubernetes/client"
+)
+
+const (
+	ExpectedNamespace = "default"
+	ExpectedPodName   = "shell-demo"
+	Token             = "some-token"
+)
+
+// State is the pod, and the secrets in its namespace, as seen by the test API
+// server.
+type State struct {
+	l           sync.Mutex
+	labels      map[string]string
+	patchCount  int
+	failPatches bool
+	secrets     map[string]map[string][]byte
+	token       string
+	tokenFile   string
+}
+
+// Labels returns a copy of the pod's current labels, or nil if the pod has no
+// labels object.
+func (s *State) Labels() map[string]string {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if s.labels == nil {
+		return nil
+	}
+	ret := make(map[string]string, len(s.labels))
+	for k, v := range s.labels {
+		ret[k] = v
+	}
+	return ret
+}
+
+// PatchCount returns the number of patch requests that succeeded.
+func (s *State) PatchCount() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.patchCount
+}
+
+// Secret returns a copy of the data of the secret with the given name, or nil
+// if it does not exist.
+func (s *State) Secret(name string) map[string][]byte {
+	s.l.Lock()
+	defer s.l.Unlock()
+	data, ok := s.secrets[name]
+	if !ok {
+		return nil
+	}
+	ret := make(map[string][]byte, len(data))
+	for k, v := range data {
+		ret[k] = v
+	}
+	return ret
+}
+
+// FailPatches makes the server reject patch requests with a 500 until it is
+// called again with false.
+func (s *State) FailPatches(fail bool) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.failPatches = fail
+}
+
+// RotateToken replaces the service account token, as the kubelet does with
+// projected tokens. Requests made with the previous token are rejected.
+func (s *State) RotateToken(token string) error {
+	s.l.Lock()
+	defer s.l.Unlock()
+	if err := ioutil.WriteFile(s.tokenFile, []byte(token), 0600); err != nil {
+		return err
+	}
+	s.token = token
+	return nil
+}
+
+// Server starts a fake Kubernetes API server serving a single pod and the
+// secrets in its namespace, and points the client package and environment at
+// it. The returned function stops the
+// server and restores the previous settings.
+func Server(t *testing.T) (testState *State, closeFunc func()) {
+	testState = &State{
+		secrets: make(map[string]map[string][]byte),
+		token:   Token,
+	}
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(testState.handle))
+
+	tmpDir, err := ioutil.TempDir("", "vault-k8s-sr")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenFile := filepath.Join(tmpDir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte(Token), 0600); err != nil {
+		t.Fatal(err)
+	}
+	testState.tokenFile = tokenFile
+
+	namespaceFile := filepath.Join(tmpDir, "namespace")
+	if err := ioutil.WriteFile(namespaceFile, []byte(ExpectedNamespace), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	caFile := filepath.Join(tmpDir, "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: ts.Certificate().Raw,
+	})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(ts.URL, "https://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prevHost := os.Getenv(client.EnvVarKubernetesServiceHost)
+	prevPort := os.Getenv(client.EnvVarKubernetesServicePort)
+	prevTokenFile, prevRootCAFile, prevNamespaceFile := client.TokenFile, client.RootCAFile, client.NamespaceFile
+
+	os.Setenv(client.EnvVarKubernetesServiceHost, host)
+	os.Setenv(client.EnvVarKubernetesServicePort, port)
+	client.TokenFile = tokenFile
+	client.RootCAFile = caFile
+	client.NamespaceFile = namespaceFile
+
+	closeFunc = func() {
+		ts.Close()
+		os.Setenv(client.EnvVarKubernetesServiceHost, prevHost)
+		os.Setenv(client.EnvVarKubernetesServicePort, prevPort)
+		client.TokenFile = prevTokenFile
+		client.RootCAFile = prevRootCAFile
+		client.NamespaceFile = prevNamespaceFile
+		os.RemoveAll(tmpDir)
+	}
+
+	return testState, closeFunc
+}
+
+func (s *State) handle(w http.ResponseWriter, r *http.Request) {
+	s.l.Lock()
+	token := s.token
+	s.l.Unlock()
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	secretsPath := fmt.Sprintf("/api/v1/namespaces/%s/secrets", ExpectedNamespace)
+	switch {
+	case r.URL.Path == fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", ExpectedNamespace, ExpectedPodName):
+		s.handlePod(w, r)
+	case r.URL.Path == secretsPath || strings.HasPrefix(r.URL.Path, secretsPath+"/"):
+		s.handleSecret(w, r, strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, secretsPath), "/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *State) handlePod(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.l.Lock()
+		pod := &client.Pod{
+			Metadata: &client.Metadata{
+				Name:   ExpectedPodName,
+				Labels: s.labels,
+			},
+		}
+		body, err := json.Marshal(pod)
+		s.l.Unlock()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(body)
+
+	case http.MethodPatch:
+		if r.Header.Get("Content-Type") != "application/json-patch+json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		var patches []*client.Patch
+		if err := json.NewDecoder(r.Body).Decode(&patches); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.l.Lock()
+		defer s.l.Unlock()
+		if s.failPatches {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err := s.applyPatches(patches); err != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(err.Error()))
+			return
+		}
+		s.patchCount++
+		w.Write([]byte("{}"))
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *State) handleSecret(w http.ResponseWriter, r *http.Request, name string) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && name == "":
+		var secret client.Secret
+		if err := json.NewDecoder(r.Body).Decode(&secret); err != nil || secret.Metadata == nil || secret.Metadata.Name == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := s.secrets[secret.Metadata.Name]; ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		s.secrets[secret.Metadata.Name] = secret.Data
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("{}"))
+
+	case r.Method == http.MethodGet && name != "":
+		data, ok := s.secrets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := json.Marshal(&client.Secret{
+			Metadata: &client.Metadata{
+				Name: name,
+			},
+			Data: data,
+		})
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(body)
+
+	case r.Method == http.MethodPatch && name != "":
+		if r.Header.Get("Content-Type") != "application/merge-patch+json" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		data, ok := s.secrets[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var patch client.Secret
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for k, v := range patch.Data {
+			data[k] = v
+		}
+		w.Write([]byte("{}"))
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// applyPatches applies JSON patches to the labels, failing the same way the
+// API server does when a label is added before the labels object exists.
+// The patches are applied atomically. It must be called with the lock held.
+func (s *State) applyPatches(patches []*client.Patch) error {
+	var labels map[string]string
+	if s.labels != nil {
+		labels = make(map[string]string, len(s.labels))
+		for k, v := range s.labels {
+			labels[k] = v
+		}
+	}
+
+	for _, patch := range patches {
+		if patch.Path == "/metadata/labels" {
+			if patch.Operation != client.Add {
+				return fmt.Errorf("unsupported operation %q on %q", patch.Operation, patch.Path)
+			}
+			labels = make(map[string]string)
+			continue
+		}
+
+		if !strings.HasPrefix(patch.Path, "/metadata/labels/") {
+			return fmt.Errorf("unsupported path %q", patch.Path)
+		}
+		if labels == nil {
+			return fmt.Errorf("doc is missing path: %q", patch.Path)
+		}
+		key := strings.TrimPrefix(patch.Path, "/metadata/labels/")
+
+		switch patch.Operation {
+		case client.Add, client.Replace:
+			if _, ok := labels[key]; !ok && patch.Operation == client.Replace {
+				return fmt.Errorf("doc is missing key: %q", patch.Path)
+			}
+			value, ok := patch.Value.(string)
+			if !ok {
+				return fmt.Errorf("label values must be strings, got %T", patch.Value)
+			}
+			labels[key] = value
+		case client.Remove:
+			delete(labels, key)
+		default:
+			return fmt.Errorf("unsupported operation %q", patch.Operation)
+		}
+	}
+
+	s.labels = labels
+	return nil
+}
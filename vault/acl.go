@@ -55,6 +55,10 @@ type ACLResults struct {
 	MFAMethods         []string
 	ControlGroup       *ControlGroup
 	CapabilitiesBitmap uint32
+
+	// MatchedPath is the path of the policy rule applied to the request, as
+	// written in the policy
+	MatchedPath string
 }
 
 // NewACL is used to construct a policy based ACL from a set of policies.
@@ -150,6 +154,8 @@ func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
 				existingPerms.CapabilitiesBitmap = DenyCapabilityInt
 				existingPerms.AllowedParameters = nil
 				existingPerms.DeniedParameters = nil
+				existingPerms.ParameterConstraints = nil
+				existingPerms.Conditions = nil
 				goto INSERT
 
 			default:
@@ -224,6 +230,27 @@ func NewACL(ctx context.Context, policies []*Policy) (*ACL, error) {
 				}
 			}
 
+			if len(pc.Permissions.ParameterConstraints) > 0 {
+				if existingPerms.ParameterConstraints == nil {
+					existingPerms.ParameterConstraints = make(map[string][]*ParameterConstraint, len(pc.Permissions.ParameterConstraints))
+				}
+				for key, value := range pc.Permissions.ParameterConstraints {
+					// A value satisfying the constraints of any of the
+					// policies is allowed
+					merged := make([]*ParameterConstraint, 0, len(value)+len(existingPerms.ParameterConstraints[key]))
+					merged = append(merged, existingPerms.ParameterConstraints[key]...)
+					existingPerms.ParameterConstraints[key] = append(merged, value...)
+				}
+			}
+
+			// The conditions of all the policies must be satisfied, so that a
+			// policy without conditions cannot be used to bypass them
+			if len(pc.Permissions.Conditions) > 0 {
+				conditions := make([]*PolicyCondition, 0, len(existingPerms.Conditions)+len(pc.Permissions.Conditions))
+				conditions = append(conditions, existingPerms.Conditions...)
+				existingPerms.Conditions = append(conditions, pc.Permissions.Conditions...)
+			}
+
 			if len(pc.Permissions.RequiredParameters) > 0 {
 				if len(existingPerms.RequiredParameters) == 0 {
 					existingPerms.RequiredParameters = pc.Permissions.RequiredParameters
@@ -357,6 +384,7 @@ func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheck
 	if ok {
 		permissions = raw.(*ACLPermissions)
 		capabilities = permissions.CapabilitiesBitmap
+		ret.MatchedPath = path
 		goto CHECK
 	}
 	if op == logical.ListOperation {
@@ -364,11 +392,12 @@ func (a *ACL) AllowOperation(ctx context.Context, req *logical.Request, capCheck
 		if ok {
 			permissions = raw.(*ACLPermissions)
 			capabilities = permissions.CapabilitiesBitmap
+			ret.MatchedPath = strings.TrimSuffix(path, "/")
 			goto CHECK
 		}
 	}
 
-	permissions = a.CheckAllowedFromNonExactPaths(path, false)
+	permissions, ret.MatchedPath = a.checkAllowedFromNonExactPaths(path, false)
 	if permissions != nil {
 		capabilities = permissions.CapabilitiesBitmap
 		goto CHECK
@@ -391,6 +420,17 @@ CHECK:
 		return ret
 	}
 
+	// Permissions don't apply to requests not satisfying their conditions
+	if len(permissions.Conditions) > 0 {
+		in := policyConditionInputFromContext(ctx)
+		for _, condition := range permissions.Conditions {
+			if !condition.Satisfied(in) {
+				ret.RootPrivs = false
+				return
+			}
+		}
+	}
+
 	ret.MFAMethods = permissions.MFAMethods
 	ret.ControlGroup = permissions.ControlGroup
 
@@ -453,6 +493,15 @@ CHECK:
 			return
 		}
 
+		for parameter, value := range req.Data {
+			// Check if the value satisfies the parameter's constraints
+			if constraints, ok := permissions.ParameterConstraints[strings.ToLower(parameter)]; ok {
+				if !valueSatisfiesConstraints(value, constraints) {
+					return
+				}
+			}
+		}
+
 		if len(permissions.DeniedParameters) == 0 {
 			goto ALLOWED_PARAMETERS
 		}
@@ -487,9 +536,13 @@ CHECK:
 
 		for parameter, value := range req.Data {
 			valueSlice, ok := permissions.AllowedParameters[strings.ToLower(parameter)]
-			// Requested parameter is not in allowed list
+			// Requested parameter is not in allowed list. Constrained
+			// parameters are implicitly allowed, their values having already
+			// been checked.
 			if !ok && !allowedAll {
-				return
+				if _, constrained := permissions.ParameterConstraints[strings.ToLower(parameter)]; !constrained {
+					return
+				}
 			}
 
 			// If the value doesn't exists in the allowed values slice,
@@ -518,6 +571,13 @@ type wcPathDescr struct {
 // of permissions from some allowed path underneath the mount (for use in mount
 // access checks), or nil indicating no non-deny permissions were found.
 func (a *ACL) CheckAllowedFromNonExactPaths(path string, bareMount bool) *ACLPermissions {
+	permissions, _ := a.checkAllowedFromNonExactPaths(path, bareMount)
+	return permissions
+}
+
+// checkAllowedFromNonExactPaths is CheckAllowedFromNonExactPaths, also
+// returning the path of the matching rule.
+func (a *ACL) checkAllowedFromNonExactPaths(path string, bareMount bool) (*ACLPermissions, string) {
 	wcPathDescrs := make([]wcPathDescr, 0, len(a.segmentWildcardPaths)+1)
 
 	less := func(i, j int) bool {
@@ -581,7 +641,7 @@ func (a *ACL) CheckAllowedFromNonExactPaths(path string, bareMount bool) *ACLPer
 		prefix, raw, ok := a.prefixRules.LongestPrefix(path)
 		if ok {
 			if len(a.segmentWildcardPaths) == 0 {
-				return raw.(*ACLPermissions)
+				return raw.(*ACLPermissions), prefix + "*"
 			}
 			wcPathDescrs = append(wcPathDescrs, wcPathDescr{
 				firstWCOrGlob: len(prefix),
@@ -593,7 +653,7 @@ func (a *ACL) CheckAllowedFromNonExactPaths(path string, bareMount bool) *ACLPer
 	}
 
 	if len(a.segmentWildcardPaths) == 0 {
-		return nil
+		return nil, ""
 	}
 
 	pathParts := strings.Split(path, "/")
@@ -649,7 +709,7 @@ SWCPATH:
 				if strings.HasPrefix(joinedPath, path) {
 					permissions := a.segmentWildcardPaths[fullWCPath].(*ACLPermissions)
 					if permissions.CapabilitiesBitmap&DenyCapabilityInt == 0 && permissions.CapabilitiesBitmap > 0 {
-						return permissions
+						return permissions, fullWCPath
 					}
 				}
 				continue SWCPATH
@@ -660,14 +720,18 @@ SWCPATH:
 	}
 
 	if bareMount || len(wcPathDescrs) == 0 {
-		return nil
+		return nil, ""
 	}
 
 	// We don't do this in the bare mount check because we don't care about
 	// priority, we only care about any capability at all.
 	sort.Slice(wcPathDescrs, less)
 
-	return wcPathDescrs[len(wcPathDescrs)-1].perms
+	match := wcPathDescrs[len(wcPathDescrs)-1]
+	if match.isPrefix {
+		return match.perms, match.wcPath + "*"
+	}
+	return match.perms, match.wcPath
 }
 
 func (c *Core) performPolicyChecks(ctx context.Context, acl *ACL, te *logical.TokenEntry, req *logical.Request, inEntity *identity.Entity, opts *PolicyCheckOpts) *AuthResults {
@@ -677,7 +741,7 @@ func (c *Core) performPolicyChecks(ctx context.Context, acl *ACL, te *logical.To
 	// should be applied is if we are only processing EGPs against a login
 	// path in which case opts.Unauth will be set.
 	if acl != nil && !opts.Unauth {
-		ret.ACLResults = acl.AllowOperation(ctx, req, false)
+		ret.ACLResults = acl.AllowOperation(c.policyConditionContext(ctx, te, req, inEntity), req, false)
 		ret.RootPrivs = ret.ACLResults.RootPrivs
 		// Root is always allowed; skip Sentinel/MFA checks
 		if ret.ACLResults.IsRoot {
@@ -707,6 +771,16 @@ func valueInParameterList(v interface{}, list []interface{}) bool {
 	return valueInSlice(v, list)
 }
 
+func valueSatisfiesConstraints(v interface{}, constraints []*ParameterConstraint) bool {
+	for _, constraint := range constraints {
+		if constraint.Satisfied(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func valueInSlice(v interface{}, list []interface{}) bool {
 	for _, el := range list {
 		if el == nil || v == nil {
@@ -2,11 +2,13 @@ package vault
 
 import (
 	"context"
+	"encoding/json"
 	"reflect"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -581,6 +583,28 @@ func testACLValuePermissions(t *testing.T, ns *namespace.Namespace) {
 		{"test/star", []string{"foo"}, []interface{}{true}, true},
 		{"test/star", []string{"bar"}, []interface{}{false}, true},
 		{"test/star", []string{"bar"}, []interface{}{true}, false},
+		{"test/constraints", []string{"common_name"}, []interface{}{"web.svc.internal"}, true},
+		{"test/constraints", []string{"common_name"}, []interface{}{"web.prod.svc.internal"}, true},
+		{"test/constraints", []string{"common_name"}, []interface{}{"web-12.example.com"}, true},
+		{"test/constraints", []string{"common_name"}, []interface{}{"prod-web-12.example.com"}, false},
+		{"test/constraints", []string{"common_name"}, []interface{}{"web-12.example.com.evil"}, false},
+		{"test/constraints", []string{"common_name"}, []interface{}{"web.example.com"}, false},
+		{"test/constraints", []string{"common_name"}, []interface{}{"svc.internal.example.com"}, false},
+		{"test/constraints", []string{"alt_names"}, []interface{}{[]interface{}{"a.svc.internal", "b.svc.internal"}}, true},
+		{"test/constraints", []string{"alt_names"}, []interface{}{[]interface{}{"a.svc.internal", "b.example.com"}}, false},
+		{"test/constraints", []string{"ttl"}, []interface{}{"1h"}, true},
+		{"test/constraints", []string{"ttl"}, []interface{}{json.Number("60")}, true},
+		{"test/constraints", []string{"ttl"}, []interface{}{7200}, true},
+		{"test/constraints", []string{"ttl"}, []interface{}{"30s"}, false},
+		{"test/constraints", []string{"ttl"}, []interface{}{7201.5}, false},
+		{"test/constraints", []string{"ttl"}, []interface{}{"forever"}, false},
+		{"test/constraints", []string{"ttl", "common_name"}, []interface{}{"2h", "web.svc.internal"}, true},
+		{"test/constraints", []string{"ttl", "common_name"}, []interface{}{"3h", "web.svc.internal"}, false},
+		{"test/constraints", []string{"format"}, []interface{}{"pem"}, true},
+		{"test/constraints", []string{"other"}, []interface{}{"anything"}, false},
+		{"test/constraints-open", []string{"count"}, []interface{}{5}, true},
+		{"test/constraints-open", []string{"count"}, []interface{}{11}, false},
+		{"test/constraints-open", []string{"other"}, []interface{}{"anything"}, true},
 	}
 
 	for _, tc := range tcases {
@@ -603,6 +627,310 @@ func testACLValuePermissions(t *testing.T, ns *namespace.Namespace) {
 	}
 }
 
+func TestACL_ParameterConstraintsMerge(t *testing.T) {
+	ns := namespace.RootNamespace
+	ctx := namespace.RootContext(nil)
+
+	policy1, err := ParseACLPolicy(ns, `
+path "pki/issue/web" {
+	capabilities = ["update"]
+	parameter_constraints "common_name" {
+		glob = ["*.svc.internal"]
+	}
+	parameter_constraints "ttl" {
+		max = "1h"
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy2, err := ParseACLPolicy(ns, `
+path "pki/issue/web" {
+	capabilities = ["update"]
+	parameter_constraints "common_name" {
+		regex = ["^[a-z]+\\.example\\.com$"]
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acl, err := NewACL(ctx, []*Policy{policy1, policy2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		data    map[string]interface{}
+		allowed bool
+	}{
+		{map[string]interface{}{"common_name": "web.svc.internal"}, true},
+		{map[string]interface{}{"common_name": "web.example.com"}, true},
+		{map[string]interface{}{"common_name": "web.example.org"}, false},
+		{map[string]interface{}{"ttl": "30m"}, true},
+		{map[string]interface{}{"ttl": "2h"}, false},
+	}
+	for _, tc := range tcases {
+		req := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "pki/issue/web",
+			Data:      tc.data,
+		}
+		if allowed := acl.AllowOperation(ctx, req, false).Allowed; allowed != tc.allowed {
+			t.Fatalf("bad: %v: expected %t, got %t", tc.data, tc.allowed, allowed)
+		}
+	}
+
+	// A deny removes the constraints along with the other capabilities
+	policy3, err := ParseACLPolicy(ns, `
+path "pki/issue/web" {
+	capabilities = ["deny"]
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acl, err = NewACL(ctx, []*Policy{policy1, policy3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := acl.exactRules.Get("pki/issue/web")
+	if constraints := raw.(*ACLPermissions).ParameterConstraints; constraints != nil {
+		t.Fatalf("expected no constraints, got %#v", constraints)
+	}
+}
+
+func TestACL_Conditions(t *testing.T) {
+	ns := namespace.RootNamespace
+
+	policy, err := ParseACLPolicy(ns, `
+path "secret/hours" {
+	capabilities = ["read"]
+	condition {
+		time_of_day  = ["09:00-17:00"]
+		days_of_week = ["monday", "tue", "Wed", "thu", "fri"]
+		timezone     = "America/New_York"
+	}
+}
+path "secret/night" {
+	capabilities = ["read"]
+	condition {
+		time_of_day = ["22:00-06:00"]
+	}
+}
+path "secret/network" {
+	capabilities = ["read"]
+	condition {
+		cidrs = ["10.0.0.0/8", "fd00::/8"]
+	}
+}
+path "secret/metadata" {
+	capabilities = ["read"]
+	condition {
+		entity_metadata = { team = "ops" }
+		group_metadata  = { oncall = "true" }
+		token_metadata  = { source = "ci" }
+	}
+}
+path "secret/mfa" {
+	capabilities = ["read"]
+	condition {
+		mfa_validated = true
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acl, err := NewACL(namespace.RootContext(nil), []*Policy{policy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wednesday 2020-01-15, 14:30 UTC
+	wednesday := time.Date(2020, 1, 15, 14, 30, 0, 0, time.UTC)
+	entity := &identity.Entity{
+		ID:       "entity",
+		Metadata: map[string]string{"team": "ops"},
+	}
+	groups := []*identity.Group{
+		{ID: "group1"},
+		{ID: "group2", Metadata: map[string]string{"oncall": "true"}},
+	}
+
+	tcases := []struct {
+		name    string
+		path    string
+		in      *policyConditionInput
+		allowed bool
+	}{
+		{"business hours", "secret/hours", &policyConditionInput{time: wednesday}, true},
+		{"before business hours in timezone", "secret/hours", &policyConditionInput{time: wednesday.Add(-6 * time.Hour)}, false},
+		{"weekend", "secret/hours", &policyConditionInput{time: wednesday.Add(72 * time.Hour)}, false},
+		{"end is exclusive", "secret/hours", &policyConditionInput{time: time.Date(2020, 1, 15, 22, 0, 0, 0, time.UTC)}, false},
+		{"before midnight", "secret/night", &policyConditionInput{time: time.Date(2020, 1, 15, 23, 0, 0, 0, time.UTC)}, true},
+		{"after midnight", "secret/night", &policyConditionInput{time: time.Date(2020, 1, 15, 5, 59, 0, 0, time.UTC)}, true},
+		{"daytime", "secret/night", &policyConditionInput{time: wednesday}, false},
+		{"ipv4 in cidr", "secret/network", &policyConditionInput{time: wednesday, remoteAddr: "10.1.2.3"}, true},
+		{"ipv4 with port in cidr", "secret/network", &policyConditionInput{time: wednesday, remoteAddr: "10.1.2.3:8200"}, true},
+		{"ipv6 in cidr", "secret/network", &policyConditionInput{time: wednesday, remoteAddr: "[fd00::1]:8200"}, true},
+		{"ip outside cidr", "secret/network", &policyConditionInput{time: wednesday, remoteAddr: "192.168.1.1"}, false},
+		{"no remote address", "secret/network", &policyConditionInput{time: wednesday}, false},
+		{
+			"matching metadata",
+			"secret/metadata",
+			&policyConditionInput{
+				time:       wednesday,
+				entity:     entity,
+				tokenMeta:  map[string]string{"source": "ci", "other": "value"},
+				groupsFunc: func() []*identity.Group { return groups },
+			},
+			true,
+		},
+		{
+			"no entity",
+			"secret/metadata",
+			&policyConditionInput{
+				time:      wednesday,
+				tokenMeta: map[string]string{"source": "ci"},
+			},
+			false,
+		},
+		{
+			"no matching group",
+			"secret/metadata",
+			&policyConditionInput{
+				time:       wednesday,
+				entity:     entity,
+				tokenMeta:  map[string]string{"source": "ci"},
+				groupsFunc: func() []*identity.Group { return groups[:1] },
+			},
+			false,
+		},
+		{
+			"token metadata mismatch",
+			"secret/metadata",
+			&policyConditionInput{
+				time:       wednesday,
+				entity:     entity,
+				tokenMeta:  map[string]string{"source": "human"},
+				groupsFunc: func() []*identity.Group { return groups },
+			},
+			false,
+		},
+		{"mfa validated", "secret/mfa", &policyConditionInput{time: wednesday, mfaValidated: true}, true},
+		{"mfa not validated", "secret/mfa", &policyConditionInput{time: wednesday}, false},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := contextWithPolicyConditionInput(namespace.RootContext(nil), tc.in)
+			req := &logical.Request{
+				Operation: logical.ReadOperation,
+				Path:      tc.path,
+			}
+			if allowed := acl.AllowOperation(ctx, req, false).Allowed; allowed != tc.allowed {
+				t.Fatalf("expected %t, got %t", tc.allowed, allowed)
+			}
+
+			// Capability checks are not subject to conditions
+			if caps := acl.Capabilities(ctx, tc.path); !reflect.DeepEqual(caps, []string{ReadCapability}) {
+				t.Fatalf("expected read capability, got %v", caps)
+			}
+		})
+	}
+}
+
+func TestACL_ConditionsMerge(t *testing.T) {
+	ns := namespace.RootNamespace
+	ctx := namespace.RootContext(nil)
+
+	policy1, err := ParseACLPolicy(ns, `
+path "secret/foo" {
+	capabilities = ["read"]
+	condition {
+		cidrs = ["10.0.0.0/8"]
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy2, err := ParseACLPolicy(ns, `
+path "secret/foo" {
+	capabilities = ["read"]
+	condition {
+		mfa_validated = true
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy3, err := ParseACLPolicy(ns, `
+path "secret/foo" {
+	capabilities = ["update"]
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acl, err := NewACL(ctx, []*Policy{policy1, policy2, policy3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tcases := []struct {
+		in      *policyConditionInput
+		allowed bool
+	}{
+		{&policyConditionInput{time: time.Now(), remoteAddr: "10.0.0.1", mfaValidated: true}, true},
+		{&policyConditionInput{time: time.Now(), remoteAddr: "10.0.0.1"}, false},
+		{&policyConditionInput{time: time.Now(), mfaValidated: true}, false},
+	}
+	for _, tc := range tcases {
+		req := &logical.Request{
+			Operation: logical.UpdateOperation,
+			Path:      "secret/foo",
+		}
+		ctx := contextWithPolicyConditionInput(ctx, tc.in)
+		if allowed := acl.AllowOperation(ctx, req, false).Allowed; allowed != tc.allowed {
+			t.Fatalf("bad: %#v: expected %t, got %t", tc.in, tc.allowed, allowed)
+		}
+	}
+
+	// Without any request attributes, conditions are not satisfied
+	req := &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      "secret/foo",
+	}
+	if acl.AllowOperation(ctx, req, false).Allowed {
+		t.Fatal("expected request to be denied")
+	}
+
+	// A deny removes the conditions along with the other capabilities
+	policy4, err := ParseACLPolicy(ns, `
+path "secret/foo" {
+	capabilities = ["deny"]
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acl, err = NewACL(ctx, []*Policy{policy1, policy4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := acl.exactRules.Get("secret/foo")
+	if conditions := raw.(*ACLPermissions).Conditions; conditions != nil {
+		t.Fatalf("expected no conditions, got %#v", conditions)
+	}
+}
+
 func TestACL_SegmentWildcardPriority(t *testing.T) {
 	ns := namespace.RootNamespace
 	ctx := namespace.ContextWithNamespace(context.Background(), ns)
@@ -1174,4 +1502,27 @@ path "test/star" {
 	denied_parameters = {
 	}
 }
+path "test/constraints" {
+	policy = "write"
+	allowed_parameters = {
+		"format" = ["pem"]
+	}
+	parameter_constraints "common_name" {
+		glob = ["*.svc.internal"]
+		regex = ["web-[0-9]+\\.example\\.com"]
+	}
+	parameter_constraints "alt_names" {
+		glob = ["*.svc.internal"]
+	}
+	parameter_constraints "ttl" {
+		min = "1m"
+		max = 7200
+	}
+}
+path "test/constraints-open" {
+	policy = "write"
+	parameter_constraints "count" {
+		max = 10
+	}
+}
 `
@@ -483,6 +483,11 @@ type Core struct {
 	raftTLSRotationStopCh chan struct{}
 	// Stores the pending peers we are waiting to give answers
 	pendingRaftPeers map[string][]byte
+	// Automated raft snapshot schedules running on the active node
+	raftSnapshotAutoLock  sync.Mutex
+	raftSnapshotAutoCtx   context.Context
+	raftSnapshotSchedules map[string]*raftSnapshotSchedule
+	raftSnapshotTargets   map[string]RaftSnapshotTargetFactory
 
 	// rawConfig stores the config as-is from the provided server configuration.
 	rawConfig *server.Config
@@ -586,6 +591,10 @@ type CoreConfig struct {
 	RecoveryMode bool
 
 	ClusterNetworkLayer cluster.NetworkLayer
+
+	// Additional storage targets for automated raft snapshots, keyed by
+	// storage type
+	RaftSnapshotTargets map[string]RaftSnapshotTargetFactory
 }
 
 func (c *CoreConfig) Clone() *CoreConfig {
@@ -594,6 +603,7 @@ func (c *CoreConfig) Clone() *CoreConfig {
 		LogicalBackends:           c.LogicalBackends,
 		CredentialBackends:        c.CredentialBackends,
 		AuditBackends:             c.AuditBackends,
+		RaftSnapshotTargets:       c.RaftSnapshotTargets,
 		Physical:                  c.Physical,
 		HAPhysical:                c.HAPhysical,
 		ServiceRegistration:       c.ServiceRegistration,
@@ -903,6 +913,15 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 	}
 	c.auditBackends = auditBackends
 
+	raftSnapshotTargets := make(map[string]RaftSnapshotTargetFactory)
+	for k, f := range builtinRaftSnapshotTargets {
+		raftSnapshotTargets[k] = f
+	}
+	for k, f := range conf.RaftSnapshotTargets {
+		raftSnapshotTargets[k] = f
+	}
+	c.raftSnapshotTargets = raftSnapshotTargets
+
 	uiStoragePrefix := systemBarrierPrefix + "ui"
 	c.uiConfig = NewUIConfig(conf.EnableUI, physical.NewView(c.physical, uiStoragePrefix), NewBarrierView(c.barrier, uiStoragePrefix))
 
@@ -1418,6 +1437,13 @@ func (c *Core) unsealInternal(ctx context.Context, masterKey []byte) (bool, erro
 	}
 
 	if c.serviceRegistration != nil {
+		// An unsealed Vault is always initialized; this also covers servers
+		// that were initialized before this process started.
+		if err := c.serviceRegistration.NotifyInitializedStateChange(true); err != nil {
+			if c.logger.IsWarn() {
+				c.logger.Warn("failed to notify initialized status", "error", err)
+			}
+		}
 		if err := c.serviceRegistration.NotifySealedStateChange(false); err != nil {
 			if c.logger.IsWarn() {
 				c.logger.Warn("failed to notify unsealed status", "error", err)
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"github.com/hashicorp/vault/helper/namespace"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -175,6 +177,109 @@ func TestRaft_Join(t *testing.T) {
 	joinFunc(cluster.Cores[2].Client, true)
 }
 
+func TestRaft_NonVoter(t *testing.T) {
+	var conf vault.CoreConfig
+	var opts = vault.TestClusterOptions{HandlerFunc: vaulthttp.Handler}
+	teststorage.RaftBackendSetup(&conf, &opts)
+	opts.SetupFunc = nil
+	cluster := vault.NewTestCluster(t, &conf, &opts)
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	addressProvider := &testhelpers.TestRaftServerAddressProvider{Cluster: cluster}
+
+	leaderCore := cluster.Cores[0]
+	leaderAPI := leaderCore.Client.Address()
+	atomic.StoreUint32(&vault.UpdateClusterAddrForTests, 1)
+
+	// Seal the leader so we can install an address provider
+	{
+		testhelpers.EnsureCoreSealed(t, leaderCore)
+		leaderCore.UnderlyingRawStorage.(*raft.RaftBackend).SetServerAddressProvider(addressProvider)
+		cluster.UnsealCore(t, leaderCore)
+		vault.TestWaitActive(t, leaderCore.Core)
+	}
+
+	joinFunc := func(core *vault.TestClusterCore, nonVoter bool) {
+		core.UnderlyingRawStorage.(*raft.RaftBackend).SetServerAddressProvider(addressProvider)
+		resp, err := core.Client.Sys().RaftJoin(&api.RaftJoinRequest{
+			LeaderAPIAddr: leaderAPI,
+			LeaderCACert:  string(cluster.CACertPEM),
+			NonVoter:      nonVoter,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Joined {
+			t.Fatalf("failed to join raft cluster")
+		}
+		cluster.UnsealCore(t, core)
+	}
+
+	joinFunc(cluster.Cores[1], false)
+	joinFunc(cluster.Cores[2], true)
+
+	client := leaderCore.Client
+	checkVoters := func(expected map[string]bool) {
+		t.Helper()
+		secret, err := client.Logical().Read("sys/storage/raft/configuration")
+		if err != nil {
+			t.Fatal(err)
+		}
+		servers := secret.Data["config"].(map[string]interface{})["servers"].([]interface{})
+		if len(servers) != len(expected) {
+			t.Fatalf("expected %d servers, got %d", len(expected), len(servers))
+		}
+		for _, s := range servers {
+			server := s.(map[string]interface{})
+			nodeID := server["node_id"].(string)
+			if server["voter"].(bool) != expected[nodeID] {
+				t.Fatalf("bad voter status for %q: %#v", nodeID, server)
+			}
+		}
+	}
+
+	checkVoters(map[string]bool{
+		"core-0": true,
+		"core-1": true,
+		"core-2": false,
+	})
+
+	if _, err := client.Logical().Write("sys/storage/raft/promote", map[string]interface{}{
+		"server_id": "core-2",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkVoters(map[string]bool{
+		"core-0": true,
+		"core-1": true,
+		"core-2": true,
+	})
+
+	if _, err := client.Logical().Write("sys/storage/raft/demote", map[string]interface{}{
+		"server_id": "core-1",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	checkVoters(map[string]bool{
+		"core-0": true,
+		"core-1": false,
+		"core-2": true,
+	})
+
+	// The leader cannot be demoted and a non-voter cannot be demoted again
+	if _, err := client.Logical().Write("sys/storage/raft/demote", map[string]interface{}{
+		"server_id": "core-0",
+	}); err == nil {
+		t.Fatal("expected error demoting the leader")
+	}
+	if _, err := client.Logical().Write("sys/storage/raft/demote", map[string]interface{}{
+		"server_id": "core-1",
+	}); err == nil {
+		t.Fatal("expected error demoting a non-voter")
+	}
+}
+
 func TestRaft_RemovePeer(t *testing.T) {
 	cluster := raftCluster(t)
 	defer cluster.Cleanup()
@@ -815,6 +920,103 @@ func TestRaft_SnapshotAPI_DifferentCluster(t *testing.T) {
 	}
 }
 
+func TestRaft_SnapshotAuto(t *testing.T) {
+	cluster := raftCluster(t)
+	defer cluster.Cleanup()
+
+	leaderClient := cluster.Cores[0].Client
+
+	dir, err := ioutil.TempDir("", "vault-raft-snapshot-auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Relative paths are rejected for local storage
+	_, err = leaderClient.Logical().Write("sys/storage/raft/snapshot-auto/config/hourly", map[string]interface{}{
+		"interval":    "1s",
+		"path_prefix": "relative/dir",
+	})
+	if err == nil {
+		t.Fatal("expected error for relative path_prefix")
+	}
+
+	_, err = leaderClient.Logical().Write("sys/storage/raft/snapshot-auto/config/hourly", map[string]interface{}{
+		"interval":    "1s",
+		"retain":      2,
+		"path_prefix": dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := leaderClient.Logical().List("sys/storage/raft/snapshot-auto/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := secret.Data["keys"].([]interface{}); len(keys) != 1 || keys[0].(string) != "hourly" {
+		t.Fatalf("bad: %#v", secret.Data)
+	}
+
+	// Wait for more snapshots than are retained to be taken, and for the
+	// oldest ones to be pruned
+	var snapshots []os.FileInfo
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		secret, err = leaderClient.Logical().Read("sys/storage/raft/snapshot-auto/status/hourly")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret.Data["last_error"].(string) != "" {
+			t.Fatalf("unexpected error: %#v", secret.Data)
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Skip snapshots being written
+		snapshots = snapshots[:0]
+		for _, f := range files {
+			if !strings.HasSuffix(f.Name(), ".tmp") {
+				snapshots = append(snapshots, f)
+			}
+		}
+
+		deleted, _ := secret.Data["last_retention_deleted"].(json.Number).Int64()
+		if deleted > 0 && len(snapshots) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected 2 retained snapshots after pruning, got %d, status: %#v", len(snapshots), secret.Data)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	for _, f := range snapshots {
+		if !strings.HasPrefix(f.Name(), "vault-snapshot-") || !strings.HasSuffix(f.Name(), ".snap") {
+			t.Fatalf("unexpected file %q", f.Name())
+		}
+		if f.Size() == 0 {
+			t.Fatalf("empty snapshot %q", f.Name())
+		}
+	}
+	if secret.Data["last_success"].(string) == "" {
+		t.Fatalf("expected a successful snapshot: %#v", secret.Data)
+	}
+
+	_, err = leaderClient.Logical().Delete("sys/storage/raft/snapshot-auto/config/hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err = leaderClient.Logical().Read("sys/storage/raft/snapshot-auto/config/hourly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != nil {
+		t.Fatalf("expected schedule to be deleted: %#v", secret)
+	}
+}
+
 func BenchmarkRaft_SingleNode(b *testing.B) {
 	cluster := raftCluster(b)
 	defer cluster.Cleanup()
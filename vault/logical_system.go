@@ -2063,6 +2063,157 @@ func (b *SystemBackend) handlePoliciesDelete(policyType PolicyType) framework.Op
 	}
 }
 
+// simulatedCapabilities returns the capabilities granted on the path to
+// requests with the condition input of the context. Unlike ACL.Capabilities,
+// capabilities whose conditions aren't satisfied are left out.
+func simulatedCapabilities(ctx context.Context, acl *ACL, path string) []string {
+	var capabilities []string
+	var sudo bool
+	for _, opCap := range []struct {
+		op         logical.Operation
+		capability string
+	}{
+		{logical.ReadOperation, ReadCapability},
+		{logical.ListOperation, ListCapability},
+		{logical.UpdateOperation, UpdateCapability},
+		{logical.DeleteOperation, DeleteCapability},
+		{logical.CreateOperation, CreateCapability},
+	} {
+		res := acl.AllowOperation(ctx, &logical.Request{Path: path, Operation: opCap.op}, false)
+		if res.IsRoot {
+			return []string{RootCapability}
+		}
+		sudo = sudo || res.RootPrivs
+		if res.Allowed {
+			capabilities = append(capabilities, opCap.capability)
+		}
+	}
+
+	if sudo {
+		capabilities = append([]string{SudoCapability}, capabilities...)
+	}
+	if len(capabilities) == 0 {
+		capabilities = []string{DenyCapability}
+	}
+	return capabilities
+}
+
+// handlePoliciesSimulate handles the "/sys/policies/simulate" endpoint to
+// evaluate a request against a set of ACL policies
+func (b *SystemBackend) handlePoliciesSimulate(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	path := strings.TrimPrefix(data.Get("path").(string), "/")
+	if path == "" {
+		return logical.ErrorResponse("missing path"), logical.ErrInvalidRequest
+	}
+
+	op := logical.Operation(strings.ToLower(data.Get("operation").(string)))
+	switch op {
+	case logical.CreateOperation, logical.ReadOperation, logical.UpdateOperation, logical.DeleteOperation, logical.ListOperation:
+	default:
+		return logical.ErrorResponse(fmt.Sprintf("invalid operation %q", op)), logical.ErrInvalidRequest
+	}
+
+	var warnings []string
+	var policies []*Policy
+	for _, name := range data.Get("policies").([]string) {
+		policy, err := b.Core.policyStore.GetPolicy(ctx, name, PolicyTypeACL)
+		if err != nil {
+			return handleError(err)
+		}
+		if policy == nil {
+			return logical.ErrorResponse(fmt.Sprintf("policy %q does not exist", name)), logical.ErrInvalidRequest
+		}
+		if policy.Templated {
+			warnings = append(warnings, fmt.Sprintf("policy %q is templated; templated paths were evaluated without an identity", policy.Name))
+		}
+		policies = append(policies, policy)
+	}
+	for i, rules := range data.Get("inline_policies").([]string) {
+		policy, err := ParseACLPolicy(ns, rules)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("inline policy %d: %s", i, err)), logical.ErrInvalidRequest
+		}
+		if policy.Name == "" {
+			policy.Name = fmt.Sprintf("inline-%d", i)
+		}
+		policies = append(policies, policy)
+	}
+	if len(policies) == 0 {
+		return logical.ErrorResponse("at least one of policies or inline_policies must be provided"), logical.ErrInvalidRequest
+	}
+
+	simReq := &logical.Request{
+		Operation: op,
+		Path:      path,
+		Data:      data.Get("parameters").(map[string]interface{}),
+	}
+	if wrapTTL := data.Get("wrap_ttl").(int); wrapTTL > 0 {
+		simReq.WrapInfo = &logical.RequestWrapInfo{
+			TTL: time.Duration(wrapTTL) * time.Second,
+		}
+	}
+
+	// Policy conditions are evaluated against the given request attributes
+	// and the current time
+	in := &policyConditionInput{
+		time:         time.Now(),
+		remoteAddr:   data.Get("remote_addr").(string),
+		tokenMeta:    data.Get("token_metadata").(map[string]string),
+		mfaValidated: data.Get("mfa_validated").(bool),
+	}
+	entityMeta := data.Get("entity_metadata").(map[string]string)
+	groupMeta := data.Get("group_metadata").(map[string]string)
+	if len(entityMeta) > 0 || len(groupMeta) > 0 {
+		in.entity = &identity.Entity{
+			Metadata: entityMeta,
+		}
+	}
+	if len(groupMeta) > 0 {
+		in.groupsFunc = func() []*identity.Group {
+			return []*identity.Group{{Metadata: groupMeta}}
+		}
+	}
+	simCtx := contextWithPolicyConditionInput(ctx, in)
+
+	simulate := func(policies []*Policy) (map[string]interface{}, error) {
+		acl, err := NewACL(ctx, policies)
+		if err != nil {
+			return nil, err
+		}
+		res := acl.AllowOperation(simCtx, simReq, false)
+		return map[string]interface{}{
+			"allowed":      res.Allowed,
+			"matched_path": res.MatchedPath,
+			"capabilities": simulatedCapabilities(simCtx, acl, path),
+		}, nil
+	}
+
+	result, err := simulate(policies)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	policyResults := make(map[string]interface{}, len(policies))
+	for _, policy := range policies {
+		policyResult, err := simulate([]*Policy{policy})
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+		policyResults[policy.Name] = policyResult
+	}
+	result["policies"] = policyResults
+
+	return &logical.Response{
+		Data:     result,
+		Warnings: warnings,
+	}, nil
+}
+
 // handleAuditTable handles the "audit" endpoint to provide the audit table
 func (b *SystemBackend) handleAuditTable(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	b.Core.auditLock.RLock()
@@ -3196,6 +3347,34 @@ func (b *SystemBackend) pathInternalUIResultantACL(ctx context.Context, req *log
 		if len(perms.RequiredParameters) > 0 {
 			res["required_parameters"] = perms.RequiredParameters
 		}
+		if len(perms.ParameterConstraints) > 0 {
+			constraints := make(map[string]interface{}, len(perms.ParameterConstraints))
+			for param, paramConstraints := range perms.ParameterConstraints {
+				var list []map[string]interface{}
+				for _, c := range paramConstraints {
+					constraint := map[string]interface{}{}
+					if len(c.Globs) > 0 {
+						constraint["glob"] = c.Globs
+					}
+					if len(c.Regexes) > 0 {
+						regexes := make([]string, 0, len(c.Regexes))
+						for _, re := range c.Regexes {
+							regexes = append(regexes, re.String())
+						}
+						constraint["regex"] = regexes
+					}
+					if c.Min != nil {
+						constraint["min"] = *c.Min
+					}
+					if c.Max != nil {
+						constraint["max"] = *c.Max
+					}
+					list = append(list, constraint)
+				}
+				constraints[param] = list
+			}
+			res["parameter_constraints"] = constraints
+		}
 
 		pt[s] = res
 	}
@@ -3745,6 +3924,18 @@ or delete a policy.
 		`,
 	},
 
+	"policy-simulate": {
+		`Evaluate a request against a set of ACL policies.`,
+		`
+Evaluate whether a request with the given path, operation and parameters would
+be allowed by a set of stored or inline ACL policies, without issuing a token.
+Policy conditions are evaluated against the current time and the given client
+address, token, entity and group metadata, and MFA validation. Returns whether the request is allowed, the path of the matching policy rule
+and the resulting capabilities on the path, along with the result for each
+policy on its own.
+		`,
+	},
+
 	"policy-name": {
 		`The name of the policy. Example: "ops"`,
 		"",
@@ -1412,6 +1412,68 @@ func (b *SystemBackend) policyPaths() []*framework.Path {
 			HelpDescription: strings.TrimSpace(sysHelp["policy-list"][1]),
 		},
 
+		{
+			Pattern: "policies/simulate$",
+
+			Fields: map[string]*framework.FieldSchema{
+				"policies": &framework.FieldSchema{
+					Type:        framework.TypeCommaStringSlice,
+					Description: "Names of stored ACL policies to evaluate the request against.",
+				},
+				"inline_policies": &framework.FieldSchema{
+					Type:        framework.TypeStringSlice,
+					Description: "ACL policies, in HCL or JSON, to evaluate the request against.",
+				},
+				"path": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The request path.",
+				},
+				"operation": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Default:     "read",
+					Description: "The request operation: create, read, update, delete or list.",
+				},
+				"parameters": &framework.FieldSchema{
+					Type:        framework.TypeMap,
+					Description: "The request parameters.",
+				},
+				"wrap_ttl": &framework.FieldSchema{
+					Type:        framework.TypeDurationSecond,
+					Description: "The requested response wrapping TTL, if any.",
+				},
+				"remote_addr": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "The client address of the request, for policy conditions on cidrs.",
+				},
+				"token_metadata": &framework.FieldSchema{
+					Type:        framework.TypeKVPairs,
+					Description: "The login metadata of the token, for policy conditions on token_metadata.",
+				},
+				"entity_metadata": &framework.FieldSchema{
+					Type:        framework.TypeKVPairs,
+					Description: "The metadata of the entity, for policy conditions on entity_metadata.",
+				},
+				"group_metadata": &framework.FieldSchema{
+					Type:        framework.TypeKVPairs,
+					Description: "The metadata of a group of the entity, for policy conditions on group_metadata.",
+				},
+				"mfa_validated": &framework.FieldSchema{
+					Type:        framework.TypeBool,
+					Description: "Whether the token was issued by a login that validated MFA, for policy conditions on mfa_validated.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handlePoliciesSimulate,
+					Summary:  "Evaluate a request against a set of ACL policies.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["policy-simulate"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["policy-simulate"][1]),
+		},
+
 		{
 			Pattern: "policies/acl/(?P<name>.+)",
 
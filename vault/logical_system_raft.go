@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"strings"
+	"time"
 
 	proto "github.com/golang/protobuf/proto"
 	wrapping "github.com/hashicorp/go-kms-wrapping"
@@ -86,6 +87,44 @@ func (b *SystemBackend) raftStoragePaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-remove-peer"][0]),
 			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-remove-peer"][1]),
 		},
+		{
+			Pattern: "storage/raft/promote",
+
+			Fields: map[string]*framework.FieldSchema{
+				"server_id": {
+					Type: framework.TypeString,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleRaftPromotePeerUpdate(),
+					Summary:  "Promotes a non-voting peer to a voter.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-promote"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-promote"][1]),
+		},
+		{
+			Pattern: "storage/raft/demote",
+
+			Fields: map[string]*framework.FieldSchema{
+				"server_id": {
+					Type: framework.TypeString,
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleRaftDemotePeerUpdate(),
+					Summary:  "Demotes a voting peer to a non-voter.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-demote"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-demote"][1]),
+		},
 		{
 			Pattern: "storage/raft/configuration",
 
@@ -127,6 +166,100 @@ func (b *SystemBackend) raftStoragePaths() []*framework.Path {
 			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-force"][0]),
 			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-force"][1]),
 		},
+		{
+			Pattern: "storage/raft/snapshot-auto/config/?$",
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ListOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigList(),
+					Summary:  "Lists the automated snapshot schedules.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config-list"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config-list"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-auto/config/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the automated snapshot schedule.",
+				},
+				"interval": {
+					Type:        framework.TypeDurationSecond,
+					Description: "Time between snapshots.",
+				},
+				"retain": {
+					Type:        framework.TypeInt,
+					Default:     1,
+					Description: "Number of snapshots to keep on the storage target; older ones are deleted.",
+				},
+				"storage_type": {
+					Type:        framework.TypeString,
+					Default:     "local",
+					Description: `Where snapshots are stored. "local" writes them to a directory on the active node.`,
+				},
+				"path_prefix": {
+					Type:        framework.TypeString,
+					Description: "Directory (or storage specific prefix) snapshots are written to.",
+				},
+				"file_prefix": {
+					Type:        framework.TypeString,
+					Default:     raftSnapshotAutoDefaultFilePrefix,
+					Description: "Prefix of the snapshot file names. Retention only considers snapshots with this prefix.",
+				},
+				"storage_config": {
+					Type:        framework.TypeKVPairs,
+					Description: "Additional configuration passed to the storage target.",
+				},
+			},
+
+			ExistenceCheck: b.handleStorageRaftSnapshotAutoConfigExistence(),
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigRead(),
+					Summary:  "Reads an automated snapshot schedule.",
+				},
+				logical.CreateOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigWrite(),
+					Summary:  "Creates or updates an automated snapshot schedule.",
+				},
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigWrite(),
+					Summary:  "Creates or updates an automated snapshot schedule.",
+				},
+				logical.DeleteOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoConfigDelete(),
+					Summary:  "Deletes an automated snapshot schedule.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-config"][1]),
+		},
+		{
+			Pattern: "storage/raft/snapshot-auto/status/" + framework.GenericNameRegex("name"),
+
+			Fields: map[string]*framework.FieldSchema{
+				"name": {
+					Type:        framework.TypeString,
+					Description: "Name of the automated snapshot schedule.",
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.ReadOperation: &framework.PathOperation{
+					Callback: b.handleStorageRaftSnapshotAutoStatusRead(),
+					Summary:  "Returns the status of an automated snapshot schedule.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-status"][0]),
+			HelpDescription: strings.TrimSpace(sysRaftHelp["raft-snapshot-auto-status"][1]),
+		},
 	}
 }
 
@@ -174,6 +307,50 @@ func (b *SystemBackend) handleRaftRemovePeerUpdate() framework.OperationFunc {
 	}
 }
 
+func (b *SystemBackend) handleRaftPromotePeerUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		serverID := d.Get("server_id").(string)
+		if len(serverID) == 0 {
+			return logical.ErrorResponse("no server id provided"), logical.ErrInvalidRequest
+		}
+
+		raftStorage, ok := b.Core.underlyingPhysical.(*raft.RaftBackend)
+		if !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		if err := raftStorage.PromotePeer(ctx, serverID); err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+
+		b.logger.Info("promoted raft peer to voter", "server_id", serverID)
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleRaftDemotePeerUpdate() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		serverID := d.Get("server_id").(string)
+		if len(serverID) == 0 {
+			return logical.ErrorResponse("no server id provided"), logical.ErrInvalidRequest
+		}
+
+		raftStorage, ok := b.Core.underlyingPhysical.(*raft.RaftBackend)
+		if !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		if err := raftStorage.DemotePeer(ctx, serverID); err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+
+		b.logger.Info("demoted raft peer to non-voter", "server_id", serverID)
+
+		return nil, nil
+	}
+}
+
 func (b *SystemBackend) handleRaftBootstrapChallengeWrite() framework.OperationFunc {
 	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
 		serverID := d.Get("server_id").(string)
@@ -429,6 +606,177 @@ func (b *SystemBackend) handleStorageRaftSnapshotWrite(force bool) framework.Ope
 	}
 }
 
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigList() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		names, err := b.Core.barrier.List(ctx, raftSnapshotAutoConfigPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return logical.ListResponse(names), nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigExistence() framework.ExistenceFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (bool, error) {
+		config, err := b.Core.readRaftSnapshotAutoConfig(ctx, d.Get("name").(string))
+		if err != nil {
+			return false, err
+		}
+
+		return config != nil, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		config, err := b.Core.readRaftSnapshotAutoConfig(ctx, d.Get("name").(string))
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return nil, nil
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"interval":       int64(config.Interval.Seconds()),
+				"retain":         config.Retain,
+				"storage_type":   config.StorageType,
+				"path_prefix":    config.PathPrefix,
+				"file_prefix":    config.FilePrefix,
+				"storage_config": config.StorageConfig,
+			},
+		}, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigWrite() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		if _, ok := b.Core.underlyingPhysical.(*raft.RaftBackend); !ok {
+			return logical.ErrorResponse("raft storage is not in use"), logical.ErrInvalidRequest
+		}
+
+		name := d.Get("name").(string)
+		config, err := b.Core.readRaftSnapshotAutoConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			config = &raftSnapshotAutoConfig{
+				Name: name,
+			}
+		}
+
+		if intervalRaw, ok := d.GetOk("interval"); ok {
+			config.Interval = time.Duration(intervalRaw.(int)) * time.Second
+		}
+		if _, ok := d.GetOk("retain"); ok || req.Operation == logical.CreateOperation {
+			config.Retain = d.Get("retain").(int)
+		}
+		if _, ok := d.GetOk("storage_type"); ok || req.Operation == logical.CreateOperation {
+			config.StorageType = d.Get("storage_type").(string)
+		}
+		if pathPrefixRaw, ok := d.GetOk("path_prefix"); ok {
+			config.PathPrefix = pathPrefixRaw.(string)
+		}
+		if _, ok := d.GetOk("file_prefix"); ok || req.Operation == logical.CreateOperation {
+			config.FilePrefix = d.Get("file_prefix").(string)
+		}
+		if storageConfigRaw, ok := d.GetOk("storage_config"); ok {
+			config.StorageConfig = storageConfigRaw.(map[string]string)
+		}
+
+		switch {
+		case config.Interval <= 0:
+			return logical.ErrorResponse("interval must be greater than zero"), logical.ErrInvalidRequest
+		case config.Retain < 1:
+			return logical.ErrorResponse("retain must be at least 1"), logical.ErrInvalidRequest
+		case config.FilePrefix == "" || strings.ContainsAny(config.FilePrefix, `/\`):
+			return logical.ErrorResponse("file_prefix must be non-empty and may not contain path separators"), logical.ErrInvalidRequest
+		}
+
+		// Validate the storage settings by building the target up front
+		if _, err := b.Core.raftSnapshotTarget(config); err != nil {
+			return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+		}
+
+		entry, err := logical.StorageEntryJSON(raftSnapshotAutoConfigPath+name, config)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.Core.barrier.Put(ctx, entry); err != nil {
+			return nil, err
+		}
+
+		if err := b.Core.restartRaftSnapshotSchedule(name, config); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoConfigDelete() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+
+		if err := b.Core.restartRaftSnapshotSchedule(name, nil); err != nil {
+			return nil, err
+		}
+		if err := b.Core.barrier.Delete(ctx, raftSnapshotAutoConfigPath+name); err != nil {
+			return nil, err
+		}
+		if err := b.Core.barrier.Delete(ctx, raftSnapshotAutoStatusPath+name); err != nil {
+			return nil, err
+		}
+
+		return nil, nil
+	}
+}
+
+func (b *SystemBackend) handleStorageRaftSnapshotAutoStatusRead() framework.OperationFunc {
+	return func(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+		name := d.Get("name").(string)
+		config, err := b.Core.readRaftSnapshotAutoConfig(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if config == nil {
+			return logical.ErrorResponse("no automated snapshot schedule named %q", name), logical.ErrInvalidRequest
+		}
+
+		status, err := b.Core.readRaftSnapshotAutoStatus(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if status == nil {
+			status = new(raftSnapshotAutoStatus)
+		}
+
+		formatTime := func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.UTC().Format(time.RFC3339Nano)
+		}
+
+		return &logical.Response{
+			Data: map[string]interface{}{
+				"last_snapshot_start":    formatTime(status.LastSnapshotStart),
+				"last_snapshot_end":      formatTime(status.LastSnapshotEnd),
+				"last_snapshot_name":     status.LastSnapshotName,
+				"last_snapshot_size":     status.LastSnapshotSize,
+				"last_retention_deleted": status.LastRetentionDeleted,
+				"last_success":           formatTime(status.LastSuccess),
+				"last_failure":           formatTime(status.LastFailure),
+				"last_error":             status.LastError,
+				"consecutive_failures":   status.ConsecutiveFailures,
+			},
+		}, nil
+	}
+}
+
 var sysRaftHelp = map[string][2]string{
 	"raft-bootstrap-challenge": {
 		"Creates a challenge for the new peer to be joined to the raft cluster.",
@@ -446,6 +794,14 @@ var sysRaftHelp = map[string][2]string{
 		"Removes a peer from the raft cluster.",
 		"",
 	},
+	"raft-promote": {
+		"Promotes a non-voting peer to a voting member of the raft cluster.",
+		"",
+	},
+	"raft-demote": {
+		"Demotes a voting peer to a non-voting member of the raft cluster.",
+		"",
+	},
 	"raft-snapshot": {
 		"Restores and saves snapshots from the raft cluster.",
 		"",
@@ -454,4 +810,20 @@ var sysRaftHelp = map[string][2]string{
 		"Force restore a raft cluster snapshot",
 		"",
 	},
+	"raft-snapshot-auto-config-list": {
+		"Lists the automated raft snapshot schedules.",
+		"",
+	},
+	"raft-snapshot-auto-config": {
+		"Configures automated raft snapshots taken by the active node.",
+		`
+Each schedule takes a snapshot every interval, writes it to the configured
+storage target and keeps only the most recent "retain" snapshots. The "local"
+storage type writes to the directory given in "path_prefix" on the active node.
+		`,
+	},
+	"raft-snapshot-auto-status": {
+		"Returns the last success and failure of an automated raft snapshot schedule.",
+		"",
+	},
 }
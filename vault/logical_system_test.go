@@ -1696,6 +1696,237 @@ func TestSystemBackend_policyCRUD(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_policySimulate(t *testing.T) {
+	b := testSystemBackend(t)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "policies/acl/secrets")
+	req.Data["policy"] = `
+path "secret/*" {
+	capabilities = ["read", "list"]
+}
+path "secret/admin" {
+	capabilities = ["deny"]
+}
+path "pki/issue/+" {
+	capabilities = ["update"]
+	parameter_constraints "common_name" {
+		glob = ["*.svc.internal"]
+	}
+}`
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v %#v", err, resp)
+	}
+
+	simulate := func(data map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "policies/simulate")
+		req.Data = data
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("err: %v %#v", err, resp)
+		}
+		return resp.Data
+	}
+
+	tcases := []struct {
+		data         map[string]interface{}
+		allowed      bool
+		matchedPath  string
+		capabilities []string
+	}{
+		{
+			map[string]interface{}{"policies": "secrets", "path": "secret/foo"},
+			true, "secret/*", []string{"read", "list"},
+		},
+		{
+			map[string]interface{}{"policies": "secrets", "path": "secret/foo", "operation": "update"},
+			false, "secret/*", []string{"read", "list"},
+		},
+		{
+			map[string]interface{}{"policies": "secrets", "path": "secret/admin"},
+			false, "secret/admin", []string{"deny"},
+		},
+		{
+			map[string]interface{}{"policies": "secrets", "path": "sys/mounts"},
+			false, "", []string{"deny"},
+		},
+		{
+			map[string]interface{}{
+				"policies":   "secrets",
+				"path":       "pki/issue/web",
+				"operation":  "update",
+				"parameters": map[string]interface{}{"common_name": "web.svc.internal"},
+			},
+			true, "pki/issue/+", []string{"update"},
+		},
+		{
+			map[string]interface{}{
+				"policies":   "secrets",
+				"path":       "pki/issue/web",
+				"operation":  "update",
+				"parameters": map[string]interface{}{"common_name": "web.example.com"},
+			},
+			false, "pki/issue/+", []string{"update"},
+		},
+		{
+			map[string]interface{}{
+				"policies":        "secrets",
+				"inline_policies": []string{`path "secret/foo" { capabilities = ["update"] }`},
+				"path":            "secret/foo",
+				"operation":       "update",
+			},
+			true, "secret/foo", []string{"update"},
+		},
+	}
+
+	for i, tc := range tcases {
+		data := simulate(tc.data)
+		if data["allowed"] != tc.allowed {
+			t.Fatalf("%d: expected allowed %t, got %#v", i, tc.allowed, data)
+		}
+		if data["matched_path"] != tc.matchedPath {
+			t.Fatalf("%d: expected matched path %q, got %#v", i, tc.matchedPath, data)
+		}
+		if !reflect.DeepEqual(data["capabilities"], tc.capabilities) {
+			t.Fatalf("%d: expected capabilities %v, got %#v", i, tc.capabilities, data)
+		}
+	}
+
+	// Results are also returned for each policy on its own
+	data := simulate(map[string]interface{}{
+		"policies":        "secrets",
+		"inline_policies": []string{`name = "writer"` + "\n" + `path "secret/foo" { capabilities = ["update"] }`},
+		"path":            "secret/foo",
+		"operation":       "update",
+	})
+	policyResults := data["policies"].(map[string]interface{})
+	if policyResults["secrets"].(map[string]interface{})["allowed"] != false {
+		t.Fatalf("bad: %#v", policyResults)
+	}
+	if policyResults["writer"].(map[string]interface{})["allowed"] != true {
+		t.Fatalf("bad: %#v", policyResults)
+	}
+
+	// Errors
+	for _, data := range []map[string]interface{}{
+		{"path": "secret/foo"},
+		{"policies": "secrets"},
+		{"policies": "nonexistent", "path": "secret/foo"},
+		{"policies": "secrets", "path": "secret/foo", "operation": "patch"},
+		{"inline_policies": []string{`path "secret/foo" { capabilities = ["bogus"] }`}, "path": "secret/foo"},
+	} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "policies/simulate")
+		req.Data = data
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != logical.ErrInvalidRequest || resp == nil || !resp.IsError() {
+			t.Fatalf("expected error for %v, got %v %#v", data, err, resp)
+		}
+	}
+}
+
+func TestSystemBackend_policySimulate_conditions(t *testing.T) {
+	b := testSystemBackend(t)
+
+	// The endpoint doesn't shadow a policy named "simulate"
+	req := logical.TestRequest(t, logical.UpdateOperation, "policies/acl/simulate")
+	req.Data["policy"] = `
+path "secret/ci" {
+	capabilities = ["read"]
+	condition {
+		cidrs          = ["10.0.0.0/8"]
+		token_metadata = { source = "ci" }
+	}
+}
+path "secret/ops" {
+	capabilities = ["read"]
+	condition {
+		group_metadata = { team = "ops" }
+		mfa_validated  = true
+	}
+}`
+	resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v %#v", err, resp)
+	}
+	req = logical.TestRequest(t, logical.ReadOperation, "policies/acl/simulate")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp == nil || resp.Data["name"] != "simulate" {
+		t.Fatalf("err: %v %#v", err, resp)
+	}
+
+	tcases := []struct {
+		data    map[string]interface{}
+		allowed bool
+	}{
+		{
+			map[string]interface{}{"path": "secret/ci"},
+			false,
+		},
+		{
+			map[string]interface{}{
+				"path":           "secret/ci",
+				"remote_addr":    "10.1.2.3",
+				"token_metadata": map[string]interface{}{"source": "ci"},
+			},
+			true,
+		},
+		{
+			map[string]interface{}{
+				"path":           "/secret/ci",
+				"remote_addr":    "10.1.2.3",
+				"token_metadata": map[string]interface{}{"source": "ci"},
+			},
+			true,
+		},
+		{
+			map[string]interface{}{
+				"path":           "secret/ci",
+				"remote_addr":    "192.168.1.1",
+				"token_metadata": map[string]interface{}{"source": "ci"},
+			},
+			false,
+		},
+		{
+			map[string]interface{}{
+				"path":           "secret/ops",
+				"group_metadata": map[string]interface{}{"team": "ops"},
+			},
+			false,
+		},
+		{
+			map[string]interface{}{
+				"path":           "secret/ops",
+				"group_metadata": map[string]interface{}{"team": "ops"},
+				"mfa_validated":  true,
+			},
+			true,
+		},
+	}
+
+	for i, tc := range tcases {
+		req := logical.TestRequest(t, logical.UpdateOperation, "policies/simulate")
+		req.Data = tc.data
+		req.Data["policies"] = "simulate"
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("%d: err: %v %#v", i, err, resp)
+		}
+		if resp.Data["allowed"] != tc.allowed {
+			t.Fatalf("%d: expected allowed %t, got %#v", i, tc.allowed, resp.Data)
+		}
+
+		// Capabilities reflect the conditions too
+		expected := []string{DenyCapability}
+		if tc.allowed {
+			expected = []string{ReadCapability}
+		}
+		if !reflect.DeepEqual(resp.Data["capabilities"], expected) {
+			t.Fatalf("%d: expected capabilities %v, got %#v", i, expected, resp.Data["capabilities"])
+		}
+	}
+}
+
 func TestSystemBackend_enableAudit(t *testing.T) {
 	c, b, _ := testCoreSystemBackend(t)
 	c.auditBackends["noop"] = func(ctx context.Context, config *audit.BackendConfig) (audit.Backend, error) {
@@ -1,8 +1,13 @@
 package vault
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +21,7 @@ import (
 	"github.com/hashicorp/vault/sdk/helper/identitytpl"
 	"github.com/hashicorp/vault/sdk/helper/parseutil"
 	"github.com/mitchellh/copystructure"
+	glob "github.com/ryanuber/go-glob"
 )
 
 const (
@@ -129,6 +135,27 @@ type PathRules struct {
 	RequiredParametersHCL []string                 `hcl:"required_parameters"`
 	MFAMethodsHCL         []string                 `hcl:"mfa_methods"`
 	ControlGroupHCL       *ControlGroupHCL         `hcl:"control_group"`
+
+	ParameterConstraintsHCL map[string]*ParameterConstraintHCL `hcl:"parameter_constraints"`
+	ConditionHCL            *PolicyConditionHCL                `hcl:"condition"`
+}
+
+type ParameterConstraintHCL struct {
+	Glob  []string    `hcl:"glob"`
+	Regex []string    `hcl:"regex"`
+	Min   interface{} `hcl:"min"`
+	Max   interface{} `hcl:"max"`
+}
+
+// ParameterConstraint restricts the values a request parameter can take. A
+// value satisfies the constraint if it matches one of the globs or regexes,
+// when any are set, and falls within the numeric bounds, when any are set.
+// Regexes are anchored, so they must match the whole value.
+type ParameterConstraint struct {
+	Globs   []string
+	Regexes []*regexp.Regexp
+	Min     *float64
+	Max     *float64
 }
 
 type ControlGroupHCL struct {
@@ -161,6 +188,14 @@ type ACLPermissions struct {
 	RequiredParameters []string
 	MFAMethods         []string
 	ControlGroup       *ControlGroup
+
+	// ParameterConstraints holds, for each parameter, the constraints of
+	// which a value must satisfy at least one
+	ParameterConstraints map[string][]*ParameterConstraint
+
+	// Conditions must all be satisfied by a request for the permissions to
+	// apply to it
+	Conditions []*PolicyCondition
 }
 
 func (p *ACLPermissions) Clone() (*ACLPermissions, error) {
@@ -195,6 +230,18 @@ func (p *ACLPermissions) Clone() (*ACLPermissions, error) {
 		ret.DeniedParameters = clonedDenied.(map[string][]interface{})
 	}
 
+	// Constraints and conditions are not modified after parsing, so they can
+	// be shared
+	if p.ParameterConstraints != nil {
+		ret.ParameterConstraints = make(map[string][]*ParameterConstraint, len(p.ParameterConstraints))
+		for key, constraints := range p.ParameterConstraints {
+			ret.ParameterConstraints[key] = append([]*ParameterConstraint(nil), constraints...)
+		}
+	}
+	if p.Conditions != nil {
+		ret.Conditions = append([]*PolicyCondition(nil), p.Conditions...)
+	}
+
 	switch {
 	case p.MFAMethods == nil:
 	case len(p.MFAMethods) == 0:
@@ -318,6 +365,8 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 			"max_wrapping_ttl",
 			"mfa_methods",
 			"control_group",
+			"parameter_constraints",
+			"condition",
 		}
 		if err := hclutil.CheckHCLKeys(item.Val, valid); err != nil {
 			return multierror.Prefix(err, fmt.Sprintf("path %q:", key))
@@ -382,6 +431,11 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 			switch cap {
 			// If it's deny, don't include any other capability
 			case DenyCapability:
+				// A deny applies regardless of the request, so conditions
+				// can't narrow it
+				if pc.ConditionHCL != nil {
+					return fmt.Errorf("path %q: a condition cannot be set on a rule with the %q capability", key, DenyCapability)
+				}
 				pc.Capabilities = []string{DenyCapability}
 				pc.Permissions.CapabilitiesBitmap = DenyCapabilityInt
 				goto PathFinished
@@ -405,6 +459,23 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 				pc.Permissions.DeniedParameters[strings.ToLower(key)] = val
 			}
 		}
+		if pc.ParameterConstraintsHCL != nil {
+			pc.Permissions.ParameterConstraints = make(map[string][]*ParameterConstraint, len(pc.ParameterConstraintsHCL))
+			for param, val := range pc.ParameterConstraintsHCL {
+				constraint, err := parseParameterConstraint(param, val)
+				if err != nil {
+					return multierror.Prefix(err, fmt.Sprintf("path %q:", key))
+				}
+				pc.Permissions.ParameterConstraints[strings.ToLower(param)] = []*ParameterConstraint{constraint}
+			}
+		}
+		if pc.ConditionHCL != nil {
+			condition, err := parsePolicyCondition(pc.ConditionHCL)
+			if err != nil {
+				return multierror.Prefix(err, fmt.Sprintf("path %q: condition:", key))
+			}
+			pc.Permissions.Conditions = []*PolicyCondition{condition}
+		}
 		if pc.MinWrappingTTLHCL != nil {
 			dur, err := parseutil.ParseDurationSecond(pc.MinWrappingTTLHCL)
 			if err != nil {
@@ -476,3 +547,131 @@ func parsePaths(result *Policy, list *ast.ObjectList, performTemplating bool, en
 	result.Paths = paths
 	return nil
 }
+
+func parseParameterConstraint(parameter string, c *ParameterConstraintHCL) (*ParameterConstraint, error) {
+	if parameter == "*" {
+		return nil, errors.New(`parameter_constraints cannot be set on the "*" parameter`)
+	}
+	if c == nil || (len(c.Glob) == 0 && len(c.Regex) == 0 && c.Min == nil && c.Max == nil) {
+		return nil, fmt.Errorf("parameter_constraints for %q must set at least one of glob, regex, min or max", parameter)
+	}
+
+	ret := &ParameterConstraint{
+		Globs: c.Glob,
+	}
+	for _, expr := range c.Regex {
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("error compiling regex for parameter %q: {{err}}", parameter), err)
+		}
+		ret.Regexes = append(ret.Regexes, re)
+	}
+	if c.Min != nil {
+		min, ok := parameterNumber(c.Min)
+		if !ok {
+			return nil, fmt.Errorf("invalid min for parameter %q: %v", parameter, c.Min)
+		}
+		ret.Min = &min
+	}
+	if c.Max != nil {
+		max, ok := parameterNumber(c.Max)
+		if !ok {
+			return nil, fmt.Errorf("invalid max for parameter %q: %v", parameter, c.Max)
+		}
+		ret.Max = &max
+	}
+	if ret.Min != nil && ret.Max != nil && *ret.Max < *ret.Min {
+		return nil, fmt.Errorf("max cannot be less than min for parameter %q", parameter)
+	}
+
+	return ret, nil
+}
+
+// Satisfied returns whether the given request parameter value satisfies the
+// constraint. Every element of a list value must satisfy it.
+func (c *ParameterConstraint) Satisfied(v interface{}) bool {
+	if list, ok := v.([]interface{}); ok {
+		for _, el := range list {
+			if !c.Satisfied(el) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if len(c.Globs) > 0 || len(c.Regexes) > 0 {
+		s, ok := v.(string)
+		if !ok {
+			if n, ok := v.(json.Number); ok {
+				s = n.String()
+			} else {
+				s = fmt.Sprint(v)
+			}
+		}
+		if !c.matches(s) {
+			return false
+		}
+	}
+
+	if c.Min != nil || c.Max != nil {
+		n, ok := parameterNumber(v)
+		if !ok {
+			return false
+		}
+		if c.Min != nil && n < *c.Min {
+			return false
+		}
+		if c.Max != nil && n > *c.Max {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *ParameterConstraint) matches(s string) bool {
+	for _, g := range c.Globs {
+		if glob.Glob(g, s) {
+			return true
+		}
+	}
+	for _, re := range c.Regexes {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// parameterNumber returns the numeric value of a parameter. Durations such as
+// "1h" are converted to seconds so that TTL parameters can be bounded.
+func parameterNumber(v interface{}) (float64, bool) {
+	var n float64
+	switch t := v.(type) {
+	case json.Number:
+		return parameterNumber(t.String())
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			dur, err := parseutil.ParseDurationSecond(t)
+			if err != nil {
+				return 0, false
+			}
+			f = dur.Seconds()
+		}
+		n = f
+	case int, int8, int16, int32, int64:
+		n = float64(reflect.ValueOf(t).Int())
+	case uint, uint8, uint16, uint32, uint64:
+		n = float64(reflect.ValueOf(t).Uint())
+	case float32, float64:
+		n = reflect.ValueOf(t).Float()
+	default:
+		return 0, false
+	}
+
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,315 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/vault/helper/identity"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+type PolicyConditionHCL struct {
+	TimeOfDay      []string          `hcl:"time_of_day"`
+	DaysOfWeek     []string          `hcl:"days_of_week"`
+	Timezone       string            `hcl:"timezone"`
+	CIDRs          []string          `hcl:"cidrs"`
+	EntityMetadata map[string]string `hcl:"entity_metadata"`
+	GroupMetadata  map[string]string `hcl:"group_metadata"`
+	TokenMetadata  map[string]string `hcl:"token_metadata"`
+	MFAValidated   bool              `hcl:"mfa_validated"`
+}
+
+// PolicyCondition restricts when a path rule applies. Each of the set
+// attributes must be satisfied by the request.
+type PolicyCondition struct {
+	TimeOfDay      []timeOfDayRange
+	DaysOfWeek     []time.Weekday
+	Location       *time.Location
+	CIDRs          []*net.IPNet
+	EntityMetadata map[string]string
+	GroupMetadata  map[string]string
+	TokenMetadata  map[string]string
+	MFAValidated   bool
+}
+
+// timeOfDayRange is a range of minutes of the day, wrapping around midnight
+// when end is not after start.
+type timeOfDayRange struct {
+	start int
+	end   int
+}
+
+func parsePolicyCondition(c *PolicyConditionHCL) (*PolicyCondition, error) {
+	ret := &PolicyCondition{
+		Location:       time.UTC,
+		EntityMetadata: c.EntityMetadata,
+		GroupMetadata:  c.GroupMetadata,
+		TokenMetadata:  c.TokenMetadata,
+		MFAValidated:   c.MFAValidated,
+	}
+
+	for _, r := range c.TimeOfDay {
+		parsed, err := parseTimeOfDayRange(r)
+		if err != nil {
+			return nil, err
+		}
+		ret.TimeOfDay = append(ret.TimeOfDay, parsed)
+	}
+
+	for _, day := range c.DaysOfWeek {
+		weekday, ok := parseWeekday(day)
+		if !ok {
+			return nil, fmt.Errorf("invalid day of week %q", day)
+		}
+		ret.DaysOfWeek = append(ret.DaysOfWeek, weekday)
+	}
+
+	if c.Timezone != "" {
+		if len(ret.TimeOfDay) == 0 && len(ret.DaysOfWeek) == 0 {
+			return nil, errors.New("timezone requires time_of_day or days_of_week")
+		}
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("invalid timezone %q: {{err}}", c.Timezone), err)
+		}
+		ret.Location = loc
+	}
+
+	for _, cidr := range c.CIDRs {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, errwrap.Wrapf(fmt.Sprintf("invalid CIDR %q: {{err}}", cidr), err)
+		}
+		ret.CIDRs = append(ret.CIDRs, ipNet)
+	}
+
+	if len(ret.TimeOfDay) == 0 && len(ret.DaysOfWeek) == 0 && len(ret.CIDRs) == 0 &&
+		len(ret.EntityMetadata) == 0 && len(ret.GroupMetadata) == 0 && len(ret.TokenMetadata) == 0 &&
+		!ret.MFAValidated {
+		return nil, errors.New("condition must set at least one attribute")
+	}
+
+	return ret, nil
+}
+
+// parseWeekday parses a day of the week, either in full or abbreviated to its
+// first three letters.
+func parseWeekday(day string) (time.Weekday, bool) {
+	key := strings.ToLower(strings.TrimSpace(day))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if key == name || key == name[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseTimeOfDayRange parses a range such as "09:00-17:00". The end is
+// exclusive and may be "24:00".
+func parseTimeOfDayRange(r string) (timeOfDayRange, error) {
+	parts := strings.Split(strings.TrimSpace(r), "-")
+	if len(parts) != 2 {
+		return timeOfDayRange{}, fmt.Errorf("invalid time of day range %q, expected HH:MM-HH:MM", r)
+	}
+
+	var minutes [2]int
+	for i, part := range parts {
+		hm := strings.Split(strings.TrimSpace(part), ":")
+		if len(hm) != 2 || len(hm[0]) != 2 || len(hm[1]) != 2 {
+			return timeOfDayRange{}, fmt.Errorf("invalid time of day range %q, expected HH:MM-HH:MM", r)
+		}
+		h, errH := strconv.Atoi(hm[0])
+		m, errM := strconv.Atoi(hm[1])
+		if errH != nil || errM != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && (m != 0 || i == 0)) {
+			return timeOfDayRange{}, fmt.Errorf("invalid time of day range %q, expected HH:MM-HH:MM", r)
+		}
+		minutes[i] = h*60 + m
+	}
+	if minutes[0] == minutes[1] {
+		return timeOfDayRange{}, fmt.Errorf("invalid time of day range %q, start and end are equal", r)
+	}
+
+	return timeOfDayRange{start: minutes[0], end: minutes[1]}, nil
+}
+
+func (r timeOfDayRange) contains(minute int) bool {
+	if r.start < r.end {
+		return minute >= r.start && minute < r.end
+	}
+	return minute >= r.start || minute < r.end
+}
+
+// policyConditionInput holds the attributes of a request which policy
+// conditions are evaluated against.
+type policyConditionInput struct {
+	time         time.Time
+	remoteAddr   string
+	entity       *identity.Entity
+	tokenMeta    map[string]string
+	mfaValidated bool
+
+	groupsOnce sync.Once
+	groupsFunc func() []*identity.Group
+	groups     []*identity.Group
+}
+
+func (in *policyConditionInput) entityGroups() []*identity.Group {
+	in.groupsOnce.Do(func() {
+		if in.groupsFunc != nil {
+			in.groups = in.groupsFunc()
+		}
+	})
+	return in.groups
+}
+
+type policyConditionInputKey struct{}
+
+func contextWithPolicyConditionInput(ctx context.Context, in *policyConditionInput) context.Context {
+	return context.WithValue(ctx, policyConditionInputKey{}, in)
+}
+
+// policyConditionInputFromContext returns the request attributes stored in
+// the context. Without any, conditions are evaluated against the current time
+// alone, so those on other attributes are not satisfied.
+func policyConditionInputFromContext(ctx context.Context) *policyConditionInput {
+	if in, ok := ctx.Value(policyConditionInputKey{}).(*policyConditionInput); ok && in != nil {
+		return in
+	}
+	return &policyConditionInput{
+		time: time.Now(),
+	}
+}
+
+// policyConditionContext attaches the attributes of the request to the
+// context for evaluating policy conditions.
+func (c *Core) policyConditionContext(ctx context.Context, te *logical.TokenEntry, req *logical.Request, entity *identity.Entity) context.Context {
+	in := &policyConditionInput{
+		time:   time.Now(),
+		entity: entity,
+	}
+	if req.Connection != nil {
+		in.remoteAddr = req.Connection.RemoteAddr
+	}
+	if te != nil {
+		in.tokenMeta = te.LoginMeta
+		in.mfaValidated = te.MFAValidated
+	}
+	if entity != nil && c.identityStore != nil {
+		in.groupsFunc = func() []*identity.Group {
+			directGroups, inheritedGroups, err := c.identityStore.groupsByEntityID(entity.ID)
+			if err != nil {
+				c.logger.Error("failed to fetch groups for policy conditions", "entity_id", entity.ID, "error", err)
+				return nil
+			}
+			return append(directGroups, inheritedGroups...)
+		}
+	}
+
+	return contextWithPolicyConditionInput(ctx, in)
+}
+
+// Satisfied returns whether the request attributes satisfy the condition.
+func (c *PolicyCondition) Satisfied(in *policyConditionInput) bool {
+	now := in.time.In(c.Location)
+
+	if len(c.DaysOfWeek) > 0 {
+		var found bool
+		for _, day := range c.DaysOfWeek {
+			if now.Weekday() == day {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(c.TimeOfDay) > 0 {
+		minute := now.Hour()*60 + now.Minute()
+		var found bool
+		for _, r := range c.TimeOfDay {
+			if r.contains(minute) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if len(c.CIDRs) > 0 {
+		ip := remoteIP(in.remoteAddr)
+		if ip == nil {
+			return false
+		}
+		var found bool
+		for _, cidr := range c.CIDRs {
+			if cidr.Contains(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if c.MFAValidated && !in.mfaValidated {
+		return false
+	}
+
+	if len(c.TokenMetadata) > 0 && !metadataMatches(in.tokenMeta, c.TokenMetadata) {
+		return false
+	}
+
+	if len(c.EntityMetadata) > 0 && (in.entity == nil || !metadataMatches(in.entity.Metadata, c.EntityMetadata)) {
+		return false
+	}
+
+	if len(c.GroupMetadata) > 0 {
+		if in.entity == nil {
+			return false
+		}
+		var found bool
+		for _, group := range in.entityGroups() {
+			if metadataMatches(group.Metadata, c.GroupMetadata) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func remoteIP(remoteAddr string) net.IP {
+	if remoteAddr == "" {
+		return nil
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	return net.ParseIP(remoteAddr)
+}
+
+func metadataMatches(metadata, expected map[string]string) bool {
+	for k, v := range expected {
+		if value, ok := metadata[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
@@ -400,6 +400,135 @@ path "/" {
 	}
 }
 
+func TestPolicy_ParseBadParameterConstraints(t *testing.T) {
+	tcases := map[string]string{
+		`
+path "/" {
+	capabilities = ["update"]
+	parameter_constraints "name" {
+		regex = ["("]
+	}
+}`: `path "/": error compiling regex for parameter "name"`,
+		`
+path "/" {
+	capabilities = ["update"]
+	parameter_constraints "ttl" {
+		min = 60
+		max = 30
+	}
+}`: `path "/": max cannot be less than min for parameter "ttl"`,
+		`
+path "/" {
+	capabilities = ["update"]
+	parameter_constraints "ttl" {
+		max = "forever"
+	}
+}`: `path "/": invalid max for parameter "ttl"`,
+		`
+path "/" {
+	capabilities = ["update"]
+	parameter_constraints "name" {
+	}
+}`: `path "/": parameter_constraints for "name" must set at least one of glob, regex, min or max`,
+		`
+path "/" {
+	capabilities = ["update"]
+	parameter_constraints "*" {
+		glob = ["*"]
+	}
+}`: `path "/": parameter_constraints cannot be set on the "*" parameter`,
+	}
+
+	for rules, expected := range tcases {
+		_, err := ParseACLPolicy(namespace.RootNamespace, strings.TrimSpace(rules))
+		if err == nil {
+			t.Fatalf("expected error for %s", rules)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("bad error: %s", err)
+		}
+	}
+}
+
+func TestPolicy_ParseBadCondition(t *testing.T) {
+	tcases := map[string]string{
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+		time_of_day = ["9:00-17:00"]
+	}
+}`: `path "/": condition: invalid time of day range "9:00-17:00"`,
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+		time_of_day = ["24:00-08:00"]
+	}
+}`: `path "/": condition: invalid time of day range "24:00-08:00"`,
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+		days_of_week = ["mon", "someday"]
+	}
+}`: `path "/": condition: invalid day of week "someday"`,
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+		days_of_week = ["mon"]
+		timezone     = "Nowhere/Special"
+	}
+}`: `path "/": condition: invalid timezone "Nowhere/Special"`,
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+		cidrs    = ["10.0.0.0/8"]
+		timezone = "UTC"
+	}
+}`: `path "/": condition: timezone requires time_of_day or days_of_week`,
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+		cidrs = ["10.0.0.0"]
+	}
+}`: `path "/": condition: invalid CIDR "10.0.0.0"`,
+		`
+path "/" {
+	capabilities = ["read"]
+	condition {
+	}
+}`: `path "/": condition: condition must set at least one attribute`,
+		`
+path "/" {
+	capabilities = ["read", "deny"]
+	condition {
+		cidrs = ["10.0.0.0/8"]
+	}
+}`: `path "/": a condition cannot be set on a rule with the "deny" capability`,
+		`
+path "/" {
+	policy = "deny"
+	condition {
+		mfa_validated = true
+	}
+}`: `path "/": a condition cannot be set on a rule with the "deny" capability`,
+	}
+
+	for rules, expected := range tcases {
+		_, err := ParseACLPolicy(namespace.RootNamespace, strings.TrimSpace(rules))
+		if err == nil {
+			t.Fatalf("expected error for %s", rules)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("bad error: %s", err)
+		}
+	}
+}
+
 func TestPolicy_ParseBadCapabilities(t *testing.T) {
 	_, err := ParseACLPolicy(namespace.RootNamespace, strings.TrimSpace(`
 path "/" {
@@ -161,12 +161,16 @@ func (c *Core) startRaftStorage(ctx context.Context) (retErr error) {
 
 func (c *Core) setupRaftActiveNode(ctx context.Context) error {
 	c.pendingRaftPeers = make(map[string][]byte)
-	return c.startPeriodicRaftTLSRotate(ctx)
+	if err := c.startPeriodicRaftTLSRotate(ctx); err != nil {
+		return err
+	}
+	return c.startRaftSnapshotAutomation(ctx)
 }
 
 func (c *Core) stopRaftActiveNode() {
 	c.pendingRaftPeers = nil
 	c.stopPeriodicRaftTLSRotate()
+	c.stopRaftSnapshotAutomation()
 }
 
 // startPeriodicRaftTLSRotate will spawn a go routine in charge of periodically
@@ -548,7 +552,7 @@ func (c *Core) InitiateRetryJoin(ctx context.Context) error {
 
 	c.logger.Info("raft retry join initiated")
 
-	if _, err = c.JoinRaftCluster(ctx, leaderInfos, false); err != nil {
+	if _, err = c.JoinRaftCluster(ctx, leaderInfos, raftStorage.RetryJoinAsNonVoter()); err != nil {
 		return err
 	}
 
@@ -0,0 +1,464 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/errwrap"
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/physical/raft"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	raftSnapshotAutoConfigPath = "core/raft/snapshot-auto/config/"
+	raftSnapshotAutoStatusPath = "core/raft/snapshot-auto/status/"
+
+	raftSnapshotAutoDefaultFilePrefix = "vault-snapshot"
+	raftSnapshotAutoFileSuffix        = ".snap"
+)
+
+// RaftSnapshotTarget is a destination that automated raft snapshots are
+// written to. A local directory target is built in; object store targets can
+// be provided through CoreConfig.RaftSnapshotTargets.
+type RaftSnapshotTarget interface {
+	// Put stores the snapshot read from r under the given name.
+	Put(ctx context.Context, name string, r io.Reader) error
+
+	// List returns the names of stored snapshots that begin with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes the named snapshot.
+	Delete(ctx context.Context, name string) error
+}
+
+// RaftSnapshotTargetFactory creates a RaftSnapshotTarget. The path prefix and
+// free-form storage configuration come from the snapshot schedule config.
+type RaftSnapshotTargetFactory func(pathPrefix string, conf map[string]string, logger log.Logger) (RaftSnapshotTarget, error)
+
+var builtinRaftSnapshotTargets = map[string]RaftSnapshotTargetFactory{
+	"local": newLocalRaftSnapshotTarget,
+}
+
+// raftSnapshotAutoConfig is the stored configuration of a single automated
+// snapshot schedule.
+type raftSnapshotAutoConfig struct {
+	Name          string            `json:"name"`
+	Interval      time.Duration     `json:"interval"`
+	Retain        int               `json:"retain"`
+	StorageType   string            `json:"storage_type"`
+	PathPrefix    string            `json:"path_prefix"`
+	FilePrefix    string            `json:"file_prefix"`
+	StorageConfig map[string]string `json:"storage_config"`
+}
+
+// raftSnapshotAutoStatus records the outcome of the runs of a schedule.
+type raftSnapshotAutoStatus struct {
+	LastSnapshotStart    time.Time `json:"last_snapshot_start"`
+	LastSnapshotEnd      time.Time `json:"last_snapshot_end"`
+	LastSnapshotName     string    `json:"last_snapshot_name"`
+	LastSuccess          time.Time `json:"last_success"`
+	LastFailure          time.Time `json:"last_failure"`
+	LastError            string    `json:"last_error"`
+	ConsecutiveFailures  int       `json:"consecutive_failures"`
+	LastSnapshotSize     int64     `json:"last_snapshot_size"`
+	LastRetentionDeleted int       `json:"last_retention_deleted"`
+}
+
+// raftSnapshotSchedule is a running automated snapshot schedule.
+type raftSnapshotSchedule struct {
+	config *raftSnapshotAutoConfig
+	target RaftSnapshotTarget
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+func (c *Core) raftSnapshotTarget(config *raftSnapshotAutoConfig) (RaftSnapshotTarget, error) {
+	factory, ok := c.raftSnapshotTargets[config.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage type %q", config.StorageType)
+	}
+	return factory(config.PathPrefix, config.StorageConfig, c.logger.Named("raft.snapshot-auto"))
+}
+
+func (c *Core) readRaftSnapshotAutoConfig(ctx context.Context, name string) (*raftSnapshotAutoConfig, error) {
+	entry, err := c.barrier.Get(ctx, raftSnapshotAutoConfigPath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var config raftSnapshotAutoConfig
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *Core) readRaftSnapshotAutoStatus(ctx context.Context, name string) (*raftSnapshotAutoStatus, error) {
+	entry, err := c.barrier.Get(ctx, raftSnapshotAutoStatusPath+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var status raftSnapshotAutoStatus
+	if err := entry.DecodeJSON(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *Core) writeRaftSnapshotAutoStatus(ctx context.Context, name string, status *raftSnapshotAutoStatus) error {
+	entry, err := logical.StorageEntryJSON(raftSnapshotAutoStatusPath+name, status)
+	if err != nil {
+		return err
+	}
+	return c.barrier.Put(ctx, entry)
+}
+
+// startRaftSnapshotAutomation loads every stored snapshot schedule and starts
+// running it. It is only called on the active node.
+func (c *Core) startRaftSnapshotAutomation(ctx context.Context) error {
+	if _, ok := c.underlyingPhysical.(*raft.RaftBackend); !ok {
+		return nil
+	}
+
+	names, err := c.barrier.List(ctx, raftSnapshotAutoConfigPath)
+	if err != nil {
+		return err
+	}
+
+	c.raftSnapshotAutoLock.Lock()
+	defer c.raftSnapshotAutoLock.Unlock()
+
+	c.raftSnapshotAutoCtx = ctx
+	c.raftSnapshotSchedules = make(map[string]*raftSnapshotSchedule)
+	for _, name := range names {
+		config, err := c.readRaftSnapshotAutoConfig(ctx, name)
+		if err != nil {
+			return err
+		}
+		if config == nil {
+			continue
+		}
+		if err := c.startRaftSnapshotScheduleLocked(ctx, config); err != nil {
+			// A misconfigured schedule must not prevent the node from
+			// becoming active; the error is recorded in its status instead.
+			c.logger.Error("failed to start automated raft snapshots", "name", name, "error", err)
+			status, _ := c.readRaftSnapshotAutoStatus(ctx, name)
+			if status == nil {
+				status = new(raftSnapshotAutoStatus)
+			}
+			status.LastFailure = time.Now()
+			status.LastError = err.Error()
+			status.ConsecutiveFailures++
+			if err := c.writeRaftSnapshotAutoStatus(ctx, name, status); err != nil {
+				c.logger.Error("failed to write automated raft snapshot status", "name", name, "error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// stopRaftSnapshotAutomation stops all running snapshot schedules and waits
+// for any in-progress snapshot to finish.
+func (c *Core) stopRaftSnapshotAutomation() {
+	c.raftSnapshotAutoLock.Lock()
+	defer c.raftSnapshotAutoLock.Unlock()
+
+	for name := range c.raftSnapshotSchedules {
+		c.stopRaftSnapshotScheduleLocked(name)
+	}
+	c.raftSnapshotSchedules = nil
+	c.raftSnapshotAutoCtx = nil
+}
+
+// restartRaftSnapshotSchedule replaces the running schedule with the given
+// name. A nil config only stops it. The schedule runs under the context the
+// automation was started with rather than the caller's request context.
+func (c *Core) restartRaftSnapshotSchedule(name string, config *raftSnapshotAutoConfig) error {
+	c.raftSnapshotAutoLock.Lock()
+	defer c.raftSnapshotAutoLock.Unlock()
+
+	// Schedules are only run on the active node
+	if c.raftSnapshotSchedules == nil {
+		return nil
+	}
+
+	c.stopRaftSnapshotScheduleLocked(name)
+	if config == nil {
+		return nil
+	}
+
+	return c.startRaftSnapshotScheduleLocked(c.raftSnapshotAutoCtx, config)
+}
+
+func (c *Core) stopRaftSnapshotScheduleLocked(name string) {
+	schedule, ok := c.raftSnapshotSchedules[name]
+	if !ok {
+		return
+	}
+	close(schedule.stopCh)
+	<-schedule.doneCh
+	delete(c.raftSnapshotSchedules, name)
+}
+
+func (c *Core) startRaftSnapshotScheduleLocked(ctx context.Context, config *raftSnapshotAutoConfig) error {
+	target, err := c.raftSnapshotTarget(config)
+	if err != nil {
+		return err
+	}
+
+	status, err := c.readRaftSnapshotAutoStatus(ctx, config.Name)
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		status = new(raftSnapshotAutoStatus)
+	}
+
+	schedule := &raftSnapshotSchedule{
+		config: config,
+		target: target,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	c.raftSnapshotSchedules[config.Name] = schedule
+
+	// Pick up where the previous active node left off so a leadership change
+	// neither skips nor doubles up a snapshot.
+	var wait time.Duration
+	if !status.LastSnapshotStart.IsZero() {
+		wait = time.Until(status.LastSnapshotStart.Add(config.Interval))
+		if wait < 0 {
+			wait = 0
+		}
+	} else {
+		wait = config.Interval
+	}
+
+	logger := c.logger.Named("raft.snapshot-auto").With("name", config.Name)
+	go func() {
+		defer close(schedule.doneCh)
+
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-schedule.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			c.runRaftSnapshotSchedule(ctx, logger, schedule, status)
+			timer.Reset(config.Interval)
+		}
+	}()
+
+	return nil
+}
+
+// runRaftSnapshotSchedule takes a single snapshot for the schedule, stores it
+// on the target, applies the retention policy and records the outcome.
+func (c *Core) runRaftSnapshotSchedule(ctx context.Context, logger log.Logger, schedule *raftSnapshotSchedule, status *raftSnapshotAutoStatus) {
+	config := schedule.config
+
+	status.LastSnapshotStart = time.Now()
+	name, size, deleted, err := c.takeRaftSnapshot(ctx, config, schedule.target)
+	status.LastSnapshotEnd = time.Now()
+	status.LastSnapshotName = name
+	status.LastSnapshotSize = size
+	status.LastRetentionDeleted = deleted
+
+	if err != nil {
+		logger.Error("automated raft snapshot failed", "error", err)
+		status.LastFailure = status.LastSnapshotEnd
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+	} else {
+		logger.Debug("automated raft snapshot completed", "snapshot", name, "size", size)
+		status.LastSuccess = status.LastSnapshotEnd
+		status.ConsecutiveFailures = 0
+	}
+
+	if err := c.writeRaftSnapshotAutoStatus(ctx, config.Name, status); err != nil {
+		logger.Error("failed to write automated raft snapshot status", "error", err)
+	}
+}
+
+func (c *Core) takeRaftSnapshot(ctx context.Context, config *raftSnapshotAutoConfig, target RaftSnapshotTarget) (string, int64, int, error) {
+	raftStorage, ok := c.underlyingPhysical.(*raft.RaftBackend)
+	if !ok {
+		return "", 0, 0, errors.New("raft storage is not in use")
+	}
+
+	// Buffer the snapshot on disk so the target can read it at its own pace
+	// without holding the raft backend lock.
+	tmp, err := ioutil.TempFile("", "vault-raft-snapshot-auto")
+	if err != nil {
+		return "", 0, 0, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	if err := raftStorage.SnapshotToWriter(tmp, c.seal.GetAccess()); err != nil {
+		return "", 0, 0, errwrap.Wrapf("failed to take snapshot: {{err}}", err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", 0, 0, err
+	}
+
+	name := fmt.Sprintf("%s-%d%s", config.FilePrefix, time.Now().UTC().UnixNano(), raftSnapshotAutoFileSuffix)
+	if err := target.Put(ctx, name, tmp); err != nil {
+		return name, size, 0, errwrap.Wrapf("failed to store snapshot: {{err}}", err)
+	}
+
+	deleted, err := pruneRaftSnapshots(ctx, target, config.FilePrefix, config.Retain)
+	if err != nil {
+		return name, size, deleted, errwrap.Wrapf("failed to apply retention: {{err}}", err)
+	}
+
+	return name, size, deleted, nil
+}
+
+// pruneRaftSnapshots deletes the oldest snapshots of the schedule with the
+// given file prefix until at most retain remain. Only names made of the
+// prefix, a dash and a timestamp are considered, so that the snapshots of a
+// schedule whose prefix starts with this one are left alone.
+func pruneRaftSnapshots(ctx context.Context, target RaftSnapshotTarget, filePrefix string, retain int) (int, error) {
+	prefix := filePrefix + "-"
+	names, err := target.List(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	type snapshot struct {
+		name    string
+		created int64
+	}
+	var snaps []snapshot
+	for _, name := range names {
+		if !strings.HasSuffix(name, raftSnapshotAutoFileSuffix) {
+			continue
+		}
+		created, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, prefix), raftSnapshotAutoFileSuffix), 10, 64)
+		if err != nil || created < 0 {
+			continue
+		}
+		snaps = append(snaps, snapshot{name: name, created: created})
+	}
+	if len(snaps) <= retain {
+		return 0, nil
+	}
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].created < snaps[j].created
+	})
+
+	var deleted int
+	for _, snap := range snaps[:len(snaps)-retain] {
+		if err := target.Delete(ctx, snap.name); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// localRaftSnapshotTarget stores snapshots in a directory on the local
+// filesystem of the active node.
+type localRaftSnapshotTarget struct {
+	dir string
+}
+
+func newLocalRaftSnapshotTarget(pathPrefix string, _ map[string]string, _ log.Logger) (RaftSnapshotTarget, error) {
+	if pathPrefix == "" {
+		return nil, errors.New("path_prefix is required for local storage")
+	}
+	if !filepath.IsAbs(pathPrefix) {
+		return nil, errors.New("path_prefix must be an absolute path for local storage")
+	}
+	return &localRaftSnapshotTarget{
+		dir: pathPrefix,
+	}, nil
+}
+
+func (l *localRaftSnapshotTarget) Put(_ context.Context, name string, r io.Reader) error {
+	if err := os.MkdirAll(l.dir, 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary name first so a partial snapshot is never picked
+	// up as a valid one.
+	tmpPath := filepath.Join(l.dir, name+".tmp")
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(l.dir, name))
+}
+
+func (l *localRaftSnapshotTarget) List(_ context.Context, prefix string) ([]string, error) {
+	infos, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasPrefix(info.Name(), prefix) {
+			continue
+		}
+		names = append(names, info.Name())
+	}
+	return names, nil
+}
+
+func (l *localRaftSnapshotTarget) Delete(_ context.Context, name string) error {
+	err := os.Remove(filepath.Join(l.dir, name))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
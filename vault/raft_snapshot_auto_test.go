@@ -0,0 +1,58 @@
+package vault
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPruneRaftSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vault-raft-snapshot-auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	target, err := newLocalRaftSnapshotTarget(dir, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{
+		"a-100.snap",
+		"a-99.snap",
+		"a-101.snap",
+		"a-b-1.snap",
+		"a-b-2.snap",
+		"a-102.snap.tmp",
+		"a-notes.txt",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("snapshot"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := pruneRaftSnapshots(context.Background(), target, "a", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 snapshots to be deleted, got %d", deleted)
+	}
+
+	// The snapshots of schedule "a-b" and files which aren't snapshots are
+	// left alone
+	names, err := target.List(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	expected := []string{"a-101.snap", "a-102.snap.tmp", "a-b-1.snap", "a-b-2.snap", "a-notes.txt"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+}
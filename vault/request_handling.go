@@ -2,6 +2,9 @@ package vault
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"strings"
@@ -172,6 +175,15 @@ func (c *Core) fetchACLTokenEntryAndEntity(ctx context.Context, req *logical.Req
 		}
 	}
 
+	// Tokens bound to a client certificate may only be used over connections
+	// presenting that certificate
+	if te.BoundCertFingerprint != "" {
+		fingerprint := clientCertFingerprint(req)
+		if subtle.ConstantTimeCompare([]byte(fingerprint), []byte(te.BoundCertFingerprint)) != 1 {
+			return nil, nil, nil, nil, logical.ErrPermissionDenied
+		}
+	}
+
 	policies := make(map[string][]string)
 	// Add tokens policies
 	policies[te.NamespaceID] = append(policies[te.NamespaceID], te.Policies...)
@@ -609,6 +621,9 @@ func (c *Core) handleRequest(ctx context.Context, req *logical.Request) (retResp
 			retErr = multierror.Append(retErr, logical.ErrPermissionDenied)
 			return nil, nil, retErr
 		}
+		if err := c.tokenStore.RecordTokenUse(ctx, te); err != nil {
+			c.logger.Warn("failed to record token use", "error", err)
+		}
 		if te.NumUses == tokenRevocationPending {
 			// We defer a revocation until after logic has run, since this is a
 			// valid request (this is the token's final use). We pass the ID in
@@ -1132,6 +1147,16 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 			}
 		}
 
+		// The fingerprint is always taken from the login connection rather
+		// than trusted from the backend
+		auth.BoundCertFingerprint = ""
+		if auth.BindClientCert {
+			auth.BoundCertFingerprint = clientCertFingerprint(req)
+			if auth.BoundCertFingerprint == "" {
+				return logical.ErrorResponse("binding the token to a client certificate requires presenting one at login"), nil, logical.ErrInvalidRequest
+			}
+		}
+
 		var registerFunc RegisterAuthFunc
 		var funcGetErr error
 		// Batch tokens should not be forwarded to perf standby
@@ -1167,6 +1192,17 @@ func (c *Core) handleLoginRequest(ctx context.Context, req *logical.Request) (re
 	return resp, auth, routeErr
 }
 
+// clientCertFingerprint returns the fingerprint of the TLS client certificate
+// presented with the request, or an empty string if there is none. As in RFC
+// 8705, it is the base64url-encoded SHA-256 hash of the DER certificate.
+func clientCertFingerprint(req *logical.Request) string {
+	if req.Connection == nil || req.Connection.ConnState == nil || len(req.Connection.ConnState.PeerCertificates) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(req.Connection.ConnState.PeerCertificates[0].Raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 // RegisterAuth uses a logical.Auth object to create a token entry in the token
 // store, and registers a corresponding token lease to the expiration manager.
 func (c *Core) RegisterAuth(ctx context.Context, tokenTTL time.Duration, path string, auth *logical.Auth) error {
@@ -1194,6 +1230,10 @@ func (c *Core) RegisterAuth(ctx context.Context, tokenTTL time.Duration, path st
 		NamespaceID:    ns.ID,
 		ExplicitMaxTTL: auth.ExplicitMaxTTL,
 		Type:           auth.TokenType,
+
+		BoundCertFingerprint: auth.BoundCertFingerprint,
+		MFAValidated:         auth.MFAValidated,
+		LoginMeta:            auth.Metadata,
 	}
 
 	if te.TTL == 0 && (len(te.Policies) != 1 || te.Policies[0] != "root") {
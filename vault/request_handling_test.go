@@ -1,10 +1,14 @@
 package vault
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
 	"testing"
 	"time"
 
 	uuid "github.com/hashicorp/go-uuid"
+	credAppRole "github.com/hashicorp/vault/builtin/credential/approle"
 	credUserpass "github.com/hashicorp/vault/builtin/credential/userpass"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/logical"
@@ -144,3 +148,131 @@ func TestRequestHandling_LoginWrapping(t *testing.T) {
 		t.Fatalf("bad: %#v", resp)
 	}
 }
+
+func TestRequestHandling_BindClientCert(t *testing.T) {
+	core, _, root := TestCoreUnsealed(t)
+
+	if err := core.loadMounts(namespace.RootContext(nil)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	core.credentialBackends["approle"] = credAppRole.Factory
+
+	req := &logical.Request{
+		Path:        "sys/auth/approle",
+		ClientToken: root,
+		Operation:   logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"type": "approle",
+		},
+		Connection: &logical.Connection{},
+	}
+	resp, err := core.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	req.Path = "sys/policy/creator"
+	req.Data = map[string]interface{}{
+		"policy": `path "auth/token/create" { capabilities = ["update", "sudo"] }`,
+	}
+	resp, err = core.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	req.Path = "auth/approle/role/test"
+	req.Data = map[string]interface{}{
+		"bind_secret_id":         false,
+		"secret_id_bound_cidrs":  "127.0.0.1/32",
+		"token_bind_client_cert": true,
+		"token_policies":         "default,creator",
+	}
+	resp, err = core.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+
+	req.Path = "auth/approle/role/test/role-id"
+	req.Operation = logical.ReadOperation
+	req.Data = nil
+	resp, err = core.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	roleID := resp.Data["role_id"]
+
+	connection := func(raw string) *logical.Connection {
+		conn := &logical.Connection{RemoteAddr: "127.0.0.1"}
+		if raw != "" {
+			conn.ConnState = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{{Raw: []byte(raw)}},
+			}
+		}
+		return conn
+	}
+
+	// Logging in without a client certificate fails
+	req = &logical.Request{
+		Path:      "auth/approle/login",
+		Operation: logical.UpdateOperation,
+		Data: map[string]interface{}{
+			"role_id": roleID,
+		},
+		Connection: connection(""),
+	}
+	resp, err = core.HandleRequest(namespace.RootContext(nil), req)
+	if err == nil {
+		t.Fatalf("expected error, got resp: %#v", resp)
+	}
+
+	req.Connection = connection("cert1")
+	resp, err = core.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp == nil || resp.Auth == nil {
+		t.Fatalf("err: %v, resp: %#v", err, resp)
+	}
+	token := resp.Auth.ClientToken
+
+	lookupSelf := func(token, cert string) error {
+		_, err := core.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Path:        "auth/token/lookup-self",
+			ClientToken: token,
+			Operation:   logical.ReadOperation,
+			Connection:  connection(cert),
+		})
+		return err
+	}
+
+	if err := lookupSelf(token, "cert1"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, cert := range []string{"", "cert2"} {
+		if err := lookupSelf(token, cert); err == nil || !strings.Contains(err.Error(), logical.ErrPermissionDenied.Error()) {
+			t.Fatalf("expected permission denied with certificate %q, got %v", cert, err)
+		}
+	}
+
+	// Child tokens, including orphan and batch tokens, are bound to the
+	// same certificate
+	for _, tokenType := range []string{"service", "batch"} {
+		resp, err = core.HandleRequest(namespace.RootContext(nil), &logical.Request{
+			Path:        "auth/token/create",
+			ClientToken: token,
+			Operation:   logical.UpdateOperation,
+			Data: map[string]interface{}{
+				"type":      tokenType,
+				"no_parent": true,
+			},
+			Connection: connection("cert1"),
+		})
+		if err != nil || resp == nil || resp.Auth == nil {
+			t.Fatalf("err: %v, resp: %#v", err, resp)
+		}
+		if err := lookupSelf(resp.Auth.ClientToken, "cert1"); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if err := lookupSelf(resp.Auth.ClientToken, "cert2"); err == nil || !strings.Contains(err.Error(), logical.ErrPermissionDenied.Error()) {
+			t.Fatalf("expected permission denied for %s token, got %v", tokenType, err)
+		}
+	}
+}
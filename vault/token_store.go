@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -74,6 +75,16 @@ var (
 	// pathSuffixSanitize is used to ensure a path suffix in a role is valid.
 	pathSuffixSanitize = regexp.MustCompile("\\w[\\w-.]+\\w")
 
+	// tokenLastUsedGranularity is how much later than its persisted last use
+	// a token must have been used for the new time to be written out. This
+	// bounds the writes to one per token per interval.
+	tokenLastUsedGranularity = 5 * time.Minute
+
+	// tokenLastUsedMaxWrites bounds how many token entries a single run of
+	// persistLastUsed updates; the remaining uses are written out by the
+	// following runs.
+	tokenLastUsedMaxWrites = 256
+
 	destroyCubbyhole = func(ctx context.Context, ts *TokenStore, te *logical.TokenEntry) error {
 		if ts.cubbyholeBackend == nil {
 			// Should only ever happen in testing
@@ -125,6 +136,65 @@ func (ts *TokenStore) paths() []*framework.Path {
 			HelpDescription: tokenListAccessorsHelp,
 		},
 
+		{
+			Pattern: "search/?$",
+
+			Fields: map[string]*framework.FieldSchema{
+				"policies": &framework.FieldSchema{
+					Type:        framework.TypeCommaStringSlice,
+					Description: "If set, only tokens having all of these policies are returned.",
+				},
+
+				"role": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "If set, only tokens created using this role are returned.",
+				},
+
+				"path_prefix": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: `If set, only tokens whose creation path starts with this prefix, such as "auth/github/", are returned.`,
+				},
+
+				"entity_id": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: "If set, only tokens tied to this entity are returned.",
+				},
+
+				"meta": &framework.FieldSchema{
+					Type:        framework.TypeKVPairs,
+					Description: "If set, only tokens having all of these metadata key/value pairs are returned.",
+				},
+
+				"min_ttl": &framework.FieldSchema{
+					Type:        framework.TypeDurationSecond,
+					Description: "If set, only tokens with at least this much time remaining before they expire are returned. Tokens that never expire match.",
+				},
+
+				"max_ttl": &framework.FieldSchema{
+					Type:        framework.TypeDurationSecond,
+					Description: "If set, only tokens expiring within this duration are returned. Tokens that never expire do not match.",
+				},
+
+				"limit": &framework.FieldSchema{
+					Type:        framework.TypeInt,
+					Default:     100,
+					Description: "Maximum number of tokens to return.",
+				},
+
+				"after": &framework.FieldSchema{
+					Type:        framework.TypeString,
+					Description: `Accessor after which to start returning tokens, taken from the "next" value of the previous page.`,
+				},
+			},
+
+			Callbacks: map[logical.Operation]framework.OperationFunc{
+				logical.UpdateOperation: ts.handleSearch,
+			},
+
+			HelpSynopsis:    strings.TrimSpace(tokenSearchHelp),
+			HelpDescription: strings.TrimSpace(tokenSearchDesc),
+		},
+
 		{
 			Pattern: "create-orphan$",
 
@@ -423,6 +493,11 @@ func (ts *TokenStore) paths() []*framework.Path {
 				Type:        framework.TypeCommaStringSlice,
 				Description: "String or JSON list of allowed entity aliases. If set, specifies the entity aliases which are allowed to be used during token generation. This field supports globbing.",
 			},
+
+			"max_idle_ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: tokenMaxIdleTTLHelp,
+			},
 		},
 
 		Callbacks: map[logical.Operation]framework.OperationFunc{
@@ -495,6 +570,13 @@ type TokenStore struct {
 	// failed. Revocation needs to handle these states accordingly.
 	tokensPendingDeletion *sync.Map
 
+	// lastUsed maps salted token IDs to the *tokenUse recording their latest
+	// use, until it is persisted with the token entry.
+	lastUsed *sync.Map
+
+	// index is used to search tokens
+	index *tokenIndex
+
 	cubbyholeDestroyer func(context.Context, *TokenStore, *logical.TokenEntry) error
 
 	logger log.Logger
@@ -529,6 +611,8 @@ func NewTokenStore(ctx context.Context, logger log.Logger, core *Core, config *l
 		logger:                logger,
 		tokenLocks:            locksutil.CreateLocks(),
 		tokensPendingDeletion: &sync.Map{},
+		lastUsed:              &sync.Map{},
+		index:                 newTokenIndex(),
 		saltLock:              sync.RWMutex{},
 		tidyLock:              new(uint32),
 		quitContext:           core.activeContext,
@@ -537,12 +621,14 @@ func NewTokenStore(ctx context.Context, logger log.Logger, core *Core, config *l
 
 	// Setup the framework endpoints
 	t.Backend = &framework.Backend{
-		AuthRenew: t.authRenew,
+		AuthRenew:    t.authRenew,
+		PeriodicFunc: t.persistLastUsed,
 
 		PathsSpecial: &logical.Paths{
 			Root: []string{
 				"revoke-orphan/*",
 				"accessors*",
+				"search*",
 			},
 
 			// Most token store items are local since tokens are local, but a
@@ -640,6 +726,20 @@ type tsRoleEntry struct {
 
 	// The set of allowed entity aliases used during token creation
 	AllowedEntityAliases []string `json:"allowed_entity_aliases" mapstructure:"allowed_entity_aliases" structs:"allowed_entity_aliases"`
+
+	// If non-zero, tokens created using this role are revoked once they have
+	// not been used for this long
+	MaxIdleTTL time.Duration `json:"max_idle_ttl" mapstructure:"max_idle_ttl" structs:"max_idle_ttl"`
+}
+
+// tokenUse records the latest use of a token
+type tokenUse struct {
+	namespace *namespace.Namespace
+	time      time.Time
+
+	// persisted is the last use stored with the token entry, so that uses
+	// which aren't due to be written out don't need a storage read
+	persisted time.Time
 }
 
 type accessorEntry struct {
@@ -734,6 +834,332 @@ func (ts *TokenStore) tokenStoreAccessorList(ctx context.Context, req *logical.R
 	return resp, nil
 }
 
+// tokenIndex is an in-memory index of the service tokens of each namespace,
+// used to search them. A namespace is indexed from storage the first time it
+// is searched, and kept up to date as tokens are created and revoked.
+type tokenIndex struct {
+	// loadLock serializes the loading of namespaces
+	loadLock sync.Mutex
+
+	l          sync.RWMutex
+	namespaces map[string]*tokenIndexNamespace
+}
+
+type tokenIndexNamespace struct {
+	loaded  bool
+	entries map[string]*tokenIndexEntry
+
+	// removed holds the accessors of the tokens revoked while loading, so
+	// that they are not indexed by the load
+	removed map[string]struct{}
+}
+
+// tokenIndexEntry holds the immutable properties of a token searches filter
+// on. It does not hold the token ID.
+type tokenIndexEntry struct {
+	accessor     string
+	policies     []string
+	path         string
+	role         string
+	entityID     string
+	meta         map[string]string
+	displayName  string
+	creationTime int64
+}
+
+// tokenQuery holds the criteria of a token search. The expiry window is
+// checked against the token's lease when searching.
+type tokenQuery struct {
+	policies   []string
+	role       string
+	pathPrefix string
+	entityID   string
+	meta       map[string]string
+}
+
+func newTokenIndex() *tokenIndex {
+	return &tokenIndex{
+		namespaces: make(map[string]*tokenIndexNamespace),
+	}
+}
+
+func newTokenIndexEntry(te *logical.TokenEntry) *tokenIndexEntry {
+	return &tokenIndexEntry{
+		accessor:     te.Accessor,
+		policies:     te.Policies,
+		path:         te.Path,
+		role:         te.Role,
+		entityID:     te.EntityID,
+		meta:         te.Meta,
+		displayName:  te.DisplayName,
+		creationTime: te.CreationTime,
+	}
+}
+
+// add indexes a created token, if its namespace is indexed.
+func (i *tokenIndex) add(te *logical.TokenEntry) {
+	if te.Accessor == "" {
+		return
+	}
+
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	n, ok := i.namespaces[te.NamespaceID]
+	if !ok {
+		return
+	}
+	n.entries[te.Accessor] = newTokenIndexEntry(te)
+}
+
+// remove removes a revoked token from the index.
+func (i *tokenIndex) remove(namespaceID, accessor string) {
+	if accessor == "" {
+		return
+	}
+
+	i.l.Lock()
+	defer i.l.Unlock()
+
+	n, ok := i.namespaces[namespaceID]
+	if !ok {
+		return
+	}
+	delete(n.entries, accessor)
+	if !n.loaded {
+		n.removed[accessor] = struct{}{}
+	}
+}
+
+// load indexes the tokens of the namespace from storage, unless already
+// done. Tokens created and revoked meanwhile are tracked as usual.
+func (i *tokenIndex) load(ctx context.Context, ts *TokenStore, ns *namespace.Namespace) (retErr error) {
+	i.loadLock.Lock()
+	defer i.loadLock.Unlock()
+
+	i.l.Lock()
+	n, ok := i.namespaces[ns.ID]
+	if ok && n.loaded {
+		i.l.Unlock()
+		return nil
+	}
+	n = &tokenIndexNamespace{
+		entries: make(map[string]*tokenIndexEntry),
+		removed: make(map[string]struct{}),
+	}
+	i.namespaces[ns.ID] = n
+	i.l.Unlock()
+
+	defer func() {
+		i.l.Lock()
+		defer i.l.Unlock()
+		if retErr != nil {
+			delete(i.namespaces, ns.ID)
+			return
+		}
+		n.loaded = true
+		n.removed = nil
+	}()
+
+	ts.logger.Info("indexing tokens", "namespace", ns.Path)
+
+	saltedAccessors, err := ts.accessorView(ns).List(ctx, "")
+	if err != nil {
+		return errwrap.Wrapf("failed to fetch accessor index entries: {{err}}", err)
+	}
+
+	for _, saltedAccessor := range saltedAccessors {
+		aEntry, err := ts.lookupByAccessor(ctx, saltedAccessor, true, false)
+		if err != nil {
+			// The token was revoked since the accessors were listed
+			if _, ok := err.(*logical.StatusBadRequest); ok {
+				continue
+			}
+			return errwrap.Wrapf("failed to read the accessor index: {{err}}", err)
+		}
+		if aEntry.TokenID == "" || aEntry.NamespaceID != ns.ID {
+			continue
+		}
+
+		te, err := ts.lookupInternal(ctx, aEntry.TokenID, false, true)
+		if err != nil {
+			return errwrap.Wrapf("failed to look up token: {{err}}", err)
+		}
+		if te == nil || te.NumUses < 0 {
+			continue
+		}
+
+		i.l.Lock()
+		_, removed := n.removed[te.Accessor]
+		_, exists := n.entries[te.Accessor]
+		if !removed && !exists {
+			n.entries[te.Accessor] = newTokenIndexEntry(te)
+		}
+		i.l.Unlock()
+	}
+
+	ts.logger.Info("finished indexing tokens", "namespace", ns.Path, "accessors", len(saltedAccessors))
+
+	return nil
+}
+
+// search returns the entries of the namespace matching the query, sorted by
+// accessor, starting after the given accessor.
+func (i *tokenIndex) search(namespaceID string, q *tokenQuery, after string) []*tokenIndexEntry {
+	i.l.RLock()
+	defer i.l.RUnlock()
+
+	n, ok := i.namespaces[namespaceID]
+	if !ok {
+		return nil
+	}
+
+	var ret []*tokenIndexEntry
+	for accessor, entry := range n.entries {
+		if accessor <= after || !q.matches(entry) {
+			continue
+		}
+		ret = append(ret, entry)
+	}
+
+	sort.Slice(ret, func(a, b int) bool {
+		return ret[a].accessor < ret[b].accessor
+	})
+	return ret
+}
+
+func (q *tokenQuery) matches(entry *tokenIndexEntry) bool {
+	switch {
+	case q.role != "" && entry.role != q.role:
+		return false
+	case q.entityID != "" && entry.entityID != q.entityID:
+		return false
+	case q.pathPrefix != "" && !strings.HasPrefix(entry.path, q.pathPrefix):
+		return false
+	}
+
+	for _, policy := range q.policies {
+		if !strutil.StrListContains(entry.policies, policy) {
+			return false
+		}
+	}
+
+	for k, v := range q.meta {
+		if value, ok := entry.meta[k]; !ok || value != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// handleSearch handles the auth/token/search path for searching tokens
+func (ts *TokenStore) handleSearch(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	ns, err := namespace.FromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := data.Get("limit").(int)
+	if limit <= 0 {
+		return logical.ErrorResponse("'limit' must be positive"), logical.ErrInvalidRequest
+	}
+
+	minTTL := time.Duration(data.Get("min_ttl").(int)) * time.Second
+	maxTTL := time.Duration(data.Get("max_ttl").(int)) * time.Second
+	if maxTTL != 0 && minTTL > maxTTL {
+		return logical.ErrorResponse("'min_ttl' cannot be greater than 'max_ttl'"), logical.ErrInvalidRequest
+	}
+
+	query := &tokenQuery{
+		policies:   data.Get("policies").([]string),
+		role:       data.Get("role").(string),
+		pathPrefix: data.Get("path_prefix").(string),
+		entityID:   data.Get("entity_id").(string),
+		meta:       data.Get("meta").(map[string]string),
+	}
+
+	if err := ts.index.load(ctx, ts, ns); err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	keyInfo := make(map[string]interface{})
+	var next string
+	for _, entry := range ts.index.search(ns.ID, query, data.Get("after").(string)) {
+		// The expiry window is checked against the current lease, which also
+		// filters out tokens revoked since they were found
+		aEntry, err := ts.lookupByAccessor(ctx, entry.accessor, false, false)
+		if err != nil {
+			if _, ok := err.(*logical.StatusBadRequest); ok {
+				continue
+			}
+			return nil, err
+		}
+		if aEntry.TokenID == "" {
+			continue
+		}
+		te, err := ts.lookupInternal(ctx, aEntry.TokenID, false, true)
+		if err != nil {
+			return nil, err
+		}
+		if te == nil || te.NumUses < 0 {
+			continue
+		}
+
+		var expireTime time.Time
+		leaseTimes, err := ts.expiration.FetchLeaseTimesByToken(ctx, te)
+		if err != nil {
+			return nil, err
+		}
+		if leaseTimes != nil {
+			expireTime = leaseTimes.ExpireTime
+		}
+
+		switch {
+		case !expireTime.IsZero() && !expireTime.After(time.Now()):
+			// Expired, and being revoked
+			continue
+		case expireTime.IsZero() && maxTTL != 0:
+			continue
+		case !expireTime.IsZero() && minTTL != 0 && time.Until(expireTime) < minTTL:
+			continue
+		case !expireTime.IsZero() && maxTTL != 0 && time.Until(expireTime) > maxTTL:
+			continue
+		}
+
+		if len(keys) == limit {
+			next = keys[len(keys)-1]
+			break
+		}
+
+		info := map[string]interface{}{
+			"policies":      entry.policies,
+			"path":          entry.path,
+			"display_name":  entry.displayName,
+			"entity_id":     entry.entityID,
+			"meta":          entry.meta,
+			"creation_time": entry.creationTime,
+			"expire_time":   nil,
+		}
+		if entry.role != "" {
+			info["role"] = entry.role
+		}
+		if !expireTime.IsZero() {
+			info["expire_time"] = expireTime
+		}
+
+		keys = append(keys, entry.accessor)
+		keyInfo[entry.accessor] = info
+	}
+
+	resp := logical.ListResponseWithInfo(keys, keyInfo)
+	if next != "" {
+		resp.Data["next"] = next
+	}
+	return resp, nil
+}
+
 // createAccessor is used to create an identifier for the token ID.
 // A storage index, mapping the accessor to the token ID is also created.
 func (ts *TokenStore) createAccessor(ctx context.Context, entry *logical.TokenEntry) error {
@@ -855,7 +1281,12 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 			return err
 		}
 
-		return ts.storeCommon(ctx, entry, true)
+		if err := ts.storeCommon(ctx, entry, true); err != nil {
+			return err
+		}
+
+		ts.index.add(entry)
+		return nil
 
 	case logical.TokenTypeBatch:
 		// Ensure fields we don't support/care about are nilled, proto marshal,
@@ -873,6 +1304,10 @@ func (ts *TokenStore) create(ctx context.Context, entry *logical.TokenEntry) err
 			EntityID:     entry.EntityID,
 			NamespaceID:  entry.NamespaceID,
 			Type:         uint32(entry.Type),
+
+			BoundCertFingerprint: entry.BoundCertFingerprint,
+			LoginMeta:            entry.LoginMeta,
+			MFAValidated:         entry.MFAValidated,
 		}
 
 		boundCIDRs := make([]string, len(entry.BoundCIDRs))
@@ -1059,6 +1494,154 @@ func (ts *TokenStore) UseTokenByID(ctx context.Context, id string) (*logical.Tok
 	return ts.UseToken(ctx, te)
 }
 
+// RecordTokenUse notes that the token was used. The time is kept in memory,
+// and persisted with the token entry by persistLastUsed.
+func (ts *TokenStore) RecordTokenUse(ctx context.Context, te *logical.TokenEntry) error {
+	// Batch tokens are not persisted, so their use is not tracked
+	if te == nil || te.Type == logical.TokenTypeBatch {
+		return nil
+	}
+
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, ts.core)
+	if err != nil {
+		return err
+	}
+	if tokenNS == nil {
+		return namespace.ErrNoNamespace
+	}
+
+	saltedID, err := ts.SaltID(namespace.ContextWithNamespace(ctx, tokenNS), te.ID)
+	if err != nil {
+		return err
+	}
+
+	use := &tokenUse{
+		namespace: tokenNS,
+		time:      time.Now(),
+	}
+	if te.LastUsedTime != 0 {
+		use.persisted = time.Unix(te.LastUsedTime, 0)
+	}
+	ts.lastUsed.Store(saltedID, use)
+	return nil
+}
+
+// lastUsedTime returns the time the token was last used, whether persisted
+// or not, or the zero time if it never was.
+func (ts *TokenStore) lastUsedTime(saltedID string, te *logical.TokenEntry) time.Time {
+	var lastUsed time.Time
+	if te.LastUsedTime != 0 {
+		lastUsed = time.Unix(te.LastUsedTime, 0)
+	}
+	if raw, ok := ts.lastUsed.Load(saltedID); ok {
+		if use := raw.(*tokenUse); use.time.After(lastUsed) {
+			lastUsed = use.time
+		}
+	}
+	return lastUsed
+}
+
+// idle returns whether the token has not been used, or if never used since
+// its creation, for longer than its maximum idle TTL.
+func (ts *TokenStore) idle(saltedID string, te *logical.TokenEntry) bool {
+	if te.MaxIdleTTL == 0 {
+		return false
+	}
+
+	lastUsed := ts.lastUsedTime(saltedID, te)
+	if lastUsed.IsZero() {
+		lastUsed = time.Unix(te.CreationTime, 0)
+	}
+	return time.Since(lastUsed) > te.MaxIdleTTL
+}
+
+// expireIdle expires the lease of an idle token, leaving its revocation to
+// the expiration manager.
+func (ts *TokenStore) expireIdle(ctx context.Context, te *logical.TokenEntry) error {
+	tokenNS, err := NamespaceByID(ctx, te.NamespaceID, ts.core)
+	if err != nil {
+		return err
+	}
+	if tokenNS == nil {
+		return namespace.ErrNoNamespace
+	}
+
+	revokeCtx := namespace.ContextWithNamespace(ts.quitContext, tokenNS)
+	leaseID, err := ts.expiration.CreateOrFetchRevocationLeaseByToken(revokeCtx, te)
+	if err != nil {
+		return err
+	}
+
+	ts.logger.Info("revoking idle token", "path", te.Path, "max_idle_ttl", te.MaxIdleTTL)
+	return ts.expiration.LazyRevoke(revokeCtx, leaseID)
+}
+
+// persistLastUsed writes out the recorded uses of tokens. To limit writes, a
+// token entry is only updated once its use is more recent than the persisted
+// one by tokenLastUsedGranularity; until then, the use is kept in memory
+// without touching storage. At most tokenLastUsedMaxWrites entries are
+// updated per run.
+func (ts *TokenStore) persistLastUsed(ctx context.Context, req *logical.Request) error {
+	var retErr *multierror.Error
+	writes := 0
+	ts.lastUsed.Range(func(key, value interface{}) bool {
+		use := value.(*tokenUse)
+		if use.time.Sub(use.persisted) < tokenLastUsedGranularity {
+			return true
+		}
+		if err := ts.persistTokenUse(ctx, key.(string), use); err != nil {
+			retErr = multierror.Append(retErr, errwrap.Wrapf("failed to persist token last use: {{err}}", err))
+		}
+		writes++
+		return writes < tokenLastUsedMaxWrites
+	})
+	return retErr.ErrorOrNil()
+}
+
+func (ts *TokenStore) persistTokenUse(ctx context.Context, saltedID string, use *tokenUse) error {
+	ctx = namespace.ContextWithNamespace(ctx, use.namespace)
+
+	te, err := ts.lookupInternal(ctx, saltedID, true, true)
+	if err != nil {
+		return err
+	}
+	if te == nil {
+		// The token has been revoked
+		ts.lastUsed.Delete(saltedID)
+		return nil
+	}
+
+	lock := locksutil.LockForKey(ts.tokenLocks, te.ID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Refresh the entry under the lock, as it may have been updated since
+	te, err = ts.lookupInternal(ctx, saltedID, true, true)
+	if err != nil {
+		return err
+	}
+	if te == nil {
+		ts.lastUsed.Delete(saltedID)
+		return nil
+	}
+	if use.time.Sub(time.Unix(te.LastUsedTime, 0)) < tokenLastUsedGranularity {
+		// The entry was updated since the use was recorded
+		use.persisted = time.Unix(te.LastUsedTime, 0)
+		return nil
+	}
+
+	te.LastUsedTime = use.time.Unix()
+	if err := ts.store(ctx, te); err != nil {
+		return err
+	}
+
+	// Keep the record if the token was used again in the meantime
+	if raw, ok := ts.lastUsed.Load(saltedID); ok && raw.(*tokenUse) == use {
+		ts.lastUsed.Delete(saltedID)
+	}
+	return nil
+}
+
 // Lookup is used to find a token given its ID. It acquires a read lock, then calls lookupInternal.
 func (ts *TokenStore) Lookup(ctx context.Context, id string) (*logical.TokenEntry, error) {
 	defer metrics.MeasureSince([]string{"token", "lookup"}, time.Now())
@@ -1246,6 +1829,15 @@ func (ts *TokenStore) lookupInternal(ctx context.Context, id string, salted, tai
 		persistNeeded = true
 	}
 
+	// Tokens that have not been used within their maximum idle TTL are no
+	// longer valid, and are revoked
+	if !tainted && ts.idle(lookupID, entry) {
+		if err := ts.expireIdle(ctx, entry); err != nil {
+			return nil, errwrap.Wrapf("failed to revoke idle token: {{err}}", err)
+		}
+		return nil, nil
+	}
+
 	// It's a root token with unlimited creation TTL (so never had an
 	// expiration); this may or may not have a lease (based on when it was
 	// generated, for later revocation purposes) but it doesn't matter, it's
@@ -1384,6 +1976,10 @@ func (ts *TokenStore) revokeInternal(ctx context.Context, saltedID string, skipO
 				ret = errwrap.Wrapf("failed to delete entry: {{err}}", err)
 			}
 		}
+		if ret == nil {
+			ts.index.remove(tokenNS.ID, entry.Accessor)
+			ts.lastUsed.Delete(saltedID)
+		}
 
 		// Check on ret again and update the sync.Map accordingly
 		if ret != nil {
@@ -1865,7 +2461,8 @@ func (ts *TokenStore) handleTidy(ctx context.Context, req *logical.Request, data
 				deletedCountAccessorEmptyToken,
 				deletedCountAccessorInvalidToken,
 				deletedCountInvalidTokenInAccessor,
-				deletedCountInvalidCubbyholeKey int64
+				deletedCountInvalidCubbyholeKey,
+				revokedCountIdleToken int64
 
 			validCubbyholeKeys := make(map[string]bool)
 
@@ -1912,6 +2509,24 @@ func (ts *TokenStore) handleTidy(ctx context.Context, req *logical.Request, data
 
 				lock.RUnlock()
 
+				// Tokens that have not been used within their maximum idle TTL
+				// are otherwise only revoked when presented again
+				if te != nil && te.MaxIdleTTL != 0 && te.NumUses >= 0 {
+					saltedID, err := ts.SaltID(quitCtx, te.ID)
+					if err != nil {
+						tidyErrors = multierror.Append(tidyErrors, errwrap.Wrapf("failed to create salted token id: {{err}}", err))
+						continue
+					}
+					if ts.idle(saltedID, te) {
+						if err := ts.expireIdle(quitCtx, te); err != nil {
+							tidyErrors = multierror.Append(tidyErrors, errwrap.Wrapf("failed to revoke idle token: {{err}}", err))
+							continue
+						}
+						revokedCountIdleToken++
+						continue
+					}
+				}
+
 				switch {
 				case te == nil:
 					// If token entry is not found assume that the token is not valid any
@@ -1994,6 +2609,7 @@ func (ts *TokenStore) handleTidy(ctx context.Context, req *logical.Request, data
 			ts.logger.Info("number of revoked tokens which were invalid but present in accessors", "count", deletedCountInvalidTokenInAccessor)
 			ts.logger.Info("number of deleted accessors which had invalid tokens", "count", deletedCountAccessorInvalidToken)
 			ts.logger.Info("number of deleted cubbyhole keys that were invalid", "count", deletedCountInvalidCubbyholeKey)
+			ts.logger.Info("number of revoked tokens which were idle", "count", revokedCountIdleToken)
 
 			return tidyErrors.ErrorOrNil()
 		}
@@ -2553,6 +3169,11 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 			te.BoundCIDRs = role.TokenBoundCIDRs
 		}
 
+		// Batch tokens are not persisted, so their use cannot be tracked
+		if te.Type != logical.TokenTypeBatch {
+			te.MaxIdleTTL = role.MaxIdleTTL
+		}
+
 	case data.NoParent:
 		// Only allow an orphan token if the client has sudo policy
 		if !isSudo {
@@ -2589,6 +3210,19 @@ func (ts *TokenStore) handleCreateCommon(ctx context.Context, req *logical.Reque
 		}
 	}
 
+	// Tokens created by a token bound to a client certificate are bound to
+	// the same certificate, orphans included, so that the binding cannot be
+	// shed by creating a new token.
+	te.BoundCertFingerprint = parent.BoundCertFingerprint
+
+	// Tokens created by a token issued with a validated second factor are
+	// considered validated as well
+	te.MFAValidated = parent.MFAValidated
+
+	// The metadata of the login the parent comes from is carried over as is;
+	// the meta supplied by the caller can't satisfy policy conditions.
+	te.LoginMeta = parent.LoginMeta
+
 	var explicitMaxTTLToUse time.Duration
 	if data.ExplicitMaxTTL != "" {
 		dur, err := parseutil.ParseDurationSecond(data.ExplicitMaxTTL)
@@ -2909,6 +3543,17 @@ func (ts *TokenStore) handleLookup(ctx context.Context, req *logical.Request, da
 		resp.Data["bound_cidrs"] = out.BoundCIDRs
 	}
 
+	if out.BoundCertFingerprint != "" {
+		resp.Data["bound_cert_fingerprint"] = out.BoundCertFingerprint
+	}
+	if out.MFAValidated {
+		resp.Data["mfa_validated"] = true
+	}
+
+	if out.MaxIdleTTL != 0 {
+		resp.Data["max_idle_ttl"] = int64(out.MaxIdleTTL.Seconds())
+	}
+
 	tokenNS, err := NamespaceByID(ctx, out.NamespaceID, ts.core)
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
@@ -2921,6 +3566,16 @@ func (ts *TokenStore) handleLookup(ctx context.Context, req *logical.Request, da
 		resp.Data["namespace_path"] = tokenNS.Path
 	}
 
+	if out.Type != logical.TokenTypeBatch {
+		saltedID, err := ts.SaltID(namespace.ContextWithNamespace(ctx, tokenNS), out.ID)
+		if err != nil {
+			return nil, err
+		}
+		if lastUsed := ts.lastUsedTime(saltedID, out); !lastUsed.IsZero() {
+			resp.Data["last_used_time"] = lastUsed.Unix()
+		}
+	}
+
 	// Fetch the last renewal time
 	leaseTimes, err := ts.expiration.FetchLeaseTimesByToken(ctx, out)
 	if err != nil {
@@ -3123,6 +3778,10 @@ func (ts *TokenStore) tokenStoreRoleRead(ctx context.Context, req *logical.Reque
 		},
 	}
 
+	if role.MaxIdleTTL > 0 {
+		resp.Data["max_idle_ttl"] = int64(role.MaxIdleTTL.Seconds())
+	}
+
 	if len(role.TokenBoundCIDRs) > 0 {
 		resp.Data["token_bound_cidrs"] = role.TokenBoundCIDRs
 	}
@@ -3339,6 +3998,25 @@ func (ts *TokenStore) tokenStoreRoleCreateUpdate(ctx context.Context, req *logic
 		entry.TokenNumUses = tokenNumUses.(int)
 	}
 
+	maxIdleTTLRaw, ok := data.GetOk("max_idle_ttl")
+	if ok {
+		entry.MaxIdleTTL = time.Second * time.Duration(maxIdleTTLRaw.(int))
+	}
+	if entry.MaxIdleTTL < 0 {
+		return logical.ErrorResponse("'max_idle_ttl' cannot be negative"), nil
+	}
+	if entry.MaxIdleTTL != 0 && entry.MaxIdleTTL < rollbackPeriod {
+		// Token uses are written out periodically, so idleness can't be
+		// tracked more finely than the period
+		return logical.ErrorResponse(fmt.Sprintf("'max_idle_ttl' must be at least %s", rollbackPeriod)), nil
+	}
+	if entry.MaxIdleTTL != 0 && entry.MaxIdleTTL < tokenLastUsedGranularity {
+		if resp == nil {
+			resp = &logical.Response{}
+		}
+		resp.AddWarning(fmt.Sprintf("The last use of tokens is persisted every %s; tokens may be revoked as idle after a restart sooner than the given max idle TTL", tokenLastUsedGranularity))
+	}
+
 	// Run validity checks on token type
 	if entry.TokenType == logical.TokenTypeBatch {
 		if !entry.Orphan {
@@ -3353,6 +4031,9 @@ func (ts *TokenStore) tokenStoreRoleCreateUpdate(ctx context.Context, req *logic
 		if entry.ExplicitMaxTTL != 0 || entry.TokenExplicitMaxTTL != 0 {
 			return logical.ErrorResponse("'token_type' cannot be 'batch' when role is set to generate tokens with an explicit max TTL"), nil
 		}
+		if entry.MaxIdleTTL != 0 {
+			return logical.ErrorResponse("'token_type' cannot be 'batch' when role is set to generate tokens with a max idle TTL"), nil
+		}
 	}
 
 	allowedEntityAliasesRaw, ok := data.GetOk("allowed_entity_aliases")
@@ -3434,6 +4115,21 @@ no effect on the token being renewed.`
 	tokenRenewableHelp = `Tokens created via this role will be
 renewable or not according to this value.
 Defaults to "true".`
+	tokenMaxIdleTTLHelp = `If set, tokens created via this role
+are revoked once they have not been used
+for this long. Must be at least one minute;
+idle expiry is approximate to about a minute.
+Only applies to service tokens.`
+	tokenSearchHelp = `Search tokens by policy, role, creation path, entity,
+metadata and expiry.`
+	tokenSearchDesc = `
+Returns the accessors of the tokens matching all of the given criteria, along
+with their properties, sorted by accessor. Results are paginated: when more
+tokens match, the "next" value of the response is passed as "after" to fetch
+the following page. Batch tokens, which are not stored, are not returned.
+Because this can be used to cause a denial of service, this endpoint requires
+'sudo' capability in addition to 'update'.
+`
 	tokenListAccessorsHelp = `List token accessors, which can then be
 be used to iterate and discover their properties
 or revoke them. Because this can be used to
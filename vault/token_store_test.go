@@ -22,6 +22,7 @@ import (
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/helper/locksutil"
 	"github.com/hashicorp/vault/sdk/helper/parseutil"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
 	"github.com/hashicorp/vault/sdk/helper/tokenutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/mitchellh/mapstructure"
@@ -762,6 +763,124 @@ func TestTokenStore_HandleRequest_LookupAccessor(t *testing.T) {
 	}
 }
 
+func TestTokenStore_HandleRequest_Search(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	search := func(data map[string]interface{}) ([]string, string) {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "search")
+		req.Data = data
+		resp, err := ts.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v\nresp: %#v", err, resp)
+		}
+		keys, _ := resp.Data["keys"].([]string)
+		for _, key := range keys {
+			if _, ok := resp.Data["key_info"].(map[string]interface{})[key]; !ok {
+				t.Fatalf("missing key info for %q", key)
+			}
+		}
+		next, _ := resp.Data["next"].(string)
+		return keys, next
+	}
+
+	create := func(data map[string]interface{}) string {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "create")
+		req.ClientToken = root
+		req.Data = data
+		resp := testMakeTokenViaRequest(t, ts, req)
+		if resp.IsError() {
+			t.Fatalf("bad: %#v", resp)
+		}
+		return resp.Auth.Accessor
+	}
+
+	red := create(map[string]interface{}{
+		"policies": "foo",
+		"meta":     map[string]string{"team": "red"},
+		"ttl":      "1h",
+	})
+	blue := create(map[string]interface{}{
+		"policies": []string{"foo", "bar"},
+		"meta":     map[string]string{"team": "blue"},
+		"ttl":      "10m",
+	})
+
+	// Tokens created before the first search are indexed from storage
+	if keys, _ := search(map[string]interface{}{"policies": "foo"}); len(keys) != 2 {
+		t.Fatalf("bad: %v", keys)
+	}
+
+	// Tokens created afterwards are added to the index
+	req := logical.TestRequest(t, logical.UpdateOperation, "roles/test")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"allowed_policies": "foo",
+	}
+	if resp, err := ts.HandleRequest(namespace.RootContext(nil), req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	req = logical.TestRequest(t, logical.UpdateOperation, "create/test")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"policies": "foo",
+		"ttl":      "2h",
+	}
+	role := testMakeTokenViaRequest(t, ts, req).Auth.Accessor
+	create(map[string]interface{}{
+		"policies": "foo",
+		"type":     "batch",
+	})
+
+	testCases := []struct {
+		query    map[string]interface{}
+		expected []string
+	}{
+		{map[string]interface{}{"policies": "foo"}, []string{red, blue, role}},
+		{map[string]interface{}{"policies": "foo,bar"}, []string{blue}},
+		{map[string]interface{}{"meta": map[string]string{"team": "red"}}, []string{red}},
+		{map[string]interface{}{"role": "test"}, []string{role}},
+		{map[string]interface{}{"path_prefix": "auth/token/create/"}, []string{role}},
+		{map[string]interface{}{"max_ttl": "30m"}, []string{blue}},
+		{map[string]interface{}{"min_ttl": "90m", "policies": "foo"}, []string{role}},
+		{map[string]interface{}{"entity_id": "nonexistent"}, nil},
+	}
+	for i, tc := range testCases {
+		keys, next := search(tc.query)
+		sort.Strings(tc.expected)
+		if !reflect.DeepEqual(keys, tc.expected) || next != "" {
+			t.Fatalf("%d: expected %v, got %v (next %q)", i, tc.expected, keys, next)
+		}
+	}
+
+	// Results are paginated
+	keys, next := search(map[string]interface{}{"policies": "foo", "limit": 2})
+	if len(keys) != 2 || next != keys[1] {
+		t.Fatalf("bad: %v, next %q", keys, next)
+	}
+	rest, next := search(map[string]interface{}{"policies": "foo", "limit": 2, "after": next})
+	if len(rest) != 1 || next != "" {
+		t.Fatalf("bad: %v, next %q", rest, next)
+	}
+	if all := append(keys, rest...); !sort.StringsAreSorted(all) {
+		t.Fatalf("expected sorted results, got %v", all)
+	}
+
+	// Revoked tokens are removed from the index
+	req = logical.TestRequest(t, logical.UpdateOperation, "revoke-accessor")
+	req.Data = map[string]interface{}{
+		"accessor": red,
+	}
+	if resp, err := ts.HandleRequest(namespace.RootContext(nil), req); err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	if keys, _ := search(map[string]interface{}{"policies": "foo"}); len(keys) != 2 || strutil.StrListContains(keys, red) {
+		t.Fatalf("bad: %v", keys)
+	}
+}
+
 func TestTokenStore_HandleRequest_ListAccessors(t *testing.T) {
 	c, _, root := TestCoreUnsealed(t)
 	ts := c.tokenStore
@@ -1673,6 +1792,178 @@ func TestTokenStore_HandleRequest_CreateToken_DisplayName(t *testing.T) {
 	}
 }
 
+func TestTokenStore_HandleRequest_CreateToken_MFAValidated(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	policy, _ := ParseACLPolicy(namespace.RootNamespace, tokenCreationPolicy)
+	policy.Name = "test1"
+	if err := c.policyStore.SetPolicy(namespace.RootContext(nil), policy); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := &logical.TokenEntry{
+		Path:         "auth/userpass/login/foo",
+		Policies:     []string{"test1"},
+		TTL:          time.Hour,
+		MFAValidated: true,
+	}
+	testMakeTokenDirectly(t, ts, parent)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "auth/token/create")
+	req.ClientToken = parent.ID
+
+	resp, err := c.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+
+	out, err := ts.Lookup(namespace.RootContext(nil), resp.Auth.ClientToken)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !out.MFAValidated {
+		t.Fatalf("expected child token to be mfa validated")
+	}
+}
+
+func TestTokenStore_HandleRequest_CreateToken_ForgedMetaCondition(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+name = "conditioned"
+path "auth/token/create*" {
+	capabilities = ["update", "create", "sudo"]
+}
+path "secret/ci" {
+	capabilities = ["read"]
+	condition {
+		token_metadata = { source = "ci" }
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.policyStore.SetPolicy(namespace.RootContext(nil), policy); err != nil {
+		t.Fatal(err)
+	}
+
+	createChild := func(parent *logical.TokenEntry) string {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "auth/token/create")
+		req.ClientToken = parent.ID
+		req.Data = map[string]interface{}{
+			"meta": map[string]interface{}{"source": "ci"},
+		}
+		resp, err := c.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v\nresp: %#v", err, resp)
+		}
+		return resp.Auth.ClientToken
+	}
+	read := func(token string) error {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "secret/ci")
+		req.ClientToken = token
+		_, err := c.HandleRequest(namespace.RootContext(nil), req)
+		return err
+	}
+
+	// A child token created with forged meta is denied
+	human := &logical.TokenEntry{
+		Path:      "auth/userpass/login/foo",
+		Policies:  []string{"conditioned"},
+		TTL:       time.Hour,
+		Meta:      map[string]string{"source": "human"},
+		LoginMeta: map[string]string{"source": "human"},
+	}
+	testMakeTokenDirectly(t, ts, human)
+	if err := read(createChild(human)); err == nil || !errwrap.Contains(err, logical.ErrPermissionDenied.Error()) {
+		t.Fatalf("expected permission denied for forged meta, got: %v", err)
+	}
+
+	// A child token of a login with the metadata inherits it
+	ci := &logical.TokenEntry{
+		Path:      "auth/approle/login",
+		Policies:  []string{"conditioned"},
+		TTL:       time.Hour,
+		Meta:      map[string]string{"source": "ci"},
+		LoginMeta: map[string]string{"source": "ci"},
+	}
+	testMakeTokenDirectly(t, ts, ci)
+	if err := read(ci.ID); err != nil {
+		t.Fatalf("expected login token to be allowed, got: %v", err)
+	}
+	if err := read(createChild(ci)); err != nil {
+		t.Fatalf("expected child token to be allowed, got: %v", err)
+	}
+}
+
+func TestTokenStore_BatchToken_Conditions(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	policy, err := ParseACLPolicy(namespace.RootNamespace, `
+name = "conditioned"
+path "secret/ci" {
+	capabilities = ["read"]
+	condition {
+		token_metadata = { source = "ci" }
+		mfa_validated  = true
+	}
+}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.policyStore.SetPolicy(namespace.RootContext(nil), policy); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(token string) error {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, "secret/ci")
+		req.ClientToken = token
+		_, err := c.HandleRequest(namespace.RootContext(nil), req)
+		return err
+	}
+
+	// The login metadata and MFA validation are encoded in the batch token
+	ci := &logical.TokenEntry{
+		Path:         "auth/approle/login",
+		Policies:     []string{"conditioned"},
+		TTL:          time.Hour,
+		Type:         logical.TokenTypeBatch,
+		LoginMeta:    map[string]string{"source": "ci"},
+		MFAValidated: true,
+	}
+	testMakeTokenDirectly(t, ts, ci)
+	out, err := ts.Lookup(namespace.RootContext(nil), ci.ID)
+	if err != nil || out == nil {
+		t.Fatalf("err: %v\nout: %#v", err, out)
+	}
+	if !reflect.DeepEqual(out.LoginMeta, ci.LoginMeta) || !out.MFAValidated {
+		t.Fatalf("bad: login meta %#v, mfa validated %t", out.LoginMeta, out.MFAValidated)
+	}
+	if err := read(ci.ID); err != nil {
+		t.Fatalf("expected batch token to be allowed, got: %v", err)
+	}
+
+	notValidated := &logical.TokenEntry{
+		Path:      "auth/approle/login",
+		Policies:  []string{"conditioned"},
+		TTL:       time.Hour,
+		Type:      logical.TokenTypeBatch,
+		LoginMeta: map[string]string{"source": "ci"},
+	}
+	testMakeTokenDirectly(t, ts, notValidated)
+	if err := read(notValidated.ID); err == nil || !errwrap.Contains(err, logical.ErrPermissionDenied.Error()) {
+		t.Fatalf("expected permission denied without MFA validation, got: %v", err)
+	}
+}
+
 func TestTokenStore_HandleRequest_CreateToken_NumUses(t *testing.T) {
 	c, _, root := TestCoreUnsealed(t)
 	ts := c.tokenStore
@@ -2420,6 +2711,12 @@ func testTokenStore_HandleRequest_Lookup(t *testing.T, batch bool) {
 	}
 	delete(resp.Data, "creation_time")
 
+	// The root token was used to set up the core
+	if resp.Data["last_used_time"].(int64) == 0 {
+		t.Fatalf("last used time was zero")
+	}
+	delete(resp.Data, "last_used_time")
+
 	if !reflect.DeepEqual(resp.Data, exp) {
 		t.Fatalf("bad: expected:%#v\nactual:%#v", exp, resp.Data)
 	}
@@ -3654,6 +3951,244 @@ func TestTokenStore_RolePathSuffix(t *testing.T) {
 	}
 }
 
+func TestTokenStore_RoleMaxIdleTTL(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "roles/test")
+	req.ClientToken = root
+	req.Data = map[string]interface{}{
+		"orphan":       true,
+		"token_type":   "batch",
+		"renewable":    false,
+		"max_idle_ttl": "1h",
+	}
+	resp, err := ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error for a batch token role, got %#v", resp)
+	}
+
+	req.Data = map[string]interface{}{
+		"max_idle_ttl": "30s",
+	}
+	resp, err = ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected an error for a max idle TTL below the persist period, got %#v", resp)
+	}
+
+	req.Data = map[string]interface{}{
+		"max_idle_ttl": "1h",
+	}
+	resp, err = ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "roles/test")
+	resp, err = ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	if resp.Data["max_idle_ttl"] != int64(3600) {
+		t.Fatalf("bad: max_idle_ttl: %#v", resp.Data["max_idle_ttl"])
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "create/test")
+	req.ClientToken = root
+	resp, err = ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	token := resp.Auth.ClientToken
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "lookup")
+	req.Data = map[string]interface{}{
+		"token": token,
+	}
+	resp, err = ts.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp == nil || resp.IsError() {
+		t.Fatalf("err: %v\nresp: %#v", err, resp)
+	}
+	if resp.Data["max_idle_ttl"] != int64(3600) {
+		t.Fatalf("bad: max_idle_ttl: %#v", resp.Data["max_idle_ttl"])
+	}
+
+	// Using the token keeps it valid past its max idle TTL from creation
+	out, err := ts.Lookup(namespace.RootContext(nil), token)
+	if err != nil || out == nil {
+		t.Fatalf("err: %v\nout: %#v", err, out)
+	}
+	out.CreationTime = time.Now().Add(-2 * time.Hour).Unix()
+	if err := ts.store(namespace.RootContext(nil), out); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.RecordTokenUse(namespace.RootContext(nil), out); err != nil {
+		t.Fatal(err)
+	}
+	out, err = ts.Lookup(namespace.RootContext(nil), token)
+	if err != nil || out == nil {
+		t.Fatalf("err: %v\nout: %#v", err, out)
+	}
+
+	// Once idle for longer than the max idle TTL, the token is revoked
+	saltedID, err := ts.SaltID(namespace.RootContext(nil), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.lastUsed.Store(saltedID, &tokenUse{
+		namespace: namespace.RootNamespace,
+		time:      time.Now().Add(-2 * time.Hour),
+	})
+	out, err = ts.Lookup(namespace.RootContext(nil), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != nil {
+		t.Fatalf("expected idle token to be invalid, got %#v", out)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err = ts.lookupInternal(namespace.RootContext(nil), token, false, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the idle token to be revoked")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestTokenStore_LastUsed(t *testing.T) {
+	c, _, root := TestCoreUnsealed(t)
+	ts := c.tokenStore
+
+	testMakeServiceTokenViaBackend(t, ts, root, "tokenid", "60s", []string{"foo"})
+	out, err := ts.Lookup(namespace.RootContext(nil), "tokenid")
+	if err != nil || out == nil {
+		t.Fatalf("err: %v\nout: %#v", err, out)
+	}
+
+	lookupAccessor := func() *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "lookup-accessor")
+		req.Data = map[string]interface{}{
+			"accessor": out.Accessor,
+		}
+		resp, err := ts.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil || resp == nil || resp.IsError() {
+			t.Fatalf("err: %v\nresp: %#v", err, resp)
+		}
+		return resp
+	}
+
+	if _, ok := lookupAccessor().Data["last_used_time"]; ok {
+		t.Fatal("expected no last used time for an unused token")
+	}
+
+	// The use is reported before being persisted
+	if err := ts.RecordTokenUse(namespace.RootContext(nil), out); err != nil {
+		t.Fatal(err)
+	}
+	lastUsed, ok := lookupAccessor().Data["last_used_time"].(int64)
+	if !ok || time.Since(time.Unix(lastUsed, 0)) > time.Minute {
+		t.Fatalf("bad: last_used_time: %v", lastUsed)
+	}
+
+	if err := ts.persistLastUsed(namespace.RootContext(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	out, err = ts.Lookup(namespace.RootContext(nil), "tokenid")
+	if err != nil || out == nil {
+		t.Fatalf("err: %v\nout: %#v", err, out)
+	}
+	if out.LastUsedTime != lastUsed {
+		t.Fatalf("expected persisted last used time %d, got %d", lastUsed, out.LastUsedTime)
+	}
+
+	// Uses within the granularity of the persisted one are not written out
+	if err := ts.RecordTokenUse(namespace.RootContext(nil), out); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.persistLastUsed(namespace.RootContext(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	saltedID, err := ts.SaltID(namespace.RootContext(nil), "tokenid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ts.lastUsed.Load(saltedID); !ok {
+		t.Fatal("expected the recent use to be kept in memory")
+	}
+	if lastUsed, _ := lookupAccessor().Data["last_used_time"].(int64); lastUsed < out.LastUsedTime {
+		t.Fatalf("bad: last_used_time: %v", lastUsed)
+	}
+}
+
+func TestTokenStore_PersistLastUsed_Limits(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	ts := c.tokenStore
+	ts.lastUsed.Range(func(key, value interface{}) bool {
+		ts.lastUsed.Delete(key)
+		return true
+	})
+
+	// Uses which aren't due to be written out are skipped without a lookup,
+	// which would have dropped the records of these nonexistent tokens
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		ts.lastUsed.Store(fmt.Sprintf("notdue%d", i), &tokenUse{
+			namespace: namespace.RootNamespace,
+			time:      now,
+			persisted: now.Add(-time.Minute),
+		})
+	}
+
+	// The number of entries updated in a run is bounded
+	for i := 0; i < tokenLastUsedMaxWrites+5; i++ {
+		ts.lastUsed.Store(fmt.Sprintf("due%d", i), &tokenUse{
+			namespace: namespace.RootNamespace,
+			time:      now,
+		})
+	}
+
+	countRecords := func() (due, notDue int) {
+		ts.lastUsed.Range(func(key, value interface{}) bool {
+			if strings.HasPrefix(key.(string), "due") {
+				due++
+			} else {
+				notDue++
+			}
+			return true
+		})
+		return due, notDue
+	}
+
+	if err := ts.persistLastUsed(namespace.RootContext(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if due, notDue := countRecords(); due != 5 || notDue != 10 {
+		t.Fatalf("expected 5 due and 10 not due records, got %d and %d", due, notDue)
+	}
+
+	if err := ts.persistLastUsed(namespace.RootContext(nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if due, notDue := countRecords(); due != 0 || notDue != 10 {
+		t.Fatalf("expected 0 due and 10 not due records, got %d and %d", due, notDue)
+	}
+}
+
 func TestTokenStore_RolePeriod(t *testing.T) {
 	core, _, root := TestCoreUnsealed(t)
 
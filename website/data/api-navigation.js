@@ -40,6 +40,7 @@ export default [
         category: 'kv',
         content: ['kv-v1', 'kv-v2']
       },
+      { category: 'ldap' },
       {
         category: 'identity',
         content: [
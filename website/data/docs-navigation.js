@@ -84,7 +84,7 @@ export default [
       },
       {
         category: 'service-registration',
-        content: ['consul']
+        content: ['consul', 'kubernetes']
       },
       'telemetry',
       { category: 'ui' },
@@ -148,7 +148,7 @@ export default [
       },
       {
         category: 'policy',
-        content: ['delete', 'fmt', 'list', 'read', 'write']
+        content: ['delete', 'fmt', 'list', 'read', 'test', 'write']
       },
       'read',
       {
@@ -160,7 +160,7 @@ export default [
       'status',
       {
         category: 'token',
-        content: ['capabilities', 'create', 'lookup', 'renew', 'revoke']
+        content: ['capabilities', 'create', 'list', 'lookup', 'renew', 'revoke']
       },
       'unwrap',
       'version',
@@ -191,12 +191,13 @@ export default [
           },
           {
             category: 'sinks',
-            content: ['file']
+            content: ['file', 'kubernetes', 'socket']
           }
         ]
       },
       { category: 'caching' },
-      { category: 'template' }
+      { category: 'template' },
+      { category: 'exec' }
     ]
   },
   '----------------',
@@ -231,6 +232,7 @@ export default [
         category: 'kv',
         content: ['kv-v1', 'kv-v2']
       },
+      { category: 'ldap' },
       { category: 'identity' },
       { category: 'nomad' },
       { category: 'pki' },